@@ -0,0 +1,57 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"a2sv.org/hub/Delivery/http/schemas"
+	"a2sv.org/hub/usecases"
+	"github.com/gin-gonic/gin"
+)
+
+// AlumniHandler handles HTTP requests for the alumni transition
+type AlumniHandler struct {
+	alumniUseCase *usecases.AlumniUseCase
+}
+
+// NewAlumniHandler creates a new AlumniHandler instance
+func NewAlumniHandler(alumniUseCase *usecases.AlumniUseCase) *AlumniHandler {
+	return &AlumniHandler{
+		alumniUseCase: alumniUseCase,
+	}
+}
+
+// PreviewTransition handles listing the users the next alumni transition will move
+// @Summary Preview the alumni transition
+// @Description List the students whose expected graduation date will have passed at the next scheduled run. That run gives them the alumni role and detaches them from their group.
+// @Tags users
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Success 200 {object} schemas.SuccessResponse{data=schemas.AlumniPreviewResponse} "Alumni transition preview"
+// @Failure 401 {object} schemas.ErrorResponse "Unauthorized - Invalid or missing token"
+// @Failure 403 {object} schemas.ErrorResponse "Forbidden - Insufficient permissions"
+// @Failure 500 {object} schemas.ErrorResponse "Internal server error"
+// @Failure 503 {object} schemas.ErrorResponse "The alumni transition is not configured"
+// @Router /api/users/alumni/preview [get]
+func (h *AlumniHandler) PreviewTransition(c *gin.Context) {
+	preview, err := h.alumniUseCase.Preview()
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, usecases.ErrAlumniNotConfigured) {
+			status = http.StatusServiceUnavailable
+		}
+		c.JSON(status, schemas.ErrorResponse{
+			Code:    status,
+			Message: "Failed to preview the alumni transition",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, schemas.SuccessResponse{
+		Success: true,
+		Code:    http.StatusOK,
+		Message: "Alumni transition preview",
+		Data:    preview,
+	})
+}
@@ -0,0 +1,154 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"a2sv.org/hub/Delivery/http/schemas"
+	"a2sv.org/hub/usecases"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// APITokenHandler handles HTTP requests for personal API tokens
+type APITokenHandler struct {
+	apiTokenUseCase *usecases.APITokenUseCase
+}
+
+// NewAPITokenHandler creates a new APITokenHandler instance
+func NewAPITokenHandler(apiTokenUseCase *usecases.APITokenUseCase) *APITokenHandler {
+	return &APITokenHandler{
+		apiTokenUseCase: apiTokenUseCase,
+	}
+}
+
+// CreateAPIToken handles creating a personal API token
+// @Summary Create API token
+// @Description Create a named personal API token for scripts and extensions. Scopes are "read", "submission:write", "vote:write", "recent_action:write", "post:write" or any role permission. The token is only shown in this response.
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param request body schemas.CreateAPITokenRequest true "Token details"
+// @Success 201 {object} schemas.SuccessResponse "API token created successfully"
+// @Failure 400 {object} schemas.ErrorResponse "Invalid request format or unknown scope"
+// @Failure 401 {object} schemas.ErrorResponse "Unauthorized - Invalid or missing token"
+// @Failure 403 {object} schemas.ErrorResponse "Called with an API token"
+// @Failure 500 {object} schemas.ErrorResponse "Internal server error"
+// @Router /api/users/me/tokens [post]
+func (h *APITokenHandler) CreateAPIToken(c *gin.Context) {
+	var input schemas.CreateAPITokenRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, schemas.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: "Invalid request format",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	token, err := h.apiTokenUseCase.Create(currentUserID(c), &input)
+	if err != nil {
+		if errors.Is(err, usecases.ErrUnknownScope) || errors.Is(err, usecases.ErrInvalidAPIToken) {
+			c.JSON(http.StatusBadRequest, schemas.ErrorResponse{
+				Code:    http.StatusBadRequest,
+				Message: "Invalid API token",
+				Details: err.Error(),
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, schemas.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to create API token",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, schemas.SuccessResponse{
+		Success: true,
+		Code:    http.StatusCreated,
+		Message: "API token created successfully",
+		Data:    token,
+	})
+}
+
+// ListAPITokens handles listing the caller's personal API tokens
+// @Summary List API tokens
+// @Description List the caller's personal API tokens without their secrets
+// @Tags users
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Success 200 {object} schemas.SuccessResponse "API tokens retrieved successfully"
+// @Failure 401 {object} schemas.ErrorResponse "Unauthorized - Invalid or missing token"
+// @Failure 403 {object} schemas.ErrorResponse "Called with an API token"
+// @Failure 500 {object} schemas.ErrorResponse "Internal server error"
+// @Router /api/users/me/tokens [get]
+func (h *APITokenHandler) ListAPITokens(c *gin.Context) {
+	tokens, err := h.apiTokenUseCase.List(currentUserID(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, schemas.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to list API tokens",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, schemas.SuccessResponse{
+		Success: true,
+		Code:    http.StatusOK,
+		Message: "API tokens retrieved successfully",
+		Data:    tokens,
+	})
+}
+
+// RevokeAPIToken handles revoking one of the caller's personal API tokens
+// @Summary Revoke API token
+// @Description Revoke one of the caller's personal API tokens. It is rejected from the next request on.
+// @Tags users
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param token_id path int true "API token ID" minimum(1)
+// @Success 200 {object} schemas.SuccessResponse "API token revoked successfully"
+// @Failure 400 {object} schemas.ErrorResponse "Invalid token ID format"
+// @Failure 401 {object} schemas.ErrorResponse "Unauthorized - Invalid or missing token"
+// @Failure 403 {object} schemas.ErrorResponse "Called with an API token"
+// @Failure 404 {object} schemas.ErrorResponse "API token not found"
+// @Failure 500 {object} schemas.ErrorResponse "Internal server error"
+// @Router /api/users/me/tokens/{token_id} [delete]
+func (h *APITokenHandler) RevokeAPIToken(c *gin.Context) {
+	tokenID, err := strconv.ParseUint(c.Param("token_id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, schemas.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: "Invalid token ID",
+			Details: "Token ID must be a positive integer",
+		})
+		return
+	}
+
+	if err := h.apiTokenUseCase.Revoke(currentUserID(c), uint(tokenID)); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, schemas.ErrorResponse{
+				Code:    http.StatusNotFound,
+				Message: "API token not found",
+				Details: err.Error(),
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, schemas.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to revoke API token",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, schemas.SuccessResponse{
+		Success: true,
+		Code:    http.StatusOK,
+		Message: "API token revoked successfully",
+	})
+}
@@ -0,0 +1,70 @@
+package handlers
+
+import (
+	"net/http"
+
+	"a2sv.org/hub/Delivery/http/schemas"
+	"a2sv.org/hub/usecases"
+	"github.com/gin-gonic/gin"
+)
+
+// AuditLogHandler handles HTTP requests for the audit log
+type AuditLogHandler struct {
+	auditUseCase *usecases.AuditUseCase
+}
+
+// NewAuditLogHandler creates a new AuditLogHandler instance
+func NewAuditLogHandler(auditUseCase *usecases.AuditUseCase) *AuditLogHandler {
+	return &AuditLogHandler{
+		auditUseCase: auditUseCase,
+	}
+}
+
+// ListAuditLogs handles searching the audit log
+// @Summary Search the audit log
+// @Description List audited create, update and delete calls, newest first. Each entry has the actor, the route, the entity and a JSON diff of the changed columns. The log is read-only.
+// @Tags audit
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param actor_id query int false "Filter by actor user ID"
+// @Param entity_type query string false "Filter by entity type, e.g. stipends or users"
+// @Param entity_id query string false "Filter by entity ID"
+// @Param action query string false "Filter by action" Enums(create, update, delete)
+// @Param from query string false "Only entries at or after this RFC 3339 time"
+// @Param to query string false "Only entries before this RFC 3339 time"
+// @Param page query int false "Page number" default(1) minimum(1)
+// @Param page_size query int false "Items per page" default(20) minimum(1) maximum(100)
+// @Success 200 {object} schemas.SuccessResponse{data=schemas.AuditLogListResponse} "Audit log retrieved successfully"
+// @Failure 400 {object} schemas.ErrorResponse "Invalid query parameters"
+// @Failure 401 {object} schemas.ErrorResponse "Unauthorized - Invalid or missing token"
+// @Failure 403 {object} schemas.ErrorResponse "Forbidden - Insufficient permissions"
+// @Failure 500 {object} schemas.ErrorResponse "Internal server error"
+// @Router /api/audit-logs [get]
+func (h *AuditLogHandler) ListAuditLogs(c *gin.Context) {
+	var query schemas.AuditLogQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, schemas.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: "Invalid query parameters",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	logs, err := h.auditUseCase.List(&query)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, schemas.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to list audit logs",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, schemas.SuccessResponse{
+		Success: true,
+		Code:    http.StatusOK,
+		Message: "Audit log retrieved successfully",
+		Data:    logs,
+	})
+}
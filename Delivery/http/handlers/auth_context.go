@@ -0,0 +1,47 @@
+package handlers
+
+import (
+	"errors"
+	"math"
+	"net/http"
+	"strconv"
+
+	"a2sv.org/hub/Delivery/http/middleware"
+	"a2sv.org/hub/Delivery/http/schemas"
+	"a2sv.org/hub/infrastructure/ip_services"
+	"a2sv.org/hub/usecases"
+	"github.com/gin-gonic/gin"
+)
+
+// currentUserID returns the ID of the authenticated user, or 0 when the request is unauthenticated
+func currentUserID(c *gin.Context) uint {
+	claims, ok := middleware.CurrentClaims(c)
+	if !ok {
+		return 0
+	}
+	return claims.ID
+}
+
+// loginClient describes the client making a login request
+func loginClient(c *gin.Context) usecases.LoginClient {
+	return usecases.LoginClient{
+		IP:     ip_services.GetClientIP(c),
+		Device: c.GetHeader("User-Agent"),
+	}
+}
+
+// respondLockedOut answers 429 with Retry-After when err is a login lockout.
+// It reports whether a response was written.
+func respondLockedOut(c *gin.Context, err error) bool {
+	var lockErr *usecases.LockoutError
+	if !errors.As(err, &lockErr) {
+		return false
+	}
+	c.Header("Retry-After", strconv.Itoa(int(math.Ceil(lockErr.RetryAfter.Seconds()))))
+	c.JSON(http.StatusTooManyRequests, schemas.ErrorResponse{
+		Code:    http.StatusTooManyRequests,
+		Message: "Too many failed logins",
+		Details: err.Error(),
+	})
+	return true
+}
@@ -1,123 +1,232 @@
 package handlers
 
 import (
-	"fmt"
+	"errors"
 	"net/http"
-	"os"
-	"time"
+	"strings"
 
 	"a2sv.org/hub/Delivery/http/schemas"
-	"a2sv.org/hub/Domain/entity"
-	"a2sv.org/hub/infrastructure/oauth"
-	"a2sv.org/hub/infrastructure/token_services"
+	"a2sv.org/hub/usecases"
 	"github.com/gin-gonic/gin"
 )
 
-// UserRepo is a global variable for accessing user data.
-// Ensure it is set in main.go.
-var UserRepo interface {
-	GetUserByEmail(email string) (*entity.User, error)
+// oauthCookiePrefix names the cookie holding the nonce and PKCE verifier
+// between the redirect and the callback; the provider name is appended
+const oauthCookiePrefix = "oauth_"
+
+// OAuthHandler handles the OAuth sign-in flows
+type OAuthHandler struct {
+	oauthUseCase *usecases.OAuthUseCase
 }
 
-// InitGoogleOAuth initiates the Google OAuth flow
-// @Summary Start Google OAuth
-// @Description Initiates the OAuth2 flow by redirecting to Google's authentication page
-// @Tags auth
-// @Accept json
-// @Produce json
-// @Success 302 {object} schemas.SuccessResponse "Redirect to Google OAuth"
-// @Failure 500 {object} schemas.ErrorResponse "Failed to initiate OAuth flow"
-// @Router /api/auth/google [get]
-func InitGoogleOAuth(c *gin.Context) {
-	url := fmt.Sprintf(
-		"https://accounts.google.com/o/oauth2/auth?client_id=%s&redirect_uri=%s&response_type=code&scope=email profile",
-		os.Getenv("GOOGLE_OAUTH_CLIENT_ID"),
-		os.Getenv("GOOGLE_OAUTH_REDIRECT_URL"),
-	)
-	c.Redirect(http.StatusTemporaryRedirect, url)
+// NewOAuthHandler creates a new OAuthHandler instance
+func NewOAuthHandler(oauthUseCase *usecases.OAuthUseCase) *OAuthHandler {
+	return &OAuthHandler{
+		oauthUseCase: oauthUseCase,
+	}
 }
 
-// HandleGoogleCallback handles the OAuth callback and only allows login for registered users
-// @Summary Handle Google OAuth callback
-// @Description Process Google OAuth callback, verify user, and return JWT token
+// InitOAuth initiates the OAuth flow of a provider
+// @Summary Start OAuth sign-in
+// @Description Initiates the OAuth2 flow by redirecting to the provider's authentication page with a signed state and a PKCE challenge. The matching nonce and verifier are kept in an HttpOnly cookie.
 // @Tags auth
 // @Accept json
 // @Produce json
-// @Param code query string true "OAuth2 authorization code from Google"
-// @Param state query string false "OAuth state for CSRF protection"
-// @Param error query string false "Error message from OAuth provider"
-// @Success 200 {object} schemas.SuccessResponse "Authentication successful"
-// @Failure 400 {object} schemas.ErrorResponse "Missing or invalid authorization code"
-// @Failure 401 {object} schemas.ErrorResponse "User not registered or email not verified"
-// @Failure 403 {object} schemas.ErrorResponse "Invalid OAuth state"
-// @Failure 500 {object} schemas.ErrorResponse "Internal server error"
-// @Router /api/auth/google/callback [get]
-func HandleGoogleCallback(c *gin.Context) {
-	code := c.Query("code")
-	if code == "" {
-		c.JSON(http.StatusBadRequest, schemas.ErrorResponse{
-			Code:    http.StatusBadRequest,
-			Message: "Missing authorization code",
-			Details: "The authorization code from Google is required",
+// @Param provider path string true "Provider name, e.g. google or github"
+// @Success 307 {object} schemas.SuccessResponse "Redirect to the provider"
+// @Failure 404 {object} schemas.ErrorResponse "Unknown provider"
+// @Failure 500 {object} schemas.ErrorResponse "Failed to initiate OAuth flow"
+// @Router /api/auth/{provider} [get]
+func (h *OAuthHandler) InitOAuth(c *gin.Context) {
+	provider := c.Param("provider")
+	flow, err := h.oauthUseCase.StartLogin(provider)
+	if errors.Is(err, usecases.ErrUnknownOAuthProvider) {
+		c.JSON(http.StatusNotFound, schemas.ErrorResponse{
+			Code:    http.StatusNotFound,
+			Message: "Unknown OAuth provider",
+			Details: err.Error(),
 		})
 		return
 	}
-
-	// Exchange the authorization code for an access token
-	token, err := oauth.ExchangeCodeForToken(code)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, schemas.ErrorResponse{
 			Code:    http.StatusInternalServerError,
-			Message: "Token exchange failed",
+			Message: "Failed to initiate OAuth flow",
 			Details: err.Error(),
 		})
 		return
 	}
 
-	// Retrieve user info from Google
-	userInfo, err := oauth.GetUserInfo(token.AccessToken)
+	setOAuthCookie(c, provider, flow)
+	c.Redirect(http.StatusTemporaryRedirect, flow.AuthURL)
+}
+
+// LinkOAuth starts linking an account of a provider to the caller
+// @Summary Link an OAuth account
+// @Description Start linking an account of the provider to the caller, for accounts whose verified email differs from the caller's. The response holds the provider page to open in the same browser; the nonce and PKCE verifier are kept in an HttpOnly cookie, and the callback links the account the caller signs in to there.
+// @Tags users
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param provider path string true "Provider name, e.g. google or github"
+// @Success 200 {object} schemas.SuccessResponse{data=schemas.OAuthLinkResponse} "Open the provider page"
+// @Failure 401 {object} schemas.ErrorResponse "Unauthorized - Invalid or missing token"
+// @Failure 403 {object} schemas.ErrorResponse "Called with an API token"
+// @Failure 404 {object} schemas.ErrorResponse "Unknown provider"
+// @Failure 409 {object} schemas.ErrorResponse "Already linked to an account of the provider"
+// @Failure 500 {object} schemas.ErrorResponse "Internal server error"
+// @Router /api/users/me/oauth/{provider} [post]
+func (h *OAuthHandler) LinkOAuth(c *gin.Context) {
+	provider := c.Param("provider")
+	flow, err := h.oauthUseCase.StartLink(currentUserID(c), provider)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, schemas.ErrorResponse{
-			Code:    http.StatusInternalServerError,
-			Message: "Failed to retrieve user info",
-			Details: err.Error(),
-		})
+		switch {
+		case errors.Is(err, usecases.ErrUnknownOAuthProvider):
+			c.JSON(http.StatusNotFound, schemas.ErrorResponse{
+				Code:    http.StatusNotFound,
+				Message: "Unknown OAuth provider",
+				Details: err.Error(),
+			})
+		case errors.Is(err, usecases.ErrOAuthAccountConflict):
+			c.JSON(http.StatusConflict, schemas.ErrorResponse{
+				Code:    http.StatusConflict,
+				Message: "OAuth account conflict",
+				Details: err.Error(),
+			})
+		default:
+			c.JSON(http.StatusInternalServerError, schemas.ErrorResponse{
+				Code:    http.StatusInternalServerError,
+				Message: "Failed to initiate OAuth flow",
+				Details: err.Error(),
+			})
+		}
 		return
 	}
 
-	email, ok := userInfo["email"].(string)
-	if !ok || email == "" {
-		c.JSON(http.StatusInternalServerError, schemas.ErrorResponse{
-			Code:    http.StatusInternalServerError,
-			Message: "Invalid email in Google response",
-			Details: "Email field is missing or invalid in Google user info",
+	setOAuthCookie(c, provider, flow)
+	c.JSON(http.StatusOK, schemas.SuccessResponse{
+		Success: true,
+		Code:    http.StatusOK,
+		Message: "Open the provider page to link the account",
+		Data:    schemas.OAuthLinkResponse{AuthURL: flow.AuthURL},
+	})
+}
+
+// HandleOAuthCallback handles the OAuth callback and only allows login for registered users
+// @Summary Handle OAuth callback
+// @Description Verify the OAuth state and PKCE verifier, sign in the user linked to the provider account and return a token pair. The first sign-in links the account to the registered user with the same verified email. A flow started by POST /api/users/me/oauth/{provider} links the account to that user instead and returns no token pair.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param provider path string true "Provider name, e.g. google or github"
+// @Param code query string true "OAuth2 authorization code from the provider"
+// @Param state query string true "OAuth state for CSRF protection"
+// @Param error query string false "Error message from OAuth provider"
+// @Success 200 {object} schemas.SuccessResponse "Authentication successful"
+// @Failure 400 {object} schemas.ErrorResponse "Missing or invalid authorization code"
+// @Failure 401 {object} schemas.ErrorResponse "User not registered or email not verified"
+// @Failure 403 {object} schemas.ErrorResponse "Invalid OAuth state or account deactivated"
+// @Failure 404 {object} schemas.ErrorResponse "Unknown provider"
+// @Failure 409 {object} schemas.ErrorResponse "User already linked to another account of the provider, or the account linked to another user"
+// @Failure 500 {object} schemas.ErrorResponse "Internal server error"
+// @Router /api/auth/{provider}/callback [get]
+func (h *OAuthHandler) HandleOAuthCallback(c *gin.Context) {
+	provider := c.Param("provider")
+	if providerError := c.Query("error"); providerError != "" {
+		c.JSON(http.StatusBadRequest, schemas.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: "OAuth sign-in was not completed",
+			Details: providerError,
 		})
 		return
 	}
 
-	user, err := UserRepo.GetUserByEmail(email)
-	if err != nil || user == nil {
-		c.JSON(http.StatusUnauthorized, schemas.ErrorResponse{
-			Code:    http.StatusUnauthorized,
-			Message: "User not registered or email not verified",
-			Details: "User not found or not verified in the system",
+	code := c.Query("code")
+	if code == "" {
+		c.JSON(http.StatusBadRequest, schemas.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: "Missing authorization code",
+			Details: "The authorization code from the provider is required",
 		})
 		return
 	}
 
-	// Generate JWT token for the user
-	jwtToken, err := token_services.CreateJWTToken(user, os.Getenv("JWT_SECRET"), 24*30*time.Hour)
+	// The cookie is single-use whatever the outcome
+	cookie, _ := c.Cookie(oauthCookiePrefix + provider)
+	c.SetCookie(oauthCookiePrefix+provider, "", -1, "/api/auth/"+provider, "", isSecureRequest(c), true)
+	nonce, verifier, _ := strings.Cut(cookie, ".")
+
+	tokens, err := h.oauthUseCase.CompleteLogin(provider, code, c.Query("state"), nonce, verifier, loginClient(c))
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, schemas.ErrorResponse{
-			Code:    http.StatusInternalServerError,
-			Message: "Failed to generate JWT token",
-			Details: err.Error(),
+		switch {
+		case errors.Is(err, usecases.ErrUnknownOAuthProvider):
+			c.JSON(http.StatusNotFound, schemas.ErrorResponse{
+				Code:    http.StatusNotFound,
+				Message: "Unknown OAuth provider",
+				Details: err.Error(),
+			})
+		case errors.Is(err, usecases.ErrInvalidOAuthState):
+			c.JSON(http.StatusForbidden, schemas.ErrorResponse{
+				Code:    http.StatusForbidden,
+				Message: "Invalid OAuth state",
+				Details: err.Error(),
+			})
+		case errors.Is(err, usecases.ErrOAuthEmailNotVerified), errors.Is(err, usecases.ErrOAuthUserNotRegistered):
+			c.JSON(http.StatusUnauthorized, schemas.ErrorResponse{
+				Code:    http.StatusUnauthorized,
+				Message: "User not registered or email not verified",
+				Details: err.Error(),
+			})
+		case errors.Is(err, usecases.ErrAccountInactive):
+			c.JSON(http.StatusForbidden, schemas.ErrorResponse{
+				Code:    http.StatusForbidden,
+				Message: "Account deactivated",
+				Details: err.Error(),
+			})
+		case errors.Is(err, usecases.ErrOAuthAccountConflict), errors.Is(err, usecases.ErrOAuthAccountTaken):
+			c.JSON(http.StatusConflict, schemas.ErrorResponse{
+				Code:    http.StatusConflict,
+				Message: "OAuth account conflict",
+				Details: err.Error(),
+			})
+		default:
+			c.JSON(http.StatusInternalServerError, schemas.ErrorResponse{
+				Code:    http.StatusInternalServerError,
+				Message: "OAuth sign-in failed",
+				Details: err.Error(),
+			})
+		}
+		return
+	}
+
+	if tokens == nil {
+		c.JSON(http.StatusOK, schemas.SuccessResponse{
+			Success: true,
+			Code:    http.StatusOK,
+			Message: "OAuth account linked",
 		})
 		return
 	}
 
+	message := "Login successful"
+	if tokens.TwoFactor != nil {
+		message = "Two-factor authentication required"
+	}
 	c.JSON(http.StatusOK, schemas.SuccessResponse{
-		Message: "Login successful",
-		Data:    jwtToken,
+		Success: true,
+		Code:    http.StatusOK,
+		Message: message,
+		Data:    tokens,
 	})
 }
+
+// setOAuthCookie keeps the nonce and PKCE verifier of a flow for its callback
+func setOAuthCookie(c *gin.Context, provider string, flow *usecases.OAuthFlow) {
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(oauthCookiePrefix+provider, flow.Nonce+"."+flow.CodeVerifier, int(usecases.OAuthStateDuration.Seconds()),
+		"/api/auth/"+provider, "", isSecureRequest(c), true)
+}
+
+// isSecureRequest reports whether the client reached us over HTTPS, directly or through a proxy
+func isSecureRequest(c *gin.Context) bool {
+	return c.Request.TLS != nil || c.GetHeader("X-Forwarded-Proto") == "https"
+}
@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
 
@@ -32,7 +33,11 @@ func (h *ExerciseHandler) CreateExercise(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, schemas.ErrorResponse{Message: err.Error()})
 		return
 	}
-	if err := h.Usecase.Create(&exercise); err != nil {
+	if err := h.Usecase.Create(currentUserID(c), &exercise); err != nil {
+		if errors.Is(err, usecases.ErrOutOfScope) {
+			c.JSON(http.StatusForbidden, schemas.ErrorResponse{Code: 403, Message: err.Error()})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, schemas.ErrorResponse{Message: err.Error()})
 		return
 	}
@@ -136,7 +141,11 @@ func (h *ExerciseHandler) UpdateExercise(c *gin.Context) {
 		return
 	}
 	exercise.ID = uint(id)
-	if err := h.Usecase.Update(&exercise); err != nil {
+	if err := h.Usecase.Update(currentUserID(c), &exercise); err != nil {
+		if errors.Is(err, usecases.ErrOutOfScope) {
+			c.JSON(http.StatusForbidden, schemas.ErrorResponse{Code: 403, Message: err.Error()})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, schemas.ErrorResponse{Message: err.Error()})
 		return
 	}
@@ -160,7 +169,11 @@ func (h *ExerciseHandler) DeleteExercise(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, schemas.ErrorResponse{Message: "invalid id"})
 		return
 	}
-	if err := h.Usecase.Delete(uint(id)); err != nil {
+	if err := h.Usecase.Delete(currentUserID(c), uint(id)); err != nil {
+		if errors.Is(err, usecases.ErrOutOfScope) {
+			c.JSON(http.StatusForbidden, schemas.ErrorResponse{Code: 403, Message: err.Error()})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, schemas.ErrorResponse{Message: err.Error()})
 		return
 	}
@@ -0,0 +1,419 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"a2sv.org/hub/Delivery/http/schemas"
+	"a2sv.org/hub/infrastructure/storage_services"
+	"a2sv.org/hub/usecases"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// FileHandler handles HTTP requests for uploaded files
+type FileHandler struct {
+	fileUseCase *usecases.FileUseCase
+}
+
+// NewFileHandler creates a new FileHandler instance
+func NewFileHandler(fileUseCase *usecases.FileUseCase) *FileHandler {
+	return &FileHandler{
+		fileUseCase: fileUseCase,
+	}
+}
+
+// UploadPhoto handles replacing a user's profile photo
+// @Summary Upload a profile photo
+// @Description Replace the user's profile photo with a JPEG, PNG or GIF of at most 5 MB. The image is scaled down to fit in 512x512 and stored as a JPEG. Users can change their own photo; changing another user's needs user:write and the user's group in scope.
+// @Tags users
+// @Accept multipart/form-data
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param id path int true "User ID"
+// @Param file formData file true "Image"
+// @Success 200 {object} schemas.SuccessResponse{data=schemas.FileURLResponse} "Photo uploaded"
+// @Failure 400 {object} schemas.ErrorResponse "Invalid file"
+// @Failure 401 {object} schemas.ErrorResponse "Unauthorized - Invalid or missing token"
+// @Failure 403 {object} schemas.ErrorResponse "Forbidden - Insufficient permissions"
+// @Failure 404 {object} schemas.ErrorResponse "User not found"
+// @Failure 413 {object} schemas.ErrorResponse "File too large"
+// @Failure 500 {object} schemas.ErrorResponse "Internal server error"
+// @Router /api/users/{id}/photo [put]
+func (h *FileHandler) UploadPhoto(c *gin.Context) {
+	h.uploadUserFile(c, "Photo uploaded", h.fileUseCase.SetPhoto)
+}
+
+// DeletePhoto handles removing a user's profile photo
+// @Summary Remove a profile photo
+// @Description Remove the user's profile photo
+// @Tags users
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param id path int true "User ID"
+// @Success 200 {object} schemas.SuccessResponse "Photo removed"
+// @Failure 400 {object} schemas.ErrorResponse "Invalid user ID"
+// @Failure 401 {object} schemas.ErrorResponse "Unauthorized - Invalid or missing token"
+// @Failure 403 {object} schemas.ErrorResponse "Forbidden - Insufficient permissions"
+// @Failure 404 {object} schemas.ErrorResponse "User not found"
+// @Failure 500 {object} schemas.ErrorResponse "Internal server error"
+// @Router /api/users/{id}/photo [delete]
+func (h *FileHandler) DeletePhoto(c *gin.Context) {
+	h.removeUserFile(c, "Photo removed", h.fileUseCase.RemovePhoto)
+}
+
+// UploadCV handles replacing a user's CV
+// @Summary Upload a CV
+// @Description Replace the user's CV with a PDF of at most 5 MB. Users can change their own CV; changing another user's needs user:write and the user's group in scope.
+// @Tags users
+// @Accept multipart/form-data
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param id path int true "User ID"
+// @Param file formData file true "PDF"
+// @Success 200 {object} schemas.SuccessResponse{data=schemas.FileURLResponse} "CV uploaded"
+// @Failure 400 {object} schemas.ErrorResponse "Invalid file"
+// @Failure 401 {object} schemas.ErrorResponse "Unauthorized - Invalid or missing token"
+// @Failure 403 {object} schemas.ErrorResponse "Forbidden - Insufficient permissions"
+// @Failure 404 {object} schemas.ErrorResponse "User not found"
+// @Failure 413 {object} schemas.ErrorResponse "File too large"
+// @Failure 500 {object} schemas.ErrorResponse "Internal server error"
+// @Router /api/users/{id}/cv [put]
+func (h *FileHandler) UploadCV(c *gin.Context) {
+	h.uploadUserFile(c, "CV uploaded", h.fileUseCase.SetCV)
+}
+
+// DeleteCV handles removing a user's CV
+// @Summary Remove a CV
+// @Description Remove the user's CV
+// @Tags users
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param id path int true "User ID"
+// @Success 200 {object} schemas.SuccessResponse "CV removed"
+// @Failure 400 {object} schemas.ErrorResponse "Invalid user ID"
+// @Failure 401 {object} schemas.ErrorResponse "Unauthorized - Invalid or missing token"
+// @Failure 403 {object} schemas.ErrorResponse "Forbidden - Insufficient permissions"
+// @Failure 404 {object} schemas.ErrorResponse "User not found"
+// @Failure 500 {object} schemas.ErrorResponse "Internal server error"
+// @Router /api/users/{id}/cv [delete]
+func (h *FileHandler) DeleteCV(c *gin.Context) {
+	h.removeUserFile(c, "CV removed", h.fileUseCase.RemoveCV)
+}
+
+func (h *FileHandler) uploadUserFile(c *gin.Context, message string, set func(actorID, userID uint, r io.Reader) (*schemas.FileURLResponse, error)) {
+	userID, ok := userIDParam(c)
+	if !ok {
+		return
+	}
+	file, _, ok := openUpload(c)
+	if !ok {
+		return
+	}
+	defer file.Close()
+
+	result, err := set(currentUserID(c), userID, file)
+	if err != nil {
+		respondFileError(c, err, "Failed to upload the file")
+		return
+	}
+
+	c.JSON(http.StatusOK, schemas.SuccessResponse{
+		Success: true,
+		Code:    http.StatusOK,
+		Message: message,
+		Data:    result,
+	})
+}
+
+func (h *FileHandler) removeUserFile(c *gin.Context, message string, remove func(actorID, userID uint) error) {
+	userID, ok := userIDParam(c)
+	if !ok {
+		return
+	}
+	if err := remove(currentUserID(c), userID); err != nil {
+		respondFileError(c, err, "Failed to remove the file")
+		return
+	}
+
+	c.JSON(http.StatusOK, schemas.SuccessResponse{
+		Success: true,
+		Code:    http.StatusOK,
+		Message: message,
+	})
+}
+
+// DownloadFile handles downloads through the signed URLs of the local storage
+// @Summary Download an uploaded file
+// @Description Download a file through a signed URL returned by the API. The URL itself authorizes the download until it expires, so no token is needed. Only used when files are stored on the local filesystem; S3 serves its signed URLs directly.
+// @Tags files
+// @Produce octet-stream
+// @Param key path string true "File key"
+// @Param expires query int true "Expiry of the URL, in Unix time"
+// @Param signature query string true "Signature of the URL"
+// @Success 200 {file} file "File"
+// @Failure 403 {object} schemas.ErrorResponse "Invalid or expired URL"
+// @Failure 404 {object} schemas.ErrorResponse "File not found"
+// @Failure 500 {object} schemas.ErrorResponse "Internal server error"
+// @Router /api/files/{key} [get]
+func (h *FileHandler) DownloadFile(c *gin.Context) {
+	key := strings.TrimPrefix(c.Param("key"), "/")
+	file, contentType, err := h.fileUseCase.Open(key, c.Query("expires"), c.Query("signature"))
+	if err != nil {
+		respondFileError(c, err, "Failed to download the file")
+		return
+	}
+	defer file.Close()
+
+	c.Header("X-Content-Type-Options", "nosniff")
+	c.Header("Cache-Control", "private, max-age=900")
+	c.DataFromReader(http.StatusOK, -1, contentType, file, nil)
+}
+
+// UploadSessionResource handles attaching a file to a session's resources
+// @Summary Upload a session resource
+// @Description Upload an image, PDF, ZIP, text or Office document of at most 25 MB to the session's resources. Needs session:write and every group of the session in scope.
+// @Tags sessions
+// @Accept multipart/form-data
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param id path int true "Session ID"
+// @Param file formData file true "File"
+// @Success 201 {object} schemas.SuccessResponse{data=schemas.AttachmentResponse} "Resource uploaded"
+// @Failure 400 {object} schemas.ErrorResponse "Invalid file"
+// @Failure 401 {object} schemas.ErrorResponse "Unauthorized - Invalid or missing token"
+// @Failure 403 {object} schemas.ErrorResponse "Forbidden - Insufficient permissions"
+// @Failure 404 {object} schemas.ErrorResponse "Session not found"
+// @Failure 413 {object} schemas.ErrorResponse "File too large"
+// @Failure 500 {object} schemas.ErrorResponse "Internal server error"
+// @Router /api/sessions/{id}/resources [post]
+func (h *FileHandler) UploadSessionResource(c *gin.Context) {
+	h.uploadAttachment(c, "session", "Resource uploaded", h.fileUseCase.AddSessionResource)
+}
+
+// ListSessionResources handles listing a session's resources
+// @Summary List session resources
+// @Description List the files uploaded to the session, with download URLs valid for 15 minutes
+// @Tags sessions
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param id path int true "Session ID"
+// @Success 200 {object} schemas.SuccessResponse{data=[]schemas.AttachmentResponse} "Resources retrieved"
+// @Failure 400 {object} schemas.ErrorResponse "Invalid session ID"
+// @Failure 401 {object} schemas.ErrorResponse "Unauthorized - Invalid or missing token"
+// @Failure 404 {object} schemas.ErrorResponse "Session not found"
+// @Failure 500 {object} schemas.ErrorResponse "Internal server error"
+// @Router /api/sessions/{id}/resources [get]
+func (h *FileHandler) ListSessionResources(c *gin.Context) {
+	h.listAttachments(c, "session", "Resources retrieved", h.fileUseCase.ListSessionResources)
+}
+
+// DeleteSessionResource handles removing a file from a session's resources
+// @Summary Remove a session resource
+// @Description Remove a file from the session's resources. Needs session:write and every group of the session in scope.
+// @Tags sessions
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param id path int true "Session ID"
+// @Param attachment_id path int true "Attachment ID"
+// @Success 200 {object} schemas.SuccessResponse "Resource removed"
+// @Failure 400 {object} schemas.ErrorResponse "Invalid ID"
+// @Failure 401 {object} schemas.ErrorResponse "Unauthorized - Invalid or missing token"
+// @Failure 403 {object} schemas.ErrorResponse "Forbidden - Insufficient permissions"
+// @Failure 404 {object} schemas.ErrorResponse "Resource not found"
+// @Failure 500 {object} schemas.ErrorResponse "Internal server error"
+// @Router /api/sessions/{id}/resources/{attachment_id} [delete]
+func (h *FileHandler) DeleteSessionResource(c *gin.Context) {
+	h.deleteAttachment(c, "session", "Resource removed", h.fileUseCase.DeleteSessionResource)
+}
+
+// UploadPostAttachment handles attaching a file to a post
+// @Summary Upload a post attachment
+// @Description Attach an image, PDF or text file of at most 10 MB to a post. Only the author of the post can attach files.
+// @Tags posts
+// @Accept multipart/form-data
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param id path int true "Post ID"
+// @Param file formData file true "File"
+// @Success 201 {object} schemas.SuccessResponse{data=schemas.AttachmentResponse} "Attachment uploaded"
+// @Failure 400 {object} schemas.ErrorResponse "Invalid file"
+// @Failure 401 {object} schemas.ErrorResponse "Unauthorized - Invalid or missing token"
+// @Failure 403 {object} schemas.ErrorResponse "Not the author of the post"
+// @Failure 404 {object} schemas.ErrorResponse "Post not found"
+// @Failure 413 {object} schemas.ErrorResponse "File too large"
+// @Failure 500 {object} schemas.ErrorResponse "Internal server error"
+// @Router /api/posts/{id}/attachments [post]
+func (h *FileHandler) UploadPostAttachment(c *gin.Context) {
+	h.uploadAttachment(c, "post", "Attachment uploaded", h.fileUseCase.AddPostAttachment)
+}
+
+// ListPostAttachments handles listing a post's attachments
+// @Summary List post attachments
+// @Description List the files attached to the post, with download URLs valid for 15 minutes
+// @Tags posts
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param id path int true "Post ID"
+// @Success 200 {object} schemas.SuccessResponse{data=[]schemas.AttachmentResponse} "Attachments retrieved"
+// @Failure 400 {object} schemas.ErrorResponse "Invalid post ID"
+// @Failure 401 {object} schemas.ErrorResponse "Unauthorized - Invalid or missing token"
+// @Failure 404 {object} schemas.ErrorResponse "Post not found"
+// @Failure 500 {object} schemas.ErrorResponse "Internal server error"
+// @Router /api/posts/{id}/attachments [get]
+func (h *FileHandler) ListPostAttachments(c *gin.Context) {
+	h.listAttachments(c, "post", "Attachments retrieved", h.fileUseCase.ListPostAttachments)
+}
+
+// DeletePostAttachment handles removing a file from a post
+// @Summary Remove a post attachment
+// @Description Remove a file from a post. Only the author of the post can remove files.
+// @Tags posts
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param id path int true "Post ID"
+// @Param attachment_id path int true "Attachment ID"
+// @Success 200 {object} schemas.SuccessResponse "Attachment removed"
+// @Failure 400 {object} schemas.ErrorResponse "Invalid ID"
+// @Failure 401 {object} schemas.ErrorResponse "Unauthorized - Invalid or missing token"
+// @Failure 403 {object} schemas.ErrorResponse "Not the author of the post"
+// @Failure 404 {object} schemas.ErrorResponse "Attachment not found"
+// @Failure 500 {object} schemas.ErrorResponse "Internal server error"
+// @Router /api/posts/{id}/attachments/{attachment_id} [delete]
+func (h *FileHandler) DeletePostAttachment(c *gin.Context) {
+	h.deleteAttachment(c, "post", "Attachment removed", h.fileUseCase.DeletePostAttachment)
+}
+
+func (h *FileHandler) uploadAttachment(c *gin.Context, owner, message string, add func(actorID, ownerID uint, name string, r io.Reader) (*schemas.AttachmentResponse, error)) {
+	ownerID, ok := fileIDParam(c, "id", owner)
+	if !ok {
+		return
+	}
+	file, header, ok := openUpload(c)
+	if !ok {
+		return
+	}
+	defer file.Close()
+
+	attachment, err := add(currentUserID(c), ownerID, header.Filename, file)
+	if err != nil {
+		respondFileError(c, err, "Failed to upload the file")
+		return
+	}
+
+	c.JSON(http.StatusCreated, schemas.SuccessResponse{
+		Success: true,
+		Code:    http.StatusCreated,
+		Message: message,
+		Data:    attachment,
+	})
+}
+
+func (h *FileHandler) listAttachments(c *gin.Context, owner, message string, list func(ownerID uint) ([]*schemas.AttachmentResponse, error)) {
+	ownerID, ok := fileIDParam(c, "id", owner)
+	if !ok {
+		return
+	}
+	attachments, err := list(ownerID)
+	if err != nil {
+		respondFileError(c, err, "Failed to list the files")
+		return
+	}
+
+	c.JSON(http.StatusOK, schemas.SuccessResponse{
+		Success: true,
+		Code:    http.StatusOK,
+		Message: message,
+		Data:    attachments,
+	})
+}
+
+func (h *FileHandler) deleteAttachment(c *gin.Context, owner, message string, remove func(actorID, ownerID, attachmentID uint) error) {
+	ownerID, ok := fileIDParam(c, "id", owner)
+	if !ok {
+		return
+	}
+	attachmentID, ok := fileIDParam(c, "attachment_id", "attachment")
+	if !ok {
+		return
+	}
+	if err := remove(currentUserID(c), ownerID, attachmentID); err != nil {
+		respondFileError(c, err, "Failed to remove the file")
+		return
+	}
+
+	c.JSON(http.StatusOK, schemas.SuccessResponse{
+		Success: true,
+		Code:    http.StatusOK,
+		Message: message,
+	})
+}
+
+// openUpload opens the multipart "file" field, rejecting files above the
+// largest accepted size before they are read
+func openUpload(c *gin.Context) (multipart.File, *multipart.FileHeader, bool) {
+	header, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, schemas.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: "A file is required",
+			Details: err.Error(),
+		})
+		return nil, nil, false
+	}
+	if header.Size > usecases.MaxUploadSize {
+		c.JSON(http.StatusRequestEntityTooLarge, schemas.ErrorResponse{
+			Code:    http.StatusRequestEntityTooLarge,
+			Message: fmt.Sprintf("The file is larger than %d MB", usecases.MaxUploadSize>>20),
+		})
+		return nil, nil, false
+	}
+	file, err := header.Open()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, schemas.ErrorResponse{Code: 500, Message: "Internal server error"})
+		return nil, nil, false
+	}
+	return file, header, true
+}
+
+func fileIDParam(c *gin.Context, param, name string) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param(param), 10, 32)
+	if err != nil || id == 0 {
+		c.JSON(http.StatusBadRequest, schemas.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: fmt.Sprintf("Invalid %s ID", name),
+			Details: fmt.Sprintf("The %s ID must be a positive integer", name),
+		})
+		return 0, false
+	}
+	return uint(id), true
+}
+
+// respondFileError maps upload and download errors to HTTP statuses
+func respondFileError(c *gin.Context, err error, message string) {
+	status := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, usecases.ErrInvalidUpload):
+		status = http.StatusBadRequest
+	case errors.Is(err, usecases.ErrUploadTooLarge):
+		status = http.StatusRequestEntityTooLarge
+	case errors.Is(err, usecases.ErrOutOfScope),
+		errors.Is(err, usecases.ErrNotPostAuthor),
+		errors.Is(err, storage_services.ErrInvalidSignature):
+		status = http.StatusForbidden
+	case errors.Is(err, gorm.ErrRecordNotFound),
+		errors.Is(err, storage_services.ErrNotFound),
+		errors.Is(err, storage_services.ErrInvalidKey):
+		status = http.StatusNotFound
+	}
+	c.JSON(status, schemas.ErrorResponse{
+		Code:    status,
+		Message: message,
+		Details: err.Error(),
+	})
+}
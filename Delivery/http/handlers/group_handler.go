@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"errors"
 	"strconv"
 
 	"a2sv.org/hub/Delivery/http/schemas"
@@ -26,6 +27,7 @@ func NewGroupHandler(groupUseCase usecases.GroupUseCase) *GroupHandler {
 // @Param group body schemas.CreateGroupRequest true "Group data"
 // @Success 201 {object} schemas.SuccessResponse "Group created successfully"
 // @Failure 400 {object} schemas.ErrorResponse "Invalid request body"
+// @Failure 403 {object} schemas.ErrorResponse "HOA set without global scope"
 // @Failure 500 {object} schemas.ErrorResponse "Internal server error"
 // @Router /api/groups [post]
 func (h *GroupHandler) CreateGroup(c *gin.Context) {
@@ -38,8 +40,16 @@ func (h *GroupHandler) CreateGroup(c *gin.Context) {
 		})
 		return
 	}
-	createdGroup, err := h.groupUseCase.Create(&input)
+	createdGroup, err := h.groupUseCase.Create(currentUserID(c), &input)
 	if err != nil {
+		if errors.Is(err, usecases.ErrOutOfScope) {
+			c.JSON(403, schemas.ErrorResponse{
+				Code:    403,
+				Message: "Only admins with global scope can set the HOA",
+				Details: err.Error(),
+			})
+			return
+		}
 		c.JSON(500, schemas.ErrorResponse{
 			Code:    500,
 			Message: "Could not create group",
@@ -102,6 +112,7 @@ func (h *GroupHandler) GetGroupByID(c *gin.Context) {
 // @Param group body schemas.UpdateGroupRequest true "Group data"
 // @Success 200 {object} schemas.SuccessResponse "Group updated successfully"
 // @Failure 400 {object} schemas.ErrorResponse "Invalid request body or group ID"
+// @Failure 403 {object} schemas.ErrorResponse "Group outside your scope, or HOA set without global scope"
 // @Failure 404 {object} schemas.ErrorResponse "Group not found"
 // @Failure 500 {object} schemas.ErrorResponse "Internal server error"
 // @Router /api/groups/{id} [patch]
@@ -125,8 +136,16 @@ func (h *GroupHandler) UpdateGroup(c *gin.Context) {
 		})
 		return
 	}
-	updatedGroup, err := h.groupUseCase.Update(uint(id), &input)
+	updatedGroup, err := h.groupUseCase.Update(currentUserID(c), uint(id), &input)
 	if err != nil {
+		if errors.Is(err, usecases.ErrOutOfScope) {
+			c.JSON(403, schemas.ErrorResponse{
+				Code:    403,
+				Message: "Group is outside your scope",
+				Details: err.Error(),
+			})
+			return
+		}
 		c.JSON(404, schemas.ErrorResponse{
 			Code:    404,
 			Message: "Group not found",
@@ -149,6 +168,7 @@ func (h *GroupHandler) UpdateGroup(c *gin.Context) {
 // @Param id path int true "Group ID"
 // @Success 200 {object} schemas.SuccessResponse "Group deleted successfully"
 // @Failure 400 {object} schemas.ErrorResponse "Invalid group ID"
+// @Failure 403 {object} schemas.ErrorResponse "Group outside your scope"
 // @Failure 404 {object} schemas.ErrorResponse "Group not found"
 // @Failure 500 {object} schemas.ErrorResponse "Internal server error"
 // @Router /api/groups/{id} [delete]
@@ -163,8 +183,16 @@ func (h *GroupHandler) DeleteGroup(c *gin.Context) {
 		})
 		return
 	}
-	err = h.groupUseCase.Delete(uint(id))
+	err = h.groupUseCase.Delete(currentUserID(c), uint(id))
 	if err != nil {
+		if errors.Is(err, usecases.ErrOutOfScope) {
+			c.JSON(403, schemas.ErrorResponse{
+				Code:    403,
+				Message: "Group is outside your scope",
+				Details: err.Error(),
+			})
+			return
+		}
 		c.JSON(404, schemas.ErrorResponse{
 			Code:    404,
 			Message: "Group not found",
@@ -0,0 +1,183 @@
+package handlers
+
+import (
+	"errors"
+	"strconv"
+
+	"a2sv.org/hub/Delivery/http/schemas"
+	"a2sv.org/hub/usecases"
+	"github.com/gin-gonic/gin"
+)
+
+// HOAHandler handles HTTP requests for Head of Academy assignments
+type HOAHandler struct {
+	hoaUseCase *usecases.HOAUseCase
+}
+
+// NewHOAHandler creates a new HOAHandler instance
+func NewHOAHandler(hoaUseCase *usecases.HOAUseCase) *HOAHandler {
+	return &HOAHandler{
+		hoaUseCase: hoaUseCase,
+	}
+}
+
+// ListGroupHOAs handles listing the Heads of Academy of a group
+// @Summary List group HOAs
+// @Description Get the Heads of Academy assigned to a group
+// @Tags groups
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param id path int true "Group ID" minimum(1)
+// @Success 200 {object} schemas.SuccessResponse "Group HOAs retrieved successfully"
+// @Failure 400 {object} schemas.ErrorResponse "Invalid group ID format"
+// @Failure 401 {object} schemas.ErrorResponse "Unauthorized - Invalid or missing token"
+// @Failure 500 {object} schemas.ErrorResponse "Internal server error"
+// @Router /api/groups/{id}/hoas [get]
+func (h *HOAHandler) ListGroupHOAs(c *gin.Context) {
+	groupID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(400, schemas.ErrorResponse{
+			Code:    400,
+			Message: "Invalid group ID",
+			Details: "Group ID must be a positive integer",
+		})
+		return
+	}
+
+	hoas, err := h.hoaUseCase.ListHOAs(uint(groupID))
+	if err != nil {
+		c.JSON(500, schemas.ErrorResponse{
+			Code:    500,
+			Message: "Failed to list group HOAs",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(200, schemas.SuccessResponse{
+		Success: true,
+		Code:    200,
+		Message: "Group HOAs retrieved successfully",
+		Data:    hoas,
+	})
+}
+
+// AssignGroupHOA handles making a user Head of Academy of a group
+// @Summary Assign group HOA
+// @Description Make a user Head of Academy of a group, giving them scope over its users, exercises and sessions
+// @Tags groups
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param id path int true "Group ID" minimum(1)
+// @Param request body schemas.AssignHOARequest true "User to assign"
+// @Success 201 {object} schemas.SuccessResponse "HOA assigned successfully"
+// @Failure 400 {object} schemas.ErrorResponse "Invalid request format"
+// @Failure 401 {object} schemas.ErrorResponse "Unauthorized - Invalid or missing token"
+// @Failure 403 {object} schemas.ErrorResponse "Forbidden - Insufficient permissions or group outside your scope"
+// @Failure 404 {object} schemas.ErrorResponse "User not found"
+// @Router /api/groups/{id}/hoas [post]
+func (h *HOAHandler) AssignGroupHOA(c *gin.Context) {
+	groupID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(400, schemas.ErrorResponse{
+			Code:    400,
+			Message: "Invalid group ID",
+			Details: "Group ID must be a positive integer",
+		})
+		return
+	}
+
+	var input schemas.AssignHOARequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(400, schemas.ErrorResponse{
+			Code:    400,
+			Message: "Invalid request format",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	hoa, err := h.hoaUseCase.AssignHOA(currentUserID(c), uint(groupID), input.UserID)
+	if err != nil {
+		if errors.Is(err, usecases.ErrOutOfScope) {
+			c.JSON(403, schemas.ErrorResponse{
+				Code:    403,
+				Message: "Group is outside your scope",
+				Details: err.Error(),
+			})
+			return
+		}
+		c.JSON(404, schemas.ErrorResponse{
+			Code:    404,
+			Message: "Failed to assign HOA",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(201, schemas.SuccessResponse{
+		Success: true,
+		Code:    201,
+		Message: "HOA assigned successfully",
+		Data:    hoa,
+	})
+}
+
+// RemoveGroupHOA handles removing a Head of Academy from a group
+// @Summary Remove group HOA
+// @Description Remove a user from the Heads of Academy of a group
+// @Tags groups
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param id path int true "Group ID" minimum(1)
+// @Param user_id path int true "User ID" minimum(1)
+// @Success 200 {object} schemas.SuccessResponse "HOA removed successfully"
+// @Failure 400 {object} schemas.ErrorResponse "Invalid ID format"
+// @Failure 401 {object} schemas.ErrorResponse "Unauthorized - Invalid or missing token"
+// @Failure 403 {object} schemas.ErrorResponse "Forbidden - Insufficient permissions or group outside your scope"
+// @Failure 404 {object} schemas.ErrorResponse "HOA not found"
+// @Router /api/groups/{id}/hoas/{user_id} [delete]
+func (h *HOAHandler) RemoveGroupHOA(c *gin.Context) {
+	groupID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(400, schemas.ErrorResponse{
+			Code:    400,
+			Message: "Invalid group ID",
+			Details: "Group ID must be a positive integer",
+		})
+		return
+	}
+	userID, err := strconv.ParseUint(c.Param("user_id"), 10, 32)
+	if err != nil {
+		c.JSON(400, schemas.ErrorResponse{
+			Code:    400,
+			Message: "Invalid user ID",
+			Details: "User ID must be a positive integer",
+		})
+		return
+	}
+
+	if err := h.hoaUseCase.RemoveHOA(currentUserID(c), uint(groupID), uint(userID)); err != nil {
+		if errors.Is(err, usecases.ErrOutOfScope) {
+			c.JSON(403, schemas.ErrorResponse{
+				Code:    403,
+				Message: "Group is outside your scope",
+				Details: err.Error(),
+			})
+			return
+		}
+		c.JSON(404, schemas.ErrorResponse{
+			Code:    404,
+			Message: "HOA not found",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(200, schemas.SuccessResponse{
+		Success: true,
+		Code:    200,
+		Message: "HOA removed successfully",
+	})
+}
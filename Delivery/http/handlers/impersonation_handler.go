@@ -0,0 +1,171 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"a2sv.org/hub/Delivery/http/middleware"
+	"a2sv.org/hub/Delivery/http/schemas"
+	"a2sv.org/hub/infrastructure/ip_services"
+	"a2sv.org/hub/usecases"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// ImpersonationHandler handles HTTP requests for admin impersonation
+type ImpersonationHandler struct {
+	impersonationUseCase *usecases.ImpersonationUseCase
+}
+
+// NewImpersonationHandler creates a new ImpersonationHandler instance
+func NewImpersonationHandler(impersonationUseCase *usecases.ImpersonationUseCase) *ImpersonationHandler {
+	return &ImpersonationHandler{
+		impersonationUseCase: impersonationUseCase,
+	}
+}
+
+// StartImpersonation handles minting a token that acts as another user
+// @Summary Impersonate a user
+// @Description Get a 15-minute, read-only token that sees the API as the user. The token carries the admin in impersonator_id. Writes made with it are rejected, and the start is recorded with the reason.
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param id path int true "User ID"
+// @Param request body schemas.StartImpersonationRequest true "Reason for the impersonation"
+// @Success 201 {object} schemas.SuccessResponse{data=schemas.ImpersonationResponse} "Impersonation started"
+// @Failure 400 {object} schemas.ErrorResponse "Invalid request format or impersonating yourself"
+// @Failure 401 {object} schemas.ErrorResponse "Unauthorized - Invalid or missing token"
+// @Failure 403 {object} schemas.ErrorResponse "Forbidden - User is outside of your groups or more privileged than you"
+// @Failure 404 {object} schemas.ErrorResponse "User not found"
+// @Failure 500 {object} schemas.ErrorResponse "Internal server error"
+// @Router /api/users/{id}/impersonate [post]
+func (h *ImpersonationHandler) StartImpersonation(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, schemas.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: "Invalid user ID",
+			Details: "User ID must be a positive integer",
+		})
+		return
+	}
+
+	var input schemas.StartImpersonationRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, schemas.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: "Invalid request format",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	result, err := h.impersonationUseCase.Start(currentUserID(c), uint(id), input.Reason, ip_services.GetClientIP(c))
+	if err != nil {
+		respondImpersonationError(c, err, "Failed to start impersonation")
+		return
+	}
+
+	c.JSON(http.StatusCreated, schemas.SuccessResponse{
+		Success: true,
+		Code:    http.StatusCreated,
+		Message: "Impersonation started",
+		Data:    result,
+	})
+}
+
+// EndImpersonation handles ending the impersonation of the presented token
+// @Summary End an impersonation
+// @Description Revoke the impersonation token used for this request and record the end of the impersonation
+// @Tags users
+// @Produce json
+// @Param Authorization header string true "Bearer impersonation token"
+// @Success 200 {object} schemas.SuccessResponse "Impersonation ended"
+// @Failure 400 {object} schemas.ErrorResponse "The token is not an impersonation token"
+// @Failure 401 {object} schemas.ErrorResponse "Unauthorized - Invalid or missing token"
+// @Failure 500 {object} schemas.ErrorResponse "Internal server error"
+// @Router /api/impersonations/end [post]
+func (h *ImpersonationHandler) EndImpersonation(c *gin.Context) {
+	claims, ok := middleware.CurrentClaims(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, schemas.ErrorResponse{
+			Code:    http.StatusUnauthorized,
+			Message: "Unauthorized",
+			Details: "missing authentication claims",
+		})
+		return
+	}
+
+	if err := h.impersonationUseCase.End(claims); err != nil {
+		respondImpersonationError(c, err, "Failed to end impersonation")
+		return
+	}
+
+	c.JSON(http.StatusOK, schemas.SuccessResponse{
+		Success: true,
+		Code:    http.StatusOK,
+		Message: "Impersonation ended",
+	})
+}
+
+// ListImpersonations handles listing impersonations
+// @Summary List impersonations
+// @Description List recorded impersonations with their actor, user, reason, start and end, newest first
+// @Tags audit
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param actor_id query int false "Filter by impersonating admin"
+// @Param user_id query int false "Filter by impersonated user"
+// @Param page query int false "Page number" default(1) minimum(1)
+// @Param page_size query int false "Items per page" default(20) minimum(1) maximum(100)
+// @Success 200 {object} schemas.SuccessResponse{data=schemas.ImpersonationListResponse} "Impersonations retrieved successfully"
+// @Failure 400 {object} schemas.ErrorResponse "Invalid query parameters"
+// @Failure 401 {object} schemas.ErrorResponse "Unauthorized - Invalid or missing token"
+// @Failure 403 {object} schemas.ErrorResponse "Forbidden - Insufficient permissions"
+// @Failure 500 {object} schemas.ErrorResponse "Internal server error"
+// @Router /api/impersonations [get]
+func (h *ImpersonationHandler) ListImpersonations(c *gin.Context) {
+	var query schemas.ImpersonationQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, schemas.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: "Invalid query parameters",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	result, err := h.impersonationUseCase.List(&query)
+	if err != nil {
+		respondImpersonationError(c, err, "Failed to list impersonations")
+		return
+	}
+
+	c.JSON(http.StatusOK, schemas.SuccessResponse{
+		Success: true,
+		Code:    http.StatusOK,
+		Message: "Impersonations retrieved successfully",
+		Data:    result,
+	})
+}
+
+// respondImpersonationError maps impersonation usecase errors to HTTP statuses
+func respondImpersonationError(c *gin.Context, err error, message string) {
+	status := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, usecases.ErrImpersonateSelf), errors.Is(err, usecases.ErrNotImpersonating):
+		status = http.StatusBadRequest
+	case errors.Is(err, usecases.ErrOutOfScope), errors.Is(err, usecases.ErrRoleEscalation),
+		errors.Is(err, usecases.ErrAccountInactive):
+		status = http.StatusForbidden
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		status = http.StatusNotFound
+	}
+	c.JSON(status, schemas.ErrorResponse{
+		Code:    status,
+		Message: message,
+		Details: err.Error(),
+	})
+}
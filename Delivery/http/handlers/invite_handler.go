@@ -0,0 +1,248 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"a2sv.org/hub/Delivery/http/schemas"
+	"a2sv.org/hub/usecases"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// InviteHandler handles HTTP requests for invite links
+type InviteHandler struct {
+	inviteUseCase *usecases.InviteUseCase
+}
+
+// NewInviteHandler creates a new InviteHandler instance
+func NewInviteHandler(inviteUseCase *usecases.InviteUseCase) *InviteHandler {
+	return &InviteHandler{
+		inviteUseCase: inviteUseCase,
+	}
+}
+
+// CreateInvite handles creating an invite link
+// @Summary Create invite link
+// @Description Create an invite link that lets people join a group with a role. Invites are single-use by default; max_uses 0 allows unlimited uses until expires_at (7 days by default). The key is only shown in this response.
+// @Tags invites
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param request body schemas.CreateInviteRequest true "Invite details"
+// @Success 201 {object} schemas.SuccessResponse "Invite created successfully"
+// @Failure 400 {object} schemas.ErrorResponse "Invalid request format or invalid group/role"
+// @Failure 401 {object} schemas.ErrorResponse "Unauthorized - Invalid or missing token"
+// @Failure 403 {object} schemas.ErrorResponse "Group outside of your scope or role more powerful than yours"
+// @Failure 500 {object} schemas.ErrorResponse "Internal server error"
+// @Router /api/invites [post]
+func (h *InviteHandler) CreateInvite(c *gin.Context) {
+	var input schemas.CreateInviteRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, schemas.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: "Invalid request format",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	invite, err := h.inviteUseCase.Create(currentUserID(c), &input)
+	if err != nil {
+		respondInviteError(c, err, "Failed to create invite")
+		return
+	}
+
+	c.JSON(http.StatusCreated, schemas.SuccessResponse{
+		Success: true,
+		Code:    http.StatusCreated,
+		Message: "Invite created successfully",
+		Data:    invite,
+	})
+}
+
+// EmailInvites handles emailing single-use invites in bulk
+// @Summary Email invites in bulk
+// @Description Email a single-use invite, bound to the address, to each of a comma-separated list of emails
+// @Tags invites
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param request body schemas.EmailInvitesRequest true "Emails and invite details"
+// @Success 200 {object} schemas.SuccessResponse "Bulk invites processed"
+// @Failure 400 {object} schemas.ErrorResponse "Invalid request format or invalid group/role"
+// @Failure 401 {object} schemas.ErrorResponse "Unauthorized - Invalid or missing token"
+// @Failure 403 {object} schemas.ErrorResponse "Group outside of your scope or role more powerful than yours"
+// @Failure 500 {object} schemas.ErrorResponse "Internal server error"
+// @Router /api/invites/email [post]
+func (h *InviteHandler) EmailInvites(c *gin.Context) {
+	var input schemas.EmailInvitesRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, schemas.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: "Invalid request format",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	results, err := h.inviteUseCase.EmailInvites(currentUserID(c), &input)
+	if err != nil {
+		respondInviteError(c, err, "Failed to send invites")
+		return
+	}
+
+	// Count successful invites
+	successCount := 0
+	for _, result := range results {
+		if result.Success {
+			successCount++
+		}
+	}
+
+	c.JSON(http.StatusOK, schemas.SuccessResponse{
+		Success: true,
+		Code:    200,
+		Message: "Bulk invites processed",
+		Data: map[string]interface{}{
+			"total":      len(results),
+			"successful": successCount,
+			"failed":     len(results) - successCount,
+			"results":    results,
+		},
+	})
+}
+
+// ListInvites handles listing the invites of a group
+// @Summary List group invites
+// @Description List the invites of a group you manage
+// @Tags invites
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param group_id query int true "Group ID" minimum(1)
+// @Success 200 {object} schemas.SuccessResponse "Invites retrieved successfully"
+// @Failure 400 {object} schemas.ErrorResponse "Invalid group ID format"
+// @Failure 401 {object} schemas.ErrorResponse "Unauthorized - Invalid or missing token"
+// @Failure 403 {object} schemas.ErrorResponse "Group outside of your scope"
+// @Failure 500 {object} schemas.ErrorResponse "Internal server error"
+// @Router /api/invites [get]
+func (h *InviteHandler) ListInvites(c *gin.Context) {
+	groupID, err := strconv.ParseUint(c.Query("group_id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, schemas.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: "Invalid group ID",
+			Details: "group_id must be a positive integer",
+		})
+		return
+	}
+
+	invites, err := h.inviteUseCase.List(currentUserID(c), uint(groupID))
+	if err != nil {
+		respondInviteError(c, err, "Failed to list invites")
+		return
+	}
+
+	c.JSON(http.StatusOK, schemas.SuccessResponse{
+		Success: true,
+		Code:    http.StatusOK,
+		Message: "Invites retrieved successfully",
+		Data:    invites,
+	})
+}
+
+// RevokeInvite handles revoking an invite
+// @Summary Revoke invite
+// @Description Stop an invite from being redeemed
+// @Tags invites
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param id path int true "Invite ID" minimum(1)
+// @Success 200 {object} schemas.SuccessResponse "Invite revoked successfully"
+// @Failure 400 {object} schemas.ErrorResponse "Invalid invite ID format"
+// @Failure 401 {object} schemas.ErrorResponse "Unauthorized - Invalid or missing token"
+// @Failure 403 {object} schemas.ErrorResponse "Group outside of your scope"
+// @Failure 404 {object} schemas.ErrorResponse "Invite not found"
+// @Failure 500 {object} schemas.ErrorResponse "Internal server error"
+// @Router /api/invites/{id} [delete]
+func (h *InviteHandler) RevokeInvite(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, schemas.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: "Invalid invite ID",
+			Details: "Invite ID must be a positive integer",
+		})
+		return
+	}
+
+	if err := h.inviteUseCase.Revoke(currentUserID(c), uint(id)); err != nil {
+		respondInviteError(c, err, "Failed to revoke invite")
+		return
+	}
+
+	c.JSON(http.StatusOK, schemas.SuccessResponse{
+		Success: true,
+		Code:    http.StatusOK,
+		Message: "Invite revoked successfully",
+	})
+}
+
+// RedeemInvite handles joining through an invite
+// @Summary Redeem invite
+// @Description Create an account in the invite's group and role and sign in. Invites sent by email can only be redeemed with that email.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body schemas.RedeemInviteRequest true "Invite key and account details"
+// @Success 201 {object} schemas.SuccessResponse "Account created successfully"
+// @Failure 400 {object} schemas.ErrorResponse "Invalid request format or invalid invite"
+// @Failure 409 {object} schemas.ErrorResponse "Email already registered, or held by a deleted user"
+// @Failure 500 {object} schemas.ErrorResponse "Internal server error"
+// @Router /api/auth/invites/redeem [post]
+func (h *InviteHandler) RedeemInvite(c *gin.Context) {
+	var input schemas.RedeemInviteRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, schemas.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: "Invalid request format",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	tokens, err := h.inviteUseCase.Redeem(&input, loginClient(c))
+	if err != nil {
+		respondInviteError(c, err, "Failed to redeem invite")
+		return
+	}
+
+	c.JSON(http.StatusCreated, schemas.SuccessResponse{
+		Success: true,
+		Code:    http.StatusCreated,
+		Message: "Account created successfully",
+		Data:    tokens,
+	})
+}
+
+// respondInviteError maps invite usecase errors to HTTP statuses
+func respondInviteError(c *gin.Context, err error, message string) {
+	status := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, usecases.ErrInvalidInvite):
+		status = http.StatusBadRequest
+	case errors.Is(err, usecases.ErrOutOfScope), errors.Is(err, usecases.ErrRoleEscalation),
+		errors.Is(err, usecases.ErrAccountInactive):
+		status = http.StatusForbidden
+	case errors.Is(err, usecases.ErrEmailAlreadyRegistered), errors.Is(err, usecases.ErrEmailOfDeletedUser):
+		status = http.StatusConflict
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		status = http.StatusNotFound
+	}
+	c.JSON(status, schemas.ErrorResponse{
+		Code:    status,
+		Message: message,
+		Details: err.Error(),
+	})
+}
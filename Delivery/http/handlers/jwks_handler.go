@@ -0,0 +1,44 @@
+package handlers
+
+import (
+	"net/http"
+
+	"a2sv.org/hub/Delivery/http/schemas"
+	"a2sv.org/hub/usecases"
+	"github.com/gin-gonic/gin"
+)
+
+// JWKSHandler publishes the public keys that verify access tokens
+type JWKSHandler struct {
+	signingKeyUseCase *usecases.SigningKeyUseCase
+}
+
+// NewJWKSHandler creates a new JWKSHandler instance
+func NewJWKSHandler(signingKeyUseCase *usecases.SigningKeyUseCase) *JWKSHandler {
+	return &JWKSHandler{
+		signingKeyUseCase: signingKeyUseCase,
+	}
+}
+
+// GetJWKS handles serving the JSON Web Key Set
+// @Summary Get the JSON Web Key Set
+// @Description Public keys that verify hub access tokens, selected by the token's kid header. Keys appear here before they start signing and stay until every token they signed has expired.
+// @Tags auth
+// @Produce json
+// @Success 200 {object} schemas.JWKSResponse "Key set"
+// @Failure 500 {object} schemas.ErrorResponse "Internal server error"
+// @Router /.well-known/jwks.json [get]
+func (h *JWKSHandler) GetJWKS(c *gin.Context) {
+	keys, err := h.signingKeyUseCase.JWKS()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, schemas.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to load signing keys",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	c.Header("Cache-Control", "public, max-age=300")
+	c.JSON(http.StatusOK, schemas.JWKSResponse{Keys: keys})
+}
@@ -0,0 +1,80 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"a2sv.org/hub/Delivery/http/schemas"
+	"a2sv.org/hub/usecases"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// LockoutHandler handles HTTP requests for login lockouts
+type LockoutHandler struct {
+	lockoutUseCase *usecases.LockoutUseCase
+}
+
+// NewLockoutHandler creates a new LockoutHandler instance
+func NewLockoutHandler(lockoutUseCase *usecases.LockoutUseCase) *LockoutHandler {
+	return &LockoutHandler{
+		lockoutUseCase: lockoutUseCase,
+	}
+}
+
+// UnlockUser handles clearing a user's login lockout
+// @Summary Unlock a user
+// @Description Clear the failed login counter and lockout of a user's account. The unlock is recorded with the acting admin.
+// @Tags users
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param id path int true "User ID"
+// @Success 200 {object} schemas.SuccessResponse "User unlocked successfully"
+// @Failure 400 {object} schemas.ErrorResponse "Invalid user ID"
+// @Failure 401 {object} schemas.ErrorResponse "Unauthorized - Invalid or missing token"
+// @Failure 403 {object} schemas.ErrorResponse "Forbidden - User is outside of your groups"
+// @Failure 404 {object} schemas.ErrorResponse "User not found"
+// @Failure 500 {object} schemas.ErrorResponse "Internal server error"
+// @Router /api/users/{id}/unlock [post]
+func (h *LockoutHandler) UnlockUser(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, schemas.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: "Invalid user ID",
+			Details: "User ID must be a positive integer",
+		})
+		return
+	}
+
+	if err := h.lockoutUseCase.Unlock(currentUserID(c), uint(id)); err != nil {
+		switch {
+		case errors.Is(err, usecases.ErrOutOfScope):
+			c.JSON(http.StatusForbidden, schemas.ErrorResponse{
+				Code:    http.StatusForbidden,
+				Message: "Forbidden - User is outside of your groups",
+				Details: err.Error(),
+			})
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			c.JSON(http.StatusNotFound, schemas.ErrorResponse{
+				Code:    http.StatusNotFound,
+				Message: "User not found",
+				Details: err.Error(),
+			})
+		default:
+			c.JSON(http.StatusInternalServerError, schemas.ErrorResponse{
+				Code:    http.StatusInternalServerError,
+				Message: "Failed to unlock user",
+				Details: err.Error(),
+			})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, schemas.SuccessResponse{
+		Success: true,
+		Code:    http.StatusOK,
+		Message: "User unlocked successfully",
+	})
+}
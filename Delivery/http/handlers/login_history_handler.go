@@ -0,0 +1,63 @@
+package handlers
+
+import (
+	"net/http"
+
+	"a2sv.org/hub/Delivery/http/schemas"
+	"a2sv.org/hub/usecases"
+	"github.com/gin-gonic/gin"
+)
+
+// LoginHistoryHandler handles HTTP requests for login history
+type LoginHistoryHandler struct {
+	loginHistoryUseCase *usecases.LoginHistoryUseCase
+}
+
+// NewLoginHistoryHandler creates a new LoginHistoryHandler instance
+func NewLoginHistoryHandler(loginHistoryUseCase *usecases.LoginHistoryUseCase) *LoginHistoryHandler {
+	return &LoginHistoryHandler{
+		loginHistoryUseCase: loginHistoryUseCase,
+	}
+}
+
+// ListMyLogins handles listing the caller's login history
+// @Summary List my logins
+// @Description List the caller's successful and failed logins with IP, device and location, newest first
+// @Tags users
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param page query int false "Page number" default(1) minimum(1)
+// @Param page_size query int false "Items per page" default(20) minimum(1) maximum(100)
+// @Success 200 {object} schemas.SuccessResponse{data=schemas.LoginHistoryResponse} "Login history retrieved successfully"
+// @Failure 400 {object} schemas.ErrorResponse "Invalid query parameters"
+// @Failure 401 {object} schemas.ErrorResponse "Unauthorized - Invalid or missing token"
+// @Failure 500 {object} schemas.ErrorResponse "Internal server error"
+// @Router /api/users/me/logins [get]
+func (h *LoginHistoryHandler) ListMyLogins(c *gin.Context) {
+	var query schemas.LoginHistoryQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, schemas.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: "Invalid query parameters",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	history, err := h.loginHistoryUseCase.List(currentUserID(c), &query)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, schemas.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to list logins",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, schemas.SuccessResponse{
+		Success: true,
+		Code:    http.StatusOK,
+		Message: "Login history retrieved successfully",
+		Data:    history,
+	})
+}
@@ -0,0 +1,226 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"a2sv.org/hub/Delivery/http/middleware"
+	"a2sv.org/hub/Delivery/http/schemas"
+	"a2sv.org/hub/usecases"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// LoginSessionHandler handles HTTP requests for signed-in devices
+type LoginSessionHandler struct {
+	tokenUseCase *usecases.TokenUseCase
+}
+
+// NewLoginSessionHandler creates a new LoginSessionHandler instance
+func NewLoginSessionHandler(tokenUseCase *usecases.TokenUseCase) *LoginSessionHandler {
+	return &LoginSessionHandler{
+		tokenUseCase: tokenUseCase,
+	}
+}
+
+// ListMySessions handles listing the caller's sessions
+// @Summary List my sessions
+// @Description List the devices the caller is signed in on with their IP, creation time and last activity, most recently seen first. The session of the request is marked current.
+// @Tags users
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Success 200 {object} schemas.SuccessResponse{data=[]schemas.LoginSessionResponse} "Sessions retrieved successfully"
+// @Failure 401 {object} schemas.ErrorResponse "Unauthorized - Invalid or missing token"
+// @Failure 500 {object} schemas.ErrorResponse "Internal server error"
+// @Router /api/users/me/sessions [get]
+func (h *LoginSessionHandler) ListMySessions(c *gin.Context) {
+	userID := currentUserID(c)
+	h.listSessions(c, userID, userID)
+}
+
+// RevokeMySession handles signing the caller out of one device
+// @Summary Revoke one of my sessions
+// @Description End a session. Its refresh token stops working and its access tokens are rejected immediately.
+// @Tags users
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param session_id path int true "Session ID"
+// @Success 200 {object} schemas.SuccessResponse "Session revoked successfully"
+// @Failure 400 {object} schemas.ErrorResponse "Invalid session ID"
+// @Failure 401 {object} schemas.ErrorResponse "Unauthorized - Invalid or missing token"
+// @Failure 404 {object} schemas.ErrorResponse "Session not found"
+// @Failure 500 {object} schemas.ErrorResponse "Internal server error"
+// @Router /api/users/me/sessions/{session_id} [delete]
+func (h *LoginSessionHandler) RevokeMySession(c *gin.Context) {
+	userID := currentUserID(c)
+	h.revokeSession(c, userID, userID)
+}
+
+// RevokeMySessions handles signing the caller out everywhere
+// @Summary Revoke all of my sessions
+// @Description End every session of the caller, including the one making the request
+// @Tags users
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Success 200 {object} schemas.SuccessResponse "Sessions revoked successfully"
+// @Failure 401 {object} schemas.ErrorResponse "Unauthorized - Invalid or missing token"
+// @Failure 500 {object} schemas.ErrorResponse "Internal server error"
+// @Router /api/users/me/sessions [delete]
+func (h *LoginSessionHandler) RevokeMySessions(c *gin.Context) {
+	userID := currentUserID(c)
+	h.revokeSessions(c, userID, userID)
+}
+
+// ListUserSessions handles listing another user's sessions
+// @Summary List a user's sessions
+// @Description List the devices a user is signed in on with their IP, creation time and last activity, most recently seen first
+// @Tags users
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param id path int true "User ID"
+// @Success 200 {object} schemas.SuccessResponse{data=[]schemas.LoginSessionResponse} "Sessions retrieved successfully"
+// @Failure 400 {object} schemas.ErrorResponse "Invalid user ID"
+// @Failure 401 {object} schemas.ErrorResponse "Unauthorized - Invalid or missing token"
+// @Failure 403 {object} schemas.ErrorResponse "Forbidden - User is outside of your groups"
+// @Failure 404 {object} schemas.ErrorResponse "User not found"
+// @Failure 500 {object} schemas.ErrorResponse "Internal server error"
+// @Router /api/users/{id}/sessions [get]
+func (h *LoginSessionHandler) ListUserSessions(c *gin.Context) {
+	userID, ok := sessionUserID(c)
+	if !ok {
+		return
+	}
+	h.listSessions(c, currentUserID(c), userID)
+}
+
+// RevokeUserSession handles signing a user out of one device
+// @Summary Revoke one of a user's sessions
+// @Description End a user's session. Its refresh token stops working and its access tokens are rejected immediately.
+// @Tags users
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param id path int true "User ID"
+// @Param session_id path int true "Session ID"
+// @Success 200 {object} schemas.SuccessResponse "Session revoked successfully"
+// @Failure 400 {object} schemas.ErrorResponse "Invalid user or session ID"
+// @Failure 401 {object} schemas.ErrorResponse "Unauthorized - Invalid or missing token"
+// @Failure 403 {object} schemas.ErrorResponse "Forbidden - User is outside of your groups"
+// @Failure 404 {object} schemas.ErrorResponse "User or session not found"
+// @Failure 500 {object} schemas.ErrorResponse "Internal server error"
+// @Router /api/users/{id}/sessions/{session_id} [delete]
+func (h *LoginSessionHandler) RevokeUserSession(c *gin.Context) {
+	userID, ok := sessionUserID(c)
+	if !ok {
+		return
+	}
+	h.revokeSession(c, currentUserID(c), userID)
+}
+
+// RevokeUserSessions handles signing a user out everywhere
+// @Summary Revoke all of a user's sessions
+// @Description End every session of a user
+// @Tags users
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param id path int true "User ID"
+// @Success 200 {object} schemas.SuccessResponse "Sessions revoked successfully"
+// @Failure 400 {object} schemas.ErrorResponse "Invalid user ID"
+// @Failure 401 {object} schemas.ErrorResponse "Unauthorized - Invalid or missing token"
+// @Failure 403 {object} schemas.ErrorResponse "Forbidden - User is outside of your groups"
+// @Failure 404 {object} schemas.ErrorResponse "User not found"
+// @Failure 500 {object} schemas.ErrorResponse "Internal server error"
+// @Router /api/users/{id}/sessions [delete]
+func (h *LoginSessionHandler) RevokeUserSessions(c *gin.Context) {
+	userID, ok := sessionUserID(c)
+	if !ok {
+		return
+	}
+	h.revokeSessions(c, currentUserID(c), userID)
+}
+
+func (h *LoginSessionHandler) listSessions(c *gin.Context, actorID, userID uint) {
+	var currentSessionID string
+	if claims, ok := middleware.CurrentClaims(c); ok && claims.ID == userID {
+		currentSessionID = claims.SessionID
+	}
+
+	sessions, err := h.tokenUseCase.ListSessions(actorID, userID, currentSessionID)
+	if err != nil {
+		respondLoginSessionError(c, err, "Failed to list sessions")
+		return
+	}
+
+	c.JSON(http.StatusOK, schemas.SuccessResponse{
+		Success: true,
+		Code:    http.StatusOK,
+		Message: "Sessions retrieved successfully",
+		Data:    sessions,
+	})
+}
+
+func (h *LoginSessionHandler) revokeSession(c *gin.Context, actorID, userID uint) {
+	sessionID, err := strconv.ParseUint(c.Param("session_id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, schemas.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: "Invalid session ID",
+			Details: "Session ID must be a positive integer",
+		})
+		return
+	}
+
+	if err := h.tokenUseCase.RevokeSession(actorID, userID, uint(sessionID)); err != nil {
+		respondLoginSessionError(c, err, "Failed to revoke session")
+		return
+	}
+
+	c.JSON(http.StatusOK, schemas.SuccessResponse{
+		Success: true,
+		Code:    http.StatusOK,
+		Message: "Session revoked successfully",
+	})
+}
+
+func (h *LoginSessionHandler) revokeSessions(c *gin.Context, actorID, userID uint) {
+	if err := h.tokenUseCase.RevokeSessions(actorID, userID); err != nil {
+		respondLoginSessionError(c, err, "Failed to revoke sessions")
+		return
+	}
+
+	c.JSON(http.StatusOK, schemas.SuccessResponse{
+		Success: true,
+		Code:    http.StatusOK,
+		Message: "Sessions revoked successfully",
+	})
+}
+
+// sessionUserID parses the :id path parameter, answering 400 when it is invalid
+func sessionUserID(c *gin.Context) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, schemas.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: "Invalid user ID",
+			Details: "User ID must be a positive integer",
+		})
+		return 0, false
+	}
+	return uint(id), true
+}
+
+// respondLoginSessionError maps session errors to HTTP statuses
+func respondLoginSessionError(c *gin.Context, err error, message string) {
+	status := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, usecases.ErrOutOfScope):
+		status = http.StatusForbidden
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		status = http.StatusNotFound
+	}
+	c.JSON(status, schemas.ErrorResponse{
+		Code:    status,
+		Message: message,
+		Details: err.Error(),
+	})
+}
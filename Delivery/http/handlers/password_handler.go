@@ -0,0 +1,157 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"a2sv.org/hub/Delivery/http/schemas"
+	"a2sv.org/hub/usecases"
+	"github.com/gin-gonic/gin"
+)
+
+// PasswordHandler handles HTTP requests for resetting and changing passwords
+type PasswordHandler struct {
+	passwordUseCase *usecases.PasswordUseCase
+}
+
+// NewPasswordHandler creates a new PasswordHandler instance
+func NewPasswordHandler(passwordUseCase *usecases.PasswordUseCase) *PasswordHandler {
+	return &PasswordHandler{
+		passwordUseCase: passwordUseCase,
+	}
+}
+
+// ForgotPassword handles requesting a password reset link
+// @Summary Forgot password
+// @Description Email a single-use password reset link. The response is the same whether or not the email has an account.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body schemas.ForgotPasswordRequest true "Account email"
+// @Success 200 {object} schemas.SuccessResponse "Reset link sent if the account exists"
+// @Failure 400 {object} schemas.ErrorResponse "Invalid request format"
+// @Failure 500 {object} schemas.ErrorResponse "Internal server error"
+// @Router /api/auth/forgot-password [post]
+func (h *PasswordHandler) ForgotPassword(c *gin.Context) {
+	var input schemas.ForgotPasswordRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, schemas.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: "Invalid request format",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	if err := h.passwordUseCase.ForgotPassword(input.Email); err != nil {
+		c.JSON(http.StatusInternalServerError, schemas.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to send password reset link",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, schemas.SuccessResponse{
+		Success: true,
+		Code:    http.StatusOK,
+		Message: "If the account exists, a password reset link has been sent",
+	})
+}
+
+// ResetPassword handles setting a new password with a reset token
+// @Summary Reset password
+// @Description Set a new password with the token from the reset link. The token works once and every existing session is signed out.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body schemas.ResetPasswordRequest true "Reset token and new password"
+// @Success 200 {object} schemas.SuccessResponse "Password reset successfully"
+// @Failure 400 {object} schemas.ErrorResponse "Invalid request format or invalid token"
+// @Failure 500 {object} schemas.ErrorResponse "Internal server error"
+// @Router /api/auth/reset-password [post]
+func (h *PasswordHandler) ResetPassword(c *gin.Context) {
+	var input schemas.ResetPasswordRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, schemas.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: "Invalid request format",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	if err := h.passwordUseCase.ResetPassword(input.Token, input.NewPassword); err != nil {
+		if errors.Is(err, usecases.ErrInvalidResetToken) {
+			c.JSON(http.StatusBadRequest, schemas.ErrorResponse{
+				Code:    http.StatusBadRequest,
+				Message: "Invalid reset token",
+				Details: err.Error(),
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, schemas.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to reset password",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, schemas.SuccessResponse{
+		Success: true,
+		Code:    http.StatusOK,
+		Message: "Password reset successfully",
+	})
+}
+
+// ChangePassword handles changing the caller's password
+// @Summary Change password
+// @Description Change the caller's password after verifying the current one. Every session is signed out and a new token pair is returned.
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param request body schemas.ChangePasswordRequest true "Current and new password"
+// @Success 200 {object} schemas.SuccessResponse "Password changed successfully"
+// @Failure 400 {object} schemas.ErrorResponse "Invalid request format or wrong current password"
+// @Failure 401 {object} schemas.ErrorResponse "Unauthorized - Invalid or missing token"
+// @Failure 403 {object} schemas.ErrorResponse "Called with an API token"
+// @Failure 500 {object} schemas.ErrorResponse "Internal server error"
+// @Router /api/users/me/password [post]
+func (h *PasswordHandler) ChangePassword(c *gin.Context) {
+	var input schemas.ChangePasswordRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, schemas.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: "Invalid request format",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	tokens, err := h.passwordUseCase.ChangePassword(currentUserID(c), input.OldPassword, input.NewPassword, loginClient(c))
+	if err != nil {
+		if errors.Is(err, usecases.ErrWrongPassword) {
+			c.JSON(http.StatusBadRequest, schemas.ErrorResponse{
+				Code:    http.StatusBadRequest,
+				Message: "Wrong password",
+				Details: err.Error(),
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, schemas.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to change password",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, schemas.SuccessResponse{
+		Success: true,
+		Code:    http.StatusOK,
+		Message: "Password changed successfully",
+		Data:    tokens,
+	})
+}
@@ -0,0 +1,129 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"a2sv.org/hub/Delivery/http/schemas"
+	"a2sv.org/hub/usecases"
+	"github.com/gin-gonic/gin"
+)
+
+// PersonalDataHandler handles HTTP requests for data exports and erasure
+type PersonalDataHandler struct {
+	personalDataUseCase *usecases.PersonalDataUseCase
+}
+
+// NewPersonalDataHandler creates a new PersonalDataHandler instance
+func NewPersonalDataHandler(personalDataUseCase *usecases.PersonalDataUseCase) *PersonalDataHandler {
+	return &PersonalDataHandler{
+		personalDataUseCase: personalDataUseCase,
+	}
+}
+
+// ExportMyData handles downloading the caller's personal data
+// @Summary Export my data
+// @Description Download a ZIP archive of JSON files holding the caller's profile, submissions, attendance, comments, posts, votes, stipends, assistant conversations, recent actions and login history
+// @Tags users
+// @Produce application/zip
+// @Param Authorization header string true "Bearer token"
+// @Success 200 {file} file "ZIP archive"
+// @Failure 401 {object} schemas.ErrorResponse "Unauthorized - Invalid or missing token"
+// @Failure 403 {object} schemas.ErrorResponse "Called with an API token"
+// @Failure 500 {object} schemas.ErrorResponse "Internal server error"
+// @Router /api/users/me/export [get]
+func (h *PersonalDataHandler) ExportMyData(c *gin.Context) {
+	archive, err := h.personalDataUseCase.Export(currentUserID(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, schemas.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to export your data",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	filename := fmt.Sprintf("hub-data-%s.zip", time.Now().Format("2006-01-02"))
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, filename))
+	c.Header("Cache-Control", "no-store")
+	c.Data(http.StatusOK, "application/zip", archive)
+}
+
+// EraseMyData handles erasing the caller's personal data
+// @Summary Erase my data
+// @Description Permanently anonymize the caller's account after confirming their password. The name, email, contact details, coding and social profiles, photo, CV and other personal fields are erased; API tokens, linked accounts, two-factor setup, login history, assistant conversations and stipend bank details are deleted; the account is deactivated and every session ends. Submissions, attendance, votes, posts and comments remain, attributed to an erased user, along with the role, group, country, university and dates used for statistics. This cannot be undone.
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param request body schemas.EraseAccountRequest true "Current password"
+// @Success 200 {object} schemas.SuccessResponse "Personal data erased"
+// @Failure 400 {object} schemas.ErrorResponse "Invalid request format or wrong password"
+// @Failure 401 {object} schemas.ErrorResponse "Unauthorized - Invalid or missing token"
+// @Failure 403 {object} schemas.ErrorResponse "Called with an API token"
+// @Failure 500 {object} schemas.ErrorResponse "Internal server error"
+// @Router /api/users/me/erase [post]
+func (h *PersonalDataHandler) EraseMyData(c *gin.Context) {
+	var input schemas.EraseAccountRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, schemas.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: "Invalid request format",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	if err := h.personalDataUseCase.EraseSelf(currentUserID(c), input.Password); err != nil {
+		if errors.Is(err, usecases.ErrWrongPassword) {
+			c.JSON(http.StatusBadRequest, schemas.ErrorResponse{
+				Code:    http.StatusBadRequest,
+				Message: "Wrong password",
+				Details: err.Error(),
+			})
+			return
+		}
+		respondUserLifecycleError(c, err, "Failed to erase your data")
+		return
+	}
+
+	c.JSON(http.StatusOK, schemas.SuccessResponse{
+		Success: true,
+		Code:    http.StatusOK,
+		Message: "Personal data erased",
+	})
+}
+
+// EraseUserData handles erasing another user's personal data
+// @Summary Erase a user's data
+// @Description Permanently anonymize a user, for erasure requests made outside the hub, such as through a partner university. Erases the same data as POST /api/users/me/erase. Needs user:delete and the user's group in scope.
+// @Tags users
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param id path int true "User ID" minimum(1)
+// @Success 200 {object} schemas.SuccessResponse "Personal data erased"
+// @Failure 400 {object} schemas.ErrorResponse "Invalid user ID, or your own account"
+// @Failure 401 {object} schemas.ErrorResponse "Unauthorized - Invalid or missing token"
+// @Failure 403 {object} schemas.ErrorResponse "Forbidden - Insufficient permissions"
+// @Failure 404 {object} schemas.ErrorResponse "User not found"
+// @Failure 409 {object} schemas.ErrorResponse "Already erased"
+// @Failure 500 {object} schemas.ErrorResponse "Internal server error"
+// @Router /api/users/{id}/erase [post]
+func (h *PersonalDataHandler) EraseUserData(c *gin.Context) {
+	id, ok := userIDParam(c)
+	if !ok {
+		return
+	}
+	if err := h.personalDataUseCase.Erase(currentUserID(c), id); err != nil {
+		respondUserLifecycleError(c, err, "Failed to erase the user's data")
+		return
+	}
+
+	c.JSON(http.StatusOK, schemas.SuccessResponse{
+		Success: true,
+		Code:    http.StatusOK,
+		Message: "Personal data erased",
+	})
+}
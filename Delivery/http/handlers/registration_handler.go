@@ -1,14 +1,23 @@
 package handlers
 
 import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
 	"net/http"
 	"strconv"
 
 	"a2sv.org/hub/Delivery/http/schemas"
+	"a2sv.org/hub/infrastructure/spreadsheet_services"
 	"a2sv.org/hub/usecases"
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
 )
 
+// maxRegistrationFileSize is the largest sheet accepted for import
+const maxRegistrationFileSize = 5 << 20
+
 // RegistrationHandler handles HTTP requests for user registration operations
 type RegistrationHandler struct {
 	bulkRegistrationUseCase usecases.BulkRegistrationUseCase
@@ -27,6 +36,7 @@ type BulkRegistrationRequest struct {
 	RoleID    uint   `json:"role_id" binding:"required"`
 	GroupID   uint   `json:"group_id" binding:"required"`
 	CountryID *uint  `json:"country_id"`
+	Mode      string `json:"mode" binding:"omitempty,oneof=per_row all_or_nothing" example:"per_row"`
 }
 
 // RegistrationParam defines the request body for user registration with a role
@@ -35,21 +45,33 @@ type BulkRegistrationRequest struct {
 // @Param emails body string true "Emails (comma-separated)"
 // @Param group_id body uint true "Group ID"
 // @Param country_id body uint false "Country ID"
+// @Param mode body string false "per_row (default) or all_or_nothing"
 type RegistrationParam struct {
 	Emails    string `json:"emails" binding:"required"`
 	GroupID   uint   `json:"group_id" binding:"required"`
 	CountryID *uint  `json:"country_id"`
+	Mode      string `json:"mode" binding:"omitempty,oneof=per_row all_or_nothing" example:"per_row"`
+}
+
+// RegistrationUploadForm defines the form fields sent with a registration sheet
+type RegistrationUploadForm struct {
+	RoleID    uint   `form:"role_id" binding:"required,min=1"`
+	GroupID   *uint  `form:"group_id" binding:"omitempty,min=1"`
+	CountryID *uint  `form:"country_id" binding:"omitempty,min=1"`
+	Mode      string `form:"mode" binding:"omitempty,oneof=per_row all_or_nothing"`
+	DryRun    bool   `form:"dry_run"`
 }
 
 // RegisterBulkUsers handles registering multiple users at once
 // @Summary Register multiple users in bulk
-// @Description Register multiple users with the provided information
+// @Description Start a background job registering a user for every email in the group and country. Every user is emailed a temporary password. In per_row mode (the default) every valid email is registered on its own; in all_or_nothing mode nobody is registered unless every email is valid and all insert in one transaction. Poll the returned job for progress.
 // @Tags Registration
 // @Accept json
 // @Produce json
 // @Param bulk-registration body BulkRegistrationRequest true "Bulk registration data"
-// @Success 200 {object} schemas.SuccessResponse "Bulk registration processed"
+// @Success 202 {object} schemas.SuccessResponse{data=schemas.RegistrationJobResponse} "Bulk registration started"
 // @Failure 400 {object} schemas.ErrorResponse "Invalid input"
+// @Failure 403 {object} schemas.ErrorResponse "Role is more powerful than your own"
 // @Failure 500 {object} schemas.ErrorResponse "Internal server error"
 // @Router /api/registration/bulk [post]
 func (h *RegistrationHandler) RegisterBulkUsers(c *gin.Context) {
@@ -78,45 +100,27 @@ func (h *RegistrationHandler) RegisterBulkUsers(c *gin.Context) {
 	}
 
 	// Register users
-	results, err := h.bulkRegistrationUseCase.RegisterUsers(request.Emails, request.RoleID, request.GroupID, request.CountryID)
+	job, err := h.bulkRegistrationUseCase.RegisterUsers(currentUserID(c), request.Emails, request.RoleID, request.GroupID, request.CountryID, request.Mode)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, schemas.ErrorResponse{Code: 500, Message: "Internal server error"})
+		respondRegistrationError(c, err, "Bulk registration failed")
 		return
 	}
-
-	// Count successful registrations
-	successCount := 0
-	for _, result := range results {
-		if result.Success {
-			successCount++
-		}
-	}
-
-	c.JSON(http.StatusOK, schemas.SuccessResponse{
-		Success: true,
-		Code:    200,
-		Message: "Bulk registration processed",
-		Data: map[string]interface{}{
-			"total":      len(results),
-			"successful": successCount,
-			"failed":     len(results) - successCount,
-			"results":    results,
-		},
-	})
+	respondRegistrationJob(c, job)
 }
 
 // RegisterUsersWithRole handles registering multiple users with a specific role ID from the URL
 // @Summary Register multiple users with a specific role
-// @Description Register multiple users with the provided information and a specific role ID
+// @Description Start a background job registering a user with the role for every email, like POST /api/registration/bulk
 // @Tags Registration
 // @Accept json
 // @Produce json
 // @Param role_id path uint true "Role ID"
 // @Param registration body RegistrationParam true "Registration data"
-// @Success 200 {object} schemas.SuccessResponse "Bulk registration processed"
+// @Success 202 {object} schemas.SuccessResponse{data=schemas.RegistrationJobResponse} "Bulk registration started"
 // @Failure 400 {object} schemas.ErrorResponse "Invalid input"
+// @Failure 403 {object} schemas.ErrorResponse "Role is more powerful than your own"
 // @Failure 500 {object} schemas.ErrorResponse "Internal server error"
-// @Router /api/registration/role/{role_id} [post]
+// @Router /api/registration/bulk/role/{role_id} [post]
 func (h *RegistrationHandler) RegisterUsersWithRole(c *gin.Context) {
 	// Get role ID from URL parameter
 	roleIDStr := c.Param("role_id")
@@ -146,13 +150,100 @@ func (h *RegistrationHandler) RegisterUsersWithRole(c *gin.Context) {
 	}
 
 	// Register users
-	results, err := h.bulkRegistrationUseCase.RegisterUsers(request.Emails, uint(roleID), request.GroupID, request.CountryID)
+	job, err := h.bulkRegistrationUseCase.RegisterUsers(currentUserID(c), request.Emails, uint(roleID), request.GroupID, request.CountryID, request.Mode)
+	if err != nil {
+		respondRegistrationError(c, err, "Bulk registration failed")
+		return
+	}
+	respondRegistrationJob(c, job)
+}
+
+// RegisterUsersFromFile handles registering the users listed in an uploaded sheet
+// @Summary Register users from a CSV or XLSX file
+// @Description Register a user for every row of a CSV or XLSX file (first worksheet) of at most 5 MB. The first row holds the headers: email (required), name, group (ID or name), country (ID, name or short code), university, phone, leetcode, codeforces, github and hackerrank. Other columns are ignored and blank rows skipped. A name defaults to one derived from the email; group_id and country_id apply to rows that leave the column empty, and every user needs a group. The users are registered by a background job like POST /api/registration/bulk, which validates every row first. With dry_run the rows are only validated, synchronously, and the per-row results returned; valid rows are reported as "Valid".
+// @Tags Registration
+// @Accept multipart/form-data
+// @Produce json
+// @Param file formData file true "CSV or XLSX file"
+// @Param role_id formData uint true "Role ID"
+// @Param group_id formData uint false "Group for rows without one"
+// @Param country_id formData uint false "Country for rows without one"
+// @Param mode formData string false "per_row (default) or all_or_nothing"
+// @Param dry_run formData bool false "Validate without registering"
+// @Success 200 {object} schemas.SuccessResponse "Dry run results"
+// @Success 202 {object} schemas.SuccessResponse{data=schemas.RegistrationJobResponse} "Bulk registration started"
+// @Failure 400 {object} schemas.ErrorResponse "Invalid input or file"
+// @Failure 403 {object} schemas.ErrorResponse "Role is more powerful than your own"
+// @Failure 500 {object} schemas.ErrorResponse "Internal server error"
+// @Router /api/registration/bulk/upload [post]
+func (h *RegistrationHandler) RegisterUsersFromFile(c *gin.Context) {
+	var form RegistrationUploadForm
+	if err := c.ShouldBind(&form); err != nil {
+		c.JSON(http.StatusBadRequest, schemas.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: "Invalid request format",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	header, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, schemas.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: "A CSV or XLSX file is required",
+			Details: err.Error(),
+		})
+		return
+	}
+	if header.Size > maxRegistrationFileSize {
+		c.JSON(http.StatusBadRequest, schemas.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: "The file is larger than 5 MB",
+		})
+		return
+	}
+	file, err := header.Open()
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, schemas.ErrorResponse{Code: 500, Message: "Internal server error"})
 		return
 	}
+	defer file.Close()
+	data, err := io.ReadAll(io.LimitReader(file, maxRegistrationFileSize))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, schemas.ErrorResponse{Code: 500, Message: "Internal server error"})
+		return
+	}
+
+	rows, err := spreadsheet_services.Read(header.Filename, data)
+	if err != nil {
+		respondRegistrationError(c, err, "Invalid file")
+		return
+	}
+
+	options := usecases.RegistrationImportOptions{
+		RoleID:    form.RoleID,
+		GroupID:   form.GroupID,
+		CountryID: form.CountryID,
+		Mode:      form.Mode,
+	}
+	if !form.DryRun {
+		job, err := h.bulkRegistrationUseCase.ImportUsers(currentUserID(c), rows, options)
+		if err != nil {
+			respondRegistrationError(c, err, "Bulk registration failed")
+			return
+		}
+		respondRegistrationJob(c, job)
+		return
+	}
 
-	// Count successful registrations
+	results, err := h.bulkRegistrationUseCase.ValidateImport(currentUserID(c), rows, options)
+	if err != nil {
+		respondRegistrationError(c, err, "Bulk registration failed")
+		return
+	}
+
+	// Count valid rows
 	successCount := 0
 	for _, result := range results {
 		if result.Success {
@@ -163,8 +254,9 @@ func (h *RegistrationHandler) RegisterUsersWithRole(c *gin.Context) {
 	c.JSON(http.StatusOK, schemas.SuccessResponse{
 		Success: true,
 		Code:    200,
-		Message: "Bulk registration processed",
+		Message: "Bulk registration validated",
 		Data: map[string]interface{}{
+			"dry_run":    true,
 			"total":      len(results),
 			"successful": successCount,
 			"failed":     len(results) - successCount,
@@ -173,5 +265,123 @@ func (h *RegistrationHandler) RegisterUsersWithRole(c *gin.Context) {
 	})
 }
 
+// GetJob handles reading the progress of a bulk registration job
+// @Summary Get a bulk registration job
+// @Description Get the status and progress of a bulk registration job. Only the admin who started the job can read it. Once it has finished, report_url downloads the per-row results.
+// @Tags Registration
+// @Produce json
+// @Param job_id path uint true "Job ID"
+// @Success 200 {object} schemas.SuccessResponse{data=schemas.RegistrationJobResponse} "Job retrieved"
+// @Failure 400 {object} schemas.ErrorResponse "Invalid job ID"
+// @Failure 404 {object} schemas.ErrorResponse "Job not found or started by someone else"
+// @Failure 500 {object} schemas.ErrorResponse "Internal server error"
+// @Router /api/registration/jobs/{job_id} [get]
+func (h *RegistrationHandler) GetJob(c *gin.Context) {
+	jobID, err := strconv.ParseUint(c.Param("job_id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, schemas.ErrorResponse{Code: 400, Message: "Invalid job ID"})
+		return
+	}
+
+	job, err := h.bulkRegistrationUseCase.GetJob(currentUserID(c), uint(jobID))
+	if err != nil {
+		respondRegistrationError(c, err, "Failed to get registration job")
+		return
+	}
+
+	c.JSON(http.StatusOK, schemas.SuccessResponse{
+		Success: true,
+		Code:    http.StatusOK,
+		Message: "Registration job retrieved",
+		Data:    job,
+	})
+}
+
+// DownloadJobReport handles downloading the per-row results of a finished bulk registration job
+// @Summary Download a bulk registration report
+// @Description Download the per-row results of a finished bulk registration job as a CSV (the default) or JSON attachment
+// @Tags Registration
+// @Produce text/csv
+// @Produce json
+// @Param job_id path uint true "Job ID"
+// @Param format query string false "csv (default) or json"
+// @Success 200 {file} file "Report"
+// @Failure 400 {object} schemas.ErrorResponse "Invalid job ID or format"
+// @Failure 404 {object} schemas.ErrorResponse "Job not found or started by someone else"
+// @Failure 409 {object} schemas.ErrorResponse "Job has not finished"
+// @Failure 500 {object} schemas.ErrorResponse "Internal server error"
+// @Router /api/registration/jobs/{job_id}/report [get]
+func (h *RegistrationHandler) DownloadJobReport(c *gin.Context) {
+	jobID, err := strconv.ParseUint(c.Param("job_id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, schemas.ErrorResponse{Code: 400, Message: "Invalid job ID"})
+		return
+	}
+	format := c.DefaultQuery("format", "csv")
+	if format != "csv" && format != "json" {
+		c.JSON(http.StatusBadRequest, schemas.ErrorResponse{Code: 400, Message: "Format must be csv or json"})
+		return
+	}
+
+	results, err := h.bulkRegistrationUseCase.GetJobReport(currentUserID(c), uint(jobID))
+	if err != nil {
+		respondRegistrationError(c, err, "Failed to get registration report")
+		return
+	}
+
+	filename := fmt.Sprintf("registration-job-%d.%s", jobID, format)
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, filename))
+	if format == "json" {
+		c.JSON(http.StatusOK, results)
+		return
+	}
+
+	c.Header("Content-Type", "text/csv; charset=utf-8")
+	c.Status(http.StatusOK)
+	writer := csv.NewWriter(c.Writer)
+	writer.Write([]string{"row", "email", "success", "user_id", "message"})
+	for _, result := range results {
+		row, userID := "", ""
+		if result.Row > 0 {
+			row = strconv.Itoa(result.Row)
+		}
+		if result.UserID > 0 {
+			userID = strconv.FormatUint(uint64(result.UserID), 10)
+		}
+		writer.Write([]string{row, result.Email, strconv.FormatBool(result.Success), userID, result.Message})
+	}
+	writer.Flush()
+}
+
+func respondRegistrationJob(c *gin.Context, job *schemas.RegistrationJobResponse) {
+	c.JSON(http.StatusAccepted, schemas.SuccessResponse{
+		Success: true,
+		Code:    http.StatusAccepted,
+		Message: "Bulk registration started",
+		Data:    job,
+	})
+}
+
+func respondRegistrationError(c *gin.Context, err error, message string) {
+	status := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, usecases.ErrInvalidRegistration),
+		errors.Is(err, spreadsheet_services.ErrUnsupportedFormat),
+		errors.Is(err, spreadsheet_services.ErrMalformed):
+		status = http.StatusBadRequest
+	case errors.Is(err, usecases.ErrRoleEscalation):
+		status = http.StatusForbidden
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		status = http.StatusNotFound
+	case errors.Is(err, usecases.ErrRegistrationJobRunning):
+		status = http.StatusConflict
+	}
+	c.JSON(status, schemas.ErrorResponse{
+		Code:    status,
+		Message: message,
+		Details: err.Error(),
+	})
+}
+
 // ForceSwaggoParse is a dummy function to ensure Swaggo parses this file.
 func ForceSwaggoParseRegistrationHandler() {}
@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"errors"
 	"strconv"
 
 	"a2sv.org/hub/Delivery/http/schemas"
@@ -45,12 +46,24 @@ func (h *RoleHandler) CreateRole(c *gin.Context) {
 		return
 	}
 
-	role := &schemas.CreateRoleRequest{
-		Type: input.Type,
-	}
-
-	createdRole, err := h.roleUseCase.Create(role)
+	createdRole, err := h.roleUseCase.Create(currentUserID(c), &input)
 	if err != nil {
+		if errors.Is(err, usecases.ErrRoleEscalation) {
+			c.JSON(403, schemas.ErrorResponse{
+				Code:    403,
+				Message: "Role holds a permission you do not have",
+				Details: err.Error(),
+			})
+			return
+		}
+		if errors.Is(err, usecases.ErrUnknownPermission) {
+			c.JSON(400, schemas.ErrorResponse{
+				Code:    400,
+				Message: "Unknown permission",
+				Details: err.Error(),
+			})
+			return
+		}
 		c.JSON(500, schemas.ErrorResponse{
 			Code:    500,
 			Message: "Failed to create role",
@@ -148,13 +161,24 @@ func (h *RoleHandler) UpdateRole(c *gin.Context) {
 		return
 	}
 
-	role := &schemas.UpdateRoleRequest{Type: input.Type}
-	if input.Type != nil {
-		role.Type = input.Type
-	}
-
-	updatedRole, err := h.roleUseCase.Update(uint(id), role)
+	updatedRole, err := h.roleUseCase.Update(currentUserID(c), uint(id), &input)
 	if err != nil {
+		if errors.Is(err, usecases.ErrRoleEscalation) {
+			c.JSON(403, schemas.ErrorResponse{
+				Code:    403,
+				Message: "Role holds a permission you do not have",
+				Details: err.Error(),
+			})
+			return
+		}
+		if errors.Is(err, usecases.ErrUnknownPermission) {
+			c.JSON(400, schemas.ErrorResponse{
+				Code:    400,
+				Message: "Unknown permission",
+				Details: err.Error(),
+			})
+			return
+		}
 		if err.Error() == "record not found" {
 			c.JSON(404, schemas.ErrorResponse{
 				Code:    404,
@@ -206,7 +230,15 @@ func (h *RoleHandler) DeleteRole(c *gin.Context) {
 		return
 	}
 
-	if err := h.roleUseCase.Delete(uint(id)); err != nil {
+	if err := h.roleUseCase.Delete(currentUserID(c), uint(id)); err != nil {
+		if errors.Is(err, usecases.ErrRoleEscalation) {
+			c.JSON(403, schemas.ErrorResponse{
+				Code:    403,
+				Message: "Role holds a permission you do not have",
+				Details: err.Error(),
+			})
+			return
+		}
 		if err.Error() == "record not found" {
 			c.JSON(404, schemas.ErrorResponse{
 				Code:    404,
@@ -278,22 +310,190 @@ func (h *RoleHandler) ListRoles(c *gin.Context) {
 		return
 	}
 
-	// Convert to response format
-	roleResponses := make([]*schemas.RoleResponse, len(roles))
-	for i, role := range roles {
-		roleResponses[i] = &schemas.RoleResponse{
-			ID:          role.ID,
-			Type:        role.Type,
-			Description: "",
-			CreatedAt:   role.CreatedAt,
-			UpdatedAt:   role.UpdatedAt,
+	c.JSON(200, schemas.SuccessResponse{
+		Success: true,
+		Code:    200,
+		Message: "List of roles retrieved successfully",
+		Data:    roles,
+	})
+}
+
+// ListRolePermissions handles listing the permissions granted to a role
+// @Summary List role permissions
+// @Description Get the permissions granted to a specific role
+// @Tags roles
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param id path int true "Role ID" minimum(1)
+// @Success 200 {object} schemas.SuccessResponse "Role permissions retrieved successfully"
+// @Failure 400 {object} schemas.ErrorResponse "Invalid role ID format"
+// @Failure 401 {object} schemas.ErrorResponse "Unauthorized - Invalid or missing token"
+// @Failure 403 {object} schemas.ErrorResponse "Forbidden - Insufficient permissions"
+// @Failure 404 {object} schemas.ErrorResponse "Role not found"
+// @Router /api/roles/{id}/permissions [get]
+func (h *RoleHandler) ListRolePermissions(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(400, schemas.ErrorResponse{
+			Code:    400,
+			Message: "Invalid role ID",
+			Details: "Role ID must be a positive integer",
+		})
+		return
+	}
+
+	permissions, err := h.roleUseCase.ListPermissions(uint(id))
+	if err != nil {
+		c.JSON(404, schemas.ErrorResponse{
+			Code:    404,
+			Message: "Role not found",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(200, schemas.SuccessResponse{
+		Success: true,
+		Code:    200,
+		Message: "Role permissions retrieved successfully",
+		Data:    permissions,
+	})
+}
+
+// AddRolePermission handles granting a permission to a role
+// @Summary Grant role permission
+// @Description Grant a permission such as stipend:write to a role
+// @Tags roles
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param id path int true "Role ID" minimum(1)
+// @Param request body schemas.RolePermissionRequest true "Permission to grant"
+// @Success 201 {object} schemas.SuccessResponse "Permission granted successfully"
+// @Failure 400 {object} schemas.ErrorResponse "Invalid request format or unknown permission"
+// @Failure 401 {object} schemas.ErrorResponse "Unauthorized - Invalid or missing token"
+// @Failure 403 {object} schemas.ErrorResponse "Forbidden - Insufficient permissions"
+// @Failure 404 {object} schemas.ErrorResponse "Role not found"
+// @Failure 500 {object} schemas.ErrorResponse "Internal server error"
+// @Router /api/roles/{id}/permissions [post]
+func (h *RoleHandler) AddRolePermission(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(400, schemas.ErrorResponse{
+			Code:    400,
+			Message: "Invalid role ID",
+			Details: "Role ID must be a positive integer",
+		})
+		return
+	}
+
+	var input schemas.RolePermissionRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(400, schemas.ErrorResponse{
+			Code:    400,
+			Message: "Invalid request format",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	if err := h.roleUseCase.AddPermission(currentUserID(c), uint(id), input.Permission); err != nil {
+		if errors.Is(err, usecases.ErrRoleEscalation) {
+			c.JSON(403, schemas.ErrorResponse{
+				Code:    403,
+				Message: "Role holds a permission you do not have",
+				Details: err.Error(),
+			})
+			return
+		}
+		if errors.Is(err, usecases.ErrUnknownPermission) {
+			c.JSON(400, schemas.ErrorResponse{
+				Code:    400,
+				Message: "Unknown permission",
+				Details: err.Error(),
+			})
+			return
+		}
+		if err.Error() == "record not found" {
+			c.JSON(404, schemas.ErrorResponse{
+				Code:    404,
+				Message: "Role not found",
+				Details: err.Error(),
+			})
+			return
+		}
+		c.JSON(500, schemas.ErrorResponse{
+			Code:    500,
+			Message: "Failed to grant permission",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(201, schemas.SuccessResponse{
+		Success: true,
+		Code:    201,
+		Message: "Permission granted successfully",
+		Data:    input,
+	})
+}
+
+// RemoveRolePermission handles revoking a permission from a role
+// @Summary Revoke role permission
+// @Description Revoke a permission from a role
+// @Tags roles
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param id path int true "Role ID" minimum(1)
+// @Param permission path string true "Permission name, e.g. stipend:write"
+// @Success 200 {object} schemas.SuccessResponse "Permission revoked successfully"
+// @Failure 400 {object} schemas.ErrorResponse "Invalid role ID format"
+// @Failure 401 {object} schemas.ErrorResponse "Unauthorized - Invalid or missing token"
+// @Failure 403 {object} schemas.ErrorResponse "Forbidden - Insufficient permissions"
+// @Failure 404 {object} schemas.ErrorResponse "Permission not granted to role"
+// @Failure 500 {object} schemas.ErrorResponse "Internal server error"
+// @Router /api/roles/{id}/permissions/{permission} [delete]
+func (h *RoleHandler) RemoveRolePermission(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(400, schemas.ErrorResponse{
+			Code:    400,
+			Message: "Invalid role ID",
+			Details: "Role ID must be a positive integer",
+		})
+		return
+	}
+
+	if err := h.roleUseCase.RemovePermission(currentUserID(c), uint(id), c.Param("permission")); err != nil {
+		if errors.Is(err, usecases.ErrRoleEscalation) {
+			c.JSON(403, schemas.ErrorResponse{
+				Code:    403,
+				Message: "Role holds a permission you do not have",
+				Details: err.Error(),
+			})
+			return
 		}
+		if err.Error() == "record not found" {
+			c.JSON(404, schemas.ErrorResponse{
+				Code:    404,
+				Message: "Permission not granted to role",
+				Details: err.Error(),
+			})
+			return
+		}
+		c.JSON(500, schemas.ErrorResponse{
+			Code:    500,
+			Message: "Failed to revoke permission",
+			Details: err.Error(),
+		})
+		return
 	}
 
 	c.JSON(200, schemas.SuccessResponse{
 		Success: true,
 		Code:    200,
-		Message: "List of roles retrieved successfully",
-		Data:    roleResponses,
+		Message: "Permission revoked successfully",
 	})
 }
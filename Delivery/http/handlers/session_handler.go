@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"errors"
 	"strconv"
 
 	"a2sv.org/hub/Delivery/http/schemas"
@@ -36,7 +37,11 @@ func (h *SessionHandler) CreateSession(c *gin.Context) {
 		c.JSON(400, schemas.ErrorResponse{Code: 400, Message: "Invalid request body", Details: err.Error()})
 		return
 	}
-	if err := h.SessionUsecase.CreateSession(&session); err != nil {
+	if err := h.SessionUsecase.CreateSession(currentUserID(c), &session); err != nil {
+		if errors.Is(err, usecases.ErrOutOfScope) {
+			c.JSON(403, schemas.ErrorResponse{Code: 403, Message: "Forbidden - Session is outside of your groups", Details: err.Error()})
+			return
+		}
 		c.JSON(500, schemas.ErrorResponse{Code: 500, Message: "Failed to create session", Details: err.Error()})
 		return
 	}
@@ -166,7 +171,11 @@ func (h *SessionHandler) UpdateSession(c *gin.Context) {
 		return
 	}
 	session.ID = uint(sessionID)
-	if err := h.SessionUsecase.UpdateSession(&session); err != nil {
+	if err := h.SessionUsecase.UpdateSession(currentUserID(c), &session); err != nil {
+		if errors.Is(err, usecases.ErrOutOfScope) {
+			c.JSON(403, schemas.ErrorResponse{Code: 403, Message: "Forbidden - Session is outside of your groups", Details: err.Error()})
+			return
+		}
 		c.JSON(404, schemas.ErrorResponse{Code: 404, Message: "Session not found", Details: err.Error()})
 		return
 	}
@@ -194,7 +203,11 @@ func (h *SessionHandler) DeleteSession(c *gin.Context) {
 		c.JSON(400, schemas.ErrorResponse{Code: 400, Message: "Invalid session ID", Details: err.Error()})
 		return
 	}
-	if err := h.SessionUsecase.DeleteSession(uint(sessionID)); err != nil {
+	if err := h.SessionUsecase.DeleteSession(currentUserID(c), uint(sessionID)); err != nil {
+		if errors.Is(err, usecases.ErrOutOfScope) {
+			c.JSON(403, schemas.ErrorResponse{Code: 403, Message: "Forbidden - Session is outside of your groups", Details: err.Error()})
+			return
+		}
 		c.JSON(404, schemas.ErrorResponse{Code: 404, Message: "Session not found", Details: err.Error()})
 		return
 	}
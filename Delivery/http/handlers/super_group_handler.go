@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
 
@@ -29,6 +30,7 @@ func NewSuperGroupHandler(superGroupUseCase usecases.SuperGroupUseCaseInterface)
 // @Param super_group body schemas.CreateSuperGroupRequest true "SuperGroup data"
 // @Success 201 {object} schemas.SuccessResponse "Super group created successfully"
 // @Failure 400 {object} schemas.ErrorResponse "Invalid request body"
+// @Failure 403 {object} schemas.ErrorResponse "Lead set without global scope"
 // @Failure 500 {object} schemas.ErrorResponse "Internal server error"
 // @Router /api/super_groups [post]
 func (h *SuperGroupHandler) CreateSuperGroup(c *gin.Context) {
@@ -39,8 +41,12 @@ func (h *SuperGroupHandler) CreateSuperGroup(c *gin.Context) {
 		return
 	}
 
-	superGroup, err := h.superGroupUseCase.Create(superGroup)
+	superGroup, err := h.superGroupUseCase.Create(currentUserID(c), superGroup)
 	if err != nil {
+		if errors.Is(err, usecases.ErrOutOfScope) {
+			c.JSON(http.StatusForbidden, schemas.ErrorResponse{Code: 403, Message: "Only admins with global scope can set the lead", Details: err.Error()})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, schemas.ErrorResponse{Code: 500, Message: "Internal server error", Details: err.Error()})
 		return
 	}
@@ -84,10 +90,11 @@ func (h *SuperGroupHandler) GetSuperGroup(c *gin.Context) {
 // @Param super_group body schemas.UpdateSuperGroupRequest true "SuperGroup data"
 // @Success 200 {object} schemas.SuccessResponse "Super group updated successfully"
 // @Failure 400 {object} schemas.ErrorResponse "Invalid input"
+// @Failure 403 {object} schemas.ErrorResponse "A group of the super group is outside your scope, or lead changed without global scope"
 // @Failure 500 {object} schemas.ErrorResponse "Internal server error"
 // @Router /api/super_groups/{id} [patch]
 func (h *SuperGroupHandler) UpdateSuperGroup(c *gin.Context) {
-	_, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, schemas.ErrorResponse{Code: 400, Message: "Invalid ID"})
 		return
@@ -99,9 +106,14 @@ func (h *SuperGroupHandler) UpdateSuperGroup(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, schemas.ErrorResponse{Code: 400, Message: "Invalid request body", Details: err.Error()})
 		return
 	}
+	superGroup.ID = uint(id)
 
-	err = h.superGroupUseCase.Update(superGroup)
+	err = h.superGroupUseCase.Update(currentUserID(c), superGroup)
 	if err != nil {
+		if errors.Is(err, usecases.ErrOutOfScope) {
+			c.JSON(http.StatusForbidden, schemas.ErrorResponse{Code: 403, Message: "Super group is outside your scope", Details: err.Error()})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, schemas.ErrorResponse{Code: 500, Message: "Internal server error", Details: err.Error()})
 		return
 	}
@@ -117,6 +129,7 @@ func (h *SuperGroupHandler) UpdateSuperGroup(c *gin.Context) {
 // @Param id path int true "SuperGroup ID"
 // @Success 200 {object} schemas.SuccessResponse "Super group deleted successfully"
 // @Failure 400 {object} schemas.ErrorResponse "Invalid super group ID"
+// @Failure 403 {object} schemas.ErrorResponse "A group of the super group is outside your scope"
 // @Failure 404 {object} schemas.ErrorResponse "Super group not found"
 // @Router /api/super_groups/{id} [delete]
 func (h *SuperGroupHandler) DeleteSuperGroup(c *gin.Context) {
@@ -126,7 +139,11 @@ func (h *SuperGroupHandler) DeleteSuperGroup(c *gin.Context) {
 		return
 	}
 
-	if err := h.superGroupUseCase.Delete(uint(id)); err != nil {
+	if err := h.superGroupUseCase.Delete(currentUserID(c), uint(id)); err != nil {
+		if errors.Is(err, usecases.ErrOutOfScope) {
+			c.JSON(http.StatusForbidden, schemas.ErrorResponse{Code: 403, Message: "Super group is outside your scope", Details: err.Error()})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, schemas.ErrorResponse{Code: 500, Message: "Internal server error", Details: err.Error()})
 		return
 	}
@@ -0,0 +1,221 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"a2sv.org/hub/Delivery/http/schemas"
+	"a2sv.org/hub/infrastructure/telegram_services"
+	"a2sv.org/hub/usecases"
+	"github.com/gin-gonic/gin"
+)
+
+// telegramSecretHeader carries the secret token the webhook was registered with
+const telegramSecretHeader = "X-Telegram-Bot-Api-Secret-Token"
+
+// TelegramHandler handles Telegram account linking, Telegram login and the bot webhook
+type TelegramHandler struct {
+	telegramUseCase *usecases.TelegramUseCase
+}
+
+// NewTelegramHandler creates a new TelegramHandler instance
+func NewTelegramHandler(telegramUseCase *usecases.TelegramUseCase) *TelegramHandler {
+	return &TelegramHandler{
+		telegramUseCase: telegramUseCase,
+	}
+}
+
+// CreateLinkCode handles creating a code to link a Telegram account through the bot
+// @Summary Create a Telegram link code
+// @Description Create a one-time code that links the Telegram account it is sent from to the caller. Send it to the bot, or open the returned link which sends it for you. The code expires after 15 minutes.
+// @Tags users
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Success 201 {object} schemas.SuccessResponse{data=schemas.TelegramLinkCodeResponse} "Code created"
+// @Failure 401 {object} schemas.ErrorResponse "Unauthorized - Invalid or missing token"
+// @Failure 500 {object} schemas.ErrorResponse "Internal server error"
+// @Failure 503 {object} schemas.ErrorResponse "Telegram is not configured"
+// @Router /api/users/me/telegram/code [post]
+func (h *TelegramHandler) CreateLinkCode(c *gin.Context) {
+	code, err := h.telegramUseCase.CreateLinkCode(currentUserID(c))
+	if err != nil {
+		respondTelegramError(c, err, "Failed to create Telegram link code")
+		return
+	}
+
+	c.JSON(http.StatusCreated, schemas.SuccessResponse{
+		Success: true,
+		Code:    http.StatusCreated,
+		Message: "Telegram link code created",
+		Data:    code,
+	})
+}
+
+// LinkAccount handles linking a Telegram account with the Login Widget
+// @Summary Link my Telegram account
+// @Description Link the Telegram account that signed the Telegram Login Widget data to the caller. The data must be at most 10 minutes old. A Telegram account linked to another user is rejected.
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param request body schemas.TelegramAuthRequest true "Login Widget data"
+// @Success 200 {object} schemas.SuccessResponse{data=schemas.TelegramAccountResponse} "Telegram account linked"
+// @Failure 400 {object} schemas.ErrorResponse "Invalid request format"
+// @Failure 401 {object} schemas.ErrorResponse "Invalid or expired Login Widget data"
+// @Failure 409 {object} schemas.ErrorResponse "Telegram account linked to another user"
+// @Failure 500 {object} schemas.ErrorResponse "Internal server error"
+// @Failure 503 {object} schemas.ErrorResponse "Telegram is not configured"
+// @Router /api/users/me/telegram [post]
+func (h *TelegramHandler) LinkAccount(c *gin.Context) {
+	var input schemas.TelegramAuthRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, schemas.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: "Invalid request format",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	account, err := h.telegramUseCase.LinkWithWidget(currentUserID(c), &input)
+	if err != nil {
+		respondTelegramError(c, err, "Failed to link Telegram account")
+		return
+	}
+
+	c.JSON(http.StatusOK, schemas.SuccessResponse{
+		Success: true,
+		Code:    http.StatusOK,
+		Message: "Telegram account linked",
+		Data:    account,
+	})
+}
+
+// UnlinkAccount handles removing the caller's Telegram account
+// @Summary Unlink my Telegram account
+// @Description Remove the caller's linked Telegram account, which then no longer signs in
+// @Tags users
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Success 200 {object} schemas.SuccessResponse "Telegram account unlinked"
+// @Failure 401 {object} schemas.ErrorResponse "Unauthorized - Invalid or missing token"
+// @Failure 500 {object} schemas.ErrorResponse "Internal server error"
+// @Router /api/users/me/telegram [delete]
+func (h *TelegramHandler) UnlinkAccount(c *gin.Context) {
+	if err := h.telegramUseCase.Unlink(currentUserID(c)); err != nil {
+		respondTelegramError(c, err, "Failed to unlink Telegram account")
+		return
+	}
+
+	c.JSON(http.StatusOK, schemas.SuccessResponse{
+		Success: true,
+		Code:    http.StatusOK,
+		Message: "Telegram account unlinked",
+	})
+}
+
+// Login handles signing in with the Telegram Login Widget
+// @Summary Sign in with Telegram
+// @Description Sign in the user linked to the Telegram account that signed the Telegram Login Widget data. Users with two-factor authentication get a challenge instead of tokens.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body schemas.TelegramAuthRequest true "Login Widget data"
+// @Success 200 {object} schemas.SuccessResponse{data=schemas.TokenPairResponse} "Login successful"
+// @Failure 400 {object} schemas.ErrorResponse "Invalid request format"
+// @Failure 401 {object} schemas.ErrorResponse "Invalid data or Telegram account not linked"
+// @Failure 403 {object} schemas.ErrorResponse "Account deactivated"
+// @Failure 500 {object} schemas.ErrorResponse "Internal server error"
+// @Failure 503 {object} schemas.ErrorResponse "Telegram is not configured"
+// @Router /api/auth/telegram [post]
+func (h *TelegramHandler) Login(c *gin.Context) {
+	var input schemas.TelegramAuthRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, schemas.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: "Invalid request format",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	tokens, err := h.telegramUseCase.Login(&input, loginClient(c))
+	if err != nil {
+		respondTelegramError(c, err, "Telegram sign-in failed")
+		return
+	}
+
+	message := "Login successful"
+	if tokens.TwoFactor != nil {
+		message = "Two-factor authentication required"
+	}
+	c.JSON(http.StatusOK, schemas.SuccessResponse{
+		Success: true,
+		Code:    http.StatusOK,
+		Message: message,
+		Data:    tokens,
+	})
+}
+
+// Webhook handles updates Telegram sends for the bot
+// @Summary Telegram bot webhook
+// @Description Receive bot updates from Telegram. A "/start CODE" message, or the bare code, links the sender's Telegram account to the user who created the code. The bot's reply is returned as a sendMessage call. Requests must carry the secret token the webhook was registered with.
+// @Tags telegram
+// @Accept json
+// @Produce json
+// @Param X-Telegram-Bot-Api-Secret-Token header string true "Webhook secret token"
+// @Success 200 {object} telegram_services.SendMessage "Bot reply, or an empty object"
+// @Failure 400 {object} schemas.ErrorResponse "Invalid update"
+// @Failure 401 {object} schemas.ErrorResponse "Invalid secret token"
+// @Failure 500 {object} schemas.ErrorResponse "Internal server error"
+// @Router /telegram/webhook [post]
+func (h *TelegramHandler) Webhook(c *gin.Context) {
+	if !h.telegramUseCase.VerifyWebhookSecret(c.GetHeader(telegramSecretHeader)) {
+		c.JSON(http.StatusUnauthorized, schemas.ErrorResponse{
+			Code:    http.StatusUnauthorized,
+			Message: "Invalid secret token",
+		})
+		return
+	}
+
+	var update telegram_services.Update
+	if err := c.ShouldBindJSON(&update); err != nil {
+		c.JSON(http.StatusBadRequest, schemas.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: "Invalid update",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	reply, err := h.telegramUseCase.HandleBotUpdate(&update)
+	if err != nil {
+		// Telegram retries the update until it gets a 2xx
+		respondTelegramError(c, err, "Failed to handle Telegram update")
+		return
+	}
+	if reply == nil {
+		c.JSON(http.StatusOK, gin.H{})
+		return
+	}
+	c.JSON(http.StatusOK, reply)
+}
+
+func respondTelegramError(c *gin.Context, err error, message string) {
+	status := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, usecases.ErrInvalidTelegramAuth), errors.Is(err, usecases.ErrTelegramNotLinked):
+		status = http.StatusUnauthorized
+	case errors.Is(err, usecases.ErrTelegramAccountTaken):
+		status = http.StatusConflict
+	case errors.Is(err, usecases.ErrAccountInactive):
+		status = http.StatusForbidden
+	case errors.Is(err, usecases.ErrTelegramNotConfigured):
+		status = http.StatusServiceUnavailable
+	}
+	c.JSON(status, schemas.ErrorResponse{
+		Code:    status,
+		Message: message,
+		Details: err.Error(),
+	})
+}
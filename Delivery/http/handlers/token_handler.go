@@ -0,0 +1,139 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"a2sv.org/hub/Delivery/http/schemas"
+	"a2sv.org/hub/Domain/entity"
+	"a2sv.org/hub/infrastructure/token_services"
+	"a2sv.org/hub/usecases"
+	"github.com/gin-gonic/gin"
+)
+
+// TokenHandler handles HTTP requests for refreshing and revoking tokens
+type TokenHandler struct {
+	tokenUseCase *usecases.TokenUseCase
+	keys         token_services.KeySource
+}
+
+// NewTokenHandler creates a new TokenHandler instance
+func NewTokenHandler(tokenUseCase *usecases.TokenUseCase, keys token_services.KeySource) *TokenHandler {
+	return &TokenHandler{
+		tokenUseCase: tokenUseCase,
+		keys:         keys,
+	}
+}
+
+// Refresh handles exchanging a refresh token for a new token pair
+// @Summary Refresh access token
+// @Description Exchange a refresh token for a new access token and a new refresh token. Each refresh token can be used once.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body schemas.RefreshTokenRequest true "Refresh token"
+// @Success 200 {object} schemas.SuccessResponse "Token refreshed successfully"
+// @Failure 400 {object} schemas.ErrorResponse "Invalid request format"
+// @Failure 401 {object} schemas.ErrorResponse "Invalid or expired refresh token"
+// @Failure 500 {object} schemas.ErrorResponse "Internal server error"
+// @Router /api/auth/refresh [post]
+func (h *TokenHandler) Refresh(c *gin.Context) {
+	var input schemas.RefreshTokenRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, schemas.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: "Invalid request format",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	tokens, err := h.tokenUseCase.Refresh(input.RefreshToken)
+	if err != nil {
+		if errors.Is(err, usecases.ErrInvalidRefreshToken) {
+			c.JSON(http.StatusUnauthorized, schemas.ErrorResponse{
+				Code:    http.StatusUnauthorized,
+				Message: "Invalid refresh token",
+				Details: err.Error(),
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, schemas.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to refresh token",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, schemas.SuccessResponse{
+		Success: true,
+		Code:    http.StatusOK,
+		Message: "Token refreshed successfully",
+		Data:    tokens,
+	})
+}
+
+// Logout handles ending the current session
+// @Summary Logout
+// @Description Revoke the Bearer access token and its session, and/or the given refresh token
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param Authorization header string false "Bearer token"
+// @Param request body schemas.LogoutRequest false "Refresh token to revoke"
+// @Success 200 {object} schemas.SuccessResponse "Logout successful"
+// @Failure 400 {object} schemas.ErrorResponse "Neither an access token nor a refresh token was provided"
+// @Failure 401 {object} schemas.ErrorResponse "Invalid refresh token"
+// @Failure 500 {object} schemas.ErrorResponse "Internal server error"
+// @Router /api/auth/logout [post]
+func (h *TokenHandler) Logout(c *gin.Context) {
+	var input schemas.LogoutRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&input); err != nil {
+			c.JSON(http.StatusBadRequest, schemas.ErrorResponse{
+				Code:    http.StatusBadRequest,
+				Message: "Invalid request format",
+				Details: err.Error(),
+			})
+			return
+		}
+	}
+
+	// /api/auth is not behind the auth middleware, so parse the token here
+	var claims *entity.Claims
+	if parsed, err := token_services.GetClaims(c, h.keys); err == nil {
+		claims = parsed
+	}
+	if claims == nil && input.RefreshToken == "" {
+		c.JSON(http.StatusBadRequest, schemas.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: "Nothing to log out",
+			Details: "Provide a valid Bearer token or a refresh_token",
+		})
+		return
+	}
+
+	if err := h.tokenUseCase.Logout(claims, input.RefreshToken); err != nil {
+		if errors.Is(err, usecases.ErrInvalidRefreshToken) {
+			c.JSON(http.StatusUnauthorized, schemas.ErrorResponse{
+				Code:    http.StatusUnauthorized,
+				Message: "Invalid refresh token",
+				Details: err.Error(),
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, schemas.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to logout",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, schemas.SuccessResponse{
+		Success: true,
+		Code:    http.StatusOK,
+		Message: "Logout successful",
+	})
+}
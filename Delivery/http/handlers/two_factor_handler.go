@@ -0,0 +1,287 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"a2sv.org/hub/Delivery/http/schemas"
+	"a2sv.org/hub/usecases"
+	"github.com/gin-gonic/gin"
+)
+
+// TwoFactorHandler handles HTTP requests for two-factor authentication
+type TwoFactorHandler struct {
+	twoFactorUseCase *usecases.TwoFactorUseCase
+}
+
+// NewTwoFactorHandler creates a new TwoFactorHandler instance
+func NewTwoFactorHandler(twoFactorUseCase *usecases.TwoFactorUseCase) *TwoFactorHandler {
+	return &TwoFactorHandler{
+		twoFactorUseCase: twoFactorUseCase,
+	}
+}
+
+// CompleteLogin handles the second step of a login
+// @Summary Complete a two-factor login
+// @Description Trade the challenge token returned by a login and a TOTP or recovery code for an access and refresh token. When the login was enrolling, the code must be a TOTP code and the response carries the new recovery codes.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body schemas.TwoFactorLoginRequest true "Challenge token and code"
+// @Success 200 {object} schemas.SuccessResponse{data=schemas.TwoFactorLoginResponse} "Login successful"
+// @Failure 400 {object} schemas.ErrorResponse "Invalid request format or enrollment not started"
+// @Failure 401 {object} schemas.ErrorResponse "Invalid code or expired challenge"
+// @Failure 403 {object} schemas.ErrorResponse "Account deactivated"
+// @Failure 429 {object} schemas.ErrorResponse "Account or IP locked out after repeated failures"
+// @Failure 500 {object} schemas.ErrorResponse "Internal server error"
+// @Router /api/auth/login/2fa [post]
+func (h *TwoFactorHandler) CompleteLogin(c *gin.Context) {
+	var input schemas.TwoFactorLoginRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, schemas.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: "Invalid request format",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	result, err := h.twoFactorUseCase.CompleteLogin(input.ChallengeToken, input.Code, loginClient(c))
+	if respondLockedOut(c, err) {
+		return
+	}
+	if err != nil {
+		respondTwoFactorError(c, err, "Two-factor login failed")
+		return
+	}
+
+	c.JSON(http.StatusOK, schemas.SuccessResponse{
+		Success: true,
+		Code:    http.StatusOK,
+		Message: "Login successful",
+		Data:    result,
+	})
+}
+
+// SetupLogin handles enrollment during a login
+// @Summary Set up two-factor authentication during login
+// @Description Get a TOTP secret and provisioning URI for a login challenge with setup_required. Scan the URI, then send the first code to POST /api/auth/login/2fa.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body schemas.TwoFactorSetupRequest true "Challenge token"
+// @Success 200 {object} schemas.SuccessResponse{data=schemas.TwoFactorEnrollmentResponse} "Secret created"
+// @Failure 400 {object} schemas.ErrorResponse "Invalid request format"
+// @Failure 401 {object} schemas.ErrorResponse "Expired challenge"
+// @Failure 409 {object} schemas.ErrorResponse "Two-factor authentication already enabled"
+// @Failure 500 {object} schemas.ErrorResponse "Internal server error"
+// @Router /api/auth/login/2fa/setup [post]
+func (h *TwoFactorHandler) SetupLogin(c *gin.Context) {
+	var input schemas.TwoFactorSetupRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, schemas.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: "Invalid request format",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	enrollment, err := h.twoFactorUseCase.SetupLogin(input.ChallengeToken)
+	if err != nil {
+		respondTwoFactorError(c, err, "Failed to set up two-factor authentication")
+		return
+	}
+
+	c.JSON(http.StatusOK, schemas.SuccessResponse{
+		Success: true,
+		Code:    http.StatusOK,
+		Message: "Two-factor secret created",
+		Data:    enrollment,
+	})
+}
+
+// GetStatus handles reading the caller's 2FA state
+// @Summary Get my two-factor status
+// @Description Report whether two-factor authentication is enabled, whether the caller's role requires it and how many recovery codes are left
+// @Tags users
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Success 200 {object} schemas.SuccessResponse{data=schemas.TwoFactorStatusResponse} "Status retrieved"
+// @Failure 401 {object} schemas.ErrorResponse "Unauthorized - Invalid or missing token"
+// @Failure 500 {object} schemas.ErrorResponse "Internal server error"
+// @Router /api/users/me/2fa [get]
+func (h *TwoFactorHandler) GetStatus(c *gin.Context) {
+	status, err := h.twoFactorUseCase.Status(currentUserID(c))
+	if err != nil {
+		respondTwoFactorError(c, err, "Failed to get two-factor status")
+		return
+	}
+
+	c.JSON(http.StatusOK, schemas.SuccessResponse{
+		Success: true,
+		Code:    http.StatusOK,
+		Message: "Two-factor status retrieved successfully",
+		Data:    status,
+	})
+}
+
+// Enroll handles starting 2FA enrollment
+// @Summary Start two-factor enrollment
+// @Description Create a TOTP secret and provisioning URI for an authenticator app. Enrollment takes effect after POST /api/users/me/2fa/confirm.
+// @Tags users
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Success 200 {object} schemas.SuccessResponse{data=schemas.TwoFactorEnrollmentResponse} "Secret created"
+// @Failure 401 {object} schemas.ErrorResponse "Unauthorized - Invalid or missing token"
+// @Failure 409 {object} schemas.ErrorResponse "Two-factor authentication already enabled"
+// @Failure 500 {object} schemas.ErrorResponse "Internal server error"
+// @Router /api/users/me/2fa/enroll [post]
+func (h *TwoFactorHandler) Enroll(c *gin.Context) {
+	enrollment, err := h.twoFactorUseCase.Enroll(currentUserID(c))
+	if err != nil {
+		respondTwoFactorError(c, err, "Failed to start two-factor enrollment")
+		return
+	}
+
+	c.JSON(http.StatusOK, schemas.SuccessResponse{
+		Success: true,
+		Code:    http.StatusOK,
+		Message: "Two-factor secret created",
+		Data:    enrollment,
+	})
+}
+
+// Confirm handles finishing 2FA enrollment
+// @Summary Confirm two-factor enrollment
+// @Description Enable two-factor authentication with a first TOTP code. The response holds recovery codes that are shown only once.
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param request body schemas.TwoFactorCodeRequest true "TOTP code"
+// @Success 200 {object} schemas.SuccessResponse{data=schemas.RecoveryCodesResponse} "Two-factor authentication enabled"
+// @Failure 400 {object} schemas.ErrorResponse "Invalid request format or enrollment not started"
+// @Failure 401 {object} schemas.ErrorResponse "Invalid code"
+// @Failure 409 {object} schemas.ErrorResponse "Two-factor authentication already enabled"
+// @Failure 500 {object} schemas.ErrorResponse "Internal server error"
+// @Router /api/users/me/2fa/confirm [post]
+func (h *TwoFactorHandler) Confirm(c *gin.Context) {
+	var input schemas.TwoFactorCodeRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, schemas.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: "Invalid request format",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	codes, err := h.twoFactorUseCase.Confirm(currentUserID(c), input.Code)
+	if err != nil {
+		respondTwoFactorError(c, err, "Failed to enable two-factor authentication")
+		return
+	}
+
+	c.JSON(http.StatusOK, schemas.SuccessResponse{
+		Success: true,
+		Code:    http.StatusOK,
+		Message: "Two-factor authentication enabled",
+		Data:    codes,
+	})
+}
+
+// RegenerateRecoveryCodes handles replacing the caller's recovery codes
+// @Summary Regenerate recovery codes
+// @Description Replace every recovery code. The old codes stop working.
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param request body schemas.TwoFactorCodeRequest true "TOTP or recovery code"
+// @Success 200 {object} schemas.SuccessResponse{data=schemas.RecoveryCodesResponse} "Recovery codes regenerated"
+// @Failure 400 {object} schemas.ErrorResponse "Invalid request format or 2FA not enabled"
+// @Failure 401 {object} schemas.ErrorResponse "Invalid code"
+// @Failure 500 {object} schemas.ErrorResponse "Internal server error"
+// @Router /api/users/me/2fa/recovery-codes [post]
+func (h *TwoFactorHandler) RegenerateRecoveryCodes(c *gin.Context) {
+	var input schemas.TwoFactorCodeRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, schemas.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: "Invalid request format",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	codes, err := h.twoFactorUseCase.RegenerateRecoveryCodes(currentUserID(c), input.Code)
+	if err != nil {
+		respondTwoFactorError(c, err, "Failed to regenerate recovery codes")
+		return
+	}
+
+	c.JSON(http.StatusOK, schemas.SuccessResponse{
+		Success: true,
+		Code:    http.StatusOK,
+		Message: "Recovery codes regenerated",
+		Data:    codes,
+	})
+}
+
+// Disable handles turning 2FA off
+// @Summary Disable two-factor authentication
+// @Description Turn two-factor authentication off and drop the recovery codes. Not allowed when the caller's role requires 2FA.
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param request body schemas.TwoFactorCodeRequest true "TOTP or recovery code"
+// @Success 200 {object} schemas.SuccessResponse "Two-factor authentication disabled"
+// @Failure 400 {object} schemas.ErrorResponse "Invalid request format or 2FA not enabled"
+// @Failure 401 {object} schemas.ErrorResponse "Invalid code"
+// @Failure 403 {object} schemas.ErrorResponse "Two-factor authentication is required for your role"
+// @Failure 500 {object} schemas.ErrorResponse "Internal server error"
+// @Router /api/users/me/2fa [delete]
+func (h *TwoFactorHandler) Disable(c *gin.Context) {
+	var input schemas.TwoFactorCodeRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, schemas.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: "Invalid request format",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	if err := h.twoFactorUseCase.Disable(currentUserID(c), input.Code); err != nil {
+		respondTwoFactorError(c, err, "Failed to disable two-factor authentication")
+		return
+	}
+
+	c.JSON(http.StatusOK, schemas.SuccessResponse{
+		Success: true,
+		Code:    http.StatusOK,
+		Message: "Two-factor authentication disabled",
+	})
+}
+
+// respondTwoFactorError maps two-factor usecase errors to HTTP statuses
+func respondTwoFactorError(c *gin.Context, err error, message string) {
+	status := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, usecases.ErrInvalidTwoFactorCode), errors.Is(err, usecases.ErrInvalidChallenge):
+		status = http.StatusUnauthorized
+	case errors.Is(err, usecases.ErrTwoFactorNotEnrolled):
+		status = http.StatusBadRequest
+	case errors.Is(err, usecases.ErrTwoFactorEnabled):
+		status = http.StatusConflict
+	case errors.Is(err, usecases.ErrTwoFactorRequired), errors.Is(err, usecases.ErrAccountInactive):
+		status = http.StatusForbidden
+	}
+	c.JSON(status, schemas.ErrorResponse{
+		Code:    status,
+		Message: message,
+		Details: err.Error(),
+	})
+}
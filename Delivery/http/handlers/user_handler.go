@@ -6,11 +6,14 @@
 package handlers
 
 import (
+	"errors"
 	"strconv"
 
+	"a2sv.org/hub/Delivery/http/middleware"
 	"a2sv.org/hub/Delivery/http/schemas"
 	"a2sv.org/hub/usecases"
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
 )
 
 // UserHandler handles HTTP requests for user operations
@@ -37,7 +40,7 @@ func NewUserHandler(userUseCase usecases.UserUseCase) *UserHandler {
 // @Failure 400 {object} schemas.ErrorResponse "Invalid request format or validation error"
 // @Failure 401 {object} schemas.ErrorResponse "Unauthorized - Invalid or missing token"
 // @Failure 403 {object} schemas.ErrorResponse "Forbidden - Insufficient permissions"
-// @Failure 409 {object} schemas.ErrorResponse "Conflict - User already exists"
+// @Failure 409 {object} schemas.ErrorResponse "Conflict - User already exists, or a deleted user has the email"
 // @Failure 500 {object} schemas.ErrorResponse "Internal server error"
 // @Router /api/users [post]
 func (h *UserHandler) CreateUser(c *gin.Context) {
@@ -51,8 +54,24 @@ func (h *UserHandler) CreateUser(c *gin.Context) {
 		return
 	}
 
-	user, err := h.userUseCase.Create(&input)
+	user, err := h.userUseCase.Create(currentUserID(c), &input)
 	if err != nil {
+		if errors.Is(err, usecases.ErrOutOfScope) {
+			c.JSON(403, schemas.ErrorResponse{
+				Code:    403,
+				Message: "Forbidden - User is outside of your groups",
+				Details: err.Error(),
+			})
+			return
+		}
+		if errors.Is(err, usecases.ErrRoleEscalation) {
+			c.JSON(403, schemas.ErrorResponse{
+				Code:    403,
+				Message: "Forbidden - Role is more powerful than your own",
+				Details: err.Error(),
+			})
+			return
+		}
 		if err.Error() == "email already exists" {
 			c.JSON(409, schemas.ErrorResponse{
 				Code:    409,
@@ -61,6 +80,14 @@ func (h *UserHandler) CreateUser(c *gin.Context) {
 			})
 			return
 		}
+		if errors.Is(err, usecases.ErrEmailOfDeletedUser) {
+			c.JSON(409, schemas.ErrorResponse{
+				Code:    409,
+				Message: "Email belongs to a deleted user",
+				Details: err.Error(),
+			})
+			return
+		}
 		c.JSON(500, schemas.ErrorResponse{
 			Code:    500,
 			Message: "Failed to create user",
@@ -121,6 +148,46 @@ func (h *UserHandler) GetUserByID(c *gin.Context) {
 	})
 }
 
+// GetCurrentUser handles retrieving the authenticated user
+// @Summary Get current user
+// @Description Get detailed information about the user identified by the Bearer token
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Success 200 {object} schemas.SuccessResponse "User details retrieved successfully"
+// @Failure 401 {object} schemas.ErrorResponse "Unauthorized - Invalid or missing token"
+// @Failure 404 {object} schemas.ErrorResponse "User not found"
+// @Router /api/users/me [get]
+func (h *UserHandler) GetCurrentUser(c *gin.Context) {
+	claims, ok := middleware.CurrentClaims(c)
+	if !ok {
+		c.JSON(401, schemas.ErrorResponse{
+			Code:    401,
+			Message: "Unauthorized",
+			Details: "missing authentication claims",
+		})
+		return
+	}
+
+	user, err := h.userUseCase.GetByID(claims.ID)
+	if err != nil {
+		c.JSON(404, schemas.ErrorResponse{
+			Code:    404,
+			Message: "User not found",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(200, schemas.SuccessResponse{
+		Success: true,
+		Code:    200,
+		Message: "User details retrieved successfully",
+		Data:    user,
+	})
+}
+
 // UpdateUser handles updating a user's information
 // @Summary Update user details
 // @Description Update an existing user's information
@@ -135,6 +202,7 @@ func (h *UserHandler) GetUserByID(c *gin.Context) {
 // @Failure 401 {object} schemas.ErrorResponse "Unauthorized - Invalid or missing token"
 // @Failure 403 {object} schemas.ErrorResponse "Forbidden - Insufficient permissions"
 // @Failure 404 {object} schemas.ErrorResponse "User not found"
+// @Failure 409 {object} schemas.ErrorResponse "Email used by another user, or by a deleted user"
 // @Failure 500 {object} schemas.ErrorResponse "Internal server error"
 // @Router /api/users/{id} [patch]
 func (h *UserHandler) UpdateUser(c *gin.Context) {
@@ -158,8 +226,50 @@ func (h *UserHandler) UpdateUser(c *gin.Context) {
 		return
 	}
 
-	err = h.userUseCase.Update(uint(id), &input)
+	// Users editing their own profile may not change their role or group
+	if middleware.IsSelfAccess(c) && (input.RoleID != nil || input.GroupID != nil) {
+		c.JSON(403, schemas.ErrorResponse{
+			Code:    403,
+			Message: "Forbidden - Insufficient permissions",
+			Details: "role_id and group_id can only be changed by an administrator",
+		})
+		return
+	}
+
+	err = h.userUseCase.Update(currentUserID(c), uint(id), &input)
 	if err != nil {
+		if errors.Is(err, usecases.ErrOutOfScope) {
+			c.JSON(403, schemas.ErrorResponse{
+				Code:    403,
+				Message: "Forbidden - User is outside of your groups",
+				Details: err.Error(),
+			})
+			return
+		}
+		if errors.Is(err, usecases.ErrRoleEscalation) {
+			c.JSON(403, schemas.ErrorResponse{
+				Code:    403,
+				Message: "Forbidden - Role is more powerful than your own",
+				Details: err.Error(),
+			})
+			return
+		}
+		if errors.Is(err, usecases.ErrPasswordChangeSelf) {
+			c.JSON(400, schemas.ErrorResponse{
+				Code:    400,
+				Message: "Invalid request format",
+				Details: err.Error(),
+			})
+			return
+		}
+		if errors.Is(err, usecases.ErrEmailAlreadyRegistered) || errors.Is(err, usecases.ErrEmailOfDeletedUser) {
+			c.JSON(409, schemas.ErrorResponse{
+				Code:    409,
+				Message: "Email already in use",
+				Details: err.Error(),
+			})
+			return
+		}
 		if err.Error() == "user not found" {
 			c.JSON(404, schemas.ErrorResponse{
 				Code:    404,
@@ -176,6 +286,8 @@ func (h *UserHandler) UpdateUser(c *gin.Context) {
 		return
 	}
 
+	// Never echo the password back
+	input.Password = nil
 	c.JSON(200, schemas.SuccessResponse{
 		Success: true,
 		Code:    200,
@@ -186,34 +298,133 @@ func (h *UserHandler) UpdateUser(c *gin.Context) {
 
 // DeleteUser handles deleting a user
 // @Summary Delete user
-// @Description Delete an existing user
+// @Description Soft delete a user and end their sessions. The user's submissions, attendance and stipends are kept, and the user can be restored with POST /api/users/{id}/restore.
 // @Tags users
 // @Accept json
 // @Produce json
 // @Param Authorization header string true "Bearer token"
 // @Param id path int true "User ID" minimum(1)
 // @Success 200 {object} schemas.SuccessResponse "User deleted successfully"
-// @Failure 400 {object} schemas.ErrorResponse "Invalid user ID format"
+// @Failure 400 {object} schemas.ErrorResponse "Invalid user ID format or deleting yourself"
 // @Failure 401 {object} schemas.ErrorResponse "Unauthorized - Invalid or missing token"
 // @Failure 403 {object} schemas.ErrorResponse "Forbidden - Insufficient permissions"
 // @Failure 404 {object} schemas.ErrorResponse "User not found"
 // @Failure 500 {object} schemas.ErrorResponse "Internal server error"
 // @Router /api/users/{id} [delete]
 func (h *UserHandler) DeleteUser(c *gin.Context) {
-	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
-	if err != nil {
+	id, ok := userIDParam(c)
+	if !ok {
+		return
+	}
+	if err := h.userUseCase.Delete(currentUserID(c), id); err != nil {
+		respondUserLifecycleError(c, err, "Failed to delete user")
+		return
+	}
+
+	c.JSON(200, schemas.SuccessResponse{
+		Success: true,
+		Code:    200,
+		Message: "User deleted successfully",
+		Data:    nil,
+	})
+}
+
+// DeactivateUser handles deactivating a user
+// @Summary Deactivate user
+// @Description Block a user from signing in and end their sessions. Deactivated users are left out of GET /api/users unless inactive=true.
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param id path int true "User ID" minimum(1)
+// @Success 200 {object} schemas.SuccessResponse "User deactivated successfully"
+// @Failure 400 {object} schemas.ErrorResponse "Invalid user ID format or deactivating yourself"
+// @Failure 401 {object} schemas.ErrorResponse "Unauthorized - Invalid or missing token"
+// @Failure 403 {object} schemas.ErrorResponse "Forbidden - Insufficient permissions"
+// @Failure 404 {object} schemas.ErrorResponse "User not found"
+// @Failure 500 {object} schemas.ErrorResponse "Internal server error"
+// @Router /api/users/{id}/deactivate [post]
+func (h *UserHandler) DeactivateUser(c *gin.Context) {
+	id, ok := userIDParam(c)
+	if !ok {
+		return
+	}
+	if err := h.userUseCase.Deactivate(currentUserID(c), id); err != nil {
+		respondUserLifecycleError(c, err, "Failed to deactivate user")
+		return
+	}
+
+	c.JSON(200, schemas.SuccessResponse{
+		Success: true,
+		Code:    200,
+		Message: "User deactivated successfully",
+	})
+}
+
+// ReactivateUser handles reactivating a user
+// @Summary Reactivate user
+// @Description Let a deactivated user sign in again. Erased users cannot be reactivated.
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param id path int true "User ID" minimum(1)
+// @Success 200 {object} schemas.SuccessResponse "User reactivated successfully"
+// @Failure 400 {object} schemas.ErrorResponse "Invalid user ID format"
+// @Failure 401 {object} schemas.ErrorResponse "Unauthorized - Invalid or missing token"
+// @Failure 403 {object} schemas.ErrorResponse "Forbidden - Insufficient permissions"
+// @Failure 404 {object} schemas.ErrorResponse "User not found"
+// @Failure 409 {object} schemas.ErrorResponse "The user's personal data has been erased"
+// @Failure 500 {object} schemas.ErrorResponse "Internal server error"
+// @Router /api/users/{id}/reactivate [post]
+func (h *UserHandler) ReactivateUser(c *gin.Context) {
+	id, ok := userIDParam(c)
+	if !ok {
+		return
+	}
+	if err := h.userUseCase.Reactivate(currentUserID(c), id); err != nil {
+		respondUserLifecycleError(c, err, "Failed to reactivate user")
+		return
+	}
+
+	c.JSON(200, schemas.SuccessResponse{
+		Success: true,
+		Code:    200,
+		Message: "User reactivated successfully",
+	})
+}
+
+// ListDeletedUsers handles listing deleted users
+// @Summary List deleted users
+// @Description Get a page of the deleted users, most recently deleted first
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param page query int false "Page number" minimum(1) default(1)
+// @Param page_size query int false "Number of items per page" minimum(1) maximum(100) default(10)
+// @Success 200 {object} schemas.SuccessResponse{data=schemas.UserListResponse} "List of deleted users retrieved successfully"
+// @Failure 400 {object} schemas.ErrorResponse "Invalid query parameters"
+// @Failure 401 {object} schemas.ErrorResponse "Unauthorized - Invalid or missing token"
+// @Failure 403 {object} schemas.ErrorResponse "Forbidden - Insufficient permissions"
+// @Failure 500 {object} schemas.ErrorResponse "Internal server error"
+// @Router /api/users/deleted [get]
+func (h *UserHandler) ListDeletedUsers(c *gin.Context) {
+	var query schemas.DeletedUserListQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
 		c.JSON(400, schemas.ErrorResponse{
 			Code:    400,
-			Message: "Invalid user ID",
-			Details: "User ID must be a positive integer",
+			Message: "Invalid query parameters",
+			Details: err.Error(),
 		})
 		return
 	}
 
-	if err := h.userUseCase.Delete(uint(id)); err != nil {
+	result, err := h.userUseCase.ListDeleted(query.Page, query.PageSize)
+	if err != nil {
 		c.JSON(500, schemas.ErrorResponse{
 			Code:    500,
-			Message: "Failed to delete user",
+			Message: "Failed to list deleted users",
 			Details: err.Error(),
 		})
 		return
@@ -222,24 +433,132 @@ func (h *UserHandler) DeleteUser(c *gin.Context) {
 	c.JSON(200, schemas.SuccessResponse{
 		Success: true,
 		Code:    200,
-		Message: "User deleted successfully",
-		Data:    nil,
+		Message: "List of deleted users retrieved successfully",
+		Data:    result,
+	})
+}
+
+// RestoreUser handles restoring a deleted user
+// @Summary Restore deleted user
+// @Description Undo the deletion of a user. A user deactivated before the deletion stays deactivated.
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param id path int true "User ID" minimum(1)
+// @Success 200 {object} schemas.SuccessResponse "User restored successfully"
+// @Failure 400 {object} schemas.ErrorResponse "Invalid user ID format"
+// @Failure 401 {object} schemas.ErrorResponse "Unauthorized - Invalid or missing token"
+// @Failure 403 {object} schemas.ErrorResponse "Forbidden - Insufficient permissions"
+// @Failure 404 {object} schemas.ErrorResponse "Deleted user not found"
+// @Failure 500 {object} schemas.ErrorResponse "Internal server error"
+// @Router /api/users/{id}/restore [post]
+func (h *UserHandler) RestoreUser(c *gin.Context) {
+	id, ok := userIDParam(c)
+	if !ok {
+		return
+	}
+	if err := h.userUseCase.Restore(currentUserID(c), id); err != nil {
+		respondUserLifecycleError(c, err, "Failed to restore user")
+		return
+	}
+
+	c.JSON(200, schemas.SuccessResponse{
+		Success: true,
+		Code:    200,
+		Message: "User restored successfully",
+	})
+}
+
+// PurgeUser handles permanently removing a deleted user
+// @Summary Purge deleted user
+// @Description Permanently remove a deleted user along with their sessions, tokens, linked accounts and login history. Users with submissions, attendance, stipends or other records on file cannot be purged.
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param id path int true "User ID" minimum(1)
+// @Success 200 {object} schemas.SuccessResponse "User purged successfully"
+// @Failure 400 {object} schemas.ErrorResponse "Invalid user ID format"
+// @Failure 401 {object} schemas.ErrorResponse "Unauthorized - Invalid or missing token"
+// @Failure 403 {object} schemas.ErrorResponse "Forbidden - Insufficient permissions"
+// @Failure 404 {object} schemas.ErrorResponse "Deleted user not found"
+// @Failure 409 {object} schemas.ErrorResponse "The user has history that must be kept"
+// @Failure 500 {object} schemas.ErrorResponse "Internal server error"
+// @Router /api/users/{id}/purge [delete]
+func (h *UserHandler) PurgeUser(c *gin.Context) {
+	id, ok := userIDParam(c)
+	if !ok {
+		return
+	}
+	if err := h.userUseCase.Purge(currentUserID(c), id); err != nil {
+		respondUserLifecycleError(c, err, "Failed to purge user")
+		return
+	}
+
+	c.JSON(200, schemas.SuccessResponse{
+		Success: true,
+		Code:    200,
+		Message: "User purged successfully",
+	})
+}
+
+// userIDParam parses the id path parameter, answering 400 when it is invalid
+func userIDParam(c *gin.Context) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil || id == 0 {
+		c.JSON(400, schemas.ErrorResponse{
+			Code:    400,
+			Message: "Invalid user ID",
+			Details: "User ID must be a positive integer",
+		})
+		return 0, false
+	}
+	return uint(id), true
+}
+
+// respondUserLifecycleError maps deactivation, deletion, restore and purge
+// errors to HTTP statuses
+func respondUserLifecycleError(c *gin.Context, err error, message string) {
+	status := 500
+	switch {
+	case errors.Is(err, usecases.ErrUserLifecycleSelf):
+		status = 400
+	case errors.Is(err, usecases.ErrOutOfScope), errors.Is(err, usecases.ErrRoleEscalation):
+		status = 403
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		status = 404
+	case errors.Is(err, usecases.ErrUserHasHistory),
+		errors.Is(err, usecases.ErrUserErased):
+		status = 409
+	}
+	c.JSON(status, schemas.ErrorResponse{
+		Code:    status,
+		Message: message,
+		Details: err.Error(),
 	})
 }
 
 // ListUsers handles listing users with pagination and filters
 // @Summary List users
-// @Description Get a paginated list of users with optional filters
+// @Description Get a page of users matching the filters, in the requested order. Text filters match a part of the field, ignoring case.
 // @Tags users
 // @Accept json
 // @Produce json
 // @Param Authorization header string true "Bearer token"
 // @Param page query int false "Page number" minimum(1) default(1)
 // @Param page_size query int false "Number of items per page" minimum(1) maximum(100) default(10)
-// @Param search query string false "Search term for filtering users"
+// @Param search query string false "Search the name or email"
+// @Param name query string false "Filter by name"
+// @Param email query string false "Filter by email"
+// @Param university query string false "Filter by university"
+// @Param country_id query int false "Filter by country ID" minimum(1)
 // @Param role_id query int false "Filter by role ID" minimum(1)
 // @Param group_id query int false "Filter by group ID" minimum(1)
-// @Success 200 {object} schemas.SuccessResponse "List of users retrieved successfully"
+// @Param inactive query bool false "List deactivated users instead of active ones" default(false)
+// @Param graduation_year query int false "Filter by year of the expected graduation date"
+// @Param sort query string false "Sort field, prefixed with - for descending" Enums(name, -name, email, -email, university, -university, created_at, -created_at, expected_graduation_date, -expected_graduation_date) default(-created_at)
+// @Success 200 {object} schemas.SuccessResponse{data=schemas.UserListResponse} "List of users retrieved successfully"
 // @Failure 400 {object} schemas.ErrorResponse "Invalid query parameters"
 // @Failure 401 {object} schemas.ErrorResponse "Unauthorized - Invalid or missing token"
 // @Failure 403 {object} schemas.ErrorResponse "Forbidden - Insufficient permissions"
@@ -247,63 +566,14 @@ func (h *UserHandler) DeleteUser(c *gin.Context) {
 // @Router /api/users [get]
 func (h *UserHandler) ListUsers(c *gin.Context) {
 	var query schemas.UserListQuery
-
-	// Parse page parameter
-	page, err := strconv.Atoi(c.DefaultQuery("page", "1"))
-	if err != nil || page < 1 {
-		c.JSON(400, schemas.ErrorResponse{
-			Code:    400,
-			Message: "Invalid page number",
-			Details: "Page number must be a positive integer",
-		})
-		return
-	}
-	query.Page = page
-
-	// Parse page_size parameter
-	pageSize, err := strconv.Atoi(c.DefaultQuery("page_size", "10"))
-	if err != nil || pageSize < 1 || pageSize > 100 {
+	if err := c.ShouldBindQuery(&query); err != nil {
 		c.JSON(400, schemas.ErrorResponse{
 			Code:    400,
-			Message: "Invalid page size",
-			Details: "Page size must be between 1 and 100",
+			Message: "Invalid query parameters",
+			Details: err.Error(),
 		})
 		return
 	}
-	query.PageSize = pageSize
-
-	// Parse search parameter
-	query.Search = c.Query("search")
-
-	// Parse role_id parameter
-	if roleIDStr := c.Query("role_id"); roleIDStr != "" {
-		roleID, err := strconv.ParseUint(roleIDStr, 10, 32)
-		if err != nil {
-			c.JSON(400, schemas.ErrorResponse{
-				Code:    400,
-				Message: "Invalid role ID",
-				Details: "Role ID must be a positive integer",
-			})
-			return
-		}
-		roleIDUint := uint(roleID)
-		query.RoleID = &roleIDUint
-	}
-
-	// Parse group_id parameter
-	if groupIDStr := c.Query("group_id"); groupIDStr != "" {
-		groupID, err := strconv.ParseUint(groupIDStr, 10, 32)
-		if err != nil {
-			c.JSON(400, schemas.ErrorResponse{
-				Code:    400,
-				Message: "Invalid group ID",
-				Details: "Group ID must be a positive integer",
-			})
-			return
-		}
-		groupIDUint := uint(groupID)
-		query.GroupID = &groupIDUint
-	}
 
 	result, err := h.userUseCase.List(&query)
 	if err != nil {
@@ -325,7 +595,7 @@ func (h *UserHandler) ListUsers(c *gin.Context) {
 
 // Login handles user authentication
 // @Summary Login user
-// @Description Authenticate a user with email and password
+// @Description Authenticate a user with email and password. Users with two-factor authentication, or whose role requires it, get a two_factor challenge instead of tokens and finish with POST /api/auth/login/2fa.
 // @Tags auth
 // @Accept json
 // @Produce json
@@ -333,6 +603,8 @@ func (h *UserHandler) ListUsers(c *gin.Context) {
 // @Success 200 {object} schemas.SuccessResponse "Login successful"
 // @Failure 400 {object} schemas.ErrorResponse "Invalid request format"
 // @Failure 401 {object} schemas.ErrorResponse "Invalid credentials"
+// @Failure 403 {object} schemas.ErrorResponse "Account deactivated"
+// @Failure 429 {object} schemas.ErrorResponse "Account or IP locked out after repeated failures"
 // @Failure 500 {object} schemas.ErrorResponse "Internal server error"
 // @Router /api/auth/login [post]
 func (h *UserHandler) Login(c *gin.Context) {
@@ -346,7 +618,18 @@ func (h *UserHandler) Login(c *gin.Context) {
 		return
 	}
 
-	result, err := h.userUseCase.Login(input.Email, input.Password)
+	result, err := h.userUseCase.Login(input.Email, input.Password, loginClient(c))
+	if respondLockedOut(c, err) {
+		return
+	}
+	if errors.Is(err, usecases.ErrAccountInactive) {
+		c.JSON(403, schemas.ErrorResponse{
+			Code:    403,
+			Message: "Account deactivated",
+			Details: err.Error(),
+		})
+		return
+	}
 	if err != nil {
 		c.JSON(401, schemas.ErrorResponse{
 			Code:    401,
@@ -356,10 +639,14 @@ func (h *UserHandler) Login(c *gin.Context) {
 		return
 	}
 
+	message := "Login successful"
+	if result.TwoFactor != nil {
+		message = "Two-factor authentication required"
+	}
 	c.JSON(200, schemas.SuccessResponse{
 		Success: true,
 		Code:    200,
-		Message: "Login successful",
+		Message: message,
 		Data:    result,
 	})
 }
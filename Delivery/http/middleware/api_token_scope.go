@@ -0,0 +1,69 @@
+package middleware
+
+import (
+	"net/http"
+
+	"a2sv.org/hub/Delivery/http/schemas"
+	"a2sv.org/hub/Domain/entity"
+	"github.com/gin-gonic/gin"
+)
+
+// ScopeAllows reports whether the credentials behind the claims may use the scope.
+// Login sessions are not scoped; API tokens are limited to the scopes they were created with.
+func ScopeAllows(claims *entity.Claims, scope string) bool {
+	if claims.APITokenID == 0 {
+		return true
+	}
+	for _, s := range claims.Scopes {
+		if s == scope || s == entity.PermissionAll {
+			return true
+		}
+	}
+	return false
+}
+
+// RequireScope guards write routes that need no role permission, so that
+// API tokens can only use them when created with the scope.
+func RequireScope(scope string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		claims, ok := CurrentClaims(c)
+		if !ok {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, schemas.ErrorResponse{
+				Code:    401,
+				Message: "invalid token",
+				Details: "missing authentication claims",
+			})
+			return
+		}
+		if !ScopeAllows(claims, scope) {
+			abortMissingScope(c, scope)
+			return
+		}
+		c.Next()
+	}
+}
+
+// RejectAPITokens restricts a route to login sessions, e.g. so an API token
+// cannot mint new tokens with wider scopes.
+func RejectAPITokens() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		claims, ok := CurrentClaims(c)
+		if ok && claims.APITokenID != 0 {
+			c.AbortWithStatusJSON(http.StatusForbidden, schemas.ErrorResponse{
+				Code:    403,
+				Message: "unauthorized User",
+				Details: "this endpoint cannot be used with an API token",
+			})
+			return
+		}
+		c.Next()
+	}
+}
+
+func abortMissingScope(c *gin.Context, scope string) {
+	c.AbortWithStatusJSON(http.StatusForbidden, schemas.ErrorResponse{
+		Code:    403,
+		Message: "unauthorized User",
+		Details: "API token is missing scope " + scope,
+	})
+}
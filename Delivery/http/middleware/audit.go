@@ -0,0 +1,131 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+
+	"a2sv.org/hub/infrastructure/ip_services"
+	"a2sv.org/hub/usecases"
+	"github.com/gin-gonic/gin"
+)
+
+// AuditRecorder stores an audit entry for every mutating API call
+type AuditRecorder interface {
+	Snapshot(entityType, entityID string) (map[string]interface{}, error)
+	Record(entry usecases.AuditEntry, before, after map[string]interface{}) error
+}
+
+// Audit records every successful POST, PUT, PATCH and DELETE under /api with
+// the caller, the route and the row of the target entity before and after
+// the call. The entity is the first path segment after /api/ and its ID the
+// next segment when that is a parameter or "me". Failed calls changed nothing
+// and are skipped.
+func Audit(recorder AuditRecorder, skipPrefixes ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		switch c.Request.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+			c.Next()
+			return
+		}
+		for _, prefix := range skipPrefixes {
+			if strings.HasPrefix(c.Request.URL.Path, prefix) {
+				c.Next()
+				return
+			}
+		}
+
+		entry := usecases.AuditEntry{
+			Method: c.Request.Method,
+			Route:  c.FullPath(),
+			Path:   c.Request.URL.Path,
+			IP:     ip_services.GetClientIP(c),
+		}
+		entry.EntityType, entry.EntityID = auditTarget(c)
+
+		before, err := recorder.Snapshot(entry.EntityType, entry.EntityID)
+		if err != nil {
+			log.Printf("Failed to snapshot %s %s for the audit log: %v", entry.EntityType, entry.EntityID, err)
+		}
+
+		// Creates only learn the new ID from the response
+		var body *bodyRecorder
+		if entry.EntityID == "" {
+			body = &bodyRecorder{ResponseWriter: c.Writer}
+			c.Writer = body
+		}
+
+		c.Next()
+
+		entry.Status = c.Writer.Status()
+		if entry.Status >= http.StatusBadRequest {
+			return
+		}
+		if claims, ok := CurrentClaims(c); ok {
+			entry.ActorID = &claims.ID
+			if claims.ImpersonatorID != 0 {
+				entry.ImpersonatorID = &claims.ImpersonatorID
+			}
+		}
+		if body != nil {
+			entry.EntityID = createdID(body.buf.Bytes())
+		}
+
+		after, err := recorder.Snapshot(entry.EntityType, entry.EntityID)
+		if err != nil {
+			log.Printf("Failed to snapshot %s %s for the audit log: %v", entry.EntityType, entry.EntityID, err)
+		}
+		if err := recorder.Record(entry, before, after); err != nil {
+			log.Printf("Failed to write audit log for %s %s: %v", entry.Method, entry.Path, err)
+		}
+	}
+}
+
+// auditTarget returns the entity type and ID addressed by the route
+func auditTarget(c *gin.Context) (string, string) {
+	parts := strings.Split(strings.TrimPrefix(c.FullPath(), "/api/"), "/")
+	if len(parts) < 2 {
+		return parts[0], ""
+	}
+	switch {
+	case parts[1] == "me":
+		if claims, ok := CurrentClaims(c); ok {
+			return parts[0], fmt.Sprint(claims.ID)
+		}
+	case strings.HasPrefix(parts[1], ":"):
+		return parts[0], c.Param(parts[1][1:])
+	}
+	return parts[0], ""
+}
+
+// createdID reads data.id from a success response
+func createdID(body []byte) string {
+	var response struct {
+		Data struct {
+			ID json.Number `json:"id"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(body, &response); err != nil {
+		return ""
+	}
+	return response.Data.ID.String()
+}
+
+// bodyRecorder keeps a copy of the response body
+type bodyRecorder struct {
+	gin.ResponseWriter
+	buf bytes.Buffer
+}
+
+func (w *bodyRecorder) Write(b []byte) (int, error) {
+	w.buf.Write(b)
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *bodyRecorder) WriteString(s string) (int, error) {
+	w.buf.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
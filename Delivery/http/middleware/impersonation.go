@@ -0,0 +1,36 @@
+package middleware
+
+import (
+	"net/http"
+
+	"a2sv.org/hub/Delivery/http/schemas"
+	"github.com/gin-gonic/gin"
+)
+
+// BlockImpersonatedWrites keeps impersonation tokens read-only: every request
+// other than GET, HEAD and OPTIONS is rejected, except to the allowed paths.
+func BlockImpersonatedWrites(allowedPaths ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		claims, ok := CurrentClaims(c)
+		if !ok || claims.ImpersonatorID == 0 {
+			c.Next()
+			return
+		}
+		switch c.Request.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+			c.Next()
+			return
+		}
+		for _, path := range allowedPaths {
+			if c.Request.URL.Path == path {
+				c.Next()
+				return
+			}
+		}
+		c.AbortWithStatusJSON(http.StatusForbidden, schemas.ErrorResponse{
+			Code:    http.StatusForbidden,
+			Message: "Forbidden - impersonation is read-only",
+			Details: "writes are not allowed while impersonating a user",
+		})
+	}
+}
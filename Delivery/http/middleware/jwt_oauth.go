@@ -1,38 +1,129 @@
 package middleware
 
 import (
-	"fmt"
+	"errors"
 	"net/http"
-	"os"
+	"strings"
 
-	"github.com/dgrijalva/jwt-go"
+	"a2sv.org/hub/Delivery/http/schemas"
+	"a2sv.org/hub/Domain/entity"
+	"a2sv.org/hub/infrastructure/token_services"
+	"a2sv.org/hub/usecases"
 	"github.com/gin-gonic/gin"
 )
 
-// JWTAuthMiddleware validates the JWT passed in the Authorization header.
-func JWTAuthMiddleware() gin.HandlerFunc {
+// ClaimsContextKey is the gin context key holding the authenticated user's claims.
+const ClaimsContextKey = "claims"
+
+// RevocationChecker reports whether a validly signed token was revoked server-side
+type RevocationChecker interface {
+	IsRevoked(claims *entity.Claims) (bool, error)
+}
+
+// APITokenAuthenticator resolves a personal API token into the claims of its owner
+type APITokenAuthenticator interface {
+	Authenticate(token string) (*entity.Claims, error)
+}
+
+// JWTAuthMiddleware validates the Bearer token passed in the Authorization header,
+// which is either a JWT verified with keys or a personal API token, rejects
+// revoked tokens and stores the resulting *entity.Claims in the gin context.
+// Requests whose path starts with one of the public prefixes skip authentication.
+func JWTAuthMiddleware(keys token_services.KeySource, revocations RevocationChecker, apiTokens APITokenAuthenticator, publicPrefixes ...string) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		tokenString := c.GetHeader("Authorization")
-		if tokenString == "" {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Missing token"})
+		for _, prefix := range publicPrefixes {
+			if strings.HasPrefix(c.Request.URL.Path, prefix) {
+				c.Next()
+				return
+			}
+		}
+
+		tokenString, err := token_services.GetBearerToken(c)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, schemas.ErrorResponse{
+				Code:    http.StatusUnauthorized,
+				Message: "invalid token",
+				Details: err.Error(),
+			})
 			return
 		}
 
-		// Parse and validate the token
-		token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-			// Ensure the token method conforms to "SigningMethodHMAC"
-			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-				return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
-			}
-			return []byte(os.Getenv("JWT_SECRET")), nil
-		})
-		if err != nil || !token.Valid {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
+		if token_services.IsAPIToken(tokenString) {
+			authenticateAPIToken(c, apiTokens, tokenString)
+			return
+		}
+
+		claims, err := token_services.ParseJWTToken(tokenString, keys)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, schemas.ErrorResponse{
+				Code:    http.StatusUnauthorized,
+				Message: "invalid token",
+				Details: err.Error(),
+			})
+			return
+		}
+
+		revoked, err := revocations.IsRevoked(claims)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusInternalServerError, schemas.ErrorResponse{
+				Code:    http.StatusInternalServerError,
+				Message: "failed to verify token",
+				Details: err.Error(),
+			})
+			return
+		}
+		if revoked {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, schemas.ErrorResponse{
+				Code:    http.StatusUnauthorized,
+				Message: "invalid token",
+				Details: "token has been revoked",
+			})
 			return
 		}
 
 		// Store token claims in the context for downstream handlers
-		c.Set("user", token.Claims)
+		c.Set(ClaimsContextKey, claims)
 		c.Next()
 	}
 }
+
+// authenticateAPIToken admits a request made with a personal API token.
+// GET requests need the read scope; writes are checked by the route's guard.
+func authenticateAPIToken(c *gin.Context, apiTokens APITokenAuthenticator, tokenString string) {
+	claims, err := apiTokens.Authenticate(tokenString)
+	if err != nil {
+		if errors.Is(err, usecases.ErrInvalidAPIToken) {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, schemas.ErrorResponse{
+				Code:    http.StatusUnauthorized,
+				Message: "invalid token",
+				Details: err.Error(),
+			})
+			return
+		}
+		c.AbortWithStatusJSON(http.StatusInternalServerError, schemas.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "failed to verify token",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	if (c.Request.Method == http.MethodGet || c.Request.Method == http.MethodHead) && !ScopeAllows(claims, entity.ScopeRead) {
+		abortMissingScope(c, entity.ScopeRead)
+		return
+	}
+
+	c.Set(ClaimsContextKey, claims)
+	c.Next()
+}
+
+// CurrentClaims returns the claims stored by JWTAuthMiddleware.
+// The boolean is false when the request was not authenticated.
+func CurrentClaims(c *gin.Context) (*entity.Claims, bool) {
+	value, exists := c.Get(ClaimsContextKey)
+	if !exists {
+		return nil, false
+	}
+	claims, ok := value.(*entity.Claims)
+	return claims, ok && claims != nil
+}
@@ -1,19 +1,23 @@
 package middleware
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
 
 	"a2sv.org/hub/Delivery/http/schemas"
-	"a2sv.org/hub/infrastructure/token_services"
 	"a2sv.org/hub/usecases"
 	"github.com/gin-gonic/gin"
 )
 
+// SelfAccessContextKey marks requests admitted by SelfOrPermission on the user's own record
+const SelfAccessContextKey = "self_access"
+
 type AuthController struct {
 	UserUsecases usecases.UserUseCaseInterface
 	RoleUsecases usecases.RoleUseCaseInterface
 }
+
 func NewRoleMiddleware(userUsecase usecases.UserUseCaseInterface, roleUsecase usecases.RoleUseCaseInterface) AuthController {
 	return AuthController{
 		UserUsecases: userUsecase,
@@ -48,30 +52,26 @@ func NewRoleMiddleware(userUsecase usecases.UserUseCaseInterface, roleUsecase us
 //			c.Next()
 //		}
 //	}
-func (ac *AuthController) RoleMiddleware(role string) gin.HandlerFunc {
+
+// RequirePermission allows the request only when the authenticated user's role
+// holds the given permission (or the wildcard permission). API tokens must also
+// have been created with the permission as a scope.
+func (ac *AuthController) RequirePermission(permission string) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		defer func() {
-			if r := recover(); r != nil {
-				c.JSON(http.StatusInternalServerError, schemas.ErrorResponse{
-					Code:    500,
-					Message: "unexpected error",
-					Details: "unexpected error",
-				})
-				c.Abort()
-			}
-		}()
-		// Extract claims from token
-		claims, err := token_services.GetClaims(c)
-		if err != nil {
+		claims, ok := CurrentClaims(c)
+		if !ok {
 			c.AbortWithStatusJSON(http.StatusUnauthorized, schemas.ErrorResponse{
-				Code:    404,
+				Code:    401,
 				Message: "invalid token",
-				Details: err.Error(),
+				Details: "missing authentication claims",
 			})
 			return
 		}
-		// Fetch User details
-		User, err := ac.UserUsecases.GetByID(claims.ID)
+		if !ScopeAllows(claims, permission) {
+			abortMissingScope(c, permission)
+			return
+		}
+		allowed, err := ac.hasPermission(claims.ID, permission)
 		if err != nil {
 			c.AbortWithStatusJSON(http.StatusNotFound, schemas.ErrorResponse{
 				Code:    404,
@@ -80,35 +80,80 @@ func (ac *AuthController) RoleMiddleware(role string) gin.HandlerFunc {
 			})
 			return
 		}
-		if User == nil {
+		if !allowed {
+			c.AbortWithStatusJSON(http.StatusForbidden, schemas.ErrorResponse{
+				Code:    403,
+				Message: "unauthorized User",
+				Details: "missing permission " + permission,
+			})
+			return
+		}
+		c.Next()
+	}
+}
+
+// SelfOrPermission allows the request when the authenticated user's role holds
+// the permission, or when the :id path parameter is the authenticated user.
+// In the latter case SelfAccessContextKey is set so handlers can restrict
+// which fields a user may change on their own record. API tokens need the
+// permission as a scope either way.
+func (ac *AuthController) SelfOrPermission(permission string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		claims, ok := CurrentClaims(c)
+		if !ok {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, schemas.ErrorResponse{
+				Code:    401,
+				Message: "invalid token",
+				Details: "missing authentication claims",
+			})
+			return
+		}
+		if !ScopeAllows(claims, permission) {
+			abortMissingScope(c, permission)
+			return
+		}
+		allowed, err := ac.hasPermission(claims.ID, permission)
+		if err != nil {
 			c.AbortWithStatusJSON(http.StatusNotFound, schemas.ErrorResponse{
 				Code:    404,
-				Message: "User record is missing",
-				Details: "no such user",
+				Message: "error trying to find  the User record",
+				Details: err.Error(),
 			})
 			return
 		}
-		if role != "" {
-			userRole, err := ac.RoleUsecases.GetByID(User.RoleID)
-			if err != nil {
-				c.AbortWithStatusJSON(http.StatusNotFound, schemas.ErrorResponse{
-					Code:    404,
-					Message: "error find role record",
-					Details: err.Error(),
-				})
-				return
-			}
-			if userRole.Type != role {
-				c.AbortWithStatusJSON(http.StatusForbidden, schemas.ErrorResponse{
-					Code:    404,
-					Message: "unauthorized User",
-					Details: "unauthorized",
-				})
-				return
-			}
+		if allowed {
+			c.Next()
+			return
 		}
-		c.Next()
+		if c.Param("id") == strconv.FormatUint(uint64(claims.ID), 10) {
+			c.Set(SelfAccessContextKey, true)
+			c.Next()
+			return
+		}
+		c.AbortWithStatusJSON(http.StatusForbidden, schemas.ErrorResponse{
+			Code:    403,
+			Message: "unauthorized User",
+			Details: "missing permission " + permission,
+		})
+	}
+}
+
+// IsSelfAccess reports whether SelfOrPermission admitted the request only
+// because the user is acting on their own record.
+func IsSelfAccess(c *gin.Context) bool {
+	return c.GetBool(SelfAccessContextKey)
+}
+
+// hasPermission looks up the user's current role so that role changes apply immediately
+func (ac *AuthController) hasPermission(userID uint, permission string) (bool, error) {
+	user, err := ac.UserUsecases.GetByID(userID)
+	if err != nil {
+		return false, err
+	}
+	if user == nil {
+		return false, errors.New("no such user")
 	}
+	return ac.RoleUsecases.HasPermission(user.RoleID, permission)
 }
 
 func (ac *AuthController) SelfMiddleware() gin.HandlerFunc {
@@ -135,12 +180,12 @@ func (ac *AuthController) SelfMiddleware() gin.HandlerFunc {
 		}
 		id = uid
 		// Extract claims from token
-		claims, err := token_services.GetClaims(c)
-		if err != nil {
+		claims, ok := CurrentClaims(c)
+		if !ok {
 			c.AbortWithStatusJSON(http.StatusUnauthorized, schemas.ErrorResponse{
-				Code:    404,
+				Code:    401,
 				Message: "invalid token",
-				Details: err.Error(),
+				Details: "missing authentication claims",
 			})
 			return
 		}
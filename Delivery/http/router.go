@@ -1,11 +1,14 @@
 package http
 
 import (
+	"log"
 	"os"
 	"time"
 
 	"a2sv.org/hub/Delivery/http/handlers"
 	"a2sv.org/hub/Delivery/http/middleware"
+	"a2sv.org/hub/Domain/entity"
+	"a2sv.org/hub/infrastructure/ip_services"
 	_ "a2sv.org/hub/docs" // 👈 Important: docs generated by swag init
 	"a2sv.org/hub/usecases"
 	"github.com/gin-contrib/cors"
@@ -47,6 +50,22 @@ func SetupRouter(
 	sessionUsecase usecases.SessionUsecase,
 	problemTrackUsecase usecases.ProblemTracksUsecase,
 	exerciseUsecase usecases.ExerciseUseCase,
+	hoaUseCase *usecases.HOAUseCase,
+	tokenUseCase *usecases.TokenUseCase,
+	apiTokenUseCase *usecases.APITokenUseCase,
+	passwordUseCase *usecases.PasswordUseCase,
+	inviteUseCase *usecases.InviteUseCase,
+	oauthUseCase *usecases.OAuthUseCase,
+	loginHistoryUseCase *usecases.LoginHistoryUseCase,
+	lockoutUseCase *usecases.LockoutUseCase,
+	twoFactorUseCase *usecases.TwoFactorUseCase,
+	auditUseCase *usecases.AuditUseCase,
+	impersonationUseCase *usecases.ImpersonationUseCase,
+	signingKeyUseCase *usecases.SigningKeyUseCase,
+	telegramUseCase *usecases.TelegramUseCase,
+	alumniUseCase *usecases.AlumniUseCase,
+	fileUseCase *usecases.FileUseCase,
+	personalDataUseCase *usecases.PersonalDataUseCase,
 	db *gorm.DB, // assuming you have a gorm.DB instance
 
 ) *gin.Engine {
@@ -56,6 +75,9 @@ func SetupRouter(
 	// @name Authorization
 	// @description Type "Bearer" followed by a space and JWT token.
 	router := gin.Default()
+	if err := router.SetTrustedProxies(ip_services.TrustedProxiesFromEnv()); err != nil {
+		log.Fatalf("Invalid TRUSTED_PROXIES: %v", err)
+	}
 
 	// Configure CORS to allow all origins and necessary headers
 	config := cors.Config{
@@ -68,6 +90,12 @@ func SetupRouter(
 	router.Use(cors.New(config))
 
 	router.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
+
+	// Telegram authenticates its webhook calls with a secret token; they come
+	// from a few shared IPs and must not be rate limited
+	telegramHandler := handlers.NewTelegramHandler(telegramUseCase)
+	router.POST("/telegram/webhook", telegramHandler.Webhook)
+
 	router.Use(middleware.UpstashRateLimiter(10, 60, os.Getenv("REDIS_URL"), os.Getenv("REDIS_TOKEN")))
 
 	userHandler := handlers.NewUserHandler(userUseCase)
@@ -81,120 +109,220 @@ func SetupRouter(
 	superToGroupHandler := handlers.NewSuperToGroupHandler(superToGroupUseCase)
 	submissionHandler := handlers.NewSubmissionHandeler(submissionUsecase)
 	stippendHandler := handlers.NewStippendHandler(stippendUsecase)
-	// RoleMiddleware := middleware.NewRoleMiddleware(&userUseCase, &roleUseCase)
+	authz := middleware.NewRoleMiddleware(&userUseCase, &roleUseCase)
 	problemHandler := handlers.NewProblemHandler(&problemUsecase)
 	recentActionHandler := handlers.NewRecentActionHandler(recentActionUseCase)
 	sessionHandler := handlers.NewSessionHandler(sessionUsecase)
 
 	problemTrackHandler := handlers.NewProblemTrackHandler(&problemTrackUsecase)
 	exerciseHandler := handlers.NewExerciseHandler(&exerciseUsecase)
+	hoaHandler := handlers.NewHOAHandler(hoaUseCase)
+	tokenHandler := handlers.NewTokenHandler(tokenUseCase, signingKeyUseCase)
+	apiTokenHandler := handlers.NewAPITokenHandler(apiTokenUseCase)
+	passwordHandler := handlers.NewPasswordHandler(passwordUseCase)
+	inviteHandler := handlers.NewInviteHandler(inviteUseCase)
+	oauthHandler := handlers.NewOAuthHandler(oauthUseCase)
+	loginHistoryHandler := handlers.NewLoginHistoryHandler(loginHistoryUseCase)
+	lockoutHandler := handlers.NewLockoutHandler(lockoutUseCase)
+	twoFactorHandler := handlers.NewTwoFactorHandler(twoFactorUseCase)
+	auditLogHandler := handlers.NewAuditLogHandler(auditUseCase)
+	impersonationHandler := handlers.NewImpersonationHandler(impersonationUseCase)
+	loginSessionHandler := handlers.NewLoginSessionHandler(tokenUseCase)
+	jwksHandler := handlers.NewJWKSHandler(signingKeyUseCase)
+	alumniHandler := handlers.NewAlumniHandler(alumniUseCase)
+	fileHandler := handlers.NewFileHandler(fileUseCase)
+	personalDataHandler := handlers.NewPersonalDataHandler(personalDataUseCase)
+
+	// Signed download URLs authorize themselves, so files are served outside
+	// the authenticated /api group
+	router.GET("/api/files/*key", fileHandler.DownloadFile)
 
 	// API routes group
 	api := router.Group("/api")
+	// Every /api route requires a valid Bearer token except the auth endpoints
+	api.Use(middleware.JWTAuthMiddleware(signingKeyUseCase, tokenUseCase, apiTokenUseCase, "/api/auth/"))
+	// Impersonation tokens are read-only
+	api.Use(middleware.BlockImpersonatedWrites("/api/impersonations/end"))
+	// Every other write is recorded in the audit log
+	api.Use(middleware.Audit(auditUseCase, "/api/auth/"))
 	{
 		// OAuth
 		authGroup := api.Group("/auth")
 		{
 			authGroup.POST("/login", userHandler.Login)
+			authGroup.POST("/login/2fa", twoFactorHandler.CompleteLogin)
+			authGroup.POST("/login/2fa/setup", twoFactorHandler.SetupLogin)
+			authGroup.POST("/refresh", tokenHandler.Refresh)
+			authGroup.POST("/logout", tokenHandler.Logout)
+			authGroup.POST("/forgot-password", passwordHandler.ForgotPassword)
+			authGroup.POST("/reset-password", passwordHandler.ResetPassword)
+			authGroup.POST("/invites/redeem", inviteHandler.RedeemInvite)
+			authGroup.POST("/telegram", telegramHandler.Login)
 
-			authGroup.GET("/google", handlers.InitGoogleOAuth)
-
-			authGroup.GET("/google/callback", handlers.HandleGoogleCallback)
+			authGroup.GET("/:provider", oauthHandler.InitOAuth)
+			authGroup.GET("/:provider/callback", oauthHandler.HandleOAuthCallback)
 		}
 		// User routes
 		users := api.Group("/users")
 		{
-			// users.POST("", RoleMiddleware.RoleMiddleware("Head of Academy"), userHandler.CreateUser)
-			// users.PATCH("/:id", RoleMiddleware.RoleMiddleware("Head of Academy"), RoleMiddleware.SelfMiddleware(), userHandler.UpdateUser)
-			// users.DELETE("/:id", RoleMiddleware.RoleMiddleware("Head of Academy"), userHandler.DeleteUser)
-			users.POST("", userHandler.CreateUser)
-			users.PATCH("/:id", userHandler.UpdateUser)
-			users.DELETE("/:id", userHandler.DeleteUser)
+			users.POST("", authz.RequirePermission(entity.PermissionUserWrite), userHandler.CreateUser)
+			users.PATCH("/:id", authz.SelfOrPermission(entity.PermissionUserWrite), userHandler.UpdateUser)
+			users.DELETE("/:id", authz.RequirePermission(entity.PermissionUserDelete), userHandler.DeleteUser)
+			users.GET("/alumni/preview", authz.RequirePermission(entity.PermissionUserWrite), alumniHandler.PreviewTransition)
+			users.GET("/deleted", authz.RequirePermission(entity.PermissionUserDelete), userHandler.ListDeletedUsers)
+			users.POST("/:id/restore", authz.RequirePermission(entity.PermissionUserDelete), userHandler.RestoreUser)
+			users.DELETE("/:id/purge", authz.RequirePermission(entity.PermissionUserDelete), userHandler.PurgeUser)
+			users.POST("/:id/deactivate", authz.RequirePermission(entity.PermissionUserWrite), userHandler.DeactivateUser)
+			users.POST("/:id/reactivate", authz.RequirePermission(entity.PermissionUserWrite), userHandler.ReactivateUser)
+			users.PUT("/:id/photo", authz.SelfOrPermission(entity.PermissionUserWrite), fileHandler.UploadPhoto)
+			users.DELETE("/:id/photo", authz.SelfOrPermission(entity.PermissionUserWrite), fileHandler.DeletePhoto)
+			users.PUT("/:id/cv", authz.SelfOrPermission(entity.PermissionUserWrite), fileHandler.UploadCV)
+			users.DELETE("/:id/cv", authz.SelfOrPermission(entity.PermissionUserWrite), fileHandler.DeleteCV)
+			users.POST("/:id/erase", authz.RequirePermission(entity.PermissionUserDelete), personalDataHandler.EraseUserData)
+			users.POST("/:id/unlock", authz.RequirePermission(entity.PermissionUserWrite), lockoutHandler.UnlockUser)
+			users.GET("/:id/sessions", authz.RequirePermission(entity.PermissionUserWrite), loginSessionHandler.ListUserSessions)
+			users.DELETE("/:id/sessions", authz.RequirePermission(entity.PermissionUserWrite), loginSessionHandler.RevokeUserSessions)
+			users.DELETE("/:id/sessions/:session_id", authz.RequirePermission(entity.PermissionUserWrite), loginSessionHandler.RevokeUserSession)
+			users.POST("/:id/impersonate", middleware.RejectAPITokens(), authz.RequirePermission(entity.PermissionUserImpersonate), impersonationHandler.StartImpersonation)
 
 			users.GET("", userHandler.ListUsers)
+			users.GET("/me", userHandler.GetCurrentUser)
+			users.GET("/me/logins", loginHistoryHandler.ListMyLogins)
+			users.GET("/me/sessions", middleware.RejectAPITokens(), loginSessionHandler.ListMySessions)
+			users.DELETE("/me/sessions", middleware.RejectAPITokens(), loginSessionHandler.RevokeMySessions)
+			users.DELETE("/me/sessions/:session_id", middleware.RejectAPITokens(), loginSessionHandler.RevokeMySession)
+			users.GET("/me/2fa", middleware.RejectAPITokens(), twoFactorHandler.GetStatus)
+			users.POST("/me/2fa/enroll", middleware.RejectAPITokens(), twoFactorHandler.Enroll)
+			users.POST("/me/2fa/confirm", middleware.RejectAPITokens(), twoFactorHandler.Confirm)
+			users.POST("/me/2fa/recovery-codes", middleware.RejectAPITokens(), twoFactorHandler.RegenerateRecoveryCodes)
+			users.DELETE("/me/2fa", middleware.RejectAPITokens(), twoFactorHandler.Disable)
+			users.POST("/me/password", middleware.RejectAPITokens(), passwordHandler.ChangePassword)
+			users.POST("/me/telegram/code", middleware.RejectAPITokens(), telegramHandler.CreateLinkCode)
+			users.POST("/me/telegram", middleware.RejectAPITokens(), telegramHandler.LinkAccount)
+			users.DELETE("/me/telegram", middleware.RejectAPITokens(), telegramHandler.UnlinkAccount)
+			users.POST("/me/oauth/:provider", middleware.RejectAPITokens(), oauthHandler.LinkOAuth)
+			users.GET("/me/export", middleware.RejectAPITokens(), personalDataHandler.ExportMyData)
+			users.POST("/me/erase", middleware.RejectAPITokens(), personalDataHandler.EraseMyData)
+			users.GET("/me/tokens", middleware.RejectAPITokens(), apiTokenHandler.ListAPITokens)
+			users.POST("/me/tokens", middleware.RejectAPITokens(), apiTokenHandler.CreateAPIToken)
+			users.DELETE("/me/tokens/:token_id", middleware.RejectAPITokens(), apiTokenHandler.RevokeAPIToken)
 			users.GET("/:id", userHandler.GetUserByID)
 		}
 
+		// Audit log routes; the log is read-only
+		api.GET("/audit-logs", authz.RequirePermission(entity.PermissionAuditRead), auditLogHandler.ListAuditLogs)
+		api.GET("/impersonations", authz.RequirePermission(entity.PermissionAuditRead), impersonationHandler.ListImpersonations)
+		api.POST("/impersonations/end", impersonationHandler.EndImpersonation)
+
 		// Registration routes
 		registration := api.Group("/registration")
 		{
-			registration.POST("/bulk", registrationHandler.RegisterBulkUsers)
-			registration.POST("/bulk/role/:role_id", registrationHandler.RegisterUsersWithRole)
+			registration.POST("/bulk", authz.RequirePermission(entity.PermissionRegistrationWrite), registrationHandler.RegisterBulkUsers)
+			registration.POST("/bulk/role/:role_id", authz.RequirePermission(entity.PermissionRegistrationWrite), registrationHandler.RegisterUsersWithRole)
+			registration.POST("/bulk/upload", authz.RequirePermission(entity.PermissionRegistrationWrite), registrationHandler.RegisterUsersFromFile)
+			registration.GET("/jobs/:job_id", authz.RequirePermission(entity.PermissionRegistrationWrite), registrationHandler.GetJob)
+			registration.GET("/jobs/:job_id/report", authz.RequirePermission(entity.PermissionRegistrationWrite), registrationHandler.DownloadJobReport)
+		}
+
+		// Invite routes
+		invites := api.Group("/invites")
+		{
+			invites.POST("", authz.RequirePermission(entity.PermissionInviteWrite), inviteHandler.CreateInvite)
+			invites.POST("/email", authz.RequirePermission(entity.PermissionInviteWrite), inviteHandler.EmailInvites)
+			invites.GET("", authz.RequirePermission(entity.PermissionInviteWrite), inviteHandler.ListInvites)
+			invites.DELETE("/:id", authz.RequirePermission(entity.PermissionInviteWrite), inviteHandler.RevokeInvite)
 		}
 
 		// Role routes
 		roles := api.Group("/roles") //correct
 		{
-			roles.POST("", roleHandler.CreateRole)
+			roles.POST("", authz.RequirePermission(entity.PermissionRoleWrite), roleHandler.CreateRole)
 			roles.GET("", roleHandler.ListRoles)
 			roles.GET("/:id", roleHandler.GetRoleByID)
-			roles.PATCH("/:id", roleHandler.UpdateRole)
-			roles.DELETE("/:id", roleHandler.DeleteRole)
+			roles.PATCH("/:id", authz.RequirePermission(entity.PermissionRoleWrite), roleHandler.UpdateRole)
+			roles.DELETE("/:id", authz.RequirePermission(entity.PermissionRoleWrite), roleHandler.DeleteRole)
+			roles.GET("/:id/permissions", roleHandler.ListRolePermissions)
+			roles.POST("/:id/permissions", authz.RequirePermission(entity.PermissionRoleWrite), roleHandler.AddRolePermission)
+			roles.DELETE("/:id/permissions/:permission", authz.RequirePermission(entity.PermissionRoleWrite), roleHandler.RemoveRolePermission)
 		}
 
 		// Session routes
 		sessions := api.Group("/sessions")
 		{
-			sessions.POST("", sessionHandler.CreateSession)
+			sessions.POST("", authz.RequirePermission(entity.PermissionSessionWrite), sessionHandler.CreateSession)
 			sessions.GET("", sessionHandler.ListSessions)
 			sessions.GET("/:id", sessionHandler.GetSessionByID)
-			sessions.PATCH("/:id", sessionHandler.UpdateSession)
-			sessions.DELETE("/:id", sessionHandler.DeleteSession)
+			sessions.PATCH("/:id", authz.RequirePermission(entity.PermissionSessionWrite), sessionHandler.UpdateSession)
+			sessions.DELETE("/:id", authz.RequirePermission(entity.PermissionSessionWrite), sessionHandler.DeleteSession)
+			sessions.POST("/:id/resources", authz.RequirePermission(entity.PermissionSessionWrite), fileHandler.UploadSessionResource)
+			sessions.GET("/:id/resources", fileHandler.ListSessionResources)
+			sessions.DELETE("/:id/resources/:attachment_id", authz.RequirePermission(entity.PermissionSessionWrite), fileHandler.DeleteSessionResource)
+		}
+		// Post attachments; only the author of a post can change them
+		posts := api.Group("/posts")
+		{
+			posts.POST("/:id/attachments", middleware.RequireScope(entity.ScopePostWrite), fileHandler.UploadPostAttachment)
+			posts.GET("/:id/attachments", fileHandler.ListPostAttachments)
+			posts.DELETE("/:id/attachments/:attachment_id", middleware.RequireScope(entity.ScopePostWrite), fileHandler.DeletePostAttachment)
 		}
+
 		// Recent Action routes
 		recentActions := api.Group("/recent_actions")
 		{
-			recentActions.POST("", recentActionHandler.CreateRecentAction)
+			recentActions.POST("", middleware.RequireScope(entity.ScopeRecentActionWrite), recentActionHandler.CreateRecentAction)
 			recentActions.GET("", recentActionHandler.ListRecentActions)
 			recentActions.GET("/:id", recentActionHandler.GetRecentActionByID)
-			recentActions.PATCH("/:id", recentActionHandler.UpdateRecentAction)
-			recentActions.DELETE("/:id", recentActionHandler.DeleteRecentAction)
+			recentActions.PATCH("/:id", middleware.RequireScope(entity.ScopeRecentActionWrite), recentActionHandler.UpdateRecentAction)
+			recentActions.DELETE("/:id", middleware.RequireScope(entity.ScopeRecentActionWrite), recentActionHandler.DeleteRecentAction)
 		}
 		// Group routes
 		groups := api.Group("/groups")
 		{
-			groups.POST("", groupHandler.CreateGroup)
+			groups.POST("", authz.RequirePermission(entity.PermissionGroupWrite), groupHandler.CreateGroup)
 			groups.GET("", groupHandler.ListGroups)
 			groups.GET("/:id", groupHandler.GetGroupByID)
 			groups.GET("/country/:country_id", groupHandler.GetGroupsByCountryID)
-			groups.PATCH("/:id", groupHandler.UpdateGroup)
-			groups.DELETE("/:id", groupHandler.DeleteGroup)
+			groups.PATCH("/:id", authz.RequirePermission(entity.PermissionGroupWrite), groupHandler.UpdateGroup)
+			groups.DELETE("/:id", authz.RequirePermission(entity.PermissionGroupWrite), groupHandler.DeleteGroup)
+			groups.GET("/:id/hoas", hoaHandler.ListGroupHOAs)
+			groups.POST("/:id/hoas", authz.RequirePermission(entity.PermissionGroupWrite), hoaHandler.AssignGroupHOA)
+			groups.DELETE("/:id/hoas/:user_id", authz.RequirePermission(entity.PermissionGroupWrite), hoaHandler.RemoveGroupHOA)
 		}
 		problems := api.Group("/problems")
 		{
-			problems.POST("", problemHandler.CreateProblem)
+			problems.POST("", authz.RequirePermission(entity.PermissionProblemWrite), problemHandler.CreateProblem)
 			problems.GET("", problemHandler.ListProblems)
 			problems.GET("/:id", problemHandler.GetProblemByID)
 			problems.GET("/name/:name", problemHandler.GetProblemByName)
-			problems.PATCH("/:id", problemHandler.UpdateProblem)
-			problems.DELETE("/:id", problemHandler.DeleteProblem)
+			problems.PATCH("/:id", authz.RequirePermission(entity.PermissionProblemWrite), problemHandler.UpdateProblem)
+			problems.DELETE("/:id", authz.RequirePermission(entity.PermissionProblemWrite), problemHandler.DeleteProblem)
 		}
 
 		// Country routes
 		countries := api.Group("/countries")
 		{
-			countries.POST("", countryHandler.CreateCountry)
+			countries.POST("", authz.RequirePermission(entity.PermissionCountryWrite), countryHandler.CreateCountry)
 			countries.GET("", countryHandler.ListCountries)
 			countries.GET("/:id", countryHandler.GetCountryByID)
-			countries.DELETE("/:id", countryHandler.DeleteCountry)
+			countries.DELETE("/:id", authz.RequirePermission(entity.PermissionCountryWrite), countryHandler.DeleteCountry)
 			// have some issue
-			countries.PATCH("/:id", countryHandler.UpdateCountry)
+			countries.PATCH("/:id", authz.RequirePermission(entity.PermissionCountryWrite), countryHandler.UpdateCountry)
 		}
 
 		// Super Group routes
 		superGroups := api.Group("/super_groups")
 		{
-			superGroups.POST("", superGroupHandler.CreateSuperGroup)
+			superGroups.POST("", authz.RequirePermission(entity.PermissionGroupWrite), superGroupHandler.CreateSuperGroup)
 			superGroups.GET("", superGroupHandler.ListSuperGroups)
 			superGroups.GET("/:id", superGroupHandler.GetSuperGroup)
-			superGroups.PATCH("/:id", superGroupHandler.UpdateSuperGroup)
-			superGroups.DELETE("/:id", superGroupHandler.DeleteSuperGroup)
+			superGroups.PATCH("/:id", authz.RequirePermission(entity.PermissionGroupWrite), superGroupHandler.UpdateSuperGroup)
+			superGroups.DELETE("/:id", authz.RequirePermission(entity.PermissionGroupWrite), superGroupHandler.DeleteSuperGroup)
 		}
 
 		// Vote routes
 		votes := api.Group("/votes") //there is error eof
 		{
-			votes.POST("", voteHandler.CreateVote)
+			votes.POST("", middleware.RequireScope(entity.ScopeVoteWrite), voteHandler.CreateVote)
 			votes.GET("", voteHandler.ListVote)
 			votes.GET("/:id", voteHandler.GetVoteByID)
 			votes.GET("/comment/:comment_id", voteHandler.GetVoteByCommentID)
@@ -203,8 +331,8 @@ func SetupRouter(
 			votes.GET("/track/:track_id", voteHandler.GetVoteByTrackID)
 			votes.GET("/submission/:submission_id", voteHandler.GetVoteBySubmissionID)
 			votes.GET("/problem/:problem_id", voteHandler.GetVoteByProblemID)
-			votes.PATCH("/:id", voteHandler.UpdateVote)
-			votes.DELETE("/:id", voteHandler.DeleteVote)
+			votes.PATCH("/:id", middleware.RequireScope(entity.ScopeVoteWrite), voteHandler.UpdateVote)
+			votes.DELETE("/:id", middleware.RequireScope(entity.ScopeVoteWrite), voteHandler.DeleteVote)
 		}
 
 		// Track routes
@@ -212,17 +340,17 @@ func SetupRouter(
 		// SuperToGroup routes
 		superToGroups := api.Group("/super_to_groups")
 		{
-			superToGroups.POST("", superToGroupHandler.CreateSuperToGroup)
+			superToGroups.POST("", authz.RequirePermission(entity.PermissionGroupWrite), superToGroupHandler.CreateSuperToGroup)
 			superToGroups.GET("", superToGroupHandler.ListSuperToGroup)
 			superToGroups.GET("/:id", superToGroupHandler.GetSuperToGroupByID)
-			superToGroups.PATCH("/:id", superToGroupHandler.UpdateSuperToGroup)
-			superToGroups.DELETE("/:id", superToGroupHandler.DeleteSuperToGroup)
+			superToGroups.PATCH("/:id", authz.RequirePermission(entity.PermissionGroupWrite), superToGroupHandler.UpdateSuperToGroup)
+			superToGroups.DELETE("/:id", authz.RequirePermission(entity.PermissionGroupWrite), superToGroupHandler.DeleteSuperToGroup)
 		}
 
 		// Submission routes
 		submissions := api.Group("/submissions")
 		{
-			submissions.POST("", submissionHandler.CreateSubmission)
+			submissions.POST("", middleware.RequireScope(entity.ScopeSubmissionWrite), submissionHandler.CreateSubmission)
 			submissions.GET("", submissionHandler.ListSubmission)
 			submissions.GET("/:id", submissionHandler.GetSubmissionByID)
 			submissions.GET("/problem/:problem_id", submissionHandler.GetSubmissionByProblemID)
@@ -232,26 +360,26 @@ func SetupRouter(
 		// Stippend routes
 		stipends := api.Group("/stipends")
 		{
-			stipends.POST("", stippendHandler.CreateStipend)
-			stipends.GET("", stippendHandler.ListStippends)
-			stipends.GET("/:id", stippendHandler.GetStippendByID)
-			stipends.PATCH("/:id", stippendHandler.UpdateStipend)
-			stipends.DELETE("/:id", stippendHandler.DeleteStipend)
+			stipends.POST("", authz.RequirePermission(entity.PermissionStipendWrite), stippendHandler.CreateStipend)
+			stipends.GET("", authz.RequirePermission(entity.PermissionStipendRead), stippendHandler.ListStippends)
+			stipends.GET("/:id", authz.RequirePermission(entity.PermissionStipendRead), stippendHandler.GetStippendByID)
+			stipends.PATCH("/:id", authz.RequirePermission(entity.PermissionStipendWrite), stippendHandler.UpdateStipend)
+			stipends.DELETE("/:id", authz.RequirePermission(entity.PermissionStipendWrite), stippendHandler.DeleteStipend)
 		}
 
 		// ProblemTracks routes
 		tracks := api.Group("/tracks")
 		{
-			tracks.POST("", trackHandler.CreateTrack)
+			tracks.POST("", authz.RequirePermission(entity.PermissionTrackWrite), trackHandler.CreateTrack)
 			tracks.GET("", trackHandler.ListTrack)
 			tracks.GET("/:id", trackHandler.GetTrackByID)
 			tracks.GET("/name/:name", trackHandler.GetTrackByName)
-			tracks.PATCH("/:id", trackHandler.UpdateTrack)
-			tracks.DELETE("/:id", trackHandler.DeleteTrack)
+			tracks.PATCH("/:id", authz.RequirePermission(entity.PermissionTrackWrite), trackHandler.UpdateTrack)
+			tracks.DELETE("/:id", authz.RequirePermission(entity.PermissionTrackWrite), trackHandler.DeleteTrack)
 			
 			problemTracks := tracks.Group("/tid/:track_id/problems")
 			{
-				problemTracks.POST("", problemTrackHandler.AddProblemToTrack)
+				problemTracks.POST("", authz.RequirePermission(entity.PermissionTrackWrite), problemTrackHandler.AddProblemToTrack)
 				problemTracks.GET("", problemTrackHandler.ListProblemsInTrack)
 				problemTracks.GET("/by-name", problemTrackHandler.GetProblemInTracksByName)
 				problemTracks.GET("/by-difficulty", problemTrackHandler.GetProblemInTracksByDifficulty)
@@ -260,16 +388,16 @@ func SetupRouter(
 			}
 		}
 
-		api.DELETE("/problem-tracks/:id", problemTrackHandler.RemoveProblemFromTrack)
+		api.DELETE("/problem-tracks/:id", authz.RequirePermission(entity.PermissionTrackWrite), problemTrackHandler.RemoveProblemFromTrack)
 
 		// Exercise routes
 		exercises := api.Group("/exercises")
 		{
-			exercises.POST("", exerciseHandler.CreateExercise)
+			exercises.POST("", authz.RequirePermission(entity.PermissionExerciseWrite), exerciseHandler.CreateExercise)
 			exercises.GET("", exerciseHandler.ListExercises)
 			exercises.GET("/:id", exerciseHandler.GetExerciseByID)
-			exercises.PATCH("/:id", exerciseHandler.UpdateExercise)
-			exercises.DELETE("/:id", exerciseHandler.DeleteExercise)
+			exercises.PATCH("/:id", authz.RequirePermission(entity.PermissionExerciseWrite), exerciseHandler.UpdateExercise)
+			exercises.DELETE("/:id", authz.RequirePermission(entity.PermissionExerciseWrite), exerciseHandler.DeleteExercise)
 		}
 
 		// Group-level exercises
@@ -279,6 +407,9 @@ func SetupRouter(
 		// Problem-level exercises
 		api.GET("/problems/pid/:problem_id/exercises", exerciseHandler.GetExercisesByProblemID)
 	}
+	// Lets other services verify access tokens without a shared secret
+	router.GET("/.well-known/jwks.json", jwksHandler.GetJWKS)
+
 	router.GET("/", func(c *gin.Context) {
 		c.JSON(200, gin.H{
 			"status":  "ok",
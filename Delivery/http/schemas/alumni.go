@@ -0,0 +1,23 @@
+package schemas
+
+import "time"
+
+// AlumniPreviewResponse lists the users the next alumni transition will move
+// swagger:model
+type AlumniPreviewResponse struct {
+	AlumniRoleID uint              `json:"alumni_role_id" example:"7"`
+	FromRoleIDs  []uint            `json:"from_role_ids" example:"3"`
+	NextRunAt    time.Time         `json:"next_run_at"`
+	Users        []AlumniCandidate `json:"users"`
+}
+
+// AlumniCandidate is a user whose expected graduation date has passed by the next run
+type AlumniCandidate struct {
+	UserID                 uint      `json:"user_id" example:"42"`
+	Name                   string    `json:"name" example:"John Doe"`
+	Email                  string    `json:"email" example:"john@example.com"`
+	RoleID                 uint      `json:"role_id" example:"3"`
+	GroupID                *uint     `json:"group_id,omitempty" example:"5"`
+	GroupName              string    `json:"group_name,omitempty" example:"G5"`
+	ExpectedGraduationDate time.Time `json:"expected_graduation_date"`
+}
@@ -0,0 +1,29 @@
+package schemas
+
+import "time"
+
+// CreateAPITokenRequest represents the body for creating a personal API token
+// swagger:model
+type CreateAPITokenRequest struct {
+	Name      string     `json:"name" binding:"required,max=255" example:"LeetCode extension"`
+	Scopes    []string   `json:"scopes" binding:"required,min=1" example:"read,submission:write"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty" example:"2026-12-31T00:00:00Z"`
+}
+
+// APITokenResponse represents a personal API token without its secret
+// swagger:model
+type APITokenResponse struct {
+	ID         uint       `json:"id" example:"1"`
+	Name       string     `json:"name" example:"LeetCode extension"`
+	Scopes     []string   `json:"scopes" example:"read,submission:write"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty" example:"2026-12-31T00:00:00Z"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty" example:"2026-10-17T08:00:00Z"`
+	CreatedAt  time.Time  `json:"created_at" example:"2026-10-17T08:00:00Z"`
+}
+
+// CreateAPITokenResponse is returned once, when the token is created
+// swagger:model
+type CreateAPITokenResponse struct {
+	APITokenResponse
+	Token string `json:"token" example:"a2sv_3kq9..."`
+}
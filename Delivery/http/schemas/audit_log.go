@@ -0,0 +1,44 @@
+package schemas
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// AuditLogQuery represents query parameters for searching the audit log
+// swagger:model
+type AuditLogQuery struct {
+	ActorID    *uint      `form:"actor_id" example:"1"`
+	EntityType string     `form:"entity_type" example:"stipends"`
+	EntityID   string     `form:"entity_id" example:"42"`
+	Action     string     `form:"action" binding:"omitempty,oneof=create update delete" example:"update"`
+	From       *time.Time `form:"from" time_format:"2006-01-02T15:04:05Z07:00" example:"2025-01-01T00:00:00Z"`
+	To         *time.Time `form:"to" time_format:"2006-01-02T15:04:05Z07:00" example:"2025-02-01T00:00:00Z"`
+	Page       int        `form:"page,default=1" example:"1"`
+	PageSize   int        `form:"page_size,default=20" example:"20"`
+}
+
+// AuditLogResponse represents one audited API call
+// swagger:model
+type AuditLogResponse struct {
+	ID             uint            `json:"id" example:"1"`
+	ActorID        *uint           `json:"actor_id,omitempty" example:"1"`
+	ImpersonatorID *uint           `json:"impersonator_id,omitempty" example:"2"`
+	Method         string          `json:"method" example:"PATCH"`
+	Route          string          `json:"route" example:"/api/stipends/:id"`
+	Path           string          `json:"path" example:"/api/stipends/42"`
+	EntityType     string          `json:"entity_type" example:"stipends"`
+	EntityID       string          `json:"entity_id,omitempty" example:"42"`
+	Action         string          `json:"action" example:"update"`
+	Status         int             `json:"status" example:"200"`
+	Changes        json.RawMessage `json:"changes" swaggertype:"object"`
+	IP             string          `json:"ip" example:"196.188.0.1"`
+	CreatedAt      time.Time       `json:"created_at"`
+}
+
+// AuditLogListResponse represents a page of the audit log
+// swagger:model
+type AuditLogListResponse struct {
+	Data []*AuditLogResponse `json:"data"`
+	Meta PaginationMeta      `json:"meta"`
+}
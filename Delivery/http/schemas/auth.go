@@ -7,11 +7,15 @@ type LoginRequest struct {
 	Password string `json:"password" binding:"required" example:"mypassword123"`
 }
 
-// LoginResponse represents the successful login response
+// LoginResponse represents the successful login response.
+// When a second factor is needed only TwoFactor is set.
 // swagger:model
 type LoginResponse struct {
-	Token string       `json:"token" example:"eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9..."`
-	User  *UserResponse `json:"user"`
+	Token        string                      `json:"token,omitempty" example:"eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9..."`
+	RefreshToken string                      `json:"refresh_token,omitempty" example:"q3Vw0b1x..."`
+	ExpiresIn    int                         `json:"expires_in,omitempty" example:"900"`
+	User         *UserResponse               `json:"user,omitempty"`
+	TwoFactor    *TwoFactorChallengeResponse `json:"two_factor,omitempty"`
 }
 
 // AuthTokenResponse represents a JWT token response
@@ -19,3 +23,45 @@ type LoginResponse struct {
 type AuthTokenResponse struct {
 	Token string `json:"token" example:"eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9..."`
 }
+
+// TokenPairResponse represents a freshly issued access and refresh token.
+// A login that needs a second factor only sets TwoFactor.
+// swagger:model
+type TokenPairResponse struct {
+	Token        string                      `json:"token,omitempty" example:"eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9..."`
+	RefreshToken string                      `json:"refresh_token,omitempty" example:"q3Vw0b1x..."`
+	ExpiresIn    int                         `json:"expires_in,omitempty" example:"900"`
+	TwoFactor    *TwoFactorChallengeResponse `json:"two_factor,omitempty"`
+}
+
+// RefreshTokenRequest represents the body of a token refresh
+// swagger:model
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refresh_token" binding:"required" example:"q3Vw0b1x..."`
+}
+
+// LogoutRequest represents the body of a logout request
+// swagger:model
+type LogoutRequest struct {
+	RefreshToken string `json:"refresh_token,omitempty" example:"q3Vw0b1x..."`
+}
+
+// ForgotPasswordRequest represents the body of a forgot-password request
+// swagger:model
+type ForgotPasswordRequest struct {
+	Email string `json:"email" binding:"required,email" example:"user@example.com"`
+}
+
+// ResetPasswordRequest represents the body of a password reset
+// swagger:model
+type ResetPasswordRequest struct {
+	Token       string `json:"token" binding:"required" example:"Xk2p..."`
+	NewPassword string `json:"new_password" binding:"required,min=8" example:"MyNewSecret123"`
+}
+
+// ChangePasswordRequest represents the body of a password change
+// swagger:model
+type ChangePasswordRequest struct {
+	OldPassword string `json:"old_password" binding:"required" example:"MySecret123"`
+	NewPassword string `json:"new_password" binding:"required,min=8" example:"MyNewSecret123"`
+}
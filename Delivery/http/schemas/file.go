@@ -0,0 +1,22 @@
+package schemas
+
+import "time"
+
+// FileURLResponse is a time-limited download URL of an uploaded file
+// swagger:model
+type FileURLResponse struct {
+	URL       string    `json:"url" example:"/api/files/photos/42/k3Jx9.jpg?expires=1767225600&signature=9f2c"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// AttachmentResponse describes a session resource or post attachment
+// swagger:model
+type AttachmentResponse struct {
+	ID           uint      `json:"id" example:"7"`
+	Name         string    `json:"name" example:"slides.pdf"`
+	ContentType  string    `json:"content_type" example:"application/pdf"`
+	Size         int64     `json:"size" example:"482113"`
+	UploadedByID uint      `json:"uploaded_by_id" example:"42"`
+	URL          string    `json:"url" example:"/api/files/sessions/3/k3Jx9.pdf?expires=1767225600&signature=9f2c"`
+	CreatedAt    time.Time `json:"created_at"`
+}
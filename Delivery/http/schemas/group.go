@@ -53,3 +53,9 @@ type GroupListResponse struct {
 	Data []*GroupResponse `json:"data"`
 	Meta PaginationMeta   `json:"meta"`
 }
+
+// AssignHOARequest represents the request body for making a user Head of Academy of a group
+// swagger:model
+type AssignHOARequest struct {
+	UserID uint `json:"user_id" binding:"required" example:"5"`
+}
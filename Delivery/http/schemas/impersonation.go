@@ -0,0 +1,47 @@
+package schemas
+
+import "time"
+
+// StartImpersonationRequest represents the body of an impersonation request
+// swagger:model
+type StartImpersonationRequest struct {
+	Reason string `json:"reason" binding:"required,max=500" example:"Student reports missing exercises on the dashboard"`
+}
+
+// ImpersonationResponse carries a token acting as another user
+// swagger:model
+type ImpersonationResponse struct {
+	ImpersonationID uint   `json:"impersonation_id" example:"1"`
+	Token           string `json:"token" example:"eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9..."`
+	ExpiresIn       int    `json:"expires_in" example:"900"`
+	UserID          uint   `json:"user_id" example:"42"`
+}
+
+// ImpersonationQuery represents query parameters for listing impersonations
+// swagger:model
+type ImpersonationQuery struct {
+	ActorID  *uint `form:"actor_id" example:"1"`
+	UserID   *uint `form:"user_id" example:"42"`
+	Page     int   `form:"page,default=1" example:"1"`
+	PageSize int   `form:"page_size,default=20" example:"20"`
+}
+
+// ImpersonationRecordResponse represents one impersonation
+// swagger:model
+type ImpersonationRecordResponse struct {
+	ID        uint       `json:"id" example:"1"`
+	ActorID   uint       `json:"actor_id" example:"1"`
+	UserID    uint       `json:"user_id" example:"42"`
+	Reason    string     `json:"reason" example:"Student reports missing exercises on the dashboard"`
+	IP        string     `json:"ip" example:"196.188.0.1"`
+	StartedAt time.Time  `json:"started_at"`
+	ExpiresAt time.Time  `json:"expires_at"`
+	EndedAt   *time.Time `json:"ended_at,omitempty"`
+}
+
+// ImpersonationListResponse represents a page of impersonations
+// swagger:model
+type ImpersonationListResponse struct {
+	Data []*ImpersonationRecordResponse `json:"data"`
+	Meta PaginationMeta                 `json:"meta"`
+}
@@ -0,0 +1,54 @@
+package schemas
+
+import "time"
+
+// CreateInviteRequest represents the body for creating an invite link
+// swagger:model
+type CreateInviteRequest struct {
+	RoleID    uint       `json:"role_id" binding:"required" example:"3"`
+	GroupID   uint       `json:"group_id" binding:"required" example:"1"`
+	MaxUses   *int       `json:"max_uses,omitempty" binding:"omitempty,min=0" example:"1"` // 0 for unlimited, defaults to 1
+	ExpiresAt *time.Time `json:"expires_at,omitempty" example:"2026-12-31T00:00:00Z"`      // defaults to 7 days
+}
+
+// EmailInvitesRequest represents the body for emailing single-use invites in bulk
+// swagger:model
+type EmailInvitesRequest struct {
+	Emails    string     `json:"emails" binding:"required" example:"a@example.com,b@example.com"`
+	RoleID    uint       `json:"role_id" binding:"required" example:"3"`
+	GroupID   uint       `json:"group_id" binding:"required" example:"1"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty" example:"2026-12-31T00:00:00Z"`
+}
+
+// RedeemInviteRequest represents the body for joining through an invite
+// swagger:model
+type RedeemInviteRequest struct {
+	Key      string `json:"key" binding:"required" example:"Xk2p..."`
+	Name     string `json:"name" binding:"required" example:"John Doe"`
+	Email    string `json:"email" binding:"required,email" example:"user@example.com"`
+	Password string `json:"password" binding:"required,min=8" example:"MySecret123"`
+}
+
+// InviteResponse represents an invite without its key
+// swagger:model
+type InviteResponse struct {
+	ID        uint       `json:"id" example:"1"`
+	RoleID    uint       `json:"role_id" example:"3"`
+	GroupID   *uint      `json:"group_id,omitempty" example:"1"`
+	CreatedBy *uint      `json:"created_by,omitempty" example:"2"`
+	Email     *string    `json:"email,omitempty" example:"user@example.com"`
+	MaxUses   int        `json:"max_uses" example:"1"`
+	UseCount  int        `json:"use_count" example:"0"`
+	Used      bool       `json:"used" example:"false"`
+	ExpiresAt time.Time  `json:"expires_at" example:"2026-12-31T00:00:00Z"`
+	RevokedAt *time.Time `json:"revoked_at,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// CreateInviteResponse is returned once, when the invite is created
+// swagger:model
+type CreateInviteResponse struct {
+	InviteResponse
+	Key  string `json:"key" example:"Xk2p..."`
+	Link string `json:"link" example:"https://yene-hub-ls0y.onrender.com/invite?key=Xk2p..."`
+}
@@ -0,0 +1,9 @@
+package schemas
+
+import "a2sv.org/hub/infrastructure/token_services"
+
+// JWKSResponse is the JSON Web Key Set of the keys that verify access tokens
+// swagger:model
+type JWKSResponse struct {
+	Keys []*token_services.JWK `json:"keys"`
+}
@@ -0,0 +1,32 @@
+package schemas
+
+import "time"
+
+// LoginHistoryQuery represents query parameters for listing login history
+// swagger:model
+type LoginHistoryQuery struct {
+	Page     int `form:"page,default=1" example:"1"`
+	PageSize int `form:"page_size,default=20" example:"20"`
+}
+
+// LoginEventResponse represents one login attempt
+// swagger:model
+type LoginEventResponse struct {
+	ID            uint      `json:"id" example:"1"`
+	Method        string    `json:"method" example:"password"`
+	Success       bool      `json:"success" example:"true"`
+	FailureReason string    `json:"failure_reason,omitempty" example:"invalid password"`
+	IP            string    `json:"ip" example:"196.188.0.1"`
+	Device        string    `json:"device" example:"Mozilla/5.0 (X11; Linux x86_64)"`
+	City          string    `json:"city,omitempty" example:"Addis Ababa"`
+	Region        string    `json:"region,omitempty" example:"Addis Ababa"`
+	Country       string    `json:"country,omitempty" example:"ET"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+// LoginHistoryResponse represents a page of login history
+// swagger:model
+type LoginHistoryResponse struct {
+	Data []*LoginEventResponse `json:"data"`
+	Meta PaginationMeta        `json:"meta"`
+}
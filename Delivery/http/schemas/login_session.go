@@ -0,0 +1,15 @@
+package schemas
+
+import "time"
+
+// LoginSessionResponse represents one signed-in device
+// swagger:model
+type LoginSessionResponse struct {
+	ID         uint      `json:"id" example:"1"`
+	IP         string    `json:"ip" example:"196.188.0.1"`
+	Device     string    `json:"device" example:"Mozilla/5.0 (X11; Linux x86_64)"`
+	CreatedAt  time.Time `json:"created_at"`
+	LastSeenAt time.Time `json:"last_seen_at"`
+	ExpiresAt  time.Time `json:"expires_at"`
+	Current    bool      `json:"current" example:"true"` // The session of the token making the request
+}
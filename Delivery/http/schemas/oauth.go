@@ -0,0 +1,7 @@
+package schemas
+
+// OAuthLinkResponse carries the provider page that links an account to the caller
+// swagger:model
+type OAuthLinkResponse struct {
+	AuthURL string `json:"auth_url" example:"https://github.com/login/oauth/authorize?client_id=..."`
+}
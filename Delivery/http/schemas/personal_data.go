@@ -0,0 +1,7 @@
+package schemas
+
+// EraseAccountRequest confirms the erasure of the caller's personal data
+// swagger:model
+type EraseAccountRequest struct {
+	Password string `json:"password" binding:"required" example:"MySecret123"`
+}
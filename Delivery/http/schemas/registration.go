@@ -0,0 +1,24 @@
+package schemas
+
+import "time"
+
+// RegistrationJobResponse describes a bulk registration job and its progress
+// swagger:model
+type RegistrationJobResponse struct {
+	ID     uint   `json:"id" example:"12"`
+	Status string `json:"status" example:"running" enums:"pending,running,completed,failed"`
+	Mode   string `json:"mode" example:"per_row" enums:"per_row,all_or_nothing"`
+	RoleID uint   `json:"role_id" example:"3"`
+	// Total is the number of rows; Processed of them have been handled so far
+	Total     int `json:"total" example:"250"`
+	Processed int `json:"processed" example:"120"`
+	Succeeded int `json:"succeeded" example:"118"`
+	Failed    int `json:"failed" example:"2"`
+	// Error says why the job as a whole failed
+	Error string `json:"error,omitempty"`
+	// ReportURL downloads the per-row results once the job has finished
+	ReportURL  string     `json:"report_url,omitempty" example:"/api/registration/jobs/12/report"`
+	CreatedAt  time.Time  `json:"created_at"`
+	StartedAt  *time.Time `json:"started_at,omitempty"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+}
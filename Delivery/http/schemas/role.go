@@ -7,8 +7,9 @@ import "time"
 // Note: The entity uses 'Type', not 'Name'.
 type CreateRoleRequest struct {
 	// Required: true
-	Type        string `json:"type" binding:"required" example:"Moderator"`
-	Description string `json:"description,omitempty" example:"Can moderate content"`
+	Type        string   `json:"type" binding:"required" example:"Moderator"`
+	Description string   `json:"description,omitempty" example:"Can moderate content"`
+	Permissions []string `json:"permissions,omitempty" example:"user:write,stipend:read"`
 }
 
 // UpdateRoleRequest represents the request body for updating a role
@@ -16,6 +17,8 @@ type CreateRoleRequest struct {
 type UpdateRoleRequest struct {
 	Type        *string `json:"type,omitempty" example:"Senior Moderator"`
 	Description *string `json:"description,omitempty" example:"Can moderate all content"`
+	// Permissions replaces the role's permissions when provided
+	Permissions *[]string `json:"permissions,omitempty" example:"user:write,stipend:read"`
 }
 
 // RoleResponse represents a role in responses
@@ -24,6 +27,7 @@ type RoleResponse struct {
 	ID          uint      `json:"id" example:"1"`
 	Type        string    `json:"type" example:"Moderator"`
 	Description string    `json:"description,omitempty" example:"Can moderate content"`
+	Permissions []string  `json:"permissions" example:"user:write,stipend:read"`
 	CreatedAt   time.Time `json:"created_at"`
 	UpdatedAt   time.Time `json:"updated_at"`
 }
@@ -34,3 +38,9 @@ type RoleListResponse struct {
 	Data []*RoleResponse `json:"data"`
 	Meta PaginationMeta  `json:"meta"`
 }
+
+// RolePermissionRequest represents the request body for granting a permission to a role
+// swagger:model
+type RolePermissionRequest struct {
+	Permission string `json:"permission" binding:"required" example:"stipend:write"`
+}
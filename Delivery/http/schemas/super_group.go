@@ -9,6 +9,7 @@ type CreateSuperGroupRequest struct {
 	Name        string `json:"name" binding:"required" example:"A2SV Generation 2"`
 	Description string `json:"description" binding:"required" example:"Second generation of A2SV students"`
 	CountryID   uint   `json:"country_id" binding:"required" example:"1"`
+	LeadID      *uint  `json:"lead_id,omitempty" example:"7"`
 }
 
 // UpdateSuperGroupRequest represents the request body for updating a super group
@@ -18,6 +19,7 @@ type UpdateSuperGroupRequest struct {
 	Description *string `json:"description,omitempty" example:"Advanced track of second generation"`
 	CountryID   *uint   `json:"country_id,omitempty" example:"1"`
 	Status      *string `json:"status,omitempty" example:"active"`
+	LeadID      *uint   `json:"lead_id,omitempty" example:"7"`
 }
 
 // SuperGroupResponse represents a super group in responses
@@ -0,0 +1,32 @@
+package schemas
+
+import "time"
+
+// TelegramLinkCodeResponse carries a one-time code to send to the bot
+// swagger:model
+type TelegramLinkCodeResponse struct {
+	Code string `json:"code" example:"Qm7kX2pLw9"`
+	// Link opens the bot with the code prefilled; empty when the bot username is not configured
+	Link      string    `json:"link,omitempty" example:"https://t.me/a2sv_hub_bot?start=Qm7kX2pLw9"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// TelegramAuthRequest is the data the Telegram Login Widget passes to the page
+// swagger:model
+type TelegramAuthRequest struct {
+	ID        int64  `json:"id" binding:"required" example:"123456789"`
+	FirstName string `json:"first_name,omitempty" example:"Abebe"`
+	LastName  string `json:"last_name,omitempty" example:"Kebede"`
+	Username  string `json:"username,omitempty" example:"abebe"`
+	PhotoURL  string `json:"photo_url,omitempty" example:"https://t.me/i/userpic/320/abebe.jpg"`
+	AuthDate  int64  `json:"auth_date" binding:"required" example:"1700000000"`
+	Hash      string `json:"hash" binding:"required" example:"4f1c..."`
+}
+
+// TelegramAccountResponse describes the caller's linked Telegram account
+// swagger:model
+type TelegramAccountResponse struct {
+	TelegramUID      string    `json:"telegram_uid" example:"123456789"`
+	TelegramUsername *string   `json:"telegram_username,omitempty" example:"@abebe"`
+	LinkedAt         time.Time `json:"linked_at"`
+}
@@ -0,0 +1,58 @@
+package schemas
+
+// TwoFactorChallengeResponse is returned instead of tokens when a login needs a second factor
+// swagger:model
+type TwoFactorChallengeResponse struct {
+	ChallengeToken string `json:"challenge_token" example:"Zx8q..."`
+	// SetupRequired is set when the user's role requires 2FA and the user has not enrolled yet
+	SetupRequired bool `json:"setup_required" example:"false"`
+	ExpiresIn     int  `json:"expires_in" example:"300"`
+}
+
+// TwoFactorLoginRequest represents the second step of a login
+// swagger:model
+type TwoFactorLoginRequest struct {
+	ChallengeToken string `json:"challenge_token" binding:"required" example:"Zx8q..."`
+	Code           string `json:"code" binding:"required" example:"123456"` // TOTP code or recovery code
+}
+
+// TwoFactorSetupRequest represents a request for a TOTP secret during a login
+// swagger:model
+type TwoFactorSetupRequest struct {
+	ChallengeToken string `json:"challenge_token" binding:"required" example:"Zx8q..."`
+}
+
+// TwoFactorLoginResponse represents the tokens issued after the second factor.
+// RecoveryCodes are only set when the login also completed enrollment.
+// swagger:model
+type TwoFactorLoginResponse struct {
+	*TokenPairResponse
+	RecoveryCodes []string `json:"recovery_codes,omitempty" example:"k3j9x-p2m7q"`
+}
+
+// TwoFactorEnrollmentResponse carries a new TOTP secret
+// swagger:model
+type TwoFactorEnrollmentResponse struct {
+	Secret          string `json:"secret" example:"JBSWY3DPEHPK3PXP"`
+	ProvisioningURI string `json:"provisioning_uri" example:"otpauth://totp/A2SV%20Hub:user@example.com?secret=JBSWY3DPEHPK3PXP&issuer=A2SV+Hub"`
+}
+
+// TwoFactorCodeRequest represents a request confirmed with a TOTP or recovery code
+// swagger:model
+type TwoFactorCodeRequest struct {
+	Code string `json:"code" binding:"required" example:"123456"`
+}
+
+// RecoveryCodesResponse carries newly generated recovery codes, shown only once
+// swagger:model
+type RecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recovery_codes" example:"k3j9x-p2m7q"`
+}
+
+// TwoFactorStatusResponse describes the caller's 2FA state
+// swagger:model
+type TwoFactorStatusResponse struct {
+	Enabled           bool  `json:"enabled" example:"true"`
+	Required          bool  `json:"required" example:"true"` // Required by the user's role
+	RecoveryCodesLeft int64 `json:"recovery_codes_left" example:"10"`
+}
@@ -19,9 +19,7 @@ type CreateUserRequest struct {
 	Department             *string    `json:"department,omitempty" example:"Computer Science"`
 	ExpectedGraduationDate *time.Time `json:"expected_graduation_date,omitempty"`
 
-	Phone            *string `json:"phone,omitempty" example:"+1234567890"`
-	TelegramUsername *string `json:"telegram_username,omitempty" example:"@username"`
-	TelegramUID      *string `json:"telegram_uid,omitempty" example:"123456789"`
+	Phone *string `json:"phone,omitempty" example:"+1234567890"`
 
 	Leetcode   *string `json:"leetcode,omitempty" example:"leetcode_user"`
 	Codeforces *string `json:"codeforces,omitempty" example:"cf_user"`
@@ -42,7 +40,7 @@ type CreateUserRequest struct {
 type UpdateUserRequest struct {
 	Name     *string `json:"name,omitempty" example:"John Doe"`
 	Email    *string `json:"email,omitempty" example:"user@example.com"`
-	Password *string `json:"password,omitempty" example:"MySecret123"`
+	Password *string `json:"password,omitempty" binding:"omitempty,min=8" example:"MySecret123"`
 	RoleID   *uint   `json:"role_id,omitempty" example:"2"`
 	GroupID  *uint   `json:"group_id,omitempty" example:"1"`
 	CountryID *uint  `json:"country_id,omitempty" example:"1"`
@@ -52,9 +50,7 @@ type UpdateUserRequest struct {
 	Department             *string    `json:"department,omitempty" example:"Computer Science"`
 	ExpectedGraduationDate *time.Time `json:"expected_graduation_date,omitempty"`
 
-	Phone            *string `json:"phone,omitempty" example:"+1234567890"`
-	TelegramUsername *string `json:"telegram_username,omitempty" example:"@username"`
-	TelegramUID      *string `json:"telegram_uid,omitempty" example:"123456789"`
+	Phone *string `json:"phone,omitempty" example:"+1234567890"`
 
 	Leetcode   *string `json:"leetcode,omitempty" example:"leetcode_user"`
 	Codeforces *string `json:"codeforces,omitempty" example:"cf_user"`
@@ -73,34 +69,59 @@ type UpdateUserRequest struct {
 // swagger:model
 // (Already refined in previous steps)
 type UserListQuery struct {
-	Page     int    `form:"page,default=1" example:"1"`
-	PageSize int    `form:"page_size,default=10" example:"10"`
-	Search   string `form:"search" example:"john"`
-	RoleID   *uint  `form:"role_id" example:"2"`
-	GroupID  *uint  `form:"group_id" example:"1"`
+	Page     int `form:"page,default=1" binding:"min=1" example:"1"`
+	PageSize int `form:"page_size,default=10" binding:"min=1,max=100" example:"10"`
+
+	// Search matches the name or the email; the text filters below match a
+	// part of the field, ignoring case
+	Search         string `form:"search" example:"john"`
+	Name           string `form:"name" example:"John"`
+	Email          string `form:"email" example:"@a2sv.org"`
+	University     string `form:"university" example:"Addis Ababa"`
+	CountryID      *uint  `form:"country_id" example:"1"`
+	RoleID         *uint  `form:"role_id" example:"2"`
+	GroupID        *uint  `form:"group_id" example:"1"`
+	Inactive       *bool  `form:"inactive" example:"false"`
+	GraduationYear *int   `form:"graduation_year" binding:"omitempty,min=1900,max=2200" example:"2026"`
+
+	// Sort is a field, prefixed with - for descending order
+	Sort string `form:"sort,default=-created_at" binding:"oneof=name -name email -email university -university created_at -created_at expected_graduation_date -expected_graduation_date" example:"-created_at"`
+}
+
+// DeletedUserListQuery pages through the deleted users
+type DeletedUserListQuery struct {
+	Page     int `form:"page,default=1" binding:"min=1" example:"1"`
+	PageSize int `form:"page_size,default=10" binding:"min=1,max=100" example:"10"`
 }
 
 // UserResponse represents a user in responses
 // swagger:model
 // (Already refined in previous steps)
 type UserResponse struct {
-	ID        uint      `json:"id" example:"1"`
-	Name      string    `json:"name" example:"John Doe"`
-	Email     string    `json:"email" example:"user@example.com"`
-	RoleID    uint      `json:"role_id" example:"2"`
-	GroupID   *uint     `json:"group_id,omitempty" example:"1"`
-	CountryID *uint     `json:"country_id,omitempty" example:"1"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID        uint       `json:"id" example:"1"`
+	Name      string     `json:"name" example:"John Doe"`
+	Email     string     `json:"email" example:"user@example.com"`
+	RoleID    uint       `json:"role_id" example:"2"`
+	GroupID   *uint      `json:"group_id,omitempty" example:"1"`
+	CountryID *uint      `json:"country_id,omitempty" example:"1"`
+	Inactive  bool       `json:"inactive" example:"false"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+	DeletedAt *time.Time `json:"deleted_at,omitempty"`
+
+	// Photo and CV are download URLs valid for 15 minutes
+	Photo *string `json:"photo,omitempty" example:"/api/files/photos/42/k3Jx9.jpg?expires=1767225600&signature=9f2c"`
+	CV    *string `json:"cv,omitempty" example:"/api/files/cvs/42/p8Qw2.pdf?expires=1767225600&signature=9f2c"`
 
 	University             *string    `json:"university,omitempty" example:"Example University"`
 	StudentID              *string    `json:"student_id,omitempty" example:"STU123"`
 	Department             *string    `json:"department,omitempty" example:"Computer Science"`
 	ExpectedGraduationDate *time.Time `json:"expected_graduation_date,omitempty"`
 
-	Phone            *string `json:"phone,omitempty" example:"+1234567890"`
-	TelegramUsername *string `json:"telegram_username,omitempty" example:"@username"`
-	TelegramUID      *string `json:"telegram_uid,omitempty" example:"123456789"`
+	Phone            *string    `json:"phone,omitempty" example:"+1234567890"`
+	TelegramUsername *string    `json:"telegram_username,omitempty" example:"@username"`
+	TelegramUID      *string    `json:"telegram_uid,omitempty" example:"123456789"` // Only set once verified
+	TelegramLinkedAt *time.Time `json:"telegram_linked_at,omitempty"`
 
 	Leetcode   *string `json:"leetcode,omitempty" example:"leetcode_user"`
 	Codeforces *string `json:"codeforces,omitempty" example:"cf_user"`
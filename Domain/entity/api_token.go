@@ -4,15 +4,30 @@ import (
 	"time"
 )
 
+// APITokenTypePersonal is the type of tokens users create for scripts and extensions
+const APITokenTypePersonal = "personal"
+
+// Scopes that only exist on API tokens. A token may also carry any role
+// permission, which it can use only while the user's role still holds it.
+const (
+	ScopeRead              = "read"                // any GET request
+	ScopeSubmissionWrite   = "submission:write"    // push submissions
+	ScopeVoteWrite         = "vote:write"          // cast and change votes
+	ScopeRecentActionWrite = "recent_action:write" // record recent actions
+	ScopePostWrite         = "post:write"          // change post attachments
+)
+
 // APIToken represents an API token for user authentication
 type APIToken struct {
-	ID        uint       `json:"id" gorm:"primaryKey"`
-	UserID    uint       `json:"user_id"`
-	User      *User      `json:"user,omitempty" gorm:"foreignKey:UserID"`
-	Name      string     `json:"name" gorm:"size:255"`
-	Type      string     `json:"type" gorm:"size:255"`
-	Token     string     `json:"token" gorm:"size:64"`
-	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+	ID         uint       `json:"id" gorm:"primaryKey"`
+	UserID     uint       `json:"user_id"`
+	User       *User      `json:"user,omitempty" gorm:"foreignKey:UserID"`
+	Name       string     `json:"name" gorm:"size:255"`
+	Type       string     `json:"type" gorm:"size:255"`
+	Token      string     `json:"-" gorm:"size:64;uniqueIndex"` // SHA-256 of the token, never the token itself
+	Scopes     string     `json:"scopes" gorm:"size:1024"`      // comma separated
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
 
 	CreatedAt time.Time `json:"created_at"`
 }
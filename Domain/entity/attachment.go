@@ -0,0 +1,21 @@
+package entity
+
+import "time"
+
+// Attachment is a file uploaded to a session's resources or to a post. The
+// file itself is kept in the file storage under Key.
+type Attachment struct {
+	ID           uint     `json:"id" gorm:"primaryKey"`
+	Key          string   `json:"-" gorm:"size:255;uniqueIndex;not null"`
+	Name         string   `json:"name" gorm:"size:255"` // File name as uploaded
+	ContentType  string   `json:"content_type" gorm:"size:127"`
+	Size         int64    `json:"size"`
+	UploadedByID uint     `json:"uploaded_by_id" gorm:"index"`
+	UploadedBy   *User    `json:"uploaded_by,omitempty" gorm:"foreignKey:UploadedByID"`
+	SessionID    *uint    `json:"session_id,omitempty" gorm:"index"`
+	Session      *Session `json:"session,omitempty" gorm:"foreignKey:SessionID;constraint:OnDelete:CASCADE"`
+	PostID       *uint    `json:"post_id,omitempty" gorm:"index"`
+	Post         *Post    `json:"post,omitempty" gorm:"foreignKey:PostID;constraint:OnDelete:CASCADE"`
+
+	CreatedAt time.Time `json:"created_at"`
+}
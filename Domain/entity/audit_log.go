@@ -0,0 +1,31 @@
+package entity
+
+import "time"
+
+// Audit log actions
+const (
+	AuditActionCreate = "create"
+	AuditActionUpdate = "update"
+	AuditActionDelete = "delete"
+)
+
+// AuditLog is one mutating API call. Rows are append-only: the repository
+// has no update or delete, and a database trigger rejects both.
+type AuditLog struct {
+	ID      uint  `json:"id" gorm:"primaryKey"`
+	ActorID *uint `json:"actor_id,omitempty" gorm:"index"` // Nil for unauthenticated calls
+	// ImpersonatorID is the admin behind the actor when the call used an impersonation token
+	ImpersonatorID *uint  `json:"impersonator_id,omitempty" gorm:"index"`
+	Method         string `json:"method" gorm:"size:8"`
+	Route          string `json:"route" gorm:"size:255"` // Route pattern, e.g. /api/stipends/:id
+	Path           string `json:"path" gorm:"size:512"`
+	EntityType     string `json:"entity_type" gorm:"size:64;index:idx_audit_logs_entity"`
+	EntityID       string `json:"entity_id,omitempty" gorm:"size:64;index:idx_audit_logs_entity"`
+	Action         string `json:"action" gorm:"size:16;index"`
+	Status         int    `json:"status"`
+	// Changes maps each changed column to {"old": ..., "new": ...}
+	Changes string `json:"changes" gorm:"type:jsonb"`
+	IP      string `json:"ip" gorm:"size:64"`
+
+	CreatedAt time.Time `json:"created_at" gorm:"index"`
+}
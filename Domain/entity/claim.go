@@ -5,10 +5,14 @@ import (
 )
 
 type Claims struct {
-	ID          uint   `json:"id" gorm:"primaryKey" `
-	Name        string `json:"name" gorm:"size:255" `
-	Email       string `json:"email" gorm:"size:255" `
-	PhoneNumber string `json:"phone_number" gorm:"size:255" `
-	Role        *Role `json:"role" gorm:"size:255" `
+	ID             uint     `json:"id" gorm:"primaryKey" `
+	Name           string   `json:"name" gorm:"size:255" `
+	Email          string   `json:"email" gorm:"size:255" `
+	PhoneNumber    string   `json:"phone_number" gorm:"size:255" `
+	Role           *Role    `json:"role" gorm:"size:255" `
+	SessionID      string   `json:"sid,omitempty"`             // Refresh token family the access token was issued for
+	APITokenID     uint     `json:"api_token_id,omitempty"`    // Set when authenticated with a personal API token
+	Scopes         []string `json:"scopes,omitempty"`          // Scopes of the API token, nil for login sessions
+	ImpersonatorID uint     `json:"impersonator_id,omitempty"` // Admin acting as the user; ID is the impersonated user
 	jwt.StandardClaims
 }
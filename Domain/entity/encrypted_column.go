@@ -0,0 +1,35 @@
+package entity
+
+// EncryptedColumn is a text column whose values are stored encrypted. Its
+// name is bound into every value, so a value does not decrypt elsewhere.
+type EncryptedColumn struct {
+	Table  string
+	Column string
+}
+
+func (c EncryptedColumn) String() string {
+	return c.Table + "." + c.Column
+}
+
+// Columns holding encrypted values
+var (
+	ColumnGoogleOAuthToken     = EncryptedColumn{Table: "google_o_auths", Column: "encrypted_token_string"}
+	ColumnStipendAccountNumber = EncryptedColumn{Table: "stipends", Column: "account_number"}
+	ColumnTwoFactorSecret      = EncryptedColumn{Table: "two_factors", Column: "secret"}
+	ColumnSigningKeyPrivateKey = EncryptedColumn{Table: "signing_keys", Column: "private_key"}
+)
+
+// EncryptedColumns lists every encrypted column; all of them are re-encrypted
+// when the master key rotates
+var EncryptedColumns = []EncryptedColumn{
+	ColumnGoogleOAuthToken,
+	ColumnStipendAccountNumber,
+	ColumnTwoFactorSecret,
+	ColumnSigningKeyPrivateKey,
+}
+
+// EncryptedValue is the stored value of an encrypted column in one row
+type EncryptedValue struct {
+	ID    uint
+	Value string
+}
@@ -0,0 +1,18 @@
+package entity
+
+import "time"
+
+// Impersonation records an admin acting as another user
+type Impersonation struct {
+	ID        uint       `json:"id" gorm:"primaryKey"`
+	ActorID   uint       `json:"actor_id" gorm:"index"` // Admin who impersonated
+	Actor     *User      `json:"actor,omitempty" gorm:"foreignKey:ActorID"`
+	UserID    uint       `json:"user_id" gorm:"index"` // Impersonated user
+	User      *User      `json:"user,omitempty" gorm:"foreignKey:UserID"`
+	Reason    string     `json:"reason" gorm:"size:500"`
+	TokenID   string     `json:"-" gorm:"size:64;uniqueIndex"` // JWT ID of the impersonation token
+	IP        string     `json:"ip" gorm:"size:64"`
+	StartedAt time.Time  `json:"started_at" gorm:"index"`
+	ExpiresAt time.Time  `json:"expires_at"`
+	EndedAt   *time.Time `json:"ended_at,omitempty"` // Nil while active or when the token simply expired
+}
@@ -7,14 +7,20 @@ import (
 // Invite represents an invitation for a user to join a group with a specific role
 type Invite struct {
 	ID      uint   `json:"id" gorm:"primaryKey"`
-	Key     string `json:"key" gorm:"size:255"` // Unique invite key
+	Key     string `json:"-" gorm:"size:255;uniqueIndex"` // SHA-256 of the invite key, the key itself is only in the link
 	RoleID  uint   `json:"role_id"`
 	Role    *Role  `json:"role,omitempty" gorm:"foreignKey:RoleID"`
 	UserID  *uint  `json:"user_id,omitempty"` // User who created the invite
 	User    *User  `json:"user,omitempty" gorm:"foreignKey:UserID"`
 	GroupID *uint  `json:"group_id,omitempty"`
 	Group   *Group `json:"group,omitempty" gorm:"foreignKey:GroupID"`
-	Used    bool   `json:"used" gorm:"default:false"` // Whether the invite has been used
+	Used    bool   `json:"used" gorm:"default:false"` // Whether the invite has been used up
+
+	Email     *string    `json:"email,omitempty" gorm:"size:255"` // Only this address may redeem the invite
+	MaxUses   int        `json:"max_uses"`                        // 0 means unlimited
+	UseCount  int        `json:"use_count" gorm:"default:0"`
+	ExpiresAt time.Time  `json:"expires_at"`
+	RevokedAt *time.Time `json:"revoked_at,omitempty"`
 
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
@@ -0,0 +1,28 @@
+package entity
+
+import "time"
+
+// Login methods recorded in the login history. OAuth logins are recorded
+// with the provider name.
+const (
+	LoginMethodPassword = "password"
+	LoginMethodInvite   = "invite"
+	LoginMethodTelegram = "telegram"
+)
+
+// LoginEvent is one successful or failed login attempt
+type LoginEvent struct {
+	ID            uint      `json:"id" gorm:"primaryKey"`
+	UserID        *uint     `json:"user_id,omitempty" gorm:"index"` // Nil when the email matched no user
+	User          *User     `json:"user,omitempty" gorm:"foreignKey:UserID"`
+	Email         string    `json:"email" gorm:"size:255;index"`
+	Method        string    `json:"method" gorm:"size:32"`
+	Success       bool      `json:"success"`
+	FailureReason string    `json:"failure_reason,omitempty" gorm:"size:255"`
+	IP            string    `json:"ip" gorm:"size:64;index"`
+	Device        string    `json:"device" gorm:"size:512"` // User-Agent
+	City          string    `json:"city,omitempty" gorm:"size:255"`
+	Region        string    `json:"region,omitempty" gorm:"size:255"`
+	Country       string    `json:"country,omitempty" gorm:"size:8"`
+	CreatedAt     time.Time `json:"created_at" gorm:"index"`
+}
@@ -0,0 +1,18 @@
+package entity
+
+import "time"
+
+// LoginSession is one signed-in device. It lives as long as its refresh
+// token family, whose FamilyID access tokens carry as their session ID.
+type LoginSession struct {
+	ID         uint       `json:"id" gorm:"primaryKey"`
+	UserID     uint       `json:"user_id" gorm:"index"`
+	User       *User      `json:"user,omitempty" gorm:"foreignKey:UserID"`
+	FamilyID   string     `json:"-" gorm:"size:64;uniqueIndex"`
+	IP         string     `json:"ip" gorm:"size:64"`
+	Device     string     `json:"device" gorm:"size:512"` // User-Agent at login
+	CreatedAt  time.Time  `json:"created_at"`
+	LastSeenAt time.Time  `json:"last_seen_at"`
+	ExpiresAt  time.Time  `json:"expires_at"`           // Expiry of the latest refresh token
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"` // Set on logout or revocation
+}
@@ -0,0 +1,34 @@
+package entity
+
+import "time"
+
+// Lockout event actions
+const (
+	LockoutActionLocked   = "locked"
+	LockoutActionUnlocked = "unlocked"
+)
+
+// LoginThrottle counts the recent failed logins of one email or one IP.
+// Key is "email:<address>" or "ip:<address>".
+type LoginThrottle struct {
+	ID            uint       `json:"id" gorm:"primaryKey"`
+	Key           string     `json:"key" gorm:"size:320;uniqueIndex"`
+	Failures      int        `json:"failures"`
+	LockedUntil   *time.Time `json:"locked_until,omitempty"`
+	LastFailureAt time.Time  `json:"last_failure_at"`
+
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// LockoutEvent records a lockout or an admin unlock
+type LockoutEvent struct {
+	ID          uint       `json:"id" gorm:"primaryKey"`
+	Key         string     `json:"key" gorm:"size:320;index"`
+	Action      string     `json:"action" gorm:"size:32"`
+	Failures    int        `json:"failures"`
+	LockedUntil *time.Time `json:"locked_until,omitempty"`
+	ActorID     *uint      `json:"actor_id,omitempty"` // Admin who unlocked, nil for lockouts
+	IP          string     `json:"ip" gorm:"size:64"`  // Client IP of the attempt that caused the lockout
+
+	CreatedAt time.Time `json:"created_at" gorm:"index"`
+}
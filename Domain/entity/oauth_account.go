@@ -0,0 +1,16 @@
+package entity
+
+import "time"
+
+// OAuthAccount links an account at an OAuth provider to the user it signs in as.
+// A user has at most one account per provider.
+type OAuthAccount struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	UserID    uint      `json:"user_id" gorm:"uniqueIndex:idx_oauth_accounts_user_provider"`
+	User      *User     `json:"user,omitempty" gorm:"foreignKey:UserID"`
+	Provider  string    `json:"provider" gorm:"size:32;uniqueIndex:idx_oauth_accounts_user_provider;uniqueIndex:idx_oauth_accounts_provider_subject"`
+	Subject   string    `json:"subject" gorm:"size:255;uniqueIndex:idx_oauth_accounts_provider_subject"` // Account ID at the provider
+	Email     string    `json:"email,omitempty" gorm:"size:255"`                                         // Provider email at link time
+	Username  string    `json:"username,omitempty" gorm:"size:255"`                                      // GitHub login, if any
+	CreatedAt time.Time `json:"created_at"`
+}
@@ -0,0 +1,15 @@
+package entity
+
+import "time"
+
+// PasswordResetToken is a single-use link sent by the forgot-password flow.
+// Only the SHA-256 of the token is stored.
+type PasswordResetToken struct {
+	ID        uint       `json:"id" gorm:"primaryKey"`
+	UserID    uint       `json:"user_id" gorm:"index"`
+	User      *User      `json:"user,omitempty" gorm:"foreignKey:UserID"`
+	TokenHash string     `json:"-" gorm:"size:64;uniqueIndex"`
+	ExpiresAt time.Time  `json:"expires_at"`
+	UsedAt    *time.Time `json:"used_at,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+}
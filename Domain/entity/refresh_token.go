@@ -0,0 +1,30 @@
+package entity
+
+import (
+	"time"
+)
+
+// RefreshToken is a single-use token exchanged for a new access token.
+// Only the SHA-256 hash of the token is stored. Tokens rotated from the same
+// login share a FamilyID, which access tokens carry as their session ID.
+type RefreshToken struct {
+	ID        uint       `json:"id" gorm:"primaryKey"`
+	UserID    uint       `json:"user_id" gorm:"index"`
+	User      *User      `json:"user,omitempty" gorm:"foreignKey:UserID"`
+	FamilyID  string     `json:"family_id" gorm:"size:64;index"`
+	TokenHash string     `json:"-" gorm:"size:64;uniqueIndex"`
+	ExpiresAt time.Time  `json:"expires_at"`
+	UsedAt    *time.Time `json:"used_at,omitempty"`    // Set when the token is rotated
+	RevokedAt *time.Time `json:"revoked_at,omitempty"` // Set on logout or forced sign-out
+
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// RevokedToken denylists an access token by its JWT ID until it expires
+type RevokedToken struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	JTI       string    `json:"jti" gorm:"size:64;uniqueIndex"`
+	ExpiresAt time.Time `json:"expires_at" gorm:"index"`
+
+	CreatedAt time.Time `json:"created_at"`
+}
@@ -0,0 +1,46 @@
+package entity
+
+import "time"
+
+// Registration job statuses
+const (
+	RegistrationJobPending   = "pending"
+	RegistrationJobRunning   = "running"
+	RegistrationJobCompleted = "completed"
+	RegistrationJobFailed    = "failed"
+)
+
+// Registration job modes
+const (
+	// RegistrationModePerRow registers every valid row on its own
+	RegistrationModePerRow = "per_row"
+	// RegistrationModeAllOrNothing registers nobody unless every row is
+	// valid, and then registers all rows in one transaction
+	RegistrationModeAllOrNothing = "all_or_nothing"
+)
+
+// RegistrationJob is a bulk registration running in the background
+type RegistrationJob struct {
+	ID          uint   `json:"id" gorm:"primaryKey"`
+	CreatedByID uint   `json:"created_by_id" gorm:"index"`
+	CreatedBy   *User  `json:"created_by,omitempty" gorm:"foreignKey:CreatedByID"`
+	RoleID      uint   `json:"role_id"`
+	Mode        string `json:"mode" gorm:"size:20"`
+	Status      string `json:"status" gorm:"size:20;index"`
+
+	Total     int    `json:"total"`
+	Processed int    `json:"processed"`
+	Succeeded int    `json:"succeeded"`
+	Failed    int    `json:"failed"`
+	Error     string `json:"error,omitempty" gorm:"type:text"` // Why the job as a whole failed
+	Report    string `json:"-" gorm:"type:text"`               // JSON encoded per-row results, set once the job ends
+
+	CreatedAt  time.Time  `json:"created_at"`
+	StartedAt  *time.Time `json:"started_at,omitempty"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+}
+
+// Finished reports whether the job has ended, successfully or not
+func (j *RegistrationJob) Finished() bool {
+	return j.Status == RegistrationJobCompleted || j.Status == RegistrationJobFailed
+}
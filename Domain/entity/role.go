@@ -11,8 +11,9 @@ type Role struct {
 	Description string `json:"description" gorm:"size:255"`
 
 	// Relations
-	Users   []User   `json:"users,omitempty" gorm:"foreignKey:RoleID"`
-	Invites []Invite `json:"invites,omitempty" gorm:"foreignKey:RoleID"`
+	Users       []User           `json:"users,omitempty" gorm:"foreignKey:RoleID"`
+	Invites     []Invite         `json:"invites,omitempty" gorm:"foreignKey:RoleID"`
+	Permissions []RolePermission `json:"permissions,omitempty" gorm:"foreignKey:RoleID"`
 
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
@@ -0,0 +1,72 @@
+package entity
+
+import (
+	"time"
+)
+
+// Permission names checked by the HTTP layer. A role holding
+// PermissionAll is granted every permission.
+const (
+	PermissionAll = "*"
+
+	PermissionUserWrite         = "user:write"
+	PermissionUserDelete        = "user:delete"
+	PermissionRoleWrite         = "role:write"
+	PermissionGroupWrite        = "group:write"
+	PermissionCountryWrite      = "country:write"
+	PermissionSessionWrite      = "session:write"
+	PermissionAttendanceRecord  = "attendance:record"
+	PermissionExerciseWrite     = "exercise:write"
+	PermissionProblemWrite      = "problem:write"
+	PermissionTrackWrite        = "track:write"
+	PermissionStipendRead       = "stipend:read"
+	PermissionStipendWrite      = "stipend:write"
+	PermissionRegistrationWrite = "registration:write"
+	PermissionInviteWrite       = "invite:write"
+	PermissionAuditRead         = "audit:read"
+	PermissionUserImpersonate   = "user:impersonate"
+	// PermissionScopeAll lifts the group scope restriction applied to HOAs
+	PermissionScopeAll = "scope:all"
+)
+
+// Permissions lists every permission that can be granted to a role
+var Permissions = []string{
+	PermissionAll,
+	PermissionUserWrite,
+	PermissionUserDelete,
+	PermissionRoleWrite,
+	PermissionGroupWrite,
+	PermissionCountryWrite,
+	PermissionSessionWrite,
+	PermissionAttendanceRecord,
+	PermissionExerciseWrite,
+	PermissionProblemWrite,
+	PermissionTrackWrite,
+	PermissionStipendRead,
+	PermissionStipendWrite,
+	PermissionRegistrationWrite,
+	PermissionInviteWrite,
+	PermissionAuditRead,
+	PermissionUserImpersonate,
+	PermissionScopeAll,
+}
+
+// IsKnownPermission reports whether the permission name is one of Permissions
+func IsKnownPermission(permission string) bool {
+	for _, p := range Permissions {
+		if p == permission {
+			return true
+		}
+	}
+	return false
+}
+
+// RolePermission grants a single permission to a role
+type RolePermission struct {
+	ID         uint   `json:"id" gorm:"primaryKey"`
+	RoleID     uint   `json:"role_id" gorm:"uniqueIndex:idx_role_permission"`
+	Role       *Role  `json:"role,omitempty" gorm:"foreignKey:RoleID"`
+	Permission string `json:"permission" gorm:"size:100;uniqueIndex:idx_role_permission"`
+
+	CreatedAt time.Time `json:"created_at"`
+}
@@ -0,0 +1,17 @@
+package entity
+
+import "time"
+
+// SigningKey is a key pair access tokens are signed with. The newest key
+// whose ActivatesAt has passed signs new tokens; every key that has not
+// retired verifies them and is published in the JWKS.
+type SigningKey struct {
+	ID          uint       `json:"id" gorm:"primaryKey"`
+	KID         string     `json:"kid" gorm:"size:64;uniqueIndex"`
+	Algorithm   string     `json:"algorithm" gorm:"size:16"` // RS256 or EdDSA
+	PrivateKey  string     `json:"-" gorm:"type:text"`       // PKCS#8 PEM, encrypted, see ColumnSigningKeyPrivateKey
+	PublicKey   string     `json:"public_key" gorm:"type:text"`
+	CreatedAt   time.Time  `json:"created_at"`
+	ActivatesAt time.Time  `json:"activates_at"`
+	RetiresAt   *time.Time `json:"retires_at,omitempty" gorm:"index"` // Set when a newer key replaces it
+}
@@ -16,6 +16,10 @@ type Stipend struct {
 	Paid      bool     `json:"paid" gorm:"default:false"`
 	Share     float64  `json:"share,omitempty"` // Share of the fund amount
 
+	// Payment details. AccountNumber is stored encrypted (ColumnStipendAccountNumber).
+	BankName      string `json:"bank_name,omitempty" gorm:"size:255"`
+	AccountNumber string `json:"account_number,omitempty" gorm:"type:text"`
+
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
 }
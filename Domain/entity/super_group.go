@@ -8,6 +8,9 @@ import (
 type SuperGroup struct {
 	ID   uint   `json:"id" gorm:"primaryKey"`
 	Name string `json:"name" gorm:"size:255"`
+	// LeadID is the user who manages every group mapped to this super group
+	LeadID *uint `json:"lead_id,omitempty"`
+	Lead   *User `json:"lead,omitempty" gorm:"foreignKey:LeadID"`
 
 	// Relations
 	Tracks        []Track        `json:"tracks,omitempty" gorm:"foreignKey:SuperGroupID"`
@@ -0,0 +1,15 @@
+package entity
+
+import "time"
+
+// TelegramLinkCode is a one-time code a user sends to the bot to link the
+// Telegram account they send it from
+type TelegramLinkCode struct {
+	ID        uint       `json:"id" gorm:"primaryKey"`
+	UserID    uint       `json:"user_id" gorm:"index"`
+	User      *User      `json:"user,omitempty" gorm:"foreignKey:UserID"`
+	CodeHash  string     `json:"-" gorm:"size:64;uniqueIndex"`
+	ExpiresAt time.Time  `json:"expires_at"`
+	UsedAt    *time.Time `json:"used_at,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+}
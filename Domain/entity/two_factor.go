@@ -0,0 +1,53 @@
+package entity
+
+import "time"
+
+// TwoFactorPermissions reach /api/users and /api/stipends. A role holding any
+// of them must use two-factor authentication.
+var TwoFactorPermissions = []string{
+	PermissionAll,
+	PermissionUserWrite,
+	PermissionUserDelete,
+	PermissionUserImpersonate,
+	PermissionStipendRead,
+	PermissionStipendWrite,
+}
+
+// TwoFactor holds a user's TOTP secret. It is pending until EnabledAt is set
+// by confirming a first code.
+type TwoFactor struct {
+	ID           uint       `json:"id" gorm:"primaryKey"`
+	UserID       uint       `json:"user_id" gorm:"uniqueIndex"`
+	User         *User      `json:"user,omitempty" gorm:"foreignKey:UserID"`
+	Secret       string     `json:"-" gorm:"size:512"` // Encrypted, see ColumnTwoFactorSecret
+	EnabledAt    *time.Time `json:"enabled_at,omitempty"`
+	LastUsedStep int64      `json:"-"` // Time step of the last accepted code, to reject replays
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// RecoveryCode is a single-use code that replaces a TOTP code.
+// Only the SHA-256 hash of the code is stored.
+type RecoveryCode struct {
+	ID       uint       `json:"id" gorm:"primaryKey"`
+	UserID   uint       `json:"user_id" gorm:"index"`
+	CodeHash string     `json:"-" gorm:"size:64;index"`
+	UsedAt   *time.Time `json:"used_at,omitempty"`
+
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// TwoFactorChallenge is the pending second step of a login. The client gets
+// the token after the first factor and trades it with a code for a session.
+type TwoFactorChallenge struct {
+	ID        uint       `json:"id" gorm:"primaryKey"`
+	UserID    uint       `json:"user_id" gorm:"index"`
+	TokenHash string     `json:"-" gorm:"size:64;uniqueIndex"`
+	Method    string     `json:"method" gorm:"size:32"` // Login method of the first factor
+	Attempts  int        `json:"attempts"`
+	ExpiresAt time.Time  `json:"expires_at"`
+	UsedAt    *time.Time `json:"used_at,omitempty"`
+
+	CreatedAt time.Time `json:"created_at"`
+}
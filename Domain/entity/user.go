@@ -2,6 +2,8 @@ package entity
 
 import (
 	"time"
+
+	"gorm.io/gorm"
 )
 
 // User represents a system user with their profile and account information.
@@ -13,23 +15,26 @@ type User struct {
 	Password string `json:"-" gorm:"size:255;not null"` // Password not included in JSON responses
 
 	// Role and Relationships
-	RoleID    uint     `json:"role_id" gorm:"default:3;not null"` // Default role ID (adjust as necessary)
-	Role      *Role    `json:"role,omitempty" gorm:"foreignKey:RoleID"`
-	GroupID   *uint    `json:"group_id,omitempty"`
-	Group     *Group   `json:"group,omitempty" gorm:"foreignKey:GroupID"`
-	CountryID *uint    `json:"country_id,omitempty"`
-	Country   *Country `json:"country,omitempty" gorm:"foreignKey:CountryID"`
+	RoleID        uint     `json:"role_id" gorm:"default:3;not null"` // Default role ID (adjust as necessary)
+	Role          *Role    `json:"role,omitempty" gorm:"foreignKey:RoleID"`
+	GroupID       *uint    `json:"group_id,omitempty"`
+	Group         *Group   `json:"group,omitempty" gorm:"foreignKey:GroupID"`
+	FormerGroupID *uint    `json:"former_group_id,omitempty"` // Group the user left when they became an alumnus
+	CountryID     *uint    `json:"country_id,omitempty"`
+	Country       *Country `json:"country,omitempty" gorm:"foreignKey:CountryID"`
 
 	// Academic Information
 	University             string     `json:"university,omitempty" gorm:"size:255"`
 	StudentID              string     `json:"student_id,omitempty" gorm:"size:255"`
 	Department             string     `json:"department,omitempty" gorm:"size:255"`
 	ExpectedGraduationDate *time.Time `json:"expected_graduation_date,omitempty"`
+	GraduatedAt            *time.Time `json:"graduated_at,omitempty"` // Set when the user is moved to the alumni role
 
 	// Contact Information
-	Phone            string  `json:"phone,omitempty" gorm:"size:20"`
-	TelegramUsername *string `json:"telegram_username,omitempty" gorm:"size:255;unique"`
-	TelegramUID      string  `json:"telegram_uid,omitempty" gorm:"size:255"`
+	Phone            string     `json:"phone,omitempty" gorm:"size:20"`
+	TelegramUsername *string    `json:"telegram_username,omitempty" gorm:"size:255;unique"`
+	TelegramUID      string     `json:"telegram_uid,omitempty" gorm:"size:255"`
+	TelegramLinkedAt *time.Time `json:"telegram_linked_at,omitempty"` // Set once the Telegram account is verified
 
 	// Coding Profiles (optional)
 	// Use pointer types so that if no value is provided, they remain nil.
@@ -60,12 +65,14 @@ type User struct {
 	// System Fields
 	Photo         string `json:"photo,omitempty" gorm:"size:255"`
 	CodeOfConduct string `json:"code_of_conduct,omitempty" gorm:"size:255"`
-	Inactive      bool   `json:"inactive" gorm:"default:false"`
+	Inactive      bool   `json:"inactive" gorm:"default:false"` // Deactivated users cannot sign in
 	Config        string `json:"config,omitempty" gorm:"type:text"`
 
 	// Timestamps
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	DeletedAt gorm.DeletedAt `json:"deleted_at,omitempty" gorm:"index"` // Set when the user is soft deleted
+	ErasedAt  *time.Time     `json:"erased_at,omitempty"`               // Set when the user's personal data is erased
 
 	// Relations (using GORM associations)
 	Submissions     []Submission   `json:"submissions,omitempty" gorm:"foreignKey:UserID"`
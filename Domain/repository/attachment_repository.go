@@ -0,0 +1,13 @@
+package repository
+
+import "a2sv.org/hub/Domain/entity"
+
+// AttachmentRepository defines methods for session resource and post attachment data operations
+type AttachmentRepository interface {
+	Create(attachment *entity.Attachment) error
+	GetByID(id uint) (*entity.Attachment, error)
+	// ListBySession and ListByPost return the oldest attachment first
+	ListBySession(sessionID uint) ([]*entity.Attachment, error)
+	ListByPost(postID uint) ([]*entity.Attachment, error)
+	Delete(id uint) error
+}
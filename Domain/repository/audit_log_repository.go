@@ -0,0 +1,26 @@
+package repository
+
+import (
+	"time"
+
+	"a2sv.org/hub/Domain/entity"
+)
+
+// AuditLogFilter narrows an audit log query; zero values match everything
+type AuditLogFilter struct {
+	ActorID    *uint
+	EntityType string
+	EntityID   string
+	Action     string
+	From       *time.Time
+	To         *time.Time
+}
+
+// AuditLogRepository defines methods for audit log data operations.
+// It deliberately has no update or delete.
+type AuditLogRepository interface {
+	Create(log *entity.AuditLog) error
+	List(filter AuditLogFilter, offset, limit int) ([]*entity.AuditLog, int64, error)
+	// Snapshot returns the current columns of a row, or nil if it does not exist
+	Snapshot(table string, id string) (map[string]interface{}, error)
+}
@@ -0,0 +1,15 @@
+package repository
+
+import (
+	"a2sv.org/hub/Domain/entity"
+)
+
+// EncryptedColumnRepository reads and rewrites the values of encrypted columns
+type EncryptedColumnRepository interface {
+	// ListStale returns up to limit non-empty values, by ascending row ID
+	// after afterID, that are not encrypted with the master key keyID
+	ListStale(column entity.EncryptedColumn, keyID string, afterID uint, limit int) ([]*entity.EncryptedValue, error)
+	// Replace rewrites a value; it returns gorm.ErrRecordNotFound if the
+	// value changed since it was read
+	Replace(column entity.EncryptedColumn, id uint, oldValue, newValue string) error
+}
@@ -0,0 +1,16 @@
+package repository
+
+import (
+	"a2sv.org/hub/Domain/entity"
+)
+
+// HOARepository defines methods for Head of Academy data operations
+type HOARepository interface {
+	Create(hoa *entity.HOA) error
+	Delete(groupID, userID uint) error
+	GetByGroupID(groupID uint) ([]*entity.HOA, error)
+
+	// GetManagedGroupIDs returns every group the user heads, either directly
+	// or as the lead of a super group the group is mapped to.
+	GetManagedGroupIDs(userID uint) ([]uint, error)
+}
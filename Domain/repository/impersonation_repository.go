@@ -0,0 +1,16 @@
+package repository
+
+import (
+	"time"
+
+	"a2sv.org/hub/Domain/entity"
+)
+
+// ImpersonationRepository defines methods for impersonation data operations
+type ImpersonationRepository interface {
+	Create(impersonation *entity.Impersonation) error
+	// End marks the impersonation of the token as ended; it returns
+	// gorm.ErrRecordNotFound if it was already ended
+	End(tokenID string, endedAt time.Time) error
+	List(actorID, userID *uint, offset, limit int) ([]*entity.Impersonation, int64, error)
+}
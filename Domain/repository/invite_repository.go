@@ -0,0 +1,22 @@
+package repository
+
+import (
+	"time"
+
+	"a2sv.org/hub/Domain/entity"
+)
+
+// InviteRepository defines methods for invite data operations
+type InviteRepository interface {
+	Create(invite *entity.Invite) error
+	GetByID(id uint) (*entity.Invite, error)
+	GetByKey(keyHash string) (*entity.Invite, error)
+	ListByGroupID(groupID uint) ([]*entity.Invite, error)
+
+	// ConsumeUse takes one use of the invite if it is still redeemable at the
+	// given time; it returns gorm.ErrRecordNotFound otherwise.
+	ConsumeUse(id uint, now time.Time) error
+	// ReleaseUse gives back a use taken by ConsumeUse
+	ReleaseUse(id uint) error
+	Revoke(id uint, revokedAt time.Time) error
+}
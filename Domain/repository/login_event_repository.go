@@ -0,0 +1,16 @@
+package repository
+
+import (
+	"a2sv.org/hub/Domain/entity"
+)
+
+// LoginEventRepository defines methods for login history data operations
+type LoginEventRepository interface {
+	Create(event *entity.LoginEvent) error
+	ListByUserID(userID uint, offset, limit int) ([]*entity.LoginEvent, int64, error)
+
+	// HasSuccessfulLogin reports whether the user ever logged in successfully
+	HasSuccessfulLogin(userID uint) (bool, error)
+	HasSeenDevice(userID uint, device string) (bool, error)
+	HasSeenCountry(userID uint, country string) (bool, error)
+}
@@ -0,0 +1,20 @@
+package repository
+
+import (
+	"time"
+
+	"a2sv.org/hub/Domain/entity"
+)
+
+// LoginSessionRepository defines methods for login session data operations
+type LoginSessionRepository interface {
+	Create(session *entity.LoginSession) error
+	GetByID(id uint) (*entity.LoginSession, error)
+	ListActiveByUserID(userID uint, now time.Time) ([]*entity.LoginSession, error)
+	// Extend moves the expiry of a session when its refresh token is rotated
+	Extend(familyID string, expiresAt, seenAt time.Time) error
+	// Touch sets last_seen_at unless it is already later than staleBefore
+	Touch(familyID string, seenAt, staleBefore time.Time) error
+	RevokeByFamilyID(familyID string, revokedAt time.Time) error
+	RevokeAllByUserID(userID uint, revokedAt time.Time) error
+}
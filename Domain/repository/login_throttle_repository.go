@@ -0,0 +1,19 @@
+package repository
+
+import (
+	"time"
+
+	"a2sv.org/hub/Domain/entity"
+)
+
+// LoginThrottleRepository defines methods for failed login counters and lockout events
+type LoginThrottleRepository interface {
+	GetByKeys(keys ...string) ([]*entity.LoginThrottle, error)
+	// RecordFailure atomically counts a failed login and returns the new count.
+	// The count restarts at 1 when neither a failure nor a lock happened after resetBefore.
+	RecordFailure(key string, at, resetBefore time.Time) (int, error)
+	Lock(key string, until time.Time) error
+	Reset(key string) error
+
+	CreateEvent(event *entity.LockoutEvent) error
+}
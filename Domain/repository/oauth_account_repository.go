@@ -0,0 +1,12 @@
+package repository
+
+import (
+	"a2sv.org/hub/Domain/entity"
+)
+
+// OAuthAccountRepository defines methods for OAuth account link data operations
+type OAuthAccountRepository interface {
+	Create(account *entity.OAuthAccount) error
+	GetBySubject(provider, subject string) (*entity.OAuthAccount, error)
+	GetByUserID(userID uint, provider string) (*entity.OAuthAccount, error)
+}
@@ -0,0 +1,17 @@
+package repository
+
+import (
+	"time"
+
+	"a2sv.org/hub/Domain/entity"
+)
+
+// PasswordResetTokenRepository defines methods for password reset token data operations
+type PasswordResetTokenRepository interface {
+	Create(token *entity.PasswordResetToken) error
+	GetByHash(tokenHash string) (*entity.PasswordResetToken, error)
+	// MarkUsed consumes the token; it returns gorm.ErrRecordNotFound if it was already used
+	MarkUsed(id uint, usedAt time.Time) error
+	// InvalidateByUserID consumes every outstanding token of the user
+	InvalidateByUserID(userID uint, usedAt time.Time) error
+}
@@ -0,0 +1,27 @@
+package repository
+
+import "a2sv.org/hub/Domain/entity"
+
+// PersonalDataRepository reads every record tied to a user, for data
+// exports, and erases the personal ones
+type PersonalDataRepository interface {
+	// The lists return the oldest record first
+	ListSubmissions(userID uint) ([]*entity.Submission, error)
+	ListAttendances(userID uint) ([]*entity.Attendance, error)
+	ListComments(userID uint) ([]*entity.Comment, error)
+	ListPosts(userID uint) ([]*entity.Post, error)
+	ListVotes(userID uint) ([]*entity.Vote, error)
+	ListStipends(userID uint) ([]*entity.Stipend, error)
+	ListAssistantMessages(userID uint) ([]*entity.AssistantMessage, error)
+	ListRecentActions(userID uint) ([]*entity.RecentAction, error)
+	ListLoginEvents(userID uint) ([]*entity.LoginEvent, error)
+	// ListInvites returns the invites bound to the email, which hold no user ID
+	ListInvites(email string) ([]*entity.Invite, error)
+
+	// EraseUser saves the anonymized user and, in the same transaction,
+	// deletes their API tokens, linked accounts, two-factor secrets, login
+	// sessions and history and assistant conversations, clears the bank
+	// details of their stipends and revokes the invites bound to their email
+	// and clears the email
+	EraseUser(user *entity.User) error
+}
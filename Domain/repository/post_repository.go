@@ -0,0 +1,8 @@
+package repository
+
+import "a2sv.org/hub/Domain/entity"
+
+// PostRepository defines methods for Post data operations
+type PostRepository interface {
+	GetPostByID(id uint) (*entity.Post, error)
+}
@@ -0,0 +1,24 @@
+package repository
+
+import (
+	"time"
+
+	"a2sv.org/hub/Domain/entity"
+)
+
+// RefreshTokenRepository defines methods for refresh token data operations
+type RefreshTokenRepository interface {
+	Create(token *entity.RefreshToken) error
+	GetByHash(tokenHash string) (*entity.RefreshToken, error)
+	MarkUsed(id uint, usedAt time.Time) error
+	RevokeFamily(familyID string, revokedAt time.Time) error
+	RevokeAllByUserID(userID uint, revokedAt time.Time) error
+	IsFamilyRevoked(familyID string) (bool, error)
+}
+
+// RevokedTokenRepository defines methods for the access token denylist
+type RevokedTokenRepository interface {
+	Create(token *entity.RevokedToken) error
+	IsRevoked(jti string) (bool, error)
+	DeleteExpired(now time.Time) error
+}
@@ -0,0 +1,18 @@
+package repository
+
+import (
+	"time"
+
+	"a2sv.org/hub/Domain/entity"
+)
+
+// RegistrationJobRepository defines methods for bulk registration job data operations
+type RegistrationJobRepository interface {
+	Create(job *entity.RegistrationJob) error
+	GetByID(id uint) (*entity.RegistrationJob, error)
+	// Update saves the job's status, progress and report
+	Update(job *entity.RegistrationJob) error
+	// FailUnfinished fails the jobs that are still pending or running and
+	// returns how many there were
+	FailUnfinished(message string, finishedAt time.Time) (int64, error)
+}
@@ -0,0 +1,14 @@
+package repository
+
+import (
+	"a2sv.org/hub/Domain/entity"
+)
+
+// RolePermissionRepository defines methods for role permission data operations
+type RolePermissionRepository interface {
+	AddPermission(permission *entity.RolePermission) error
+	RemovePermission(roleID uint, permission string) error
+	ListPermissionsByRoleID(roleID uint) ([]*entity.RolePermission, error)
+	ReplacePermissions(roleID uint, permissions []string) error
+	HasPermission(roleID uint, permission string) (bool, error)
+}
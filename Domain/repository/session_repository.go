@@ -13,7 +13,7 @@ type SessionRepository interface {
 	GetSessionByName(name string) ([]*entity.Session, error)
 	GetSessionByID(id uint) (*entity.Session, error)
 	GetSessionByStartTime(startTime string) ([]*entity.Session, error)
-	
+	GetSessionGroupIDs(sessionID uint) ([]uint, error)
 
 	UpdateSession(Session *entity.Session) error
 
@@ -0,0 +1,17 @@
+package repository
+
+import (
+	"time"
+
+	"a2sv.org/hub/Domain/entity"
+)
+
+// SigningKeyRepository defines methods for JWT signing key data operations
+type SigningKeyRepository interface {
+	Create(key *entity.SigningKey) error
+	// ListUnretired returns the keys that still verify at now, oldest first
+	ListUnretired(now time.Time) ([]*entity.SigningKey, error)
+	// RetireAll schedules the retirement of every key not yet scheduled
+	RetireAll(retiresAt time.Time) error
+	DeleteRetired(now time.Time) error
+}
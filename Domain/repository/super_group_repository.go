@@ -12,6 +12,8 @@ type SuperGroupRepository interface {
 
 	GetSuperGroupByName(name string) (*entity.SuperGroup, error)
 	GetSuperGroupByID(id uint) (*entity.SuperGroup, error)
+	// GetGroupIDs returns the IDs of the groups mapped to the super group
+	GetGroupIDs(superGroupID uint) ([]uint, error)
 
 	UpdateSuperGroup(SuperGroup *entity.SuperGroup) error
 
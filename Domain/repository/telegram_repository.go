@@ -0,0 +1,22 @@
+package repository
+
+import (
+	"time"
+
+	"a2sv.org/hub/Domain/entity"
+)
+
+// TelegramRepository defines methods for Telegram link codes and the linked accounts of users
+type TelegramRepository interface {
+	CreateLinkCode(code *entity.TelegramLinkCode) error
+	// UseLinkCode consumes an unexpired code; it returns gorm.ErrRecordNotFound
+	// if the code does not exist, expired or was already used
+	UseLinkCode(codeHash string, usedAt time.Time) (*entity.TelegramLinkCode, error)
+
+	// GetLinkedUser returns the user the Telegram account is linked to
+	GetLinkedUser(telegramUID string) (*entity.User, error)
+	// Link marks the Telegram account as verified for the user. Another
+	// user's unverified claim to the same username is cleared.
+	Link(userID uint, telegramUID string, username *string, linkedAt time.Time) error
+	Unlink(userID uint) error
+}
@@ -0,0 +1,34 @@
+package repository
+
+import (
+	"time"
+
+	"a2sv.org/hub/Domain/entity"
+)
+
+// TwoFactorRepository defines methods for TOTP secrets, recovery codes and login challenges
+type TwoFactorRepository interface {
+	GetByUserID(userID uint) (*entity.TwoFactor, error)
+	// Save creates the user's pending secret or replaces the secret of a pending one
+	Save(twoFactor *entity.TwoFactor) error
+	Enable(userID uint, enabledAt time.Time) error
+	// Delete removes the secret and the recovery codes of the user
+	Delete(userID uint) error
+	// UseStep records an accepted code; it returns gorm.ErrRecordNotFound if
+	// a code of the same or a later step was already used
+	UseStep(userID uint, step int64) error
+
+	// ReplaceRecoveryCodes drops the user's recovery codes and stores the new hashes
+	ReplaceRecoveryCodes(userID uint, codeHashes []string) error
+	// UseRecoveryCode consumes a code; it returns gorm.ErrRecordNotFound if
+	// the code does not exist or was already used
+	UseRecoveryCode(userID uint, codeHash string, usedAt time.Time) error
+	CountRecoveryCodes(userID uint) (int64, error)
+
+	CreateChallenge(challenge *entity.TwoFactorChallenge) error
+	GetChallengeByHash(tokenHash string) (*entity.TwoFactorChallenge, error)
+	// AddChallengeAttempt counts a wrong code and returns the new attempt count
+	AddChallengeAttempt(id uint) (int, error)
+	// MarkChallengeUsed consumes the challenge; it returns gorm.ErrRecordNotFound if it was already used
+	MarkChallengeUsed(id uint, usedAt time.Time) error
+}
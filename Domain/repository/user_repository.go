@@ -1,6 +1,8 @@
 package repository
 
 import (
+	"time"
+
 	"a2sv.org/hub/Domain/entity"
 	"a2sv.org/hub/Delivery/http/schemas"
 )
@@ -9,6 +11,8 @@ import (
 type UserRepository interface {
 	// Create method
 	CreateUser(user *entity.User) error
+	// CreateUsers creates all of the users or, if any fails, none of them
+	CreateUsers(users []*entity.User) error
 
 	// List methods
 	ListUser(page, page_size int) ([]*entity.User, error)
@@ -24,5 +28,29 @@ type UserRepository interface {
 
 	// Update and Delete methods
 	UpdateUser(user *entity.User) error
+	// SetInactive deactivates or reactivates the user
+	SetInactive(id uint, inactive bool) error
+	// DeleteUser soft deletes the user, keeping the row and its history
 	DeleteUser(id uint) error
+
+	// ListGraduates lists the active users holding one of the roles whose
+	// expected graduation date is before the given time
+	ListGraduates(roleIDs []uint, before time.Time) ([]*entity.User, error)
+	// MoveToAlumni gives a user holding one of fromRoleIDs the alumni role and
+	// moves their group to FormerGroupID, or returns gorm.ErrRecordNotFound
+	MoveToAlumni(id uint, fromRoleIDs []uint, alumniRoleID uint, at time.Time) error
+
+	// Deleted users; GetDeletedUser, RestoreUser and PurgeUser return
+	// gorm.ErrRecordNotFound for a user that is not deleted
+	ListDeletedUsers(page, pageSize int) ([]*entity.User, int, error)
+	GetDeletedUser(id uint) (*entity.User, error)
+	GetDeletedUserByEmail(email string) (*entity.User, error)
+	RestoreUser(id uint) error
+	// HasHistory reports whether submissions, attendance or stipends
+	// reference the user
+	HasHistory(id uint) (bool, error)
+	// PurgeUser permanently deletes a deleted user along with their sign-in
+	// data: sessions, tokens, two-factor settings, linked accounts and login
+	// history
+	PurgeUser(id uint) error
 }
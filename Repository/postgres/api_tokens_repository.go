@@ -1,57 +1,38 @@
 package postgres
 
 import (
+	"time"
+
 	"a2sv.org/hub/Domain/entity"
 	"a2sv.org/hub/Domain/repository"
 	"gorm.io/gorm"
 )
 
+// apiTokenRepository is not cached so that a revoked token is rejected on the next request
 type apiTokenRepository struct {
-	BaseRepository
+	db *gorm.DB
 }
 
 func NewAPITokenRepository(db *gorm.DB) repository.APITokenRepository {
-	return &apiTokenRepository{
-		BaseRepository: NewBaseRepository(db, "api_token"),
-	}
+	return &apiTokenRepository{db: db}
 }
 
 func (r *apiTokenRepository) Create(token *entity.APIToken) error {
-	err := r.db.Create(token).Error
-	if err != nil {
-		return err
-	}
-
-	// Cache the newly created token
-	_ = r.cacheDetail("byid", token, token.ID)
-	_ = r.cacheDetail("bytoken", token, token.Token)
-	_ = r.cacheDetail("byuser", token, token.UserID)
-
-	// Invalidate list caches
-	r.invalidateCache("list")
-	
-	return nil
+	return r.db.Create(token).Error
 }
 
 func (r *apiTokenRepository) GetByID(id uint) (*entity.APIToken, error) {
 	var token entity.APIToken
-	err := r.getCachedDetail("byid", &token, func() error {
-		return r.db.First(&token, id).Error
-	}, id)
-	
-	if err != nil {
+	if err := r.db.First(&token, id).Error; err != nil {
 		return nil, err
 	}
 	return &token, nil
 }
 
+// GetByToken looks a token up by its hash
 func (r *apiTokenRepository) GetByToken(tokenStr string) (*entity.APIToken, error) {
 	var token entity.APIToken
-	err := r.getCachedDetail("bytoken", &token, func() error {
-		return r.db.Where("token = ?", tokenStr).First(&token).Error
-	}, tokenStr)
-	
-	if err != nil {
+	if err := r.db.Where("token = ?", tokenStr).First(&token).Error; err != nil {
 		return nil, err
 	}
 	return &token, nil
@@ -59,51 +40,37 @@ func (r *apiTokenRepository) GetByToken(tokenStr string) (*entity.APIToken, erro
 
 func (r *apiTokenRepository) GetByUserID(userID uint) ([]*entity.APIToken, error) {
 	var tokens []*entity.APIToken
-	err := r.getCachedList("byuser", &tokens, func() error {
-		return r.db.Where("user_id = ?", userID).Find(&tokens).Error
-	}, userID)
-	
+	err := r.db.Where("user_id = ?", userID).Order("created_at DESC").Find(&tokens).Error
 	return tokens, err
 }
 
 func (r *apiTokenRepository) List() ([]*entity.APIToken, error) {
 	var tokens []*entity.APIToken
-	err := r.getCachedList("list", &tokens, func() error {
-		return r.db.Find(&tokens).Error
-	})
-	
+	err := r.db.Find(&tokens).Error
 	return tokens, err
 }
 
 func (r *apiTokenRepository) Update(token *entity.APIToken) error {
-	err := r.db.Save(token).Error
-	if err != nil {
-		return err
-	}
-
-	// Update detail caches
-	_ = r.cacheDetail("byid", token, token.ID)
-	_ = r.cacheDetail("bytoken", token, token.Token)
-	_ = r.cacheDetail("byuser", token, token.UserID)
+	return r.db.Save(token).Error
+}
 
-	// Invalidate list caches
-	r.invalidateCache("list")
-	
-	return nil
+// TouchLastUsed records when a token was last used without rewriting the rest of the row
+func (r *apiTokenRepository) TouchLastUsed(id uint, usedAt time.Time) error {
+	return r.db.Model(&entity.APIToken{}).Where("id = ?", id).Update("last_used_at", usedAt).Error
 }
 
 func (r *apiTokenRepository) Delete(id uint) error {
-	var token entity.APIToken
-	if err := r.db.First(&token, id).Error; err != nil {
-		return err
+	result := r.db.Delete(&entity.APIToken{}, id)
+	if result.Error != nil {
+		return result.Error
 	}
-
-	if err := r.db.Delete(&token).Error; err != nil {
-		return err
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
 	}
-
-	// Invalidate all caches for this token
-	r.invalidateAllCache()
-	
 	return nil
 }
+
+// DeleteByUserID revokes every token of the user
+func (r *apiTokenRepository) DeleteByUserID(userID uint) error {
+	return r.db.Where("user_id = ?", userID).Delete(&entity.APIToken{}).Error
+}
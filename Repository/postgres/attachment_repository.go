@@ -0,0 +1,52 @@
+package postgres
+
+import (
+	"a2sv.org/hub/Domain/entity"
+	"a2sv.org/hub/Domain/repository"
+	"gorm.io/gorm"
+)
+
+// attachmentRepository is not cached; attachments are listed with the
+// session or post they belong to
+type attachmentRepository struct {
+	db *gorm.DB
+}
+
+func NewAttachmentRepository(db *gorm.DB) repository.AttachmentRepository {
+	return &attachmentRepository{db: db}
+}
+
+func (r *attachmentRepository) Create(attachment *entity.Attachment) error {
+	return r.db.Create(attachment).Error
+}
+
+func (r *attachmentRepository) GetByID(id uint) (*entity.Attachment, error) {
+	var attachment entity.Attachment
+	if err := r.db.First(&attachment, id).Error; err != nil {
+		return nil, err
+	}
+	return &attachment, nil
+}
+
+func (r *attachmentRepository) ListBySession(sessionID uint) ([]*entity.Attachment, error) {
+	var attachments []*entity.Attachment
+	err := r.db.Where("session_id = ?", sessionID).Order("id").Find(&attachments).Error
+	return attachments, err
+}
+
+func (r *attachmentRepository) ListByPost(postID uint) ([]*entity.Attachment, error) {
+	var attachments []*entity.Attachment
+	err := r.db.Where("post_id = ?", postID).Order("id").Find(&attachments).Error
+	return attachments, err
+}
+
+func (r *attachmentRepository) Delete(id uint) error {
+	result := r.db.Delete(&entity.Attachment{}, id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
@@ -0,0 +1,64 @@
+package postgres
+
+import (
+	"errors"
+
+	"a2sv.org/hub/Domain/entity"
+	"a2sv.org/hub/Domain/repository"
+	"gorm.io/gorm"
+)
+
+// auditLogRepository is append-only and not cached
+type auditLogRepository struct {
+	db *gorm.DB
+}
+
+func NewAuditLogRepository(db *gorm.DB) repository.AuditLogRepository {
+	return &auditLogRepository{db: db}
+}
+
+func (r *auditLogRepository) Create(log *entity.AuditLog) error {
+	return r.db.Create(log).Error
+}
+
+func (r *auditLogRepository) List(filter repository.AuditLogFilter, offset, limit int) ([]*entity.AuditLog, int64, error) {
+	query := r.db.Model(&entity.AuditLog{})
+	if filter.ActorID != nil {
+		query = query.Where("actor_id = ?", *filter.ActorID)
+	}
+	if filter.EntityType != "" {
+		query = query.Where("entity_type = ?", filter.EntityType)
+	}
+	if filter.EntityID != "" {
+		query = query.Where("entity_id = ?", filter.EntityID)
+	}
+	if filter.Action != "" {
+		query = query.Where("action = ?", filter.Action)
+	}
+	if filter.From != nil {
+		query = query.Where("created_at >= ?", *filter.From)
+	}
+	if filter.To != nil {
+		query = query.Where("created_at < ?", *filter.To)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var logs []*entity.AuditLog
+	err := query.Order("created_at DESC, id DESC").Offset(offset).Limit(limit).Find(&logs).Error
+	return logs, total, err
+}
+
+func (r *auditLogRepository) Snapshot(table string, id string) (map[string]interface{}, error) {
+	row := map[string]interface{}{}
+	err := r.db.Table(table).Where("id = ?", id).Take(&row).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return row, nil
+}
@@ -0,0 +1,49 @@
+package postgres
+
+import (
+	"strings"
+
+	"a2sv.org/hub/Domain/entity"
+	"a2sv.org/hub/Domain/repository"
+	"a2sv.org/hub/infrastructure/encryption_services"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// encryptedColumnRepository works on the encrypted columns of any table. The
+// table and column names come from entity.EncryptedColumns, never from input.
+type encryptedColumnRepository struct {
+	db *gorm.DB
+}
+
+func NewEncryptedColumnRepository(db *gorm.DB) repository.EncryptedColumnRepository {
+	return &encryptedColumnRepository{db: db}
+}
+
+func (r *encryptedColumnRepository) ListStale(column entity.EncryptedColumn, keyID string, afterID uint, limit int) ([]*entity.EncryptedValue, error) {
+	col := clause.Column{Name: column.Column}
+	// Key IDs may contain _, which LIKE treats as a wildcard
+	current := strings.ReplaceAll(encryption_services.Prefix+keyID+":", "_", `\_`) + "%"
+
+	var values []*entity.EncryptedValue
+	err := r.db.Table(column.Table).
+		Select("id, ? AS value", col).
+		Where("id > ? AND ? <> '' AND ? NOT LIKE ?", afterID, col, col, current).
+		Order("id ASC").
+		Limit(limit).
+		Scan(&values).Error
+	return values, err
+}
+
+func (r *encryptedColumnRepository) Replace(column entity.EncryptedColumn, id uint, oldValue, newValue string) error {
+	result := r.db.Table(column.Table).
+		Where("id = ? AND ? = ?", id, clause.Column{Name: column.Column}, oldValue).
+		UpdateColumn(column.Column, newValue)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
@@ -0,0 +1,51 @@
+package postgres
+
+import (
+	"a2sv.org/hub/Domain/entity"
+	"a2sv.org/hub/Domain/repository"
+	"gorm.io/gorm"
+)
+
+// hoaRepository is not cached so that scope changes apply on the next request
+type hoaRepository struct {
+	db *gorm.DB
+}
+
+func NewHOARepository(db *gorm.DB) repository.HOARepository {
+	return &hoaRepository{db: db}
+}
+
+func (r *hoaRepository) Create(hoa *entity.HOA) error {
+	return r.db.Where("group_id = ? AND user_id = ?", hoa.GroupID, hoa.UserID).FirstOrCreate(hoa).Error
+}
+
+func (r *hoaRepository) Delete(groupID, userID uint) error {
+	result := r.db.Where("group_id = ? AND user_id = ?", groupID, userID).Delete(&entity.HOA{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (r *hoaRepository) GetByGroupID(groupID uint) ([]*entity.HOA, error) {
+	var hoas []*entity.HOA
+	err := r.db.Where("group_id = ?", groupID).Find(&hoas).Error
+	return hoas, err
+}
+
+func (r *hoaRepository) GetManagedGroupIDs(userID uint) ([]uint, error) {
+	var groupIDs []uint
+	err := r.db.Raw(`
+		SELECT group_id FROM hoas WHERE user_id = ?
+		UNION
+		SELECT id FROM groups WHERE hoa_id = ?
+		UNION
+		SELECT stg.group_id FROM super_to_groups stg
+		JOIN super_groups sg ON sg.id = stg.super_group_id
+		WHERE sg.lead_id = ?`, userID, userID, userID).
+		Scan(&groupIDs).Error
+	return groupIDs, err
+}
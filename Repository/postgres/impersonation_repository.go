@@ -0,0 +1,53 @@
+package postgres
+
+import (
+	"time"
+
+	"a2sv.org/hub/Domain/entity"
+	"a2sv.org/hub/Domain/repository"
+	"gorm.io/gorm"
+)
+
+// impersonationRepository is not cached so that ended impersonations show up at once
+type impersonationRepository struct {
+	db *gorm.DB
+}
+
+func NewImpersonationRepository(db *gorm.DB) repository.ImpersonationRepository {
+	return &impersonationRepository{db: db}
+}
+
+func (r *impersonationRepository) Create(impersonation *entity.Impersonation) error {
+	return r.db.Create(impersonation).Error
+}
+
+func (r *impersonationRepository) End(tokenID string, endedAt time.Time) error {
+	result := r.db.Model(&entity.Impersonation{}).
+		Where("token_id = ? AND ended_at IS NULL", tokenID).
+		Update("ended_at", endedAt)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (r *impersonationRepository) List(actorID, userID *uint, offset, limit int) ([]*entity.Impersonation, int64, error) {
+	query := r.db.Model(&entity.Impersonation{})
+	if actorID != nil {
+		query = query.Where("actor_id = ?", *actorID)
+	}
+	if userID != nil {
+		query = query.Where("user_id = ?", *userID)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var impersonations []*entity.Impersonation
+	err := query.Order("started_at DESC").Offset(offset).Limit(limit).Find(&impersonations).Error
+	return impersonations, total, err
+}
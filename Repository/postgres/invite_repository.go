@@ -0,0 +1,84 @@
+package postgres
+
+import (
+	"time"
+
+	"a2sv.org/hub/Domain/entity"
+	"a2sv.org/hub/Domain/repository"
+	"gorm.io/gorm"
+)
+
+// inviteRepository is not cached so that use counts and revocation are exact
+type inviteRepository struct {
+	db *gorm.DB
+}
+
+func NewInviteRepository(db *gorm.DB) repository.InviteRepository {
+	return &inviteRepository{db: db}
+}
+
+func (r *inviteRepository) Create(invite *entity.Invite) error {
+	return r.db.Create(invite).Error
+}
+
+func (r *inviteRepository) GetByID(id uint) (*entity.Invite, error) {
+	var invite entity.Invite
+	if err := r.db.First(&invite, id).Error; err != nil {
+		return nil, err
+	}
+	return &invite, nil
+}
+
+func (r *inviteRepository) GetByKey(keyHash string) (*entity.Invite, error) {
+	var invite entity.Invite
+	if err := r.db.Where("key = ?", keyHash).First(&invite).Error; err != nil {
+		return nil, err
+	}
+	return &invite, nil
+}
+
+func (r *inviteRepository) ListByGroupID(groupID uint) ([]*entity.Invite, error) {
+	var invites []*entity.Invite
+	err := r.db.Where("group_id = ?", groupID).Order("created_at DESC").Find(&invites).Error
+	return invites, err
+}
+
+func (r *inviteRepository) ConsumeUse(id uint, now time.Time) error {
+	result := r.db.Model(&entity.Invite{}).
+		Where("id = ? AND revoked_at IS NULL AND expires_at > ? AND (max_uses = 0 OR use_count < max_uses)", id, now).
+		Updates(map[string]interface{}{
+			"use_count":  gorm.Expr("use_count + 1"),
+			"used":       gorm.Expr("max_uses > 0 AND use_count + 1 >= max_uses"),
+			"updated_at": now,
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (r *inviteRepository) ReleaseUse(id uint) error {
+	return r.db.Model(&entity.Invite{}).
+		Where("id = ? AND use_count > 0", id).
+		Updates(map[string]interface{}{
+			"use_count":  gorm.Expr("use_count - 1"),
+			"used":       false,
+			"updated_at": time.Now(),
+		}).Error
+}
+
+func (r *inviteRepository) Revoke(id uint, revokedAt time.Time) error {
+	result := r.db.Model(&entity.Invite{}).
+		Where("id = ? AND revoked_at IS NULL", id).
+		Update("revoked_at", revokedAt)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
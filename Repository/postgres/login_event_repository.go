@@ -0,0 +1,49 @@
+package postgres
+
+import (
+	"a2sv.org/hub/Domain/entity"
+	"a2sv.org/hub/Domain/repository"
+	"gorm.io/gorm"
+)
+
+// loginEventRepository is append-only and not cached
+type loginEventRepository struct {
+	db *gorm.DB
+}
+
+func NewLoginEventRepository(db *gorm.DB) repository.LoginEventRepository {
+	return &loginEventRepository{db: db}
+}
+
+func (r *loginEventRepository) Create(event *entity.LoginEvent) error {
+	return r.db.Create(event).Error
+}
+
+func (r *loginEventRepository) ListByUserID(userID uint, offset, limit int) ([]*entity.LoginEvent, int64, error) {
+	var events []*entity.LoginEvent
+	var total int64
+	query := r.db.Model(&entity.LoginEvent{}).Where("user_id = ?", userID)
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	err := query.Order("created_at DESC").Offset(offset).Limit(limit).Find(&events).Error
+	return events, total, err
+}
+
+func (r *loginEventRepository) HasSuccessfulLogin(userID uint) (bool, error) {
+	return r.exists("user_id = ? AND success", userID)
+}
+
+func (r *loginEventRepository) HasSeenDevice(userID uint, device string) (bool, error) {
+	return r.exists("user_id = ? AND success AND device = ?", userID, device)
+}
+
+func (r *loginEventRepository) HasSeenCountry(userID uint, country string) (bool, error) {
+	return r.exists("user_id = ? AND success AND country = ?", userID, country)
+}
+
+func (r *loginEventRepository) exists(query string, args ...interface{}) (bool, error) {
+	var count int64
+	err := r.db.Model(&entity.LoginEvent{}).Where(query, args...).Limit(1).Count(&count).Error
+	return count > 0, err
+}
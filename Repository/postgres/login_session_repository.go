@@ -0,0 +1,62 @@
+package postgres
+
+import (
+	"time"
+
+	"a2sv.org/hub/Domain/entity"
+	"a2sv.org/hub/Domain/repository"
+	"gorm.io/gorm"
+)
+
+// loginSessionRepository is not cached so that revocation applies immediately
+type loginSessionRepository struct {
+	db *gorm.DB
+}
+
+func NewLoginSessionRepository(db *gorm.DB) repository.LoginSessionRepository {
+	return &loginSessionRepository{db: db}
+}
+
+func (r *loginSessionRepository) Create(session *entity.LoginSession) error {
+	return r.db.Create(session).Error
+}
+
+func (r *loginSessionRepository) GetByID(id uint) (*entity.LoginSession, error) {
+	var session entity.LoginSession
+	if err := r.db.First(&session, id).Error; err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+func (r *loginSessionRepository) ListActiveByUserID(userID uint, now time.Time) ([]*entity.LoginSession, error) {
+	var sessions []*entity.LoginSession
+	err := r.db.Where("user_id = ? AND revoked_at IS NULL AND expires_at > ?", userID, now).
+		Order("last_seen_at DESC").
+		Find(&sessions).Error
+	return sessions, err
+}
+
+func (r *loginSessionRepository) Extend(familyID string, expiresAt, seenAt time.Time) error {
+	return r.db.Model(&entity.LoginSession{}).
+		Where("family_id = ? AND revoked_at IS NULL", familyID).
+		Updates(map[string]interface{}{"expires_at": expiresAt, "last_seen_at": seenAt}).Error
+}
+
+func (r *loginSessionRepository) Touch(familyID string, seenAt, staleBefore time.Time) error {
+	return r.db.Model(&entity.LoginSession{}).
+		Where("family_id = ? AND last_seen_at < ?", familyID, staleBefore).
+		Update("last_seen_at", seenAt).Error
+}
+
+func (r *loginSessionRepository) RevokeByFamilyID(familyID string, revokedAt time.Time) error {
+	return r.db.Model(&entity.LoginSession{}).
+		Where("family_id = ? AND revoked_at IS NULL", familyID).
+		Update("revoked_at", revokedAt).Error
+}
+
+func (r *loginSessionRepository) RevokeAllByUserID(userID uint, revokedAt time.Time) error {
+	return r.db.Model(&entity.LoginSession{}).
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		Update("revoked_at", revokedAt).Error
+}
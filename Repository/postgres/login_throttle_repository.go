@@ -0,0 +1,58 @@
+package postgres
+
+import (
+	"time"
+
+	"a2sv.org/hub/Domain/entity"
+	"a2sv.org/hub/Domain/repository"
+	"gorm.io/gorm"
+)
+
+// loginThrottleRepository is not cached so that concurrent attempts see the same counters
+type loginThrottleRepository struct {
+	db *gorm.DB
+}
+
+func NewLoginThrottleRepository(db *gorm.DB) repository.LoginThrottleRepository {
+	return &loginThrottleRepository{db: db}
+}
+
+func (r *loginThrottleRepository) GetByKeys(keys ...string) ([]*entity.LoginThrottle, error) {
+	var throttles []*entity.LoginThrottle
+	err := r.db.Where("key IN ?", keys).Find(&throttles).Error
+	return throttles, err
+}
+
+func (r *loginThrottleRepository) RecordFailure(key string, at, resetBefore time.Time) (int, error) {
+	var failures int
+	err := r.db.Raw(`
+		INSERT INTO login_throttles (key, failures, last_failure_at, updated_at)
+		VALUES (?, 1, ?, ?)
+		ON CONFLICT (key) DO UPDATE SET
+			failures = CASE
+				WHEN login_throttles.last_failure_at < ?
+					AND (login_throttles.locked_until IS NULL OR login_throttles.locked_until < ?)
+				THEN 1
+				ELSE login_throttles.failures + 1
+			END,
+			last_failure_at = EXCLUDED.last_failure_at,
+			updated_at = EXCLUDED.updated_at
+		RETURNING failures`,
+		key, at, at, resetBefore, resetBefore,
+	).Scan(&failures).Error
+	return failures, err
+}
+
+func (r *loginThrottleRepository) Lock(key string, until time.Time) error {
+	return r.db.Model(&entity.LoginThrottle{}).
+		Where("key = ?", key).
+		Updates(map[string]interface{}{"locked_until": until, "updated_at": time.Now()}).Error
+}
+
+func (r *loginThrottleRepository) Reset(key string) error {
+	return r.db.Where("key = ?", key).Delete(&entity.LoginThrottle{}).Error
+}
+
+func (r *loginThrottleRepository) CreateEvent(event *entity.LockoutEvent) error {
+	return r.db.Create(event).Error
+}
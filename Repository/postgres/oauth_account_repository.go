@@ -0,0 +1,36 @@
+package postgres
+
+import (
+	"a2sv.org/hub/Domain/entity"
+	"a2sv.org/hub/Domain/repository"
+	"gorm.io/gorm"
+)
+
+// oauthAccountRepository is not cached since it decides who a provider account signs in as
+type oauthAccountRepository struct {
+	db *gorm.DB
+}
+
+func NewOAuthAccountRepository(db *gorm.DB) repository.OAuthAccountRepository {
+	return &oauthAccountRepository{db: db}
+}
+
+func (r *oauthAccountRepository) Create(account *entity.OAuthAccount) error {
+	return r.db.Create(account).Error
+}
+
+func (r *oauthAccountRepository) GetBySubject(provider, subject string) (*entity.OAuthAccount, error) {
+	var account entity.OAuthAccount
+	if err := r.db.Where("provider = ? AND subject = ?", provider, subject).First(&account).Error; err != nil {
+		return nil, err
+	}
+	return &account, nil
+}
+
+func (r *oauthAccountRepository) GetByUserID(userID uint, provider string) (*entity.OAuthAccount, error) {
+	var account entity.OAuthAccount
+	if err := r.db.Where("user_id = ? AND provider = ?", userID, provider).First(&account).Error; err != nil {
+		return nil, err
+	}
+	return &account, nil
+}
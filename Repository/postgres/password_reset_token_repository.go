@@ -0,0 +1,49 @@
+package postgres
+
+import (
+	"time"
+
+	"a2sv.org/hub/Domain/entity"
+	"a2sv.org/hub/Domain/repository"
+	"gorm.io/gorm"
+)
+
+// passwordResetTokenRepository is not cached so that a token can only be consumed once
+type passwordResetTokenRepository struct {
+	db *gorm.DB
+}
+
+func NewPasswordResetTokenRepository(db *gorm.DB) repository.PasswordResetTokenRepository {
+	return &passwordResetTokenRepository{db: db}
+}
+
+func (r *passwordResetTokenRepository) Create(token *entity.PasswordResetToken) error {
+	return r.db.Create(token).Error
+}
+
+func (r *passwordResetTokenRepository) GetByHash(tokenHash string) (*entity.PasswordResetToken, error) {
+	var token entity.PasswordResetToken
+	if err := r.db.Where("token_hash = ?", tokenHash).First(&token).Error; err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+func (r *passwordResetTokenRepository) MarkUsed(id uint, usedAt time.Time) error {
+	result := r.db.Model(&entity.PasswordResetToken{}).
+		Where("id = ? AND used_at IS NULL", id).
+		Update("used_at", usedAt)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (r *passwordResetTokenRepository) InvalidateByUserID(userID uint, usedAt time.Time) error {
+	return r.db.Model(&entity.PasswordResetToken{}).
+		Where("user_id = ? AND used_at IS NULL", userID).
+		Update("used_at", usedAt).Error
+}
@@ -0,0 +1,126 @@
+package postgres
+
+import (
+	"time"
+
+	"a2sv.org/hub/Domain/entity"
+	"a2sv.org/hub/Domain/repository"
+	"gorm.io/gorm"
+)
+
+// personalDataRepository is not cached; exports and erasures are rare and
+// must see the current rows
+type personalDataRepository struct {
+	db *gorm.DB
+}
+
+func NewPersonalDataRepository(db *gorm.DB) repository.PersonalDataRepository {
+	return &personalDataRepository{db: db}
+}
+
+func (r *personalDataRepository) ListSubmissions(userID uint) ([]*entity.Submission, error) {
+	var submissions []*entity.Submission
+	err := r.byUser(userID).Find(&submissions).Error
+	return submissions, err
+}
+
+func (r *personalDataRepository) ListAttendances(userID uint) ([]*entity.Attendance, error) {
+	var attendances []*entity.Attendance
+	err := r.byUser(userID).Find(&attendances).Error
+	return attendances, err
+}
+
+func (r *personalDataRepository) ListComments(userID uint) ([]*entity.Comment, error) {
+	var comments []*entity.Comment
+	err := r.byUser(userID).Find(&comments).Error
+	return comments, err
+}
+
+func (r *personalDataRepository) ListPosts(userID uint) ([]*entity.Post, error) {
+	var posts []*entity.Post
+	err := r.byUser(userID).Find(&posts).Error
+	return posts, err
+}
+
+func (r *personalDataRepository) ListVotes(userID uint) ([]*entity.Vote, error) {
+	var votes []*entity.Vote
+	err := r.byUser(userID).Find(&votes).Error
+	return votes, err
+}
+
+func (r *personalDataRepository) ListStipends(userID uint) ([]*entity.Stipend, error) {
+	var stipends []*entity.Stipend
+	err := r.byUser(userID).Find(&stipends).Error
+	return stipends, err
+}
+
+func (r *personalDataRepository) ListAssistantMessages(userID uint) ([]*entity.AssistantMessage, error) {
+	var messages []*entity.AssistantMessage
+	err := r.byUser(userID).Find(&messages).Error
+	return messages, err
+}
+
+func (r *personalDataRepository) ListRecentActions(userID uint) ([]*entity.RecentAction, error) {
+	var actions []*entity.RecentAction
+	err := r.byUser(userID).Find(&actions).Error
+	return actions, err
+}
+
+func (r *personalDataRepository) ListLoginEvents(userID uint) ([]*entity.LoginEvent, error) {
+	var events []*entity.LoginEvent
+	err := r.byUser(userID).Find(&events).Error
+	return events, err
+}
+
+func (r *personalDataRepository) ListInvites(email string) ([]*entity.Invite, error) {
+	var invites []*entity.Invite
+	err := r.db.Where("LOWER(email) = LOWER(?)", email).Order("id").Find(&invites).Error
+	return invites, err
+}
+
+func (r *personalDataRepository) byUser(userID uint) *gorm.DB {
+	return r.db.Where("user_id = ?", userID).Order("id")
+}
+
+func (r *personalDataRepository) EraseUser(user *entity.User) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		// Read the email before it is overwritten; failed logins that typed
+		// it were recorded without a user ID
+		var email string
+		if err := tx.Model(&entity.User{}).Where("id = ?", user.ID).Pluck("email", &email).Error; err != nil {
+			return err
+		}
+		if err := tx.Save(user).Error; err != nil {
+			return err
+		}
+		// Refresh tokens are kept: they hold only hashes, and their revocation
+		// is what rejects access tokens still in circulation
+		for _, model := range []interface{}{
+			&entity.APIToken{},
+			&entity.LoginSession{},
+			&entity.PasswordResetToken{},
+			&entity.TelegramLinkCode{},
+			&entity.OAuthAccount{},
+			&entity.GoogleOAuth{},
+			&entity.TwoFactor{},
+			&entity.RecoveryCode{},
+			&entity.TwoFactorChallenge{},
+			&entity.AssistantMessage{},
+		} {
+			if err := tx.Unscoped().Where("user_id = ?", user.ID).Delete(model).Error; err != nil {
+				return err
+			}
+		}
+		if err := tx.Where("user_id = ? OR email = ?", user.ID, email).Delete(&entity.LoginEvent{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&entity.Stipend{}).Where("user_id = ?", user.ID).
+			Updates(map[string]interface{}{"bank_name": "", "account_number": ""}).Error; err != nil {
+			return err
+		}
+		// An invite without an email may be redeemed by anyone, so the
+		// invites are revoked before their email is cleared
+		return tx.Model(&entity.Invite{}).Where("LOWER(email) = LOWER(?)", email).
+			Updates(map[string]interface{}{"email": nil, "revoked_at": gorm.Expr("COALESCE(revoked_at, ?)", time.Now())}).Error
+	})
+}
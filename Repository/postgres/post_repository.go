@@ -0,0 +1,23 @@
+package postgres
+
+import (
+	"a2sv.org/hub/Domain/entity"
+	"a2sv.org/hub/Domain/repository"
+	"gorm.io/gorm"
+)
+
+type postRepository struct {
+	db *gorm.DB
+}
+
+func NewPostRepository(db *gorm.DB) repository.PostRepository {
+	return &postRepository{db: db}
+}
+
+func (r *postRepository) GetPostByID(id uint) (*entity.Post, error) {
+	var post entity.Post
+	if err := r.db.First(&post, id).Error; err != nil {
+		return nil, err
+	}
+	return &post, nil
+}
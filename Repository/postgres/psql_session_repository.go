@@ -63,6 +63,13 @@ func (r *sessionRepository) GetSessionByStartTime(startTime string) ([]*entity.S
 	return sessions, err
 }
 
+// GetSessionGroupIDs returns the groups a session is scheduled for
+func (r *sessionRepository) GetSessionGroupIDs(sessionID uint) ([]uint, error) {
+	var groupIDs []uint
+	err := r.db.Model(&entity.GroupSession{}).Where("session_id = ?", sessionID).Pluck("group_id", &groupIDs).Error
+	return groupIDs, err
+}
+
 func (r *sessionRepository) GetSessionByName(name string) ([]*entity.Session, error) {
 	var sessions []*entity.Session
 	err := r.getCachedList("byname", &sessions, func() error {
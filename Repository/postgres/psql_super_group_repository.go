@@ -29,6 +29,12 @@ func (r *SuperGroupRepository) GetSuperGroupByID(id uint) (*entity.SuperGroup, e
 	return &superGroup, nil
 }
 
+func (r *SuperGroupRepository) GetGroupIDs(superGroupID uint) ([]uint, error) {
+	var groupIDs []uint
+	err := r.db.Model(&entity.SuperToGroup{}).Where("super_group_id = ?", superGroupID).Pluck("group_id", &groupIDs).Error
+	return groupIDs, err
+}
+
 func (r *SuperGroupRepository) GetSuperGroupByName(name string) (*entity.SuperGroup, error) {
 	var superGroups *entity.SuperGroup
 	result := r.db.Where("name = ?", name).Find(&superGroups)
@@ -1,10 +1,14 @@
 package postgres
 
 import (
+	"strings"
+	"time"
+
 	"a2sv.org/hub/Delivery/http/schemas"
 	"a2sv.org/hub/Domain/entity"
 	"a2sv.org/hub/Domain/repository"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // userRepository implements the repository.UserRepository interface
@@ -35,13 +39,78 @@ func (r *userRepository) CreateUser(user *entity.User) error {
 
 	return nil
 }
+
+// CreateUsers creates the users in a single transaction
+func (r *userRepository) CreateUsers(users []*entity.User) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		return tx.CreateInBatches(users, 500).Error
+	})
+}
+
+// ListUsers retrieves one page of the users matching the query along with
+// the number of matching users
 func (r *userRepository) ListUsers(query *schemas.UserListQuery) ([]*entity.User, int, error) {
+	db := r.db.Model(&entity.User{})
+	if query.Search != "" {
+		pattern := containsPattern(query.Search)
+		db = db.Where("(name ILIKE ? OR email ILIKE ?)", pattern, pattern)
+	}
+	if query.Name != "" {
+		db = db.Where("name ILIKE ?", containsPattern(query.Name))
+	}
+	if query.Email != "" {
+		db = db.Where("email ILIKE ?", containsPattern(query.Email))
+	}
+	if query.University != "" {
+		db = db.Where("university ILIKE ?", containsPattern(query.University))
+	}
+	if query.CountryID != nil {
+		db = db.Where("country_id = ?", *query.CountryID)
+	}
+	if query.RoleID != nil {
+		db = db.Where("role_id = ?", *query.RoleID)
+	}
+	if query.GroupID != nil {
+		db = db.Where("group_id = ?", *query.GroupID)
+	}
+	if query.Inactive != nil {
+		db = db.Where("inactive = ?", *query.Inactive)
+	}
+	if query.GraduationYear != nil {
+		from := time.Date(*query.GraduationYear, time.January, 1, 0, 0, 0, 0, time.UTC)
+		db = db.Where("expected_graduation_date >= ? AND expected_graduation_date < ?", from, from.AddDate(1, 0, 0))
+	}
+
+	var total int64
+	if err := db.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	column, desc := strings.CutPrefix(query.Sort, "-")
+	if !userSortColumns[column] {
+		column, desc = "created_at", true
+	}
 	var users []*entity.User
-	// err := r.getCachedList("list", &users, func() error {
-	err:=r.db.Order("created_at desc").Find(&users).Error
-	// }, query)
+	err := db.Order(clause.OrderByColumn{Column: clause.Column{Name: column}, Desc: desc}).
+		Order("id").
+		Offset((query.Page - 1) * query.PageSize).
+		Limit(query.PageSize).
+		Find(&users).Error
+	return users, int(total), err
+}
 
-	return users, len(users), err
+// userSortColumns are the columns users can be sorted by
+var userSortColumns = map[string]bool{
+	"name":                     true,
+	"email":                    true,
+	"university":               true,
+	"created_at":               true,
+	"expected_graduation_date": true,
+}
+
+// containsPattern returns a LIKE pattern matching values that contain s
+func containsPattern(s string) string {
+	return "%" + strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(s) + "%"
 }
 
 // GetUserByID retrieves a user by ID using the cache
@@ -133,7 +202,19 @@ func (r *userRepository) UpdateUser(user *entity.User) error {
 	return nil
 }
 
-// DeleteUser deletes a user and invalidates affected cache entries
+// SetInactive deactivates or reactivates a user
+func (r *userRepository) SetInactive(id uint, inactive bool) error {
+	result := r.db.Model(&entity.User{}).Where("id = ?", id).Update("inactive", inactive)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// DeleteUser soft deletes a user and invalidates affected cache entries
 func (r *userRepository) DeleteUser(id uint) error {
 	var user entity.User
 	if err := r.db.First(&user, id).Error; err != nil {
@@ -149,3 +230,131 @@ func (r *userRepository) DeleteUser(id uint) error {
 
 	return nil
 }
+
+// ListGraduates retrieves the active users holding one of the roles whose
+// expected graduation date is before the given time, with their group
+func (r *userRepository) ListGraduates(roleIDs []uint, before time.Time) ([]*entity.User, error) {
+	var users []*entity.User
+	err := r.db.Preload("Group").
+		Where("role_id IN ? AND inactive = ? AND expected_graduation_date < ?", roleIDs, false, before).
+		Order("expected_graduation_date").Order("id").
+		Find(&users).Error
+	return users, err
+}
+
+// MoveToAlumni gives the user the alumni role and leaves their group. The
+// update only applies while the user still holds one of fromRoleIDs, so a
+// user is moved once however many runs see them.
+func (r *userRepository) MoveToAlumni(id uint, fromRoleIDs []uint, alumniRoleID uint, at time.Time) error {
+	result := r.db.Model(&entity.User{}).
+		Where("id = ? AND role_id IN ?", id, fromRoleIDs).
+		Updates(map[string]interface{}{
+			"role_id":         alumniRoleID,
+			"former_group_id": gorm.Expr("group_id"),
+			"group_id":        nil,
+			"graduated_at":    at,
+			"updated_at":      at,
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// ListDeletedUsers retrieves one page of the deleted users, most recently
+// deleted first, along with the number of deleted users
+func (r *userRepository) ListDeletedUsers(page, pageSize int) ([]*entity.User, int, error) {
+	db := r.db.Unscoped().Model(&entity.User{}).Where("deleted_at IS NOT NULL")
+	var total int64
+	if err := db.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var users []*entity.User
+	err := db.Order("deleted_at DESC").Order("id").
+		Offset((page - 1) * pageSize).Limit(pageSize).
+		Find(&users).Error
+	return users, int(total), err
+}
+
+// GetDeletedUser retrieves a deleted user by ID
+func (r *userRepository) GetDeletedUser(id uint) (*entity.User, error) {
+	var user entity.User
+	if err := r.db.Unscoped().Where("id = ? AND deleted_at IS NOT NULL", id).First(&user).Error; err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// GetDeletedUserByEmail retrieves a deleted user by email. Emails stay unique
+// across deleted users, so they block new users with the same email.
+func (r *userRepository) GetDeletedUserByEmail(email string) (*entity.User, error) {
+	var user entity.User
+	if err := r.db.Unscoped().Where("email = ? AND deleted_at IS NOT NULL", email).First(&user).Error; err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// RestoreUser undoes the deletion of a user
+func (r *userRepository) RestoreUser(id uint) error {
+	result := r.db.Unscoped().Model(&entity.User{}).
+		Where("id = ? AND deleted_at IS NOT NULL", id).
+		Update("deleted_at", nil)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// HasHistory reports whether submissions, attendance or stipends reference the user
+func (r *userRepository) HasHistory(id uint) (bool, error) {
+	for _, query := range []*gorm.DB{
+		r.db.Model(&entity.Submission{}).Where("user_id = ?", id),
+		r.db.Model(&entity.Attendance{}).Where("user_id = ? OR head_id = ?", id, id),
+		r.db.Model(&entity.Stipend{}).Where("user_id = ?", id),
+	} {
+		var count int64
+		if err := query.Limit(1).Count(&count).Error; err != nil {
+			return false, err
+		}
+		if count > 0 {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// PurgeUser permanently deletes a deleted user and their sign-in data in one
+// transaction. Any other record still referencing the user makes it fail.
+func (r *userRepository) PurgeUser(id uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var user entity.User
+		if err := tx.Unscoped().Where("id = ? AND deleted_at IS NOT NULL", id).First(&user).Error; err != nil {
+			return err
+		}
+		for _, model := range []interface{}{
+			&entity.APIToken{},
+			&entity.RefreshToken{},
+			&entity.LoginSession{},
+			&entity.PasswordResetToken{},
+			&entity.TelegramLinkCode{},
+			&entity.OAuthAccount{},
+			&entity.GoogleOAuth{},
+			&entity.TwoFactor{},
+			&entity.RecoveryCode{},
+			&entity.TwoFactorChallenge{},
+			&entity.LoginEvent{},
+		} {
+			if err := tx.Unscoped().Where("user_id = ?", id).Delete(model).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Unscoped().Delete(&user).Error
+	})
+}
@@ -0,0 +1,86 @@
+package postgres
+
+import (
+	"time"
+
+	"a2sv.org/hub/Domain/entity"
+	"a2sv.org/hub/Domain/repository"
+	"gorm.io/gorm"
+)
+
+// refreshTokenRepository is not cached so that revocation applies immediately
+type refreshTokenRepository struct {
+	db *gorm.DB
+}
+
+func NewRefreshTokenRepository(db *gorm.DB) repository.RefreshTokenRepository {
+	return &refreshTokenRepository{db: db}
+}
+
+func (r *refreshTokenRepository) Create(token *entity.RefreshToken) error {
+	return r.db.Create(token).Error
+}
+
+func (r *refreshTokenRepository) GetByHash(tokenHash string) (*entity.RefreshToken, error) {
+	var token entity.RefreshToken
+	if err := r.db.Where("token_hash = ?", tokenHash).First(&token).Error; err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+// MarkUsed flags a token as rotated. It fails if another request rotated it first.
+func (r *refreshTokenRepository) MarkUsed(id uint, usedAt time.Time) error {
+	result := r.db.Model(&entity.RefreshToken{}).
+		Where("id = ? AND used_at IS NULL", id).
+		Update("used_at", usedAt)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (r *refreshTokenRepository) RevokeFamily(familyID string, revokedAt time.Time) error {
+	return r.db.Model(&entity.RefreshToken{}).
+		Where("family_id = ? AND revoked_at IS NULL", familyID).
+		Update("revoked_at", revokedAt).Error
+}
+
+func (r *refreshTokenRepository) RevokeAllByUserID(userID uint, revokedAt time.Time) error {
+	return r.db.Model(&entity.RefreshToken{}).
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		Update("revoked_at", revokedAt).Error
+}
+
+func (r *refreshTokenRepository) IsFamilyRevoked(familyID string) (bool, error) {
+	var count int64
+	err := r.db.Model(&entity.RefreshToken{}).
+		Where("family_id = ? AND revoked_at IS NOT NULL", familyID).
+		Count(&count).Error
+	return count > 0, err
+}
+
+type revokedTokenRepository struct {
+	db *gorm.DB
+}
+
+func NewRevokedTokenRepository(db *gorm.DB) repository.RevokedTokenRepository {
+	return &revokedTokenRepository{db: db}
+}
+
+func (r *revokedTokenRepository) Create(token *entity.RevokedToken) error {
+	return r.db.Where("jti = ?", token.JTI).FirstOrCreate(token).Error
+}
+
+func (r *revokedTokenRepository) IsRevoked(jti string) (bool, error) {
+	var count int64
+	err := r.db.Model(&entity.RevokedToken{}).Where("jti = ?", jti).Count(&count).Error
+	return count > 0, err
+}
+
+func (r *revokedTokenRepository) DeleteExpired(now time.Time) error {
+	return r.db.Where("expires_at < ?", now).Delete(&entity.RevokedToken{}).Error
+}
@@ -0,0 +1,45 @@
+package postgres
+
+import (
+	"time"
+
+	"a2sv.org/hub/Domain/entity"
+	"a2sv.org/hub/Domain/repository"
+	"gorm.io/gorm"
+)
+
+// registrationJobRepository is not cached so that progress is always current
+type registrationJobRepository struct {
+	db *gorm.DB
+}
+
+func NewRegistrationJobRepository(db *gorm.DB) repository.RegistrationJobRepository {
+	return &registrationJobRepository{db: db}
+}
+
+func (r *registrationJobRepository) Create(job *entity.RegistrationJob) error {
+	return r.db.Create(job).Error
+}
+
+func (r *registrationJobRepository) GetByID(id uint) (*entity.RegistrationJob, error) {
+	var job entity.RegistrationJob
+	if err := r.db.First(&job, id).Error; err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+func (r *registrationJobRepository) Update(job *entity.RegistrationJob) error {
+	return r.db.Save(job).Error
+}
+
+func (r *registrationJobRepository) FailUnfinished(message string, finishedAt time.Time) (int64, error) {
+	result := r.db.Model(&entity.RegistrationJob{}).
+		Where("status IN ?", []string{entity.RegistrationJobPending, entity.RegistrationJobRunning}).
+		Updates(map[string]interface{}{
+			"status":      entity.RegistrationJobFailed,
+			"error":       message,
+			"finished_at": finishedAt,
+		})
+	return result.RowsAffected, result.Error
+}
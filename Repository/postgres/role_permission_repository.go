@@ -0,0 +1,60 @@
+package postgres
+
+import (
+	"a2sv.org/hub/Domain/entity"
+	"a2sv.org/hub/Domain/repository"
+	"gorm.io/gorm"
+)
+
+// rolePermissionRepository is not cached so that permission changes apply on the next request
+type rolePermissionRepository struct {
+	db *gorm.DB
+}
+
+func NewRolePermissionRepository(db *gorm.DB) repository.RolePermissionRepository {
+	return &rolePermissionRepository{db: db}
+}
+
+func (r *rolePermissionRepository) AddPermission(permission *entity.RolePermission) error {
+	return r.db.Where("role_id = ? AND permission = ?", permission.RoleID, permission.Permission).
+		FirstOrCreate(permission).Error
+}
+
+func (r *rolePermissionRepository) RemovePermission(roleID uint, permission string) error {
+	result := r.db.Where("role_id = ? AND permission = ?", roleID, permission).Delete(&entity.RolePermission{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (r *rolePermissionRepository) ListPermissionsByRoleID(roleID uint) ([]*entity.RolePermission, error) {
+	var permissions []*entity.RolePermission
+	err := r.db.Where("role_id = ?", roleID).Order("permission").Find(&permissions).Error
+	return permissions, err
+}
+
+func (r *rolePermissionRepository) ReplacePermissions(roleID uint, permissions []string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("role_id = ?", roleID).Delete(&entity.RolePermission{}).Error; err != nil {
+			return err
+		}
+		for _, p := range permissions {
+			if err := tx.Create(&entity.RolePermission{RoleID: roleID, Permission: p}).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (r *rolePermissionRepository) HasPermission(roleID uint, permission string) (bool, error) {
+	var count int64
+	err := r.db.Model(&entity.RolePermission{}).
+		Where("role_id = ? AND permission IN ?", roleID, []string{permission, entity.PermissionAll}).
+		Count(&count).Error
+	return count > 0, err
+}
@@ -0,0 +1,40 @@
+package postgres
+
+import (
+	"time"
+
+	"a2sv.org/hub/Domain/entity"
+	"a2sv.org/hub/Domain/repository"
+	"gorm.io/gorm"
+)
+
+// signingKeyRepository is not cached; the usecase keeps the keys in memory
+type signingKeyRepository struct {
+	db *gorm.DB
+}
+
+func NewSigningKeyRepository(db *gorm.DB) repository.SigningKeyRepository {
+	return &signingKeyRepository{db: db}
+}
+
+func (r *signingKeyRepository) Create(key *entity.SigningKey) error {
+	return r.db.Create(key).Error
+}
+
+func (r *signingKeyRepository) ListUnretired(now time.Time) ([]*entity.SigningKey, error) {
+	var keys []*entity.SigningKey
+	err := r.db.Where("retires_at IS NULL OR retires_at > ?", now).
+		Order("activates_at ASC").
+		Find(&keys).Error
+	return keys, err
+}
+
+func (r *signingKeyRepository) RetireAll(retiresAt time.Time) error {
+	return r.db.Model(&entity.SigningKey{}).
+		Where("retires_at IS NULL").
+		Update("retires_at", retiresAt).Error
+}
+
+func (r *signingKeyRepository) DeleteRetired(now time.Time) error {
+	return r.db.Where("retires_at <= ?", now).Delete(&entity.SigningKey{}).Error
+}
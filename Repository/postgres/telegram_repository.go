@@ -0,0 +1,88 @@
+package postgres
+
+import (
+	"time"
+
+	"a2sv.org/hub/Domain/entity"
+	"a2sv.org/hub/Domain/repository"
+	"gorm.io/gorm"
+)
+
+// telegramRepository is not cached since linked accounts sign users in
+type telegramRepository struct {
+	db *gorm.DB
+}
+
+func NewTelegramRepository(db *gorm.DB) repository.TelegramRepository {
+	return &telegramRepository{db: db}
+}
+
+func (r *telegramRepository) CreateLinkCode(code *entity.TelegramLinkCode) error {
+	return r.db.Create(code).Error
+}
+
+func (r *telegramRepository) UseLinkCode(codeHash string, usedAt time.Time) (*entity.TelegramLinkCode, error) {
+	var code entity.TelegramLinkCode
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&entity.TelegramLinkCode{}).
+			Where("code_hash = ? AND used_at IS NULL AND expires_at > ?", codeHash, usedAt).
+			Update("used_at", usedAt)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return tx.Where("code_hash = ?", codeHash).First(&code).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &code, nil
+}
+
+func (r *telegramRepository) GetLinkedUser(telegramUID string) (*entity.User, error) {
+	var user entity.User
+	err := r.db.Where("telegram_uid = ? AND telegram_linked_at IS NOT NULL", telegramUID).First(&user).Error
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+func (r *telegramRepository) Link(userID uint, telegramUID string, username *string, linkedAt time.Time) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if username != nil {
+			err := tx.Model(&entity.User{}).
+				Where("telegram_username = ? AND id <> ?", *username, userID).
+				Update("telegram_username", nil).Error
+			if err != nil {
+				return err
+			}
+		}
+		result := tx.Model(&entity.User{}).
+			Where("id = ?", userID).
+			Updates(map[string]interface{}{
+				"telegram_uid":       telegramUID,
+				"telegram_username":  username,
+				"telegram_linked_at": linkedAt,
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return nil
+	})
+}
+
+func (r *telegramRepository) Unlink(userID uint) error {
+	return r.db.Model(&entity.User{}).
+		Where("id = ?", userID).
+		Updates(map[string]interface{}{
+			"telegram_uid":       "",
+			"telegram_username":  nil,
+			"telegram_linked_at": nil,
+		}).Error
+}
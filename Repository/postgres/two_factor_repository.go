@@ -0,0 +1,126 @@
+package postgres
+
+import (
+	"time"
+
+	"a2sv.org/hub/Domain/entity"
+	"a2sv.org/hub/Domain/repository"
+	"gorm.io/gorm"
+)
+
+// twoFactorRepository is not cached so that codes and challenges can only be used once
+type twoFactorRepository struct {
+	db *gorm.DB
+}
+
+func NewTwoFactorRepository(db *gorm.DB) repository.TwoFactorRepository {
+	return &twoFactorRepository{db: db}
+}
+
+func (r *twoFactorRepository) GetByUserID(userID uint) (*entity.TwoFactor, error) {
+	var twoFactor entity.TwoFactor
+	if err := r.db.Where("user_id = ?", userID).First(&twoFactor).Error; err != nil {
+		return nil, err
+	}
+	return &twoFactor, nil
+}
+
+func (r *twoFactorRepository) Save(twoFactor *entity.TwoFactor) error {
+	return r.db.Save(twoFactor).Error
+}
+
+func (r *twoFactorRepository) Enable(userID uint, enabledAt time.Time) error {
+	return r.db.Model(&entity.TwoFactor{}).
+		Where("user_id = ?", userID).
+		Update("enabled_at", enabledAt).Error
+}
+
+func (r *twoFactorRepository) Delete(userID uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userID).Delete(&entity.RecoveryCode{}).Error; err != nil {
+			return err
+		}
+		return tx.Where("user_id = ?", userID).Delete(&entity.TwoFactor{}).Error
+	})
+}
+
+func (r *twoFactorRepository) UseStep(userID uint, step int64) error {
+	result := r.db.Model(&entity.TwoFactor{}).
+		Where("user_id = ? AND last_used_step < ?", userID, step).
+		Update("last_used_step", step)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (r *twoFactorRepository) ReplaceRecoveryCodes(userID uint, codeHashes []string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userID).Delete(&entity.RecoveryCode{}).Error; err != nil {
+			return err
+		}
+		now := time.Now()
+		codes := make([]*entity.RecoveryCode, 0, len(codeHashes))
+		for _, hash := range codeHashes {
+			codes = append(codes, &entity.RecoveryCode{UserID: userID, CodeHash: hash, CreatedAt: now})
+		}
+		return tx.Create(&codes).Error
+	})
+}
+
+func (r *twoFactorRepository) UseRecoveryCode(userID uint, codeHash string, usedAt time.Time) error {
+	result := r.db.Model(&entity.RecoveryCode{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", userID, codeHash).
+		Update("used_at", usedAt)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (r *twoFactorRepository) CountRecoveryCodes(userID uint) (int64, error) {
+	var count int64
+	err := r.db.Model(&entity.RecoveryCode{}).
+		Where("user_id = ? AND used_at IS NULL", userID).
+		Count(&count).Error
+	return count, err
+}
+
+func (r *twoFactorRepository) CreateChallenge(challenge *entity.TwoFactorChallenge) error {
+	return r.db.Create(challenge).Error
+}
+
+func (r *twoFactorRepository) GetChallengeByHash(tokenHash string) (*entity.TwoFactorChallenge, error) {
+	var challenge entity.TwoFactorChallenge
+	if err := r.db.Where("token_hash = ?", tokenHash).First(&challenge).Error; err != nil {
+		return nil, err
+	}
+	return &challenge, nil
+}
+
+func (r *twoFactorRepository) AddChallengeAttempt(id uint) (int, error) {
+	var attempts int
+	err := r.db.Raw(
+		"UPDATE two_factor_challenges SET attempts = attempts + 1 WHERE id = ? RETURNING attempts", id,
+	).Scan(&attempts).Error
+	return attempts, err
+}
+
+func (r *twoFactorRepository) MarkChallengeUsed(id uint, usedAt time.Time) error {
+	result := r.db.Model(&entity.TwoFactorChallenge{}).
+		Where("id = ? AND used_at IS NULL", id).
+		Update("used_at", usedAt)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
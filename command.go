@@ -0,0 +1,64 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"a2sv.org/hub/infrastructure/encryption_services"
+	"a2sv.org/hub/usecases"
+)
+
+const commandUsage = `Usage: main <command> [flags]
+
+Commands:
+  rotate-keys              Create a new JWT signing key and retire the current ones
+  list-keys                List the JWT signing keys that still verify tokens
+  generate-encryption-key  Print a new master key for ENCRYPTION_KEYS
+  reencrypt-secrets        Re-encrypt stored secrets with the ENCRYPTION_KEY_ID master key`
+
+// runCommand runs a maintenance command given on the command line
+func runCommand(args []string, signingKeys *usecases.SigningKeyUseCase, encryption *usecases.EncryptionUseCase) error {
+	switch args[0] {
+	case "rotate-keys":
+		flags := flag.NewFlagSet("rotate-keys", flag.ExitOnError)
+		algorithm := flags.String("algorithm", os.Getenv("JWT_SIGNING_ALGORITHM"), "RS256 or EdDSA (default "+usecases.DefaultSigningAlgorithm+")")
+		activateIn := flags.Duration("activate-in", time.Hour, "how long the key is only published before it signs, so other services can fetch it")
+		flags.Parse(args[1:])
+
+		key, err := signingKeys.Rotate(*algorithm, *activateIn)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Created %s key %s, signing from %s\n", key.Algorithm, key.KID, key.ActivatesAt.Format(time.RFC3339))
+		return nil
+	case "list-keys":
+		keys, err := signingKeys.List()
+		if err != nil {
+			return err
+		}
+		for _, key := range keys {
+			retires := "-"
+			if key.RetiresAt != nil {
+				retires = key.RetiresAt.Format(time.RFC3339)
+			}
+			fmt.Printf("%s\t%s\tactivates %s\tretires %s\n", key.KID, key.Algorithm, key.ActivatesAt.Format(time.RFC3339), retires)
+		}
+		return nil
+	case "generate-encryption-key":
+		key, err := encryption_services.GenerateKey()
+		if err != nil {
+			return err
+		}
+		fmt.Println(key)
+		return nil
+	case "reencrypt-secrets":
+		counts, err := encryption.Reencrypt()
+		for column, count := range counts {
+			fmt.Printf("%s\t%d re-encrypted\n", column, count)
+		}
+		return err
+	}
+	return fmt.Errorf("unknown command %q\n\n%s", args[0], commandUsage)
+}
@@ -56,6 +56,7 @@ func NewDBConnection() (*gorm.DB, error) {
 	err = db.AutoMigrate(
 		&entity.User{},
 		&entity.Role{},
+		&entity.RolePermission{},
 		&entity.Country{},
 		&entity.Group{},
 		&entity.Track{},
@@ -71,22 +72,79 @@ func NewDBConnection() (*gorm.DB, error) {
 		&entity.PostToTag{},
 		&entity.PostTag{},
 		&entity.Invite{},
+		&entity.RegistrationJob{},
+		&entity.Attachment{},
 		&entity.SuperToGroup{},
 		&entity.DailyProblem{},
 		&entity.Exercise{},
 		&entity.ProblemTrack{},
 		&entity.GoogleOAuth{},
+		&entity.OAuthAccount{},
+		&entity.TelegramLinkCode{},
 		&entity.GroupSession{},
 		&entity.HOA{},
 		&entity.Fund{},
 		&entity.Stipend{},
 		&entity.RecentAction{},
 		&entity.APIToken{},
+		&entity.RefreshToken{},
+		&entity.RevokedToken{},
+		&entity.LoginSession{},
+		&entity.SigningKey{},
+		&entity.PasswordResetToken{},
+		&entity.LoginEvent{},
+		&entity.LoginThrottle{},
+		&entity.LockoutEvent{},
+		&entity.TwoFactor{},
+		&entity.RecoveryCode{},
+		&entity.TwoFactorChallenge{},
+		&entity.AuditLog{},
+		&entity.Impersonation{},
 	)
 	if err != nil {
 		return nil, err
 	}
 
+	// Google sign-in links used to live on google_o_auths
+	if db.Migrator().HasColumn(&entity.GoogleOAuth{}, "subject") {
+		err := db.Exec(`INSERT INTO o_auth_accounts (user_id, provider, subject, email, created_at)
+			SELECT user_id, 'google', subject, COALESCE(email, ''), created_at FROM google_o_auths
+			WHERE subject IS NOT NULL
+			ON CONFLICT DO NOTHING`).Error
+		if err != nil {
+			return nil, err
+		}
+		for _, column := range []string{"subject", "email"} {
+			if err := db.Migrator().DropColumn(&entity.GoogleOAuth{}, column); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	// A Telegram account is linked to at most one user. Unverified IDs typed
+	// in before linking existed may repeat, so only linked ones are unique.
+	err = db.Exec(`CREATE UNIQUE INDEX IF NOT EXISTS idx_users_telegram_uid_linked
+		ON users (telegram_uid) WHERE telegram_linked_at IS NOT NULL`).Error
+	if err != nil {
+		return nil, err
+	}
+
+	// The audit log is append-only, even for direct SQL
+	for _, statement := range []string{
+		`CREATE OR REPLACE FUNCTION audit_logs_append_only() RETURNS trigger AS $$
+		BEGIN
+			RAISE EXCEPTION 'audit_logs is append-only';
+		END;
+		$$ LANGUAGE plpgsql`,
+		`DROP TRIGGER IF EXISTS audit_logs_append_only ON audit_logs`,
+		`CREATE TRIGGER audit_logs_append_only BEFORE UPDATE OR DELETE ON audit_logs
+			FOR EACH ROW EXECUTE FUNCTION audit_logs_append_only()`,
+	} {
+		if err := db.Exec(statement).Error; err != nil {
+			return nil, err
+		}
+	}
+
 	log.Println("Successfully connected to the database")
 	return db, nil
 }
@@ -1,17 +1,11 @@
 package email_services
 
 import (
-	"encoding/json"
 	"fmt"
 	"log"
-	"net"
-	"net/http"
 	"net/smtp"
 	"os"
-	"strings"
-	"time"
 
-	"a2sv.org/hub/Domain/entity"
 	"github.com/gin-gonic/gin"
 )
 
@@ -154,61 +148,13 @@ func SendEmail(toEmail, title, body, link string) error {
 }
 
 // SendLoginAlertEmail sends a login alert email containing the client's IP, location, and device info.
-func SendLoginAlertEmail(c *gin.Context, alertRecipient, link string) error {
-	ip := GetClientIP(c)
-	device := GetDevice(c)
-
-	// Handle loopback addresses.
-	location := "Localhost"
-	if ip != "::1" && ip != "127.0.0.1" {
-		var err error
-		location, err = GetGeoLocation(ip)
-		if err != nil {
-			log.Printf("❌ Failed to get location for IP %s: %v", ip, err)
-			location = "Unknown location"
-		}
-	}
-
+func SendLoginAlertEmail(alertRecipient, ip, device, location, link string) error {
 	title := "Login Attempt Alert"
 	body := fmt.Sprintf("A login attempt was made from location: %s, IP address: %s, using device: %s.", location, ip, device)
-	// No specific action link is needed for this alert.
 	return SendEmail(alertRecipient, title, body, link)
 }
 
-// GetClientIP extracts the client's IP address from the gin context.
-func GetClientIP(c *gin.Context) string {
-	// Check for X-Forwarded-For header in case of a proxy.
-	ip := c.GetHeader("X-Forwarded-For")
-	if ip != "" {
-		ips := strings.Split(ip, ",")
-		return strings.TrimSpace(ips[0])
-	}
-	// Fall back to RemoteAddr.
-	ip, _, err := net.SplitHostPort(c.Request.RemoteAddr)
-	if err != nil {
-		return c.Request.RemoteAddr
-	}
-	return ip
-}
-
 // GetDevice retrieves the User-Agent string from the gin context.
 func GetDevice(c *gin.Context) string {
 	return c.GetHeader("User-Agent")
 }
-
-// GetGeoLocation returns a formatted location string based on the client's IP address.
-func GetGeoLocation(ip string) (string, error) {
-	url := fmt.Sprintf("https://ipinfo.io/%s/json", ip)
-	client := http.Client{Timeout: 5 * time.Second}
-	resp, err := client.Get(url)
-	if err != nil {
-		return "", err
-	}
-	defer resp.Body.Close()
-
-	var info entity.GeoInfo
-	if err := json.NewDecoder(resp.Body).Decode(&info); err != nil {
-		return "", err
-	}
-	return fmt.Sprintf("%s, %s, %s", info.City, info.Region, info.Country), nil
-}
\ No newline at end of file
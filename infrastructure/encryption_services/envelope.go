@@ -0,0 +1,219 @@
+// Package encryption_services encrypts secrets stored in the database with
+// envelope encryption: every value gets its own AES-256-GCM data key, which
+// is itself encrypted with a versioned master key.
+package encryption_services
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// Prefix marks an encrypted value. The full form is
+// enc:<key ID>:<encrypted data key>:<encrypted value>, both base64url.
+const Prefix = "enc:"
+
+// keySize is the size of master and data keys (AES-256)
+const keySize = 32
+
+var (
+	ErrNotConfigured = errors.New("no encryption master key is configured")
+	ErrUnknownKey    = errors.New("value is encrypted with an unknown master key")
+	ErrMalformed     = errors.New("malformed encrypted value")
+	ErrTampered      = errors.New("encrypted value failed authentication")
+)
+
+var keyIDPattern = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// Envelope encrypts values with the current master key and decrypts values
+// encrypted with any known one
+type Envelope struct {
+	keys      map[string]cipher.AEAD
+	currentID string
+}
+
+// NewEnvelope returns an envelope over the master keys by ID; new values are
+// encrypted with the key currentID
+func NewEnvelope(keys map[string][]byte, currentID string) (*Envelope, error) {
+	envelope := &Envelope{keys: make(map[string]cipher.AEAD, len(keys)), currentID: currentID}
+	for id, key := range keys {
+		if !keyIDPattern.MatchString(id) {
+			return nil, fmt.Errorf("invalid master key ID %q", id)
+		}
+		aead, err := newAEAD(key)
+		if err != nil {
+			return nil, fmt.Errorf("master key %s: %w", id, err)
+		}
+		envelope.keys[id] = aead
+	}
+	if currentID != "" && envelope.keys[currentID] == nil {
+		return nil, fmt.Errorf("current master key %q is not among the configured keys", currentID)
+	}
+	return envelope, nil
+}
+
+// EnvelopeFromEnv reads the master keys from ENCRYPTION_KEYS, a comma
+// separated list of id:base64key, and the ID of the key new values are
+// encrypted with from ENCRYPTION_KEY_ID (default: the first key listed).
+// Without keys the envelope is returned unconfigured.
+func EnvelopeFromEnv() (*Envelope, error) {
+	keys := map[string][]byte{}
+	first := ""
+	for _, entry := range strings.Split(os.Getenv("ENCRYPTION_KEYS"), ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		id, encoded, ok := strings.Cut(entry, ":")
+		if !ok {
+			return nil, fmt.Errorf("ENCRYPTION_KEYS entry %q is not id:base64key", entry)
+		}
+		key, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return nil, fmt.Errorf("master key %s is not valid base64: %w", id, err)
+		}
+		keys[id] = key
+		if first == "" {
+			first = id
+		}
+	}
+	currentID := os.Getenv("ENCRYPTION_KEY_ID")
+	if currentID == "" {
+		currentID = first
+	}
+	return NewEnvelope(keys, currentID)
+}
+
+// GenerateKey returns a random master key, base64 encoded for ENCRYPTION_KEYS
+func GenerateKey() (string, error) {
+	key := make([]byte, keySize)
+	if _, err := rand.Read(key); err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(key), nil
+}
+
+// CurrentKeyID returns the ID of the master key new values are encrypted with
+func (e *Envelope) CurrentKeyID() string {
+	return e.currentID
+}
+
+// Encrypt encrypts the plaintext with a new data key. The context, such as
+// the column the value is stored in, must be given again to decrypt it, so a
+// value copied into another column does not decrypt.
+func (e *Envelope) Encrypt(plaintext, context string) (string, error) {
+	master := e.keys[e.currentID]
+	if master == nil {
+		return "", ErrNotConfigured
+	}
+
+	dataKey := make([]byte, keySize)
+	if _, err := rand.Read(dataKey); err != nil {
+		return "", err
+	}
+	wrappedKey, err := seal(master, dataKey, []byte(e.currentID))
+	if err != nil {
+		return "", err
+	}
+	data, err := newAEAD(dataKey)
+	if err != nil {
+		return "", err
+	}
+	sealed, err := seal(data, []byte(plaintext), []byte(context))
+	if err != nil {
+		return "", err
+	}
+	return Prefix + e.currentID + ":" +
+		base64.RawURLEncoding.EncodeToString(wrappedKey) + ":" +
+		base64.RawURLEncoding.EncodeToString(sealed), nil
+}
+
+// Decrypt decrypts a value returned by Encrypt with the same context
+func (e *Envelope) Decrypt(ciphertext, context string) (string, error) {
+	keyID, wrappedKey, sealed, err := parse(ciphertext)
+	if err != nil {
+		return "", err
+	}
+	master := e.keys[keyID]
+	if master == nil {
+		return "", ErrUnknownKey
+	}
+	dataKey, err := open(master, wrappedKey, []byte(keyID))
+	if err != nil {
+		return "", err
+	}
+	data, err := newAEAD(dataKey)
+	if err != nil {
+		return "", err
+	}
+	plaintext, err := open(data, sealed, []byte(context))
+	if err != nil {
+		return "", err
+	}
+	return string(plaintext), nil
+}
+
+// KeyID returns the ID of the master key a value was encrypted with
+func KeyID(ciphertext string) (string, error) {
+	keyID, _, _, err := parse(ciphertext)
+	return keyID, err
+}
+
+// IsEncrypted reports whether a stored value is in the encrypted form
+func IsEncrypted(value string) bool {
+	return strings.HasPrefix(value, Prefix)
+}
+
+func parse(ciphertext string) (keyID string, wrappedKey, sealed []byte, err error) {
+	parts := strings.Split(strings.TrimPrefix(ciphertext, Prefix), ":")
+	if !IsEncrypted(ciphertext) || len(parts) != 3 {
+		return "", nil, nil, ErrMalformed
+	}
+	wrappedKey, err = base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return "", nil, nil, ErrMalformed
+	}
+	sealed, err = base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return "", nil, nil, ErrMalformed
+	}
+	return parts[0], wrappedKey, sealed, nil
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	if len(key) != keySize {
+		return nil, fmt.Errorf("key must be %d bytes, got %d", keySize, len(key))
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// seal encrypts with a random nonce, which is prepended to the result
+func seal(aead cipher.AEAD, plaintext, additionalData []byte) ([]byte, error) {
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return aead.Seal(nonce, nonce, plaintext, additionalData), nil
+}
+
+func open(aead cipher.AEAD, sealed, additionalData []byte) ([]byte, error) {
+	if len(sealed) < aead.NonceSize() {
+		return nil, ErrMalformed
+	}
+	nonce, ciphertext := sealed[:aead.NonceSize()], sealed[aead.NonceSize():]
+	plaintext, err := aead.Open(nil, nonce, ciphertext, additionalData)
+	if err != nil {
+		return nil, ErrTampered
+	}
+	return plaintext, nil
+}
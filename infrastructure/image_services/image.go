@@ -0,0 +1,107 @@
+// Package image_services resizes uploaded images
+package image_services
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/color"
+	"image/draw"
+	_ "image/gif" // Registers the GIF decoder
+	"image/jpeg"
+	_ "image/png" // Registers the PNG decoder
+)
+
+// maxPixels bounds the size of an image that is decoded, so a small file
+// cannot expand to gigabytes of pixels
+const maxPixels = 40_000_000
+
+// jpegQuality is the quality resized images are encoded with
+const jpegQuality = 85
+
+var (
+	ErrUnsupported = errors.New("unsupported image, upload a JPEG, PNG or GIF")
+	ErrTooLarge    = errors.New("the image has too many pixels")
+)
+
+// FitJPEG decodes a JPEG, PNG or GIF image, scales it down to fit in a
+// maxSide by maxSide square and returns it as a JPEG. Smaller images keep
+// their size. Transparent areas become white, and metadata such as EXIF is
+// dropped.
+func FitJPEG(data []byte, maxSide int) ([]byte, error) {
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrUnsupported
+	}
+	if config.Width <= 0 || config.Height <= 0 {
+		return nil, ErrUnsupported
+	}
+	if config.Width*config.Height > maxPixels {
+		return nil, ErrTooLarge
+	}
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrUnsupported
+	}
+
+	// Flatten onto white, which also converts any color model to RGBA
+	bounds := src.Bounds()
+	flat := image.NewRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	draw.Draw(flat, flat.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+	draw.Draw(flat, flat.Bounds(), src, bounds.Min, draw.Over)
+
+	width, height := fit(bounds.Dx(), bounds.Dy(), maxSide)
+	resized := flat
+	if width != bounds.Dx() || height != bounds.Dy() {
+		resized = scaleDown(flat, width, height)
+	}
+
+	var out bytes.Buffer
+	if err := jpeg.Encode(&out, resized, &jpeg.Options{Quality: jpegQuality}); err != nil {
+		return nil, err
+	}
+	return out.Bytes(), nil
+}
+
+// fit returns the size of a width by height image scaled down, keeping its
+// aspect ratio, to fit in a maxSide square
+func fit(width, height, maxSide int) (int, int) {
+	if width <= maxSide && height <= maxSide {
+		return width, height
+	}
+	if width >= height {
+		return maxSide, max(1, height*maxSide/width)
+	}
+	return max(1, width*maxSide/height), maxSide
+}
+
+// scaleDown resizes with a box filter: every destination pixel is the
+// average of the source pixels it covers
+func scaleDown(src *image.RGBA, width, height int) *image.RGBA {
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	srcWidth, srcHeight := src.Bounds().Dx(), src.Bounds().Dy()
+	for y := 0; y < height; y++ {
+		y0, y1 := y*srcHeight/height, max((y+1)*srcHeight/height, y*srcHeight/height+1)
+		for x := 0; x < width; x++ {
+			x0, x1 := x*srcWidth/width, max((x+1)*srcWidth/width, x*srcWidth/width+1)
+			var r, g, b, a, n uint64
+			for sy := y0; sy < y1; sy++ {
+				row := src.Pix[sy*src.Stride:]
+				for sx := x0; sx < x1; sx++ {
+					pixel := row[sx*4 : sx*4+4]
+					r += uint64(pixel[0])
+					g += uint64(pixel[1])
+					b += uint64(pixel[2])
+					a += uint64(pixel[3])
+					n++
+				}
+			}
+			offset := y*dst.Stride + x*4
+			dst.Pix[offset] = uint8(r / n)
+			dst.Pix[offset+1] = uint8(g / n)
+			dst.Pix[offset+2] = uint8(b / n)
+			dst.Pix[offset+3] = uint8(a / n)
+		}
+	}
+	return dst
+}
@@ -0,0 +1,50 @@
+package ip_services
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+
+	"a2sv.org/hub/Domain/entity"
+)
+
+// GeoLocator resolves an IP address to a location
+type GeoLocator interface {
+	Locate(ip string) (*entity.GeoInfo, error)
+}
+
+// IPInfoLocator looks IP addresses up on ipinfo.io
+type IPInfoLocator struct {
+	client http.Client
+}
+
+// NewIPInfoLocator creates a GeoLocator backed by ipinfo.io
+func NewIPInfoLocator() *IPInfoLocator {
+	return &IPInfoLocator{client: http.Client{Timeout: 5 * time.Second}}
+}
+
+// Locate returns the location of a public IP. Loopback and private addresses
+// resolve to an empty location without a lookup.
+func (l *IPInfoLocator) Locate(ip string) (*entity.GeoInfo, error) {
+	parsed := net.ParseIP(ip)
+	if parsed == nil || parsed.IsLoopback() || parsed.IsPrivate() || parsed.IsUnspecified() {
+		return &entity.GeoInfo{}, nil
+	}
+
+	resp, err := l.client.Get(fmt.Sprintf("https://ipinfo.io/%s/json", ip))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("ipinfo.io returned %d", resp.StatusCode)
+	}
+
+	var info entity.GeoInfo
+	if err := json.NewDecoder(resp.Body).Decode(&info); err != nil {
+		return nil, err
+	}
+	return &info, nil
+}
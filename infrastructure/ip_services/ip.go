@@ -1,21 +1,29 @@
 package ip_services
 
 import (
-	"net"
+	"os"
 	"strings"
 
 	"github.com/gin-gonic/gin"
 )
 
+// GetClientIP returns the IP of the client. Forwarding headers are only
+// believed when the request comes from a trusted proxy, see
+// TrustedProxiesFromEnv; otherwise a client could pick the IP that login
+// throttling, rate limiting and the audit log key on.
 func GetClientIP(c *gin.Context) string {
-	ip := c.GetHeader("X-Forwarded-For")
-	if ip != "" {
-		return strings.Split(ip, ",")[0]
-	}
-	ip = c.GetHeader("X-Real-IP")
-	if ip != "" {
-		return ip
+	return c.ClientIP()
+}
+
+// TrustedProxiesFromEnv returns the IPs and CIDR ranges listed in
+// TRUSTED_PROXIES, separated by commas. Empty means no proxy is trusted and
+// the client IP is the address of the connection.
+func TrustedProxiesFromEnv() []string {
+	var proxies []string
+	for _, proxy := range strings.Split(os.Getenv("TRUSTED_PROXIES"), ",") {
+		if proxy = strings.TrimSpace(proxy); proxy != "" {
+			proxies = append(proxies, proxy)
+		}
 	}
-	ip, _, _ = net.SplitHostPort(c.Request.RemoteAddr)
-	return ip
+	return proxies
 }
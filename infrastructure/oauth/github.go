@@ -0,0 +1,62 @@
+package oauth
+
+import (
+	"errors"
+	"strconv"
+)
+
+// githubProvider reads the account from the GitHub REST API, which has no
+// OpenID Connect userinfo endpoint
+type githubProvider struct {
+	Config
+}
+
+type githubUser struct {
+	ID    int64  `json:"id"`
+	Login string `json:"login"`
+	Name  string `json:"name"`
+}
+
+type githubEmail struct {
+	Email    string `json:"email"`
+	Primary  bool   `json:"primary"`
+	Verified bool   `json:"verified"`
+}
+
+func (p githubProvider) Name() string {
+	return p.Config.Name
+}
+
+// GetUserInfo reads the account from UserInfoURL and its primary verified
+// email from UserInfoURL + "/emails", since the public profile email is
+// neither required nor verified
+func (p githubProvider) GetUserInfo(accessToken string) (*UserInfo, error) {
+	var user githubUser
+	if err := getJSON(p.UserInfoURL, accessToken, &user); err != nil {
+		return nil, err
+	}
+	if user.ID == 0 {
+		return nil, errors.New("GitHub user response has no ID")
+	}
+	var emails []githubEmail
+	if err := getJSON(p.UserInfoURL+"/emails", accessToken, &emails); err != nil {
+		return nil, err
+	}
+
+	info := &UserInfo{
+		Subject:     strconv.FormatInt(user.ID, 10),
+		Name:        user.Name,
+		GitHubLogin: user.Login,
+	}
+	if info.Name == "" {
+		info.Name = user.Login
+	}
+	for _, e := range emails {
+		if e.Primary && e.Verified {
+			info.Email = e.Email
+			info.EmailVerified = true
+			break
+		}
+	}
+	return info, nil
+}
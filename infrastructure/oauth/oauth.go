@@ -1,67 +1,328 @@
 package oauth
 
 import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
 	"encoding/json"
-	"io/ioutil"
+	"errors"
+	"fmt"
+	"io"
+	"log"
 	"net/http"
 	"net/url"
 	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"a2sv.org/hub/infrastructure/token_services"
+)
+
+// Provider types. An oidc provider serves an OpenID Connect userinfo
+// endpoint; a github provider serves the GitHub REST API.
+const (
+	ProviderTypeOIDC   = "oidc"
+	ProviderTypeGitHub = "github"
 )
 
+// Provider is an OAuth2 identity provider users sign in with
+type Provider interface {
+	Name() string
+	// AuthCodeURL returns the provider URL the browser is sent to
+	AuthCodeURL(state, codeChallenge string) string
+	// ExchangeCodeForToken exchanges the code and PKCE verifier for an OAuth token
+	ExchangeCodeForToken(code, codeVerifier string) (*Token, error)
+	// GetUserInfo retrieves the signed-in account using the access token
+	GetUserInfo(accessToken string) (*UserInfo, error)
+}
+
+// Config describes an OAuth2 client and the provider endpoints it talks to
+type Config struct {
+	Name         string // Used in the /api/auth/:provider routes
+	Type         string // ProviderTypeOIDC or ProviderTypeGitHub
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+	AuthURL      string
+	TokenURL     string
+	UserInfoURL  string
+	Scopes       []string
+}
+
+// presets fill in the endpoints of well-known providers
+var presets = map[string]Config{
+	"google": {
+		Type:        ProviderTypeOIDC,
+		AuthURL:     "https://accounts.google.com/o/oauth2/auth",
+		TokenURL:    "https://oauth2.googleapis.com/token",
+		UserInfoURL: "https://openidconnect.googleapis.com/v1/userinfo",
+		Scopes:      []string{"openid", "email", "profile"},
+	},
+	"github": {
+		Type:        ProviderTypeGitHub,
+		AuthURL:     "https://github.com/login/oauth/authorize",
+		TokenURL:    "https://github.com/login/oauth/access_token",
+		UserInfoURL: "https://api.github.com/user",
+		Scopes:      []string{"read:user", "user:email"},
+	},
+}
+
+var providerNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9-]*$`)
+
 // Token represents the OAuth token response.
 type Token struct {
-	AccessToken string `json:"access_token"`
-	ExpiresIn   int    `json:"expires_in"`
-	TokenType   string `json:"token_type"`
-	Scope       string `json:"scope"`
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token,omitempty"`
+	ExpiresIn    int    `json:"expires_in"`
+	TokenType    string `json:"token_type"`
+	Scope        string `json:"scope"`
+	IDToken      string `json:"id_token,omitempty"`
+}
+
+// UserInfo is the profile of the signed-in account
+type UserInfo struct {
+	Subject       string `json:"sub"`
+	Email         string `json:"email"`
+	EmailVerified bool   `json:"email_verified"`
+	Name          string `json:"name"`
+	GitHubLogin   string `json:"-"` // GitHub username, set by github providers
+}
+
+// ProvidersFromEnv reads the providers listed in OAUTH_PROVIDERS (comma
+// separated, default: google and github when their client ID is set).
+// Provider NAME is configured by NAME_OAUTH_CLIENT_ID, NAME_OAUTH_CLIENT_SECRET
+// and NAME_OAUTH_REDIRECT_URL. Providers without a preset also need
+// NAME_OAUTH_TYPE, NAME_OAUTH_AUTH_URL, NAME_OAUTH_TOKEN_URL,
+// NAME_OAUTH_USERINFO_URL and NAME_OAUTH_SCOPES, which override the preset
+// otherwise, e.g. to point the flow at a local fake for testing.
+func ProvidersFromEnv() ([]Provider, error) {
+	names := splitList(os.Getenv("OAUTH_PROVIDERS"))
+	if len(names) == 0 {
+		for _, name := range []string{"google", "github"} {
+			if os.Getenv(envPrefix(name)+"CLIENT_ID") != "" {
+				names = append(names, name)
+			}
+		}
+	}
+
+	providers := make([]Provider, 0, len(names))
+	for _, name := range names {
+		name = strings.ToLower(name)
+		if !providerNamePattern.MatchString(name) {
+			return nil, fmt.Errorf("invalid OAuth provider name %q", name)
+		}
+		cfg := presets[name]
+		prefix := envPrefix(name)
+		cfg.Name = name
+		cfg.ClientID = os.Getenv(prefix + "CLIENT_ID")
+		cfg.ClientSecret = os.Getenv(prefix + "CLIENT_SECRET")
+		cfg.RedirectURL = os.Getenv(prefix + "REDIRECT_URL")
+		cfg.Type = envOrDefault(prefix+"TYPE", cfg.Type)
+		cfg.AuthURL = envOrDefault(prefix+"AUTH_URL", cfg.AuthURL)
+		cfg.TokenURL = envOrDefault(prefix+"TOKEN_URL", cfg.TokenURL)
+		cfg.UserInfoURL = envOrDefault(prefix+"USERINFO_URL", cfg.UserInfoURL)
+		if scopes := splitList(os.Getenv(prefix + "SCOPES")); len(scopes) > 0 {
+			cfg.Scopes = scopes
+		}
+
+		provider, err := NewProvider(cfg)
+		if err != nil {
+			return nil, err
+		}
+		providers = append(providers, provider)
+	}
+	return providers, nil
+}
+
+// NewProvider returns the provider implementation for the config's type
+func NewProvider(cfg Config) (Provider, error) {
+	if cfg.ClientID == "" || cfg.RedirectURL == "" || cfg.AuthURL == "" || cfg.TokenURL == "" || cfg.UserInfoURL == "" {
+		return nil, fmt.Errorf("OAuth provider %s needs a client ID, a redirect URL and its endpoints", cfg.Name)
+	}
+	switch cfg.Type {
+	case ProviderTypeOIDC:
+		return oidcProvider{cfg}, nil
+	case ProviderTypeGitHub:
+		return githubProvider{cfg}, nil
+	}
+	return nil, fmt.Errorf("OAuth provider %s has unknown type %q", cfg.Name, cfg.Type)
 }
 
-// ExchangeCodeForToken exchanges the provided code for an OAuth token.
-func ExchangeCodeForToken(code string) (*Token, error) {
+// AuthCodeURL returns the provider URL the browser is sent to
+func (cfg Config) AuthCodeURL(state, codeChallenge string) string {
+	params := url.Values{}
+	params.Set("client_id", cfg.ClientID)
+	params.Set("redirect_uri", cfg.RedirectURL)
+	params.Set("response_type", "code")
+	params.Set("scope", strings.Join(cfg.Scopes, " "))
+	params.Set("state", state)
+	params.Set("code_challenge", codeChallenge)
+	params.Set("code_challenge_method", "S256")
+	return cfg.AuthURL + "?" + params.Encode()
+}
+
+// ExchangeCodeForToken exchanges the provided code and PKCE verifier for an OAuth token.
+func (cfg Config) ExchangeCodeForToken(code, codeVerifier string) (*Token, error) {
 	data := url.Values{}
 	data.Set("code", code)
-	data.Set("client_id", os.Getenv("GOOGLE_OAUTH_CLIENT_ID"))
-	data.Set("client_secret", os.Getenv("GOOGLE_OAUTH_CLIENT_SECRET"))
-	data.Set("redirect_uri", os.Getenv("GOOGLE_OAUTH_REDIRECT_URL"))
+	data.Set("client_id", cfg.ClientID)
+	data.Set("client_secret", cfg.ClientSecret)
+	data.Set("redirect_uri", cfg.RedirectURL)
 	data.Set("grant_type", "authorization_code")
+	data.Set("code_verifier", codeVerifier)
 
-	resp, err := http.PostForm("https://oauth2.googleapis.com/token", data)
+	req, err := http.NewRequest(http.MethodPost, cfg.TokenURL, strings.NewReader(data.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	// GitHub answers form-encoded unless asked for JSON
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := http.DefaultClient.Do(req)
 	if err != nil {
 		return nil, err
 	}
 	defer resp.Body.Close()
 
-	body, err := ioutil.ReadAll(resp.Body)
+	body, err := io.ReadAll(resp.Body)
 	if err != nil {
 		return nil, err
 	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("token endpoint returned %d: %s", resp.StatusCode, body)
+	}
 	var token Token
 	err = json.Unmarshal(body, &token)
 	if err != nil {
 		return nil, err
 	}
+	if token.AccessToken == "" {
+		return nil, errors.New("token endpoint returned no access token")
+	}
 	return &token, nil
 }
 
-// GetUserInfo retrieves the user's information from Google using the access token.
-func GetUserInfo(accessToken string) (map[string]interface{}, error) {
-	req, err := http.NewRequest("GET", "https://www.googleapis.com/oauth2/v2/userinfo", nil)
-	if err != nil {
+// oidcProvider reads the account from an OpenID Connect userinfo endpoint
+type oidcProvider struct {
+	Config
+}
+
+func (p oidcProvider) Name() string {
+	return p.Config.Name
+}
+
+// GetUserInfo retrieves the user's profile using the access token.
+func (p oidcProvider) GetUserInfo(accessToken string) (*UserInfo, error) {
+	var userInfo UserInfo
+	if err := getJSON(p.UserInfoURL, accessToken, &userInfo); err != nil {
 		return nil, err
 	}
+	if userInfo.Subject == "" {
+		return nil, errors.New("userinfo response has no subject")
+	}
+	return &userInfo, nil
+}
+
+// getJSON fetches a URL with the access token and decodes the JSON response into v
+func getJSON(endpoint, accessToken string, v interface{}) error {
+	req, err := http.NewRequest(http.MethodGet, endpoint, nil)
+	if err != nil {
+		return err
+	}
 	req.Header.Set("Authorization", "Bearer "+accessToken)
+	req.Header.Set("Accept", "application/json")
 
-	client := &http.Client{}
-	resp, err := client.Do(req)
+	resp, err := http.DefaultClient.Do(req)
 	if err != nil {
-		return nil, err
+		return err
 	}
 	defer resp.Body.Close()
 
-	var userInfo map[string]interface{}
-	err = json.NewDecoder(resp.Body).Decode(&userInfo)
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s returned %d", endpoint, resp.StatusCode)
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
+
+// NewCodeVerifier returns a random PKCE code verifier (RFC 7636)
+func NewCodeVerifier() (string, error) {
+	return token_services.GenerateConfirmationToken(64)
+}
+
+// CodeChallenge returns the S256 challenge of a PKCE code verifier
+func CodeChallenge(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+// StateSecretFromEnv returns the key states are signed with:
+// OAUTH_STATE_SECRET, falling back to JWT_SECRET. Without either, a random
+// key is used, so sign-ins in progress fail after a restart.
+func StateSecretFromEnv() (string, error) {
+	for _, name := range []string{"OAUTH_STATE_SECRET", "JWT_SECRET"} {
+		if secret := os.Getenv(name); secret != "" {
+			return secret, nil
+		}
+	}
+	log.Println("Warning: neither OAUTH_STATE_SECRET nor JWT_SECRET is set, signing OAuth states with a random key")
+	return token_services.GenerateConfirmationToken(32)
+}
+
+// SignState returns a state parameter carrying the nonce and its expiry, signed with the secret
+func SignState(secret, nonce string, expiresAt time.Time) string {
+	payload := nonce + "." + strconv.FormatInt(expiresAt.Unix(), 10)
+	return base64.RawURLEncoding.EncodeToString([]byte(payload)) + "." + stateSignature(secret, payload)
+}
+
+// VerifyState checks the signature and expiry of a state parameter and returns its nonce
+func VerifyState(secret, state string) (string, error) {
+	encoded, signature, ok := strings.Cut(state, ".")
+	if !ok {
+		return "", errors.New("malformed state")
+	}
+	raw, err := base64.RawURLEncoding.DecodeString(encoded)
 	if err != nil {
-		return nil, err
+		return "", errors.New("malformed state")
+	}
+	payload := string(raw)
+	if !hmac.Equal([]byte(signature), []byte(stateSignature(secret, payload))) {
+		return "", errors.New("invalid state signature")
+	}
+	nonce, expiry, ok := strings.Cut(payload, ".")
+	if !ok {
+		return "", errors.New("malformed state")
+	}
+	expiresAt, err := strconv.ParseInt(expiry, 10, 64)
+	if err != nil || time.Now().Unix() > expiresAt {
+		return "", errors.New("state expired")
+	}
+	return nonce, nil
+}
+
+func stateSignature(secret, payload string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte("oauth-state:" + payload))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// envPrefix returns the prefix of a provider's environment variables
+func envPrefix(name string) string {
+	return strings.ToUpper(strings.ReplaceAll(name, "-", "_")) + "_OAUTH_"
+}
+
+// splitList splits a comma or space separated list
+func splitList(value string) []string {
+	return strings.FieldsFunc(value, func(r rune) bool {
+		return r == ',' || r == ' '
+	})
+}
+
+func envOrDefault(key, fallback string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
 	}
-	return userInfo, nil
+	return fallback
 }
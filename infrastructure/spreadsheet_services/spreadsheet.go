@@ -0,0 +1,246 @@
+// Package spreadsheet_services reads the rows of uploaded CSV and XLSX files
+package spreadsheet_services
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/csv"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// maxPartSize bounds how much of a single XLSX part is decompressed
+const maxPartSize = 32 << 20
+
+var (
+	ErrUnsupportedFormat = errors.New("unsupported file format, upload a .csv or .xlsx file")
+	ErrMalformed         = errors.New("malformed spreadsheet")
+)
+
+// Read returns the rows of a CSV or XLSX file, picked by the file name's
+// extension. For XLSX only the first worksheet is read.
+func Read(filename string, data []byte) ([][]string, error) {
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".csv":
+		return ReadCSV(bytes.NewReader(data))
+	case ".xlsx":
+		return ReadXLSX(bytes.NewReader(data), int64(len(data)))
+	}
+	return nil, ErrUnsupportedFormat
+}
+
+// ReadCSV returns the records of a comma separated file. Rows may have
+// different numbers of fields and a leading byte order mark is ignored.
+func ReadCSV(r io.Reader) ([][]string, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	rows, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrMalformed, err)
+	}
+	if len(rows) > 0 && len(rows[0]) > 0 {
+		rows[0][0] = strings.TrimPrefix(rows[0][0], "\ufeff")
+	}
+	return rows, nil
+}
+
+// ReadXLSX returns the cell values of the first worksheet of a workbook.
+// Missing cells are returned as empty strings.
+func ReadXLSX(r io.ReaderAt, size int64) ([][]string, error) {
+	archive, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrMalformed, err)
+	}
+	files := make(map[string]*zip.File, len(archive.File))
+	for _, file := range archive.File {
+		files[file.Name] = file
+	}
+
+	sheetPath, err := firstSheetPath(files)
+	if err != nil {
+		return nil, err
+	}
+	var sharedStrings []string
+	if file := files["xl/sharedStrings.xml"]; file != nil {
+		if sharedStrings, err = readSharedStrings(file); err != nil {
+			return nil, err
+		}
+	}
+	file := files[sheetPath]
+	if file == nil {
+		return nil, fmt.Errorf("%w: missing %s", ErrMalformed, sheetPath)
+	}
+	return readSheet(file, sharedStrings)
+}
+
+type workbookXML struct {
+	Sheets []struct {
+		RelationshipID string `xml:"http://schemas.openxmlformats.org/officeDocument/2006/relationships id,attr"`
+	} `xml:"sheets>sheet"`
+}
+
+type relationshipsXML struct {
+	Relationships []struct {
+		ID     string `xml:"Id,attr"`
+		Target string `xml:"Target,attr"`
+	} `xml:"Relationship"`
+}
+
+type sharedStringsXML struct {
+	Items []struct {
+		Text string `xml:"t"`
+		Runs []struct {
+			Text string `xml:"t"`
+		} `xml:"r"`
+	} `xml:"si"`
+}
+
+type worksheetXML struct {
+	Rows []struct {
+		Cells []struct {
+			Ref    string `xml:"r,attr"`
+			Type   string `xml:"t,attr"`
+			Value  string `xml:"v"`
+			Inline struct {
+				Text string `xml:"t"`
+				Runs []struct {
+					Text string `xml:"t"`
+				} `xml:"r"`
+			} `xml:"is"`
+		} `xml:"c"`
+	} `xml:"sheetData>row"`
+}
+
+// firstSheetPath resolves the part of the first worksheet through the
+// workbook relationships, falling back to the conventional name
+func firstSheetPath(files map[string]*zip.File) (string, error) {
+	const fallback = "xl/worksheets/sheet1.xml"
+	workbookFile, relsFile := files["xl/workbook.xml"], files["xl/_rels/workbook.xml.rels"]
+	if workbookFile == nil {
+		return "", fmt.Errorf("%w: not an XLSX workbook", ErrMalformed)
+	}
+	if relsFile == nil {
+		return fallback, nil
+	}
+
+	var workbook workbookXML
+	if err := decodePart(workbookFile, &workbook); err != nil {
+		return "", err
+	}
+	var rels relationshipsXML
+	if err := decodePart(relsFile, &rels); err != nil {
+		return "", err
+	}
+	if len(workbook.Sheets) == 0 {
+		return "", fmt.Errorf("%w: the workbook has no worksheets", ErrMalformed)
+	}
+	for _, rel := range rels.Relationships {
+		if rel.ID != workbook.Sheets[0].RelationshipID {
+			continue
+		}
+		if strings.HasPrefix(rel.Target, "/") {
+			return strings.TrimPrefix(rel.Target, "/"), nil
+		}
+		return path.Join("xl", rel.Target), nil
+	}
+	return fallback, nil
+}
+
+func readSharedStrings(file *zip.File) ([]string, error) {
+	var table sharedStringsXML
+	if err := decodePart(file, &table); err != nil {
+		return nil, err
+	}
+	values := make([]string, len(table.Items))
+	for i, item := range table.Items {
+		values[i] = item.Text
+		for _, run := range item.Runs {
+			values[i] += run.Text
+		}
+	}
+	return values, nil
+}
+
+func readSheet(file *zip.File, sharedStrings []string) ([][]string, error) {
+	var sheet worksheetXML
+	if err := decodePart(file, &sheet); err != nil {
+		return nil, err
+	}
+	rows := make([][]string, 0, len(sheet.Rows))
+	for _, row := range sheet.Rows {
+		var values []string
+		for i, cell := range row.Cells {
+			column := i
+			if cell.Ref != "" {
+				var err error
+				if column, err = columnIndex(cell.Ref); err != nil {
+					return nil, err
+				}
+			}
+			value := cell.Value
+			switch cell.Type {
+			case "s":
+				index, err := strconv.Atoi(cell.Value)
+				if err != nil || index < 0 || index >= len(sharedStrings) {
+					return nil, fmt.Errorf("%w: cell %s refers to a missing shared string", ErrMalformed, cell.Ref)
+				}
+				value = sharedStrings[index]
+			case "inlineStr":
+				value = cell.Inline.Text
+				for _, run := range cell.Inline.Runs {
+					value += run.Text
+				}
+			}
+			for len(values) <= column {
+				values = append(values, "")
+			}
+			values[column] = value
+		}
+		rows = append(rows, values)
+	}
+	return rows, nil
+}
+
+// columnIndex returns the zero-based column of a cell reference such as "C7"
+func columnIndex(ref string) (int, error) {
+	column := 0
+	letters := 0
+	for _, r := range ref {
+		if r < 'A' || r > 'Z' {
+			break
+		}
+		column = column*26 + int(r-'A') + 1
+		letters++
+	}
+	// XLSX has at most 16384 columns (XFD)
+	if letters == 0 || letters > 3 || column > 16384 {
+		return 0, fmt.Errorf("%w: invalid cell reference %q", ErrMalformed, ref)
+	}
+	return column - 1, nil
+}
+
+func decodePart(file *zip.File, v interface{}) error {
+	reader, err := file.Open()
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrMalformed, err)
+	}
+	defer reader.Close()
+	data, err := io.ReadAll(io.LimitReader(reader, maxPartSize+1))
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrMalformed, err)
+	}
+	if len(data) > maxPartSize {
+		return fmt.Errorf("%w: %s is too large", ErrMalformed, file.Name)
+	}
+	if err := xml.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("%w: %s: %v", ErrMalformed, file.Name, err)
+	}
+	return nil
+}
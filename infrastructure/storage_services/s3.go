@@ -0,0 +1,303 @@
+package storage_services
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	s3Algorithm      = "AWS4-HMAC-SHA256"
+	s3TimeFormat     = "20060102T150405Z"
+	s3UnsignedBody   = "UNSIGNED-PAYLOAD"
+	s3RequestTimeout = time.Minute
+	// s3MaxURLExpiry is the longest validity S3 accepts for a signed URL
+	s3MaxURLExpiry = 7 * 24 * time.Hour
+)
+
+// S3Storage stores files in a bucket of S3 or of an S3-compatible service
+// such as MinIO. Requests are signed with AWS Signature Version 4.
+type S3Storage struct {
+	endpoint  *url.URL
+	region    string
+	bucket    string
+	accessKey string
+	secretKey string
+	pathStyle bool
+	client    *http.Client
+	now       func() time.Time
+}
+
+// S3Config configures an S3Storage
+type S3Config struct {
+	// Endpoint is the service URL, e.g. http://localhost:9000 for a local
+	// MinIO. Empty means AWS S3 in the region.
+	Endpoint  string
+	Region    string
+	Bucket    string
+	AccessKey string
+	SecretKey string
+	// PathStyle addresses the bucket as <endpoint>/<bucket>/<key> instead of
+	// <bucket>.<endpoint>/<key>, as MinIO expects
+	PathStyle bool
+}
+
+// NewS3Storage returns a storage in the configured bucket
+func NewS3Storage(config S3Config) (*S3Storage, error) {
+	if config.Bucket == "" || config.AccessKey == "" || config.SecretKey == "" {
+		return nil, fmt.Errorf("S3 storage needs a bucket, an access key and a secret key")
+	}
+	if config.Region == "" {
+		config.Region = "us-east-1"
+	}
+	if config.Endpoint == "" {
+		config.Endpoint = "https://s3." + config.Region + ".amazonaws.com"
+	}
+	endpoint, err := url.Parse(config.Endpoint)
+	if err != nil || endpoint.Host == "" || (endpoint.Scheme != "http" && endpoint.Scheme != "https") {
+		return nil, fmt.Errorf("invalid S3 endpoint %q", config.Endpoint)
+	}
+	return &S3Storage{
+		endpoint:  endpoint,
+		region:    config.Region,
+		bucket:    config.Bucket,
+		accessKey: config.AccessKey,
+		secretKey: config.SecretKey,
+		pathStyle: config.PathStyle,
+		client:    &http.Client{Timeout: s3RequestTimeout},
+		now:       time.Now,
+	}, nil
+}
+
+// S3FromEnv configures the storage from S3_ENDPOINT, S3_REGION (default
+// us-east-1), S3_BUCKET, S3_ACCESS_KEY_ID and S3_SECRET_ACCESS_KEY. Buckets
+// are addressed path-style when S3_ENDPOINT is set, unless
+// S3_PATH_STYLE=false.
+func S3FromEnv() (*S3Storage, error) {
+	config := S3Config{
+		Endpoint:  os.Getenv("S3_ENDPOINT"),
+		Region:    os.Getenv("S3_REGION"),
+		Bucket:    os.Getenv("S3_BUCKET"),
+		AccessKey: os.Getenv("S3_ACCESS_KEY_ID"),
+		SecretKey: os.Getenv("S3_SECRET_ACCESS_KEY"),
+		PathStyle: os.Getenv("S3_ENDPOINT") != "",
+	}
+	if value := os.Getenv("S3_PATH_STYLE"); value != "" {
+		pathStyle, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, fmt.Errorf("S3_PATH_STYLE %q is not a boolean", value)
+		}
+		config.PathStyle = pathStyle
+	}
+	return NewS3Storage(config)
+}
+
+func (s *S3Storage) Put(key string, r io.Reader, size int64, contentType string) error {
+	body, err := io.ReadAll(io.LimitReader(r, size))
+	if err != nil {
+		return err
+	}
+	payloadHash := sha256.Sum256(body)
+	request, err := s.newRequest(http.MethodPut, key, bytes.NewReader(body), hex.EncodeToString(payloadHash[:]))
+	if err != nil {
+		return err
+	}
+	request.ContentLength = int64(len(body))
+	request.Header.Set("Content-Type", contentType)
+	response, err := s.do(request)
+	if err != nil {
+		return err
+	}
+	response.Body.Close()
+	return nil
+}
+
+func (s *S3Storage) Open(key string) (io.ReadCloser, error) {
+	request, err := s.newRequest(http.MethodGet, key, nil, s3UnsignedBody)
+	if err != nil {
+		return nil, err
+	}
+	response, err := s.do(request)
+	if err != nil {
+		return nil, err
+	}
+	return response.Body, nil
+}
+
+func (s *S3Storage) Delete(key string) error {
+	request, err := s.newRequest(http.MethodDelete, key, nil, s3UnsignedBody)
+	if err != nil {
+		return err
+	}
+	response, err := s.do(request)
+	if err == ErrNotFound {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	response.Body.Close()
+	return nil
+}
+
+// SignedURL returns a presigned GET URL, which S3 serves directly
+func (s *S3Storage) SignedURL(key string, expires time.Duration) (string, error) {
+	if !ValidKey(key) {
+		return "", ErrInvalidKey
+	}
+	if expires > s3MaxURLExpiry {
+		expires = s3MaxURLExpiry
+	}
+	now := s.now().UTC()
+	objectURL := s.objectURL(key)
+	query := url.Values{
+		"X-Amz-Algorithm":     {s3Algorithm},
+		"X-Amz-Credential":    {s.accessKey + "/" + s.credentialScope(now)},
+		"X-Amz-Date":          {now.Format(s3TimeFormat)},
+		"X-Amz-Expires":       {strconv.Itoa(int(expires.Seconds()))},
+		"X-Amz-SignedHeaders": {"host"},
+	}
+	objectURL.RawQuery = canonicalQuery(query)
+	signature := s.signature(now, http.MethodGet, objectURL, http.Header{}, []string{"host"}, s3UnsignedBody)
+	objectURL.RawQuery += "&X-Amz-Signature=" + signature
+	return objectURL.String(), nil
+}
+
+// newRequest builds a request for the object signed in the Authorization header
+func (s *S3Storage) newRequest(method, key string, body io.Reader, payloadHash string) (*http.Request, error) {
+	if !ValidKey(key) {
+		return nil, ErrInvalidKey
+	}
+	objectURL := s.objectURL(key)
+	request, err := http.NewRequest(method, objectURL.String(), body)
+	if err != nil {
+		return nil, err
+	}
+	now := s.now().UTC()
+	request.Header.Set("X-Amz-Date", now.Format(s3TimeFormat))
+	request.Header.Set("X-Amz-Content-Sha256", payloadHash)
+	signedHeaders := []string{"host", "x-amz-content-sha256", "x-amz-date"}
+	signature := s.signature(now, method, objectURL, request.Header, signedHeaders, payloadHash)
+	request.Header.Set("Authorization", fmt.Sprintf("%s Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		s3Algorithm, s.accessKey, s.credentialScope(now), strings.Join(signedHeaders, ";"), signature))
+	return request, nil
+}
+
+// do sends the request, turning 404 into ErrNotFound and other failures
+// into an error carrying the S3 error document
+func (s *S3Storage) do(request *http.Request) (*http.Response, error) {
+	response, err := s.client.Do(request)
+	if err != nil {
+		return nil, err
+	}
+	if response.StatusCode >= 200 && response.StatusCode < 300 {
+		return response, nil
+	}
+	defer response.Body.Close()
+	if response.StatusCode == http.StatusNotFound {
+		return nil, ErrNotFound
+	}
+	message, _ := io.ReadAll(io.LimitReader(response.Body, 1024))
+	return nil, fmt.Errorf("S3 %s %s: %s: %s", request.Method, request.URL.Path, response.Status, message)
+}
+
+func (s *S3Storage) objectURL(key string) *url.URL {
+	objectURL := *s.endpoint
+	path := "/" + key
+	if s.pathStyle {
+		path = "/" + s.bucket + path
+	} else {
+		objectURL.Host = s.bucket + "." + objectURL.Host
+	}
+	objectURL.Path = strings.TrimSuffix(s.endpoint.Path, "/") + path
+	objectURL.RawPath = uriEncode(objectURL.Path, false)
+	objectURL.RawQuery = ""
+	return &objectURL
+}
+
+func (s *S3Storage) credentialScope(now time.Time) string {
+	return now.Format("20060102") + "/" + s.region + "/s3/aws4_request"
+}
+
+// signature computes the Signature Version 4 of a request. header holds the
+// signed headers other than host.
+func (s *S3Storage) signature(now time.Time, method string, requestURL *url.URL, header http.Header, signedHeaders []string, payloadHash string) string {
+	var canonicalHeaders strings.Builder
+	for _, name := range signedHeaders {
+		value := header.Get(name)
+		if name == "host" {
+			value = requestURL.Host
+		}
+		canonicalHeaders.WriteString(name + ":" + strings.TrimSpace(value) + "\n")
+	}
+	canonicalRequest := strings.Join([]string{
+		method,
+		requestURL.EscapedPath(),
+		requestURL.RawQuery,
+		canonicalHeaders.String(),
+		strings.Join(signedHeaders, ";"),
+		payloadHash,
+	}, "\n")
+	requestHash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := strings.Join([]string{
+		s3Algorithm,
+		now.Format(s3TimeFormat),
+		s.credentialScope(now),
+		hex.EncodeToString(requestHash[:]),
+	}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+s.secretKey), now.Format("20060102"))
+	key = hmacSHA256(key, s.region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	return hex.EncodeToString(hmacSHA256(key, stringToSign))
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+// canonicalQuery encodes the query sorted by name, as Signature Version 4 expects
+func canonicalQuery(query url.Values) string {
+	names := make([]string, 0, len(query))
+	for name := range query {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var parts []string
+	for _, name := range names {
+		for _, value := range query[name] {
+			parts = append(parts, uriEncode(name, true)+"="+uriEncode(value, true))
+		}
+	}
+	return strings.Join(parts, "&")
+}
+
+// uriEncode percent-encodes every byte except the unreserved characters and,
+// unless encodeSlash is set, "/"
+func uriEncode(s string, encodeSlash bool) string {
+	var encoded strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case 'A' <= c && c <= 'Z', 'a' <= c && c <= 'z', '0' <= c && c <= '9',
+			c == '-', c == '_', c == '.', c == '~', c == '/' && !encodeSlash:
+			encoded.WriteByte(c)
+		default:
+			fmt.Fprintf(&encoded, "%%%02X", c)
+		}
+	}
+	return encoded.String()
+}
@@ -0,0 +1,88 @@
+// Package storage_services stores uploaded files in a local directory or an
+// S3-compatible bucket and hands out time-limited download URLs for them
+package storage_services
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+)
+
+var (
+	ErrNotFound         = errors.New("file not found")
+	ErrInvalidKey       = errors.New("invalid file key")
+	ErrInvalidSignature = errors.New("invalid or expired download URL")
+)
+
+// keyPattern restricts keys to URL- and path-safe characters
+var keyPattern = regexp.MustCompile(`^[A-Za-z0-9._-]+(/[A-Za-z0-9._-]+)*$`)
+
+// Storage stores files under keys such as "photos/42/abc.jpg"
+type Storage interface {
+	// Put stores size bytes read from r under the key, replacing any file there
+	Put(key string, r io.Reader, size int64, contentType string) error
+	// Open returns the contents of the file, or ErrNotFound
+	Open(key string) (io.ReadCloser, error)
+	// Delete removes the file. Removing a missing file is not an error.
+	Delete(key string) error
+	// SignedURL returns a URL that downloads the file until it expires
+	SignedURL(key string, expires time.Duration) (string, error)
+}
+
+// URLVerifier is implemented by storages whose signed URLs are served by
+// this API rather than by the storage itself
+type URLVerifier interface {
+	// Verify checks the expiry and signature of a signed URL for the key
+	Verify(key, expires, signature string) error
+}
+
+// ValidKey reports whether the key can be stored. Keys are relative
+// slash-separated paths without "." or ".." segments.
+func ValidKey(key string) bool {
+	if len(key) > 255 || !keyPattern.MatchString(key) {
+		return false
+	}
+	for _, segment := range strings.Split(key, "/") {
+		if segment == "." || segment == ".." {
+			return false
+		}
+	}
+	return true
+}
+
+// FromEnv returns the storage picked by STORAGE_BACKEND: "local" (the
+// default) stores files under STORAGE_LOCAL_DIR (default ./uploads) and signs
+// download URLs with STORAGE_SIGNING_SECRET, falling back to JWT_SECRET; "s3"
+// stores them in the S3_BUCKET bucket, see S3FromEnv.
+func FromEnv() (Storage, error) {
+	switch backend := os.Getenv("STORAGE_BACKEND"); backend {
+	case "", "local":
+		dir := os.Getenv("STORAGE_LOCAL_DIR")
+		if dir == "" {
+			dir = "uploads"
+		}
+		secret := []byte(os.Getenv("STORAGE_SIGNING_SECRET"))
+		if len(secret) == 0 {
+			secret = []byte(os.Getenv("JWT_SECRET"))
+		}
+		if len(secret) == 0 {
+			// Download URLs then stop working on restart
+			log.Println("Warning: neither STORAGE_SIGNING_SECRET nor JWT_SECRET is set, signing download URLs with a random key")
+			secret = make([]byte, 32)
+			if _, err := rand.Read(secret); err != nil {
+				return nil, err
+			}
+		}
+		return NewLocalStorage(dir, "/api/files", secret)
+	case "s3":
+		return S3FromEnv()
+	default:
+		return nil, fmt.Errorf("unknown STORAGE_BACKEND %q, use local or s3", backend)
+	}
+}
@@ -0,0 +1,111 @@
+// Package telegram_services verifies Telegram Login Widget data and defines
+// the bot webhook payloads.
+package telegram_services
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	ErrInvalidHash = errors.New("invalid Telegram login hash")
+	ErrExpiredAuth = errors.New("Telegram login data expired")
+)
+
+// LoginData is what the Telegram Login Widget hands to the page after the
+// user confirms the login in Telegram
+type LoginData struct {
+	ID        int64
+	FirstName string
+	LastName  string
+	Username  string
+	PhotoURL  string
+	AuthDate  int64
+	Hash      string
+}
+
+// VerifyLoginData checks the widget hash, an HMAC-SHA256 of the fields keyed
+// with the SHA-256 of the bot token, and that the login is at most maxAge old.
+// See https://core.telegram.org/widgets/login#checking-authorization
+func VerifyLoginData(botToken string, data *LoginData, maxAge time.Duration) error {
+	secret := sha256.Sum256([]byte(botToken))
+	mac := hmac.New(sha256.New, secret[:])
+	mac.Write([]byte(data.checkString()))
+	expected := hex.EncodeToString(mac.Sum(nil))
+	if !hmac.Equal([]byte(expected), []byte(strings.ToLower(data.Hash))) {
+		return ErrInvalidHash
+	}
+	if time.Since(time.Unix(data.AuthDate, 0)) > maxAge {
+		return ErrExpiredAuth
+	}
+	return nil
+}
+
+// checkString returns the fields the widget sent, sorted by name as key=value
+// lines. Empty optional fields were not sent and are left out.
+func (d *LoginData) checkString() string {
+	fields := map[string]string{
+		"id":         strconv.FormatInt(d.ID, 10),
+		"first_name": d.FirstName,
+		"last_name":  d.LastName,
+		"username":   d.Username,
+		"photo_url":  d.PhotoURL,
+		"auth_date":  strconv.FormatInt(d.AuthDate, 10),
+	}
+	lines := make([]string, 0, len(fields))
+	for key, value := range fields {
+		if value != "" {
+			lines = append(lines, key+"="+value)
+		}
+	}
+	sort.Strings(lines)
+	return strings.Join(lines, "\n")
+}
+
+// Update is an incoming bot update. Only the fields used are declared.
+// See https://core.telegram.org/bots/api#update
+type Update struct {
+	UpdateID int64    `json:"update_id"`
+	Message  *Message `json:"message,omitempty"`
+}
+
+// Message is a message sent to the bot
+type Message struct {
+	MessageID int64  `json:"message_id"`
+	From      *User  `json:"from,omitempty"`
+	Chat      Chat   `json:"chat"`
+	Text      string `json:"text,omitempty"`
+}
+
+// User is a Telegram account
+type User struct {
+	ID        int64  `json:"id"`
+	IsBot     bool   `json:"is_bot"`
+	FirstName string `json:"first_name"`
+	Username  string `json:"username,omitempty"`
+}
+
+// Chat is the conversation a message was sent in
+type Chat struct {
+	ID   int64  `json:"id"`
+	Type string `json:"type"` // "private" for a direct conversation with the bot
+}
+
+// SendMessage is a sendMessage call. Returned as the webhook response, it
+// lets the bot reply without calling the Bot API.
+type SendMessage struct {
+	Method string `json:"method"`
+	ChatID int64  `json:"chat_id"`
+	Text   string `json:"text"`
+}
+
+// Reply returns the sendMessage call answering a chat
+func Reply(chatID int64, text string) *SendMessage {
+	return &SendMessage{Method: "sendMessage", ChatID: chatID, Text: text}
+}
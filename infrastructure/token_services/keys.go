@@ -0,0 +1,148 @@
+package token_services
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/golang-jwt/jwt"
+)
+
+// Algorithms tokens can be signed with
+const (
+	AlgorithmRS256 = "RS256"
+	AlgorithmEdDSA = "EdDSA"
+)
+
+// rsaKeyBits is the size of generated RSA keys
+const rsaKeyBits = 2048
+
+// ErrUnknownKey is returned when a token names a key that is not in the key set
+var ErrUnknownKey = errors.New("unknown signing key")
+
+// SigningKey is the private key new tokens are signed with
+type SigningKey struct {
+	ID         string // Sent as the kid header
+	Algorithm  string
+	PrivateKey crypto.Signer
+}
+
+// VerificationKey is a public key tokens are verified with
+type VerificationKey struct {
+	ID        string
+	Algorithm string
+	PublicKey crypto.PublicKey
+}
+
+// KeySource supplies the keys tokens are signed and verified with
+type KeySource interface {
+	SigningKey() (*SigningKey, error)
+	// VerificationKey returns ErrUnknownKey when no key has the ID
+	VerificationKey(kid string) (*VerificationKey, error)
+}
+
+// JWK is the JSON Web Key form of a public key (RFC 7517)
+type JWK struct {
+	KeyType   string `json:"kty"`
+	KeyID     string `json:"kid"`
+	Use       string `json:"use"`
+	Algorithm string `json:"alg"`
+	N         string `json:"n,omitempty"`   // RSA modulus
+	E         string `json:"e,omitempty"`   // RSA exponent
+	Curve     string `json:"crv,omitempty"` // OKP curve
+	X         string `json:"x,omitempty"`   // OKP public key
+}
+
+// GenerateKeyPair creates a key pair for the algorithm and returns it as
+// PKCS#8 and PKIX PEM blocks along with a key ID derived from the public key.
+func GenerateKeyPair(algorithm string) (kid, privatePEM, publicPEM string, err error) {
+	var private crypto.Signer
+	switch algorithm {
+	case AlgorithmRS256:
+		private, err = rsa.GenerateKey(rand.Reader, rsaKeyBits)
+	case AlgorithmEdDSA:
+		_, private, err = ed25519.GenerateKey(rand.Reader)
+	default:
+		return "", "", "", fmt.Errorf("unsupported signing algorithm %q", algorithm)
+	}
+	if err != nil {
+		return "", "", "", err
+	}
+
+	privateDER, err := x509.MarshalPKCS8PrivateKey(private)
+	if err != nil {
+		return "", "", "", err
+	}
+	publicDER, err := x509.MarshalPKIXPublicKey(private.Public())
+	if err != nil {
+		return "", "", "", err
+	}
+	sum := sha256.Sum256(publicDER)
+	kid = base64.RawURLEncoding.EncodeToString(sum[:16])
+	privatePEM = string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: privateDER}))
+	publicPEM = string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicDER}))
+	return kid, privatePEM, publicPEM, nil
+}
+
+// ParsePrivateKey decodes a PKCS#8 PEM private key
+func ParsePrivateKey(privatePEM string) (crypto.Signer, error) {
+	block, _ := pem.Decode([]byte(privatePEM))
+	if block == nil {
+		return nil, errors.New("invalid private key PEM")
+	}
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	signer, ok := key.(crypto.Signer)
+	if !ok {
+		return nil, errors.New("private key cannot sign")
+	}
+	return signer, nil
+}
+
+// ParsePublicKey decodes a PKIX PEM public key
+func ParsePublicKey(publicPEM string) (crypto.PublicKey, error) {
+	block, _ := pem.Decode([]byte(publicPEM))
+	if block == nil {
+		return nil, errors.New("invalid public key PEM")
+	}
+	return x509.ParsePKIXPublicKey(block.Bytes)
+}
+
+// PublicJWK returns the JWK of a verification key
+func PublicJWK(key *VerificationKey) (*JWK, error) {
+	jwk := &JWK{KeyID: key.ID, Use: "sig", Algorithm: key.Algorithm}
+	switch pub := key.PublicKey.(type) {
+	case *rsa.PublicKey:
+		jwk.KeyType = "RSA"
+		jwk.N = base64.RawURLEncoding.EncodeToString(pub.N.Bytes())
+		jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes())
+	case ed25519.PublicKey:
+		jwk.KeyType = "OKP"
+		jwk.Curve = "Ed25519"
+		jwk.X = base64.RawURLEncoding.EncodeToString(pub)
+	default:
+		return nil, fmt.Errorf("unsupported public key type %T", key.PublicKey)
+	}
+	return jwk, nil
+}
+
+// signingMethod maps an algorithm name to its jwt signing method
+func signingMethod(algorithm string) (jwt.SigningMethod, error) {
+	switch algorithm {
+	case AlgorithmRS256:
+		return jwt.SigningMethodRS256, nil
+	case AlgorithmEdDSA:
+		return jwt.SigningMethodEdDSA, nil
+	}
+	return nil, fmt.Errorf("unsupported signing algorithm %q", algorithm)
+}
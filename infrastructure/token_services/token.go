@@ -2,9 +2,11 @@ package token_services
 
 import (
 	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
+	"fmt"
 	"math/big"
-	"os"
 	"strings"
 	"time"
 
@@ -15,12 +17,12 @@ import (
 	"a2sv.org/hub/Domain/entity"
 )
 
-func GenerateToken(user *entity.User, password, jwtSecret string) (string, error) {
+func GenerateToken(user *entity.User, password string, keys KeySource) (string, error) {
 	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password)); err != nil {
 		return "", errors.New("invalid user name or password")
 	}
 
-	accessToken, err := CreateJWTToken(user, jwtSecret, 24*30*time.Hour)
+	accessToken, err := CreateJWTToken(user, keys, 24*30*time.Hour)
 	if err != nil {
 		return "", err
 	}
@@ -28,23 +30,73 @@ func GenerateToken(user *entity.User, password, jwtSecret string) (string, error
 	return accessToken, nil
 }
 
-func CreateJWTToken(user *entity.User, jwtSecret string, duration time.Duration) (string, error) {
+// Lifetimes of the tokens issued at login
+const (
+	AccessTokenDuration  = 15 * time.Minute
+	RefreshTokenDuration = 30 * 24 * time.Hour
+)
+
+func CreateJWTToken(user *entity.User, keys KeySource, duration time.Duration) (string, error) {
+	return CreateSessionJWTToken(user, keys, duration, "")
+}
+
+// CreateSessionJWTToken creates a token bound to a login session so that
+// revoking the session also rejects the token.
+func CreateSessionJWTToken(user *entity.User, keys KeySource, duration time.Duration, sessionID string) (string, error) {
+	claims, err := newClaims(user, duration)
+	if err != nil {
+		return "", err
+	}
+	claims.SessionID = sessionID
+	return signClaims(claims, keys)
+}
+
+// CreateImpersonationJWTToken creates a token acting as user on behalf of the
+// impersonator. It also returns the token's JWT ID so that it can be ended.
+func CreateImpersonationJWTToken(user *entity.User, impersonatorID uint, keys KeySource, duration time.Duration) (string, string, error) {
+	claims, err := newClaims(user, duration)
+	if err != nil {
+		return "", "", err
+	}
+	claims.ImpersonatorID = impersonatorID
+	token, err := signClaims(claims, keys)
+	return token, claims.Id, err
+}
+
+func newClaims(user *entity.User, duration time.Duration) (*entity.Claims, error) {
+	jti, err := GenerateConfirmationToken(32)
+	if err != nil {
+		return nil, err
+	}
 
 	expirationTime := time.Now().Add(duration)
-	claims := &entity.Claims{
+	return &entity.Claims{
 		ID:          user.ID,
 		Name:        user.Name,
 		Email:       user.Email,
 		PhoneNumber: user.Phone,
 		Role:        user.Role,
 		StandardClaims: jwt.StandardClaims{
+			Id:        jti,
 			ExpiresAt: expirationTime.Unix(),
 			IssuedAt:  time.Now().Unix(),
 		},
-	}
+	}, nil
+}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	tokenString, err := token.SignedString([]byte(jwtSecret))
+// signClaims signs with the active key and names it in the kid header
+func signClaims(claims *entity.Claims, keys KeySource) (string, error) {
+	key, err := keys.SigningKey()
+	if err != nil {
+		return "", err
+	}
+	method, err := signingMethod(key.Algorithm)
+	if err != nil {
+		return "", err
+	}
+	token := jwt.NewWithClaims(method, claims)
+	token.Header["kid"] = key.ID
+	tokenString, err := token.SignedString(key.PrivateKey)
 	if err != nil {
 		return "", err
 	}
@@ -52,29 +104,76 @@ func CreateJWTToken(user *entity.User, jwtSecret string, duration time.Duration)
 	return tokenString, nil
 }
 
-func GetClaims(c *gin.Context) (*entity.Claims, error) {
+// APITokenPrefix marks personal API tokens so they can be told apart from JWTs
+const APITokenPrefix = "a2sv_"
+
+// GenerateOpaqueToken returns a random token to hand to the client and the
+// SHA-256 hash of it to store server-side.
+func GenerateOpaqueToken() (token string, tokenHash string, err error) {
+	token, err = GenerateConfirmationToken(48)
+	if err != nil {
+		return "", "", err
+	}
+	return token, HashToken(token), nil
+}
 
-	var jwtSecret = []byte(os.Getenv("JWT_SECRET"))
+// HashToken returns the hex SHA-256 of an opaque token (64 characters)
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+func GetClaims(c *gin.Context, keys KeySource) (*entity.Claims, error) {
+	tokenString, err := GetBearerToken(c)
+	if err != nil {
+		return &entity.Claims{}, err
+	}
+
+	return ParseJWTToken(tokenString, keys)
+}
+
+// GetBearerToken returns the raw token of the Authorization header
+func GetBearerToken(c *gin.Context) (string, error) {
 	authHeader := c.GetHeader("Authorization")
 	if authHeader == "" {
-		return &entity.Claims{}, errors.New("missing authorization header")
+		return "", errors.New("missing authorization header")
 	}
 
 	TokenString := strings.Split(authHeader, " ")
 	if len(TokenString) != 2 || TokenString[0] != "Bearer" {
-		return &entity.Claims{}, errors.New("invalid token format")
+		return "", errors.New("invalid token format")
 	}
-	tokenString := TokenString[1]
+	return TokenString[1], nil
+}
+
+// IsAPIToken reports whether a bearer token is a personal API token rather than a JWT
+func IsAPIToken(token string) bool {
+	return strings.HasPrefix(token, APITokenPrefix)
+}
 
+// ParseJWTToken validates a signed token string against the key named by its
+// kid header and returns its claims.
+func ParseJWTToken(tokenString string, keys KeySource) (*entity.Claims, error) {
 	token, err := jwt.ParseWithClaims(tokenString, &entity.Claims{}, func(token *jwt.Token) (interface{}, error) {
-		return jwtSecret, nil
+		kid, _ := token.Header["kid"].(string)
+		if kid == "" {
+			return nil, errors.New("missing kid header")
+		}
+		key, err := keys.VerificationKey(kid)
+		if err != nil {
+			return nil, err
+		}
+		if token.Method.Alg() != key.Algorithm {
+			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+		}
+		return key.PublicKey, nil
 	})
 
 	if err != nil {
 		return &entity.Claims{}, err
 	}
 	if claims, ok := token.Claims.(*entity.Claims); ok && token.Valid {
-		return claims, err
+		return claims, nil
 	}
 	return &entity.Claims{}, errors.New("invalid token")
 }
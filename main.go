@@ -6,9 +6,12 @@ import (
 	"os"
 
 	deliveryHttp "a2sv.org/hub/Delivery/http"
-	"a2sv.org/hub/Delivery/http/handlers"
 	"a2sv.org/hub/Repository/postgres"
 	"a2sv.org/hub/infrastructure"
+	"a2sv.org/hub/infrastructure/encryption_services"
+	"a2sv.org/hub/infrastructure/ip_services"
+	"a2sv.org/hub/infrastructure/oauth"
+	"a2sv.org/hub/infrastructure/storage_services"
 	"a2sv.org/hub/usecases"
 
 	"github.com/joho/godotenv"
@@ -44,8 +47,8 @@ func main() {
 
 	// Initialize repository
 	userRepo := postgres.NewUserRepository(db)
-	handlers.UserRepo = userRepo // Set the global UserRepo variable
 	roleRepo := postgres.NewRoleRepository(db)
+	rolePermissionRepo := postgres.NewRolePermissionRepository(db)
 	groupRepo := postgres.NewGroupRepository(db)
 	countryRepo := postgres.NewCountryRepository(db)
 	superGroupRepo := postgres.NewSuperGroupRepository(db)
@@ -59,24 +62,100 @@ func main() {
 	sessionRepo := postgres.NewSessionRepository(db)           // should implement repository.SessionRepository
 	problemTrackRepo := postgres.NewProblemInTracksRepository(db) // should implement repository.ProblemInTracksRepository
 	exerciseRepo := postgres.NewExerciseRepository(db) // implement repository.ExerciseRepository
+	hoaRepo := postgres.NewHOARepository(db)
+	refreshTokenRepo := postgres.NewRefreshTokenRepository(db)
+	revokedTokenRepo := postgres.NewRevokedTokenRepository(db)
+	loginSessionRepo := postgres.NewLoginSessionRepository(db)
+	apiTokenRepo := postgres.NewAPITokenRepository(db)
+	passwordResetTokenRepo := postgres.NewPasswordResetTokenRepository(db)
+	inviteRepo := postgres.NewInviteRepository(db)
+	oauthAccountRepo := postgres.NewOAuthAccountRepository(db)
+	loginEventRepo := postgres.NewLoginEventRepository(db)
+	loginThrottleRepo := postgres.NewLoginThrottleRepository(db)
+	twoFactorRepo := postgres.NewTwoFactorRepository(db)
+	auditLogRepo := postgres.NewAuditLogRepository(db)
+	impersonationRepo := postgres.NewImpersonationRepository(db)
+	signingKeyRepo := postgres.NewSigningKeyRepository(db)
+	telegramRepo := postgres.NewTelegramRepository(db)
+	encryptedColumnRepo := postgres.NewEncryptedColumnRepository(db)
+	registrationJobRepo := postgres.NewRegistrationJobRepository(db)
+	attachmentRepo := postgres.NewAttachmentRepository(db)
+	postRepo := postgres.NewPostRepository(db)
+	personalDataRepo := postgres.NewPersonalDataRepository(db)
 
 	// Initialize use case
-	userUseCase := usecases.NewUserUseCase(userRepo)
-	roleUseCase := usecases.NewRoleUseCase(roleRepo)
-	groupUseCase := usecases.NewGroupUseCase(groupRepo)
+	envelope, err := encryption_services.EnvelopeFromEnv()
+	if err != nil {
+		log.Fatalf("Failed to load encryption keys: %v", err)
+	}
+	if envelope.CurrentKeyID() == "" {
+		log.Println("ENCRYPTION_KEYS is not set: JWT signing keys and two-factor secrets are stored unencrypted")
+	}
+	encryptionUseCase := usecases.NewEncryptionUseCase(envelope, encryptedColumnRepo)
+	signingKeyUseCase := usecases.NewSigningKeyUseCase(signingKeyRepo, encryptionUseCase)
+
+	// Maintenance commands run instead of the server
+	if len(os.Args) > 1 {
+		if err := runCommand(os.Args[1:], signingKeyUseCase, encryptionUseCase); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+	if err := signingKeyUseCase.EnsureKey(os.Getenv("JWT_SIGNING_ALGORITHM")); err != nil {
+		log.Fatalf("Failed to load JWT signing keys: %v", err)
+	}
+
+	hoaUseCase := usecases.NewHOAUseCase(hoaRepo, userRepo, rolePermissionRepo)
+	tokenUseCase := usecases.NewTokenUseCase(userRepo, refreshTokenRepo, revokedTokenRepo, loginSessionRepo, apiTokenRepo, hoaUseCase, signingKeyUseCase)
+	loginHistoryUseCase := usecases.NewLoginHistoryUseCase(loginEventRepo, ip_services.NewIPInfoLocator())
+	auditUseCase := usecases.NewAuditUseCase(auditLogRepo)
+	lockoutUseCase := usecases.NewLockoutUseCase(loginThrottleRepo, userRepo, hoaUseCase, auditUseCase)
+	impersonationUseCase := usecases.NewImpersonationUseCase(impersonationRepo, userRepo, rolePermissionRepo, hoaUseCase, tokenUseCase)
+	twoFactorUseCase := usecases.NewTwoFactorUseCase(twoFactorRepo, userRepo, rolePermissionRepo, tokenUseCase, loginHistoryUseCase, lockoutUseCase, auditUseCase, encryptionUseCase)
+	storage, err := storage_services.FromEnv()
+	if err != nil {
+		log.Fatalf("Failed to set up file storage: %v", err)
+	}
+	fileUseCase := usecases.NewFileUseCase(storage, attachmentRepo, userRepo, sessionRepo, postRepo, hoaUseCase)
+	personalDataUseCase := usecases.NewPersonalDataUseCase(personalDataRepo, userRepo, rolePermissionRepo, hoaUseCase, tokenUseCase, fileUseCase, encryptionUseCase)
+	userUseCase := usecases.NewUserUseCase(userRepo, rolePermissionRepo, hoaUseCase, tokenUseCase, loginHistoryUseCase, lockoutUseCase, twoFactorUseCase, fileUseCase)
+	apiTokenUseCase := usecases.NewAPITokenUseCase(apiTokenRepo, userRepo)
+	passwordUseCase := usecases.NewPasswordUseCase(userRepo, passwordResetTokenRepo, tokenUseCase, auditUseCase)
+	oauthProviders, err := oauth.ProvidersFromEnv()
+	if err != nil {
+		log.Fatalf("Failed to configure OAuth providers: %v", err)
+	}
+	oauthStateSecret, err := oauth.StateSecretFromEnv()
+	if err != nil {
+		log.Fatalf("Failed to configure OAuth providers: %v", err)
+	}
+	oauthUseCase := usecases.NewOAuthUseCase(userRepo, oauthAccountRepo, tokenUseCase, loginHistoryUseCase, twoFactorUseCase, oauthProviders, oauthStateSecret)
+	telegramUseCase := usecases.NewTelegramUseCase(telegramRepo, tokenUseCase, loginHistoryUseCase, twoFactorUseCase)
+	inviteUseCase := usecases.NewInviteUseCase(inviteRepo, userRepo, roleRepo, groupRepo, rolePermissionRepo, hoaUseCase, tokenUseCase, twoFactorUseCase, auditUseCase)
+	roleUseCase := usecases.NewRoleUseCase(roleRepo, rolePermissionRepo, userRepo)
+	groupUseCase := usecases.NewGroupUseCase(groupRepo, hoaUseCase)
 	countryUseCase := usecases.NewCountryUseCase(countryRepo)
-	bulkRegistrationUseCase := usecases.NewBulkRegistrationUseCase(userRepo, roleRepo, groupRepo, countryRepo)
-	superGroupUseCase := usecases.NewSuperGroupUseCase(superGroupRepo)
+	bulkRegistrationUseCase := usecases.NewBulkRegistrationUseCase(userRepo, roleRepo, groupRepo, countryRepo, rolePermissionRepo, registrationJobRepo, auditUseCase)
+	if err := bulkRegistrationUseCase.FailInterruptedJobs(); err != nil {
+		log.Printf("Failed to fail interrupted registration jobs: %v", err)
+	}
+	superGroupUseCase := usecases.NewSuperGroupUseCase(superGroupRepo, hoaUseCase)
 	recentActionUseCase := usecases.NewRecentActionUsecase(recentActionRepo)
 	voteUseCase := usecases.NewVoteUsecase(voteRepo)
 	trackUseCase := usecases.NewTrackUsecase(trackRepo)
-	stippendUseCase := usecases.NewStipendUsecase(stippendRepo)
+	stippendUseCase := usecases.NewStipendUsecase(stippendRepo, encryptionUseCase)
 	submissionUseCase := usecases.NewSubmissionUsecase(submissionRepo)
 	superToGroupUseCase := usecases.NewSuperToGroupUsecase(superToGroupRepo)
 	problemUseCase := usecases.NewProblemUsecase(problemRepo)
-	sessionUsecase := usecases.NewSessionUsecase(sessionRepo)
+	sessionUsecase := usecases.NewSessionUsecase(sessionRepo, hoaUseCase)
 	problemTrackUsecase := usecases.NewProblemTracksUsecase(problemTrackRepo)
-	exerciseUsecase := usecases.NewExerciseUseCase(exerciseRepo)
+	exerciseUsecase := usecases.NewExerciseUseCase(exerciseRepo, hoaUseCase)
+	alumniConfig, err := usecases.AlumniConfigFromEnv()
+	if err != nil {
+		log.Fatalf("Failed to configure the alumni transition: %v", err)
+	}
+	alumniUseCase := usecases.NewAlumniUseCase(userRepo, roleRepo, auditUseCase, alumniConfig)
+	alumniUseCase.Start()
 	// Setup router
 	router := deliveryHttp.SetupRouter(
 		*userUseCase,
@@ -95,6 +174,22 @@ func main() {
 		*sessionUsecase,
 		*problemTrackUsecase,
 		*exerciseUsecase, // pass as value for compatibility with router signature
+		hoaUseCase,
+		tokenUseCase,
+		apiTokenUseCase,
+		passwordUseCase,
+		inviteUseCase,
+		oauthUseCase,
+		loginHistoryUseCase,
+		lockoutUseCase,
+		twoFactorUseCase,
+		auditUseCase,
+		impersonationUseCase,
+		signingKeyUseCase,
+		telegramUseCase,
+		alumniUseCase,
+		fileUseCase,
+		personalDataUseCase,
 		db,
 	)
 	// Print all registered routes for debugging
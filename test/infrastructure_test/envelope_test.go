@@ -0,0 +1,161 @@
+package infrastructure_test
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"strings"
+	"testing"
+
+	"a2sv.org/hub/infrastructure/encryption_services"
+)
+
+const envelopeContext = "stipends.account_number"
+
+var (
+	oldMasterKey = bytes.Repeat([]byte{1}, 32)
+	newMasterKey = bytes.Repeat([]byte{2}, 32)
+)
+
+func newEnvelope(t *testing.T, keys map[string][]byte, currentID string) *encryption_services.Envelope {
+	t.Helper()
+	envelope, err := encryption_services.NewEnvelope(keys, currentID)
+	if err != nil {
+		t.Fatalf("NewEnvelope: %v", err)
+	}
+	return envelope
+}
+
+func TestEnvelopeRoundTrip(t *testing.T) {
+	envelope := newEnvelope(t, map[string][]byte{"k1": oldMasterKey}, "k1")
+	for _, plaintext := range []string{"1000123456789", "ünïcödé", strings.Repeat("x", 4096)} {
+		encrypted, err := envelope.Encrypt(plaintext, envelopeContext)
+		if err != nil {
+			t.Fatalf("Encrypt: %v", err)
+		}
+		if !encryption_services.IsEncrypted(encrypted) || strings.Contains(encrypted, plaintext) {
+			t.Fatalf("Encrypt(%.20q) = %.40q, want an encrypted value", plaintext, encrypted)
+		}
+		if keyID, err := encryption_services.KeyID(encrypted); err != nil || keyID != "k1" {
+			t.Errorf("KeyID = %q, %v, want k1", keyID, err)
+		}
+		decrypted, err := envelope.Decrypt(encrypted, envelopeContext)
+		if err != nil {
+			t.Fatalf("Decrypt: %v", err)
+		}
+		if decrypted != plaintext {
+			t.Errorf("Decrypt = %.20q, want %.20q", decrypted, plaintext)
+		}
+	}
+
+	// Every value gets its own data key and nonce
+	a, _ := envelope.Encrypt("same", envelopeContext)
+	b, _ := envelope.Encrypt("same", envelopeContext)
+	if a == b {
+		t.Error("Encrypt returned the same value twice")
+	}
+}
+
+func TestEnvelopeDecryptErrors(t *testing.T) {
+	envelope := newEnvelope(t, map[string][]byte{"k1": oldMasterKey}, "k1")
+	encrypted, err := envelope.Encrypt("1000123456789", envelopeContext)
+	if err != nil {
+		t.Fatalf("Encrypt: %v", err)
+	}
+	parts := strings.Split(encrypted, ":")
+
+	// flipLastByte changes the last byte of a base64url field
+	flipLastByte := func(field string) string {
+		raw, err := base64.RawURLEncoding.DecodeString(field)
+		if err != nil {
+			t.Fatalf("decode field: %v", err)
+		}
+		raw[len(raw)-1] ^= 1
+		return base64.RawURLEncoding.EncodeToString(raw)
+	}
+
+	tests := []struct {
+		name       string
+		ciphertext string
+		context    string
+		wantErr    error
+	}{
+		{"other column", encrypted, "users.email", encryption_services.ErrTampered},
+		{"tampered value", strings.Join([]string{parts[0], parts[1], parts[2], flipLastByte(parts[3])}, ":"), envelopeContext, encryption_services.ErrTampered},
+		{"tampered data key", strings.Join([]string{parts[0], parts[1], flipLastByte(parts[2]), parts[3]}, ":"), envelopeContext, encryption_services.ErrTampered},
+		{"other key ID", strings.Join([]string{parts[0], "k2", parts[2], parts[3]}, ":"), envelopeContext, encryption_services.ErrUnknownKey},
+		{"not encrypted", "1000123456789", envelopeContext, encryption_services.ErrMalformed},
+		{"missing field", strings.Join(parts[:3], ":"), envelopeContext, encryption_services.ErrMalformed},
+		{"invalid base64", strings.Join([]string{parts[0], parts[1], parts[2], "!!!"}, ":"), envelopeContext, encryption_services.ErrMalformed},
+		{"truncated value", strings.Join([]string{parts[0], parts[1], parts[2], "AAAA"}, ":"), envelopeContext, encryption_services.ErrMalformed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := envelope.Decrypt(tt.ciphertext, tt.context); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Decrypt() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestEnvelopeRotation(t *testing.T) {
+	before := newEnvelope(t, map[string][]byte{"k1": oldMasterKey}, "k1")
+	oldValue, err := before.Encrypt("1000123456789", envelopeContext)
+	if err != nil {
+		t.Fatalf("Encrypt: %v", err)
+	}
+
+	// During the rotation both keys decrypt and new values use the new one
+	rotating := newEnvelope(t, map[string][]byte{"k1": oldMasterKey, "k2": newMasterKey}, "k2")
+	if decrypted, err := rotating.Decrypt(oldValue, envelopeContext); err != nil || decrypted != "1000123456789" {
+		t.Fatalf("Decrypt of the old value = %q, %v", decrypted, err)
+	}
+	newValue, err := rotating.Encrypt("1000123456789", envelopeContext)
+	if err != nil {
+		t.Fatalf("Encrypt: %v", err)
+	}
+	if keyID, _ := encryption_services.KeyID(newValue); keyID != "k2" {
+		t.Errorf("KeyID of a new value = %q, want k2", keyID)
+	}
+
+	// Once the old key is removed, only re-encrypted values decrypt
+	after := newEnvelope(t, map[string][]byte{"k2": newMasterKey}, "k2")
+	if _, err := after.Decrypt(oldValue, envelopeContext); !errors.Is(err, encryption_services.ErrUnknownKey) {
+		t.Errorf("Decrypt of the old value error = %v, want %v", err, encryption_services.ErrUnknownKey)
+	}
+	if decrypted, err := after.Decrypt(newValue, envelopeContext); err != nil || decrypted != "1000123456789" {
+		t.Errorf("Decrypt of the new value = %q, %v", decrypted, err)
+	}
+
+	// A different master key under the same ID does not decrypt either
+	swapped := newEnvelope(t, map[string][]byte{"k1": newMasterKey}, "k1")
+	if _, err := swapped.Decrypt(oldValue, envelopeContext); !errors.Is(err, encryption_services.ErrTampered) {
+		t.Errorf("Decrypt with another key error = %v, want %v", err, encryption_services.ErrTampered)
+	}
+}
+
+func TestEnvelopeNotConfigured(t *testing.T) {
+	envelope := newEnvelope(t, nil, "")
+	if _, err := envelope.Encrypt("1000123456789", envelopeContext); !errors.Is(err, encryption_services.ErrNotConfigured) {
+		t.Errorf("Encrypt() error = %v, want %v", err, encryption_services.ErrNotConfigured)
+	}
+}
+
+func TestNewEnvelopeRejectsInvalidKeys(t *testing.T) {
+	tests := []struct {
+		name      string
+		keys      map[string][]byte
+		currentID string
+	}{
+		{"short key", map[string][]byte{"k1": oldMasterKey[:16]}, "k1"},
+		{"invalid ID", map[string][]byte{"k:1": oldMasterKey}, "k:1"},
+		{"unknown current key", map[string][]byte{"k1": oldMasterKey}, "k2"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := encryption_services.NewEnvelope(tt.keys, tt.currentID); err == nil {
+				t.Error("NewEnvelope() succeeded, want an error")
+			}
+		})
+	}
+}
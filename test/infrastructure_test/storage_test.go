@@ -0,0 +1,171 @@
+package infrastructure_test
+
+import (
+	"errors"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"a2sv.org/hub/infrastructure/storage_services"
+)
+
+func TestStorageValidKey(t *testing.T) {
+	tests := []struct {
+		key  string
+		want bool
+	}{
+		{"photos/12/3f9a.jpg", true},
+		{"cv.pdf", true},
+		{"posts/7/attachments/a_b-c.1.txt", true},
+		{"..hidden/file", true},
+		{"", false},
+		{"../etc/passwd", false},
+		{"photos/../../etc/passwd", false},
+		{"photos/..", false},
+		{"./photos/a.jpg", false},
+		{"photos/./a.jpg", false},
+		{"/etc/passwd", false},
+		{"photos/", false},
+		{"photos//a.jpg", false},
+		{`photos\..\a.jpg`, false},
+		{"photos/a b.jpg", false},
+		{"photos/a%2F..jpg", false},
+		{strings.Repeat("a", 255), true},
+		{strings.Repeat("a", 256), false},
+	}
+	for _, tt := range tests {
+		if got := storage_services.ValidKey(tt.key); got != tt.want {
+			t.Errorf("ValidKey(%.40q) = %v, want %v", tt.key, got, tt.want)
+		}
+	}
+}
+
+func newLocalStorage(t *testing.T) (*storage_services.LocalStorage, string) {
+	t.Helper()
+	root := filepath.Join(t.TempDir(), "uploads")
+	storage, err := storage_services.NewLocalStorage(root, "/api/files", []byte("test-secret"))
+	if err != nil {
+		t.Fatalf("NewLocalStorage: %v", err)
+	}
+	return storage, root
+}
+
+func TestLocalStorageRejectsTraversal(t *testing.T) {
+	storage, root := newLocalStorage(t)
+	outside := filepath.Join(filepath.Dir(root), "outside.txt")
+	if err := os.WriteFile(outside, []byte("secret"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, key := range []string{"../outside.txt", "a/../../outside.txt", "/outside.txt"} {
+		if err := storage.Put(key, strings.NewReader("x"), 1, "text/plain"); !errors.Is(err, storage_services.ErrInvalidKey) {
+			t.Errorf("Put(%q) error = %v, want %v", key, err, storage_services.ErrInvalidKey)
+		}
+		if _, err := storage.Open(key); !errors.Is(err, storage_services.ErrInvalidKey) {
+			t.Errorf("Open(%q) error = %v, want %v", key, err, storage_services.ErrInvalidKey)
+		}
+		if err := storage.Delete(key); !errors.Is(err, storage_services.ErrInvalidKey) {
+			t.Errorf("Delete(%q) error = %v, want %v", key, err, storage_services.ErrInvalidKey)
+		}
+		if _, err := storage.SignedURL(key, time.Minute); !errors.Is(err, storage_services.ErrInvalidKey) {
+			t.Errorf("SignedURL(%q) error = %v, want %v", key, err, storage_services.ErrInvalidKey)
+		}
+	}
+	if _, err := os.Stat(outside); err != nil {
+		t.Errorf("file outside the root was touched: %v", err)
+	}
+}
+
+func TestLocalStoragePutOpenDelete(t *testing.T) {
+	storage, _ := newLocalStorage(t)
+	const key = "posts/7/note.txt"
+
+	if err := storage.Put(key, strings.NewReader("hello, world"), 5, "text/plain"); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	file, err := storage.Open(key)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	content, err := io.ReadAll(file)
+	file.Close()
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	// Put stops at the declared size
+	if string(content) != "hello" {
+		t.Errorf("content = %q, want %q", content, "hello")
+	}
+
+	if err := storage.Delete(key); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if _, err := storage.Open(key); !errors.Is(err, storage_services.ErrNotFound) {
+		t.Errorf("Open after Delete error = %v, want %v", err, storage_services.ErrNotFound)
+	}
+	if err := storage.Delete(key); err != nil {
+		t.Errorf("second Delete: %v", err)
+	}
+}
+
+func TestLocalStorageVerify(t *testing.T) {
+	storage, _ := newLocalStorage(t)
+	const key = "photos/12/3f9a.jpg"
+
+	// signedQuery returns the expires and signature of a signed URL
+	signedQuery := func(key string, expires time.Duration) (string, string) {
+		t.Helper()
+		signed, err := storage.SignedURL(key, expires)
+		if err != nil {
+			t.Fatalf("SignedURL: %v", err)
+		}
+		u, err := url.Parse(signed)
+		if err != nil {
+			t.Fatalf("parse %q: %v", signed, err)
+		}
+		if u.Path != "/api/files/"+key {
+			t.Fatalf("path = %q, want /api/files/%s", u.Path, key)
+		}
+		return u.Query().Get("expires"), u.Query().Get("signature")
+	}
+	expires, signature := signedQuery(key, time.Minute)
+	pastExpires, pastSignature := signedQuery(key, -time.Minute)
+	other, err := storage_services.NewLocalStorage(t.TempDir(), "/api/files", []byte("other-secret"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, otherSignature := func() (string, string) {
+		signed, _ := other.SignedURL(key, time.Minute)
+		u, _ := url.Parse(signed)
+		return u.Query().Get("expires"), u.Query().Get("signature")
+	}()
+
+	tests := []struct {
+		name      string
+		key       string
+		expires   string
+		signature string
+		wantErr   error
+	}{
+		{"valid", key, expires, signature, nil},
+		{"expired", key, pastExpires, pastSignature, storage_services.ErrInvalidSignature},
+		{"expiry pushed back", key, "99999999999", signature, storage_services.ErrInvalidSignature},
+		{"other key", "photos/12/other.jpg", expires, signature, storage_services.ErrInvalidSignature},
+		{"traversal key", "photos/12/../../3f9a.jpg", expires, signature, storage_services.ErrInvalidSignature},
+		{"tampered signature", key, expires, strings.Repeat("0", len(signature)), storage_services.ErrInvalidSignature},
+		{"signed with another secret", key, expires, otherSignature, storage_services.ErrInvalidSignature},
+		{"empty signature", key, expires, "", storage_services.ErrInvalidSignature},
+		{"non-numeric expires", key, "tomorrow", signature, storage_services.ErrInvalidSignature},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := storage.Verify(tt.key, tt.expires, tt.signature); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Verify() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
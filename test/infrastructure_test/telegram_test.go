@@ -0,0 +1,70 @@
+package infrastructure_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"a2sv.org/hub/infrastructure/telegram_services"
+)
+
+const telegramBotToken = "123456:ABC-DEF1234ghIkl-zyx57W2v1u123ew11"
+
+// Hashes computed independently as
+// hex(HMAC-SHA256(key=SHA256(bot token), sorted "key=value" lines))
+const (
+	telegramFullHash    = "c430fc6249b8216f37a4aa93180fb7572fb537965178dbffdb7fefef2389c799"
+	telegramMinimalHash = "2e9ce11d9a29c6c691a799080716336024cfc882b7b4e6c43a5fde950ef176b8"
+)
+
+// telegramAuthDate is the auth_date the hashes were computed for; tests
+// that only check the hash pass a maxAge reaching back to it
+const telegramAuthDate = 1700000000
+
+func telegramLoginData() *telegram_services.LoginData {
+	return &telegram_services.LoginData{
+		ID:        424242,
+		FirstName: "Abebe",
+		LastName:  "Kebede",
+		Username:  "abebe",
+		PhotoURL:  "https://t.me/i/userpic/320/abebe.jpg",
+		AuthDate:  telegramAuthDate,
+		Hash:      telegramFullHash,
+	}
+}
+
+func TestTelegramVerifyLoginData(t *testing.T) {
+	sinceAuthDate := time.Since(time.Unix(telegramAuthDate, 0)) + time.Hour
+
+	tests := []struct {
+		name    string
+		token   string
+		modify  func(*telegram_services.LoginData)
+		maxAge  time.Duration
+		wantErr error
+	}{
+		{"known vector", telegramBotToken, func(*telegram_services.LoginData) {}, sinceAuthDate, nil},
+		{"uppercase hash", telegramBotToken, func(d *telegram_services.LoginData) { d.Hash = strings.ToUpper(d.Hash) }, sinceAuthDate, nil},
+		{"optional fields left out", telegramBotToken, func(d *telegram_services.LoginData) {
+			d.LastName, d.Username, d.PhotoURL = "", "", ""
+			d.Hash = telegramMinimalHash
+		}, sinceAuthDate, nil},
+		{"other bot token", "654321:other", func(*telegram_services.LoginData) {}, sinceAuthDate, telegram_services.ErrInvalidHash},
+		{"changed ID", telegramBotToken, func(d *telegram_services.LoginData) { d.ID++ }, sinceAuthDate, telegram_services.ErrInvalidHash},
+		{"changed username", telegramBotToken, func(d *telegram_services.LoginData) { d.Username = "kebede" }, sinceAuthDate, telegram_services.ErrInvalidHash},
+		{"changed auth date", telegramBotToken, func(d *telegram_services.LoginData) { d.AuthDate = time.Now().Unix() }, sinceAuthDate, telegram_services.ErrInvalidHash},
+		{"empty hash", telegramBotToken, func(d *telegram_services.LoginData) { d.Hash = "" }, sinceAuthDate, telegram_services.ErrInvalidHash},
+		{"expired", telegramBotToken, func(*telegram_services.LoginData) {}, 24 * time.Hour, telegram_services.ErrExpiredAuth},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := telegramLoginData()
+			tt.modify(data)
+			err := telegram_services.VerifyLoginData(tt.token, data, tt.maxAge)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("VerifyLoginData() = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,93 @@
+package infrastructure_test
+
+import (
+	"testing"
+	"time"
+
+	"a2sv.org/hub/infrastructure/totp_services"
+)
+
+// rfcSecret is the SHA-1 key of the RFC 6238 test vectors,
+// "12345678901234567890", in base32
+const rfcSecret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+func TestTOTPCodeMatchesRFC6238(t *testing.T) {
+	// The RFC lists 8-digit codes; 6-digit codes are their last 6 digits
+	tests := []struct {
+		unix int64
+		code string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1111111111, "050471"},
+		{1234567890, "005924"},
+		{2000000000, "279037"},
+		{20000000000, "353130"},
+	}
+	for _, tt := range tests {
+		code, err := totp_services.Code(rfcSecret, totp_services.Step(time.Unix(tt.unix, 0)))
+		if err != nil {
+			t.Fatalf("Code at %d: %v", tt.unix, err)
+		}
+		if code != tt.code {
+			t.Errorf("Code at %d = %s, want %s", tt.unix, code, tt.code)
+		}
+	}
+}
+
+func TestTOTPValidate(t *testing.T) {
+	now := time.Unix(1111111111, 0)
+	step := totp_services.Step(now)
+	codeAt := func(offset int64) string {
+		code, err := totp_services.Code(rfcSecret, step+offset)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return code
+	}
+
+	tests := []struct {
+		name     string
+		secret   string
+		code     string
+		wantOK   bool
+		wantStep int64
+	}{
+		{"current step", rfcSecret, codeAt(0), true, step},
+		{"previous step within skew", rfcSecret, codeAt(-1), true, step - 1},
+		{"next step within skew", rfcSecret, codeAt(1), true, step + 1},
+		{"two steps old", rfcSecret, codeAt(-2), false, 0},
+		{"two steps ahead", rfcSecret, codeAt(2), false, 0},
+		{"spaces are ignored", rfcSecret, " " + codeAt(0)[:3] + " " + codeAt(0)[3:], true, step},
+		{"lowercase padded secret", "gezdgnbvgy3tqojqgezdgnbvgy3tqojq==", codeAt(0), true, step},
+		{"too short", rfcSecret, codeAt(0)[:5], false, 0},
+		{"too long", rfcSecret, codeAt(0) + "1", false, 0},
+		{"empty", rfcSecret, "", false, 0},
+		{"invalid secret", "not base32!", codeAt(0), false, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotStep, ok := totp_services.Validate(tt.secret, tt.code, now)
+			if ok != tt.wantOK || gotStep != tt.wantStep {
+				t.Errorf("Validate(%q) = %d, %v, want %d, %v", tt.code, gotStep, ok, tt.wantStep, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestTOTPGenerateSecret(t *testing.T) {
+	secret, err := totp_services.GenerateSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(secret) != 32 {
+		t.Errorf("secret %q has %d characters, want 32 (160 bits)", secret, len(secret))
+	}
+	code, err := totp_services.Code(secret, 1)
+	if err != nil {
+		t.Fatalf("generated secret does not decode: %v", err)
+	}
+	if _, ok := totp_services.Validate(secret, code, time.Unix(30, 0)); !ok {
+		t.Error("code of a generated secret does not validate")
+	}
+}
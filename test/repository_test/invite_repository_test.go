@@ -0,0 +1,64 @@
+package repository_test
+
+import (
+	"regexp"
+	"testing"
+	"time"
+
+	"a2sv.org/hub/Domain/entity"
+	"a2sv.org/hub/Repository/postgres"
+	"a2sv.org/hub/test/mocks/mock_db"
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+// An invite with max_uses 0 is unlimited: the 0 must reach the database
+// rather than be replaced by a column default, and every redemption must
+// still find the invite usable
+func TestInviteRepository_UnlimitedInvite(t *testing.T) {
+	db, mock, err := mock_db.NewMockDB(t)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mock_db.CloseDB(db)
+	repo := postgres.NewInviteRepository(db)
+
+	groupID := uint(1)
+	now := time.Now()
+	invite := &entity.Invite{
+		Key:       "hash",
+		RoleID:    3,
+		GroupID:   &groupID,
+		MaxUses:   0,
+		ExpiresAt: now.Add(time.Hour),
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "invites" ("key","role_id","user_id","group_id","used","email","max_uses","use_count","expires_at","revoked_at","created_at","updated_at")`)).
+		WithArgs("hash", 3, nil, groupID, false, nil, 0, 0, sqlmock.AnyArg(), nil, sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(7))
+	mock.ExpectCommit()
+	if err := repo.Create(invite); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if invite.ID != 7 || invite.MaxUses != 0 {
+		t.Fatalf("created invite ID = %d, MaxUses = %d, want 7 and 0", invite.ID, invite.MaxUses)
+	}
+
+	consume := regexp.QuoteMeta(`UPDATE "invites" SET "updated_at"=$1,"use_count"=use_count + 1,"used"=max_uses > 0 AND use_count + 1 >= max_uses WHERE id = $2 AND revoked_at IS NULL AND expires_at > $3 AND (max_uses = 0 OR use_count < max_uses)`)
+	for i := 0; i < 2; i++ {
+		mock.ExpectBegin()
+		mock.ExpectExec(consume).
+			WithArgs(sqlmock.AnyArg(), invite.ID, sqlmock.AnyArg()).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+		if err := repo.ConsumeUse(invite.ID, now); err != nil {
+			t.Fatalf("redemption %d: %v", i+1, err)
+		}
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,210 @@
+package usecases
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"a2sv.org/hub/Delivery/http/schemas"
+	"a2sv.org/hub/Domain/entity"
+	"a2sv.org/hub/Domain/repository"
+	"a2sv.org/hub/infrastructure/email_services"
+	"gorm.io/gorm"
+)
+
+// Defaults of the alumni transition. Students hold the role new users get.
+const (
+	defaultAlumniFromRoleIDs = "3"
+	defaultAlumniInterval    = 24 * time.Hour
+)
+
+// AlumniConfig configures the alumni transition
+type AlumniConfig struct {
+	// RoleID is the role graduates are given; 0 disables the transition
+	RoleID uint
+	// FromRoleIDs are the roles of the students who graduate
+	FromRoleIDs []uint
+	// Interval is the time between two runs
+	Interval time.Duration
+}
+
+// AlumniConfigFromEnv reads the alumni role from ALUMNI_ROLE_ID, the student
+// roles from ALUMNI_FROM_ROLE_IDS (comma separated, default 3) and the time
+// between runs from ALUMNI_TRANSITION_INTERVAL (default 24h). Without
+// ALUMNI_ROLE_ID the transition is disabled.
+func AlumniConfigFromEnv() (AlumniConfig, error) {
+	config := AlumniConfig{Interval: defaultAlumniInterval}
+	value := os.Getenv("ALUMNI_ROLE_ID")
+	if value == "" {
+		return config, nil
+	}
+	roleID, err := strconv.ParseUint(value, 10, 32)
+	if err != nil || roleID == 0 {
+		return config, fmt.Errorf("ALUMNI_ROLE_ID %q is not a role ID", value)
+	}
+	config.RoleID = uint(roleID)
+
+	fromRoles := os.Getenv("ALUMNI_FROM_ROLE_IDS")
+	if fromRoles == "" {
+		fromRoles = defaultAlumniFromRoleIDs
+	}
+	for _, entry := range strings.Split(fromRoles, ",") {
+		id, err := strconv.ParseUint(strings.TrimSpace(entry), 10, 32)
+		if err != nil || id == 0 {
+			return config, fmt.Errorf("ALUMNI_FROM_ROLE_IDS entry %q is not a role ID", entry)
+		}
+		if uint(id) == config.RoleID {
+			return config, errors.New("ALUMNI_FROM_ROLE_IDS must not include the alumni role")
+		}
+		config.FromRoleIDs = append(config.FromRoleIDs, uint(id))
+	}
+
+	if value := os.Getenv("ALUMNI_TRANSITION_INTERVAL"); value != "" {
+		interval, err := time.ParseDuration(value)
+		if err != nil || interval < time.Minute {
+			return config, fmt.Errorf("ALUMNI_TRANSITION_INTERVAL %q is not a duration of at least 1m", value)
+		}
+		config.Interval = interval
+	}
+	return config, nil
+}
+
+// AlumniUseCase moves students whose expected graduation date has passed to
+// the alumni role on a schedule
+type AlumniUseCase struct {
+	userRepo repository.UserRepository
+	roleRepo repository.RoleRepository
+	audit    *AuditUseCase
+	config   AlumniConfig
+
+	mu      sync.Mutex
+	nextRun time.Time
+}
+
+func NewAlumniUseCase(userRepo repository.UserRepository, roleRepo repository.RoleRepository, audit *AuditUseCase, config AlumniConfig) *AlumniUseCase {
+	return &AlumniUseCase{
+		userRepo: userRepo,
+		roleRepo: roleRepo,
+		audit:    audit,
+		config:   config,
+	}
+}
+
+// Start runs the transition now and then every interval in the background.
+// It does nothing when the transition is disabled.
+func (u *AlumniUseCase) Start() {
+	if u.config.RoleID == 0 {
+		log.Println("Alumni transition disabled: ALUMNI_ROLE_ID is not set")
+		return
+	}
+	if _, err := u.roleRepo.GetRoleByID(u.config.RoleID); err != nil {
+		log.Printf("Alumni transition disabled: alumni role %d: %v", u.config.RoleID, err)
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(u.config.Interval)
+		defer ticker.Stop()
+		for {
+			now := time.Now()
+			u.mu.Lock()
+			u.nextRun = now.Add(u.config.Interval)
+			u.mu.Unlock()
+
+			count, err := u.Transition(now)
+			if err != nil {
+				log.Printf("Alumni transition failed after moving %d users: %v", count, err)
+			} else if count > 0 {
+				log.Printf("Moved %d graduated users to the alumni role", count)
+			}
+			<-ticker.C
+		}
+	}()
+}
+
+// Transition moves every student whose expected graduation date is before
+// now to the alumni role, detaches them from their group and notifies them.
+// Their submissions, attendance and stipends are left as they are. It returns
+// the number of users moved.
+func (u *AlumniUseCase) Transition(now time.Time) (int, error) {
+	users, err := u.userRepo.ListGraduates(u.config.FromRoleIDs, now)
+	if err != nil {
+		return 0, err
+	}
+	count := 0
+	for _, user := range users {
+		entry := AuditEntry{
+			Method:     auditMethodJob,
+			Route:      auditRouteAlumniTransition,
+			EntityType: "users",
+			EntityID:   fmt.Sprint(user.ID),
+		}
+		err := u.audit.Track(entry, func() (uint, error) {
+			return user.ID, u.userRepo.MoveToAlumni(user.ID, u.config.FromRoleIDs, u.config.RoleID, now)
+		})
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			// Moved by another instance, or given another role meanwhile
+			continue
+		}
+		if err != nil {
+			return count, fmt.Errorf("user %d: %w", user.ID, err)
+		}
+		count++
+		go func(user *entity.User) {
+			if err := sendAlumniEmail(user); err != nil {
+				log.Printf("Failed to notify alumnus %d: %v", user.ID, err)
+			}
+		}(user)
+	}
+	return count, nil
+}
+
+// Preview lists the users the next run will move
+func (u *AlumniUseCase) Preview() (*schemas.AlumniPreviewResponse, error) {
+	if u.config.RoleID == 0 {
+		return nil, ErrAlumniNotConfigured
+	}
+	u.mu.Lock()
+	nextRun := u.nextRun
+	u.mu.Unlock()
+	if nextRun.IsZero() {
+		nextRun = time.Now()
+	}
+
+	users, err := u.userRepo.ListGraduates(u.config.FromRoleIDs, nextRun)
+	if err != nil {
+		return nil, err
+	}
+	response := &schemas.AlumniPreviewResponse{
+		AlumniRoleID: u.config.RoleID,
+		FromRoleIDs:  u.config.FromRoleIDs,
+		NextRunAt:    nextRun,
+		Users:        make([]schemas.AlumniCandidate, 0, len(users)),
+	}
+	for _, user := range users {
+		candidate := schemas.AlumniCandidate{
+			UserID:                 user.ID,
+			Name:                   user.Name,
+			Email:                  user.Email,
+			RoleID:                 user.RoleID,
+			GroupID:                user.GroupID,
+			ExpectedGraduationDate: *user.ExpectedGraduationDate,
+		}
+		if user.Group != nil {
+			candidate.GroupName = user.Group.Name
+		}
+		response.Users = append(response.Users, candidate)
+	}
+	return response, nil
+}
+
+// sendAlumniEmail tells a user they have become an alumnus
+func sendAlumniEmail(user *entity.User) error {
+	body := fmt.Sprintf("Congratulations on graduating, %s!\n\nYour A2SV Hub account is now an alumni account. You have left your group, and your submissions, attendance and stipend records remain available.", user.Name)
+	return email_services.SendEmail(user.Email, "Welcome to the A2SV alumni", body, "yene-hub-ls0y.onrender.com/api/auth/login")
+}
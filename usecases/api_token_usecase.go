@@ -0,0 +1,161 @@
+package usecases
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"a2sv.org/hub/Delivery/http/schemas"
+	"a2sv.org/hub/Domain/entity"
+	"a2sv.org/hub/Domain/repository"
+	"a2sv.org/hub/infrastructure/token_services"
+	"gorm.io/gorm"
+)
+
+// apiTokenTouchInterval limits how often last_used_at is written for a busy token
+const apiTokenTouchInterval = 5 * time.Minute
+
+// APITokenUseCase manages personal API tokens and authenticates requests made with them
+type APITokenUseCase struct {
+	apiTokenRepo repository.APITokenRepository
+	userRepo     repository.UserRepository
+}
+
+func NewAPITokenUseCase(apiTokenRepo repository.APITokenRepository, userRepo repository.UserRepository) *APITokenUseCase {
+	return &APITokenUseCase{
+		apiTokenRepo: apiTokenRepo,
+		userRepo:     userRepo,
+	}
+}
+
+// Create issues a new token for the user. The plain token is only returned here.
+func (u *APITokenUseCase) Create(userID uint, input *schemas.CreateAPITokenRequest) (*schemas.CreateAPITokenResponse, error) {
+	if err := validateScopes(input.Scopes); err != nil {
+		return nil, err
+	}
+	if input.ExpiresAt != nil && !input.ExpiresAt.After(time.Now()) {
+		return nil, fmt.Errorf("%w: expires_at must be in the future", ErrInvalidAPIToken)
+	}
+
+	secret, err := token_services.GenerateConfirmationToken(40)
+	if err != nil {
+		return nil, err
+	}
+	plain := token_services.APITokenPrefix + secret
+
+	token := &entity.APIToken{
+		UserID:    userID,
+		Name:      input.Name,
+		Type:      entity.APITokenTypePersonal,
+		Token:     token_services.HashToken(plain),
+		Scopes:    strings.Join(input.Scopes, ","),
+		ExpiresAt: input.ExpiresAt,
+		CreatedAt: time.Now(),
+	}
+	if err := u.apiTokenRepo.Create(token); err != nil {
+		return nil, err
+	}
+
+	return &schemas.CreateAPITokenResponse{
+		APITokenResponse: *entityToAPITokenResponse(token),
+		Token:            plain,
+	}, nil
+}
+
+// List returns the user's tokens
+func (u *APITokenUseCase) List(userID uint) ([]*schemas.APITokenResponse, error) {
+	tokens, err := u.apiTokenRepo.GetByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+	responses := make([]*schemas.APITokenResponse, 0, len(tokens))
+	for _, t := range tokens {
+		responses = append(responses, entityToAPITokenResponse(t))
+	}
+	return responses, nil
+}
+
+// Revoke deletes one of the user's tokens
+func (u *APITokenUseCase) Revoke(userID, tokenID uint) error {
+	token, err := u.apiTokenRepo.GetByID(tokenID)
+	if err != nil {
+		return err
+	}
+	if token.UserID != userID {
+		return gorm.ErrRecordNotFound
+	}
+	return u.apiTokenRepo.Delete(tokenID)
+}
+
+// Authenticate resolves a plain API token into the claims of its owner
+func (u *APITokenUseCase) Authenticate(plain string) (*entity.Claims, error) {
+	token, err := u.apiTokenRepo.GetByToken(token_services.HashToken(plain))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInvalidAPIToken
+		}
+		return nil, err
+	}
+	now := time.Now()
+	if token.ExpiresAt != nil && now.After(*token.ExpiresAt) {
+		return nil, ErrInvalidAPIToken
+	}
+
+	user, err := u.userRepo.GetUserByID(token.UserID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInvalidAPIToken
+		}
+		return nil, err
+	}
+	if user.Inactive {
+		return nil, ErrInvalidAPIToken
+	}
+
+	if token.LastUsedAt == nil || now.Sub(*token.LastUsedAt) > apiTokenTouchInterval {
+		_ = u.apiTokenRepo.TouchLastUsed(token.ID, now)
+	}
+
+	return &entity.Claims{
+		ID:          user.ID,
+		Name:        user.Name,
+		Email:       user.Email,
+		PhoneNumber: user.Phone,
+		Role:        user.Role,
+		APITokenID:  token.ID,
+		Scopes:      splitScopes(token.Scopes),
+	}, nil
+}
+
+// validateScopes accepts role permissions and the token-only scopes
+func validateScopes(scopes []string) error {
+	for _, s := range scopes {
+		switch s {
+		case entity.ScopeRead, entity.ScopeSubmissionWrite, entity.ScopeVoteWrite, entity.ScopeRecentActionWrite, entity.ScopePostWrite:
+			continue
+		}
+		if !entity.IsKnownPermission(s) {
+			return fmt.Errorf("%w: %s", ErrUnknownScope, s)
+		}
+	}
+	return nil
+}
+
+func splitScopes(scopes string) []string {
+	if scopes == "" {
+		return []string{}
+	}
+	return strings.Split(scopes, ",")
+}
+
+func entityToAPITokenResponse(t *entity.APIToken) *schemas.APITokenResponse {
+	return &schemas.APITokenResponse{
+		ID:         t.ID,
+		Name:       t.Name,
+		Scopes:     splitScopes(t.Scopes),
+		ExpiresAt:  t.ExpiresAt,
+		LastUsedAt: t.LastUsedAt,
+		CreatedAt:  t.CreatedAt,
+	}
+}
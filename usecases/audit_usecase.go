@@ -0,0 +1,328 @@
+package usecases
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"reflect"
+
+	"a2sv.org/hub/Delivery/http/schemas"
+	"a2sv.org/hub/Domain/entity"
+	"a2sv.org/hub/Domain/repository"
+)
+
+// auditedTables maps the resource of a route (the path segment after /api/)
+// to the table whose rows are diffed. Other resources are logged without a diff.
+var auditedTables = map[string]string{
+	"users":           "users",
+	"roles":           "roles",
+	"groups":          "groups",
+	"sessions":        "sessions",
+	"recent_actions":  "recent_actions",
+	"problems":        "problems",
+	"countries":       "countries",
+	"super_groups":    "super_groups",
+	"votes":           "votes",
+	"super_to_groups": "super_to_groups",
+	"submissions":     "submissions",
+	"stipends":        "stipends",
+	"tracks":          "tracks",
+	"exercises":       "exercises",
+	"invites":         "invites",
+	"problem-tracks":  "problem_tracks",
+	// Only written outside the API, see Track
+	"lockout_events": "lockout_events",
+	"two_factors":    "two_factors",
+}
+
+// auditRedactedColumns are secrets; the log only says that they changed
+var auditRedactedColumns = map[string]bool{
+	"password":               true,
+	"key":                    true,
+	"token":                  true,
+	"token_hash":             true,
+	"secret":                 true,
+	"encrypted_token_string": true,
+}
+
+// auditPersonalColumns are the personal data of a table, the fields a user's
+// erasure clears. The log only says that they changed, since the append-only
+// log could not be cleared with them.
+var auditPersonalColumns = map[string]map[string]bool{
+	"users": {
+		"name":               true,
+		"email":              true,
+		"student_id":         true,
+		"phone":              true,
+		"telegram_username":  true,
+		"telegram_uid":       true,
+		"leetcode":           true,
+		"codeforces":         true,
+		"github":             true,
+		"hackerrank":         true,
+		"linkedin":           true,
+		"instagram":          true,
+		"birthday":           true,
+		"gender":             true,
+		"short_bio":          true,
+		"preferred_language": true,
+		"cv":                 true,
+		"mentor_name":        true,
+		"tshirt_color":       true,
+		"tshirt_size":        true,
+		"photo":              true,
+		"config":             true,
+	},
+	"invites": {
+		"email": true,
+	},
+	"stipends": {
+		"bank_name":      true,
+		"account_number": true,
+	},
+}
+
+// auditIgnoredColumns change on every write and would only add noise
+var auditIgnoredColumns = map[string]bool{
+	"updated_at": true,
+}
+
+const auditRedacted = "[redacted]"
+
+// Writes the audit middleware does not see are recorded by the code making
+// them: background jobs, with auditMethodJob as the method and the job as the
+// route, and the /api/auth/ routes, which the middleware skips
+const (
+	auditMethodJob = "JOB"
+
+	auditRouteAlumniTransition = "alumni-transition"
+	auditRouteBulkRegistration = "bulk-registration"
+	auditRouteLogin            = "/api/auth/login"
+	auditRouteLogin2FA         = "/api/auth/login/2fa"
+	auditRouteLogin2FASetup    = "/api/auth/login/2fa/setup"
+	auditRouteResetPassword    = "/api/auth/reset-password"
+	auditRouteRedeemInvite     = "/api/auth/invites/redeem"
+	auditRouteUnlockUser       = "/api/users/:id/unlock"
+)
+
+// AuditEntry describes the API call being audited
+type AuditEntry struct {
+	ActorID        *uint
+	ImpersonatorID *uint
+	Method         string
+	Route          string
+	Path           string
+	EntityType     string
+	EntityID       string
+	Status         int
+	IP             string
+}
+
+type auditChange struct {
+	Old interface{} `json:"old"`
+	New interface{} `json:"new"`
+}
+
+// AuditUseCase writes and searches the append-only audit log
+type AuditUseCase struct {
+	auditLogRepo repository.AuditLogRepository
+}
+
+func NewAuditUseCase(auditLogRepo repository.AuditLogRepository) *AuditUseCase {
+	return &AuditUseCase{
+		auditLogRepo: auditLogRepo,
+	}
+}
+
+// Snapshot returns the current row of an audited entity, or nil when the
+// resource is not diffed or the row does not exist
+func (u *AuditUseCase) Snapshot(entityType, entityID string) (map[string]interface{}, error) {
+	table, ok := auditedTables[entityType]
+	if !ok || entityID == "" {
+		return nil, nil
+	}
+	return u.auditLogRepo.Snapshot(table, entityID)
+}
+
+// Record stores the entry with the diff between the row before and after the call
+func (u *AuditUseCase) Record(entry AuditEntry, before, after map[string]interface{}) error {
+	diff := diffRows(auditedTables[entry.EntityType], before, after)
+	// An erasure must not copy the erased values into the log
+	if before != nil && before["erased_at"] == nil && after["erased_at"] != nil {
+		for column, change := range diff {
+			if change.Old != nil {
+				change.Old = auditRedacted
+			}
+			diff[column] = change
+		}
+	}
+	changes, err := json.Marshal(diff)
+	if err != nil {
+		return err
+	}
+	return u.auditLogRepo.Create(&entity.AuditLog{
+		ActorID:        entry.ActorID,
+		ImpersonatorID: entry.ImpersonatorID,
+		Method:         entry.Method,
+		Route:          entry.Route,
+		Path:           entry.Path,
+		EntityType:     entry.EntityType,
+		EntityID:       entry.EntityID,
+		Action:         auditAction(entry.Method, before, after),
+		Status:         entry.Status,
+		Changes:        string(changes),
+		IP:             entry.IP,
+	})
+}
+
+// Track records a write made outside an audited API call. The row of
+// entry.EntityID, if set, is snapshotted before write, which returns the ID of
+// the row it wrote; a row it created has no snapshot before. Failing to write
+// the log is logged, like in the middleware, and does not fail the write.
+func (u *AuditUseCase) Track(entry AuditEntry, write func() (uint, error)) error {
+	before := u.trackedSnapshot(entry)
+	id, err := write()
+	if err != nil {
+		return err
+	}
+	entry.EntityID = fmt.Sprint(id)
+	u.recordTracked(entry, before)
+	return nil
+}
+
+// Created records rows created outside an audited API call
+func (u *AuditUseCase) Created(entry AuditEntry, ids ...uint) {
+	for _, id := range ids {
+		entry.EntityID = fmt.Sprint(id)
+		u.recordTracked(entry, nil)
+	}
+}
+
+func (u *AuditUseCase) trackedSnapshot(entry AuditEntry) map[string]interface{} {
+	row, err := u.Snapshot(entry.EntityType, entry.EntityID)
+	if err != nil {
+		log.Printf("Failed to snapshot %s %s for the audit log: %v", entry.EntityType, entry.EntityID, err)
+	}
+	return row
+}
+
+func (u *AuditUseCase) recordTracked(entry AuditEntry, before map[string]interface{}) {
+	if entry.Path == "" {
+		entry.Path = entry.Route
+	}
+	if entry.Status == 0 {
+		entry.Status = http.StatusOK
+	}
+	if err := u.Record(entry, before, u.trackedSnapshot(entry)); err != nil {
+		log.Printf("Failed to write audit log for %s %s %s: %v", entry.Route, entry.EntityType, entry.EntityID, err)
+	}
+}
+
+// List searches the audit log, newest first
+func (u *AuditUseCase) List(query *schemas.AuditLogQuery) (*schemas.AuditLogListResponse, error) {
+	if query.Page < 1 {
+		query.Page = 1
+	}
+	if query.PageSize < 1 || query.PageSize > 100 {
+		query.PageSize = 20
+	}
+
+	filter := repository.AuditLogFilter{
+		ActorID:    query.ActorID,
+		EntityType: query.EntityType,
+		EntityID:   query.EntityID,
+		Action:     query.Action,
+		From:       query.From,
+		To:         query.To,
+	}
+	logs, total, err := u.auditLogRepo.List(filter, (query.Page-1)*query.PageSize, query.PageSize)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]*schemas.AuditLogResponse, 0, len(logs))
+	for _, l := range logs {
+		responses = append(responses, &schemas.AuditLogResponse{
+			ID:             l.ID,
+			ActorID:        l.ActorID,
+			ImpersonatorID: l.ImpersonatorID,
+			Method:         l.Method,
+			Route:          l.Route,
+			Path:           l.Path,
+			EntityType:     l.EntityType,
+			EntityID:       l.EntityID,
+			Action:         l.Action,
+			Status:         l.Status,
+			Changes:        json.RawMessage(l.Changes),
+			IP:             l.IP,
+			CreatedAt:      l.CreatedAt,
+		})
+	}
+	return &schemas.AuditLogListResponse{
+		Data: responses,
+		Meta: schemas.PaginationMeta{
+			Total:      int(total),
+			Page:       query.Page,
+			PageSize:   query.PageSize,
+			TotalPages: int((total + int64(query.PageSize) - 1) / int64(query.PageSize)),
+		},
+	}, nil
+}
+
+// diffRows returns the columns of table whose value differs between the two
+// rows. A nil row stands for a row that does not exist.
+func diffRows(table string, before, after map[string]interface{}) map[string]auditChange {
+	changes := map[string]auditChange{}
+	for column, old := range before {
+		if auditIgnoredColumns[column] {
+			continue
+		}
+		value, exists := after[column]
+		if exists && reflect.DeepEqual(old, value) {
+			continue
+		}
+		changes[column] = redactChange(table, column, old, value)
+	}
+	for column, value := range after {
+		if _, seen := before[column]; seen || auditIgnoredColumns[column] {
+			continue
+		}
+		changes[column] = redactChange(table, column, nil, value)
+	}
+	return changes
+}
+
+func redactChange(table, column string, old, value interface{}) auditChange {
+	if !auditRedactedColumns[column] && !auditPersonalColumns[table][column] {
+		return auditChange{Old: old, New: value}
+	}
+	change := auditChange{}
+	if old != nil {
+		change.Old = auditRedacted
+	}
+	if value != nil {
+		change.New = auditRedacted
+	}
+	return change
+}
+
+// auditAction tells the action from the rows, or from the method when the
+// resource is not diffed. Writes to sub-resources such as /groups/:id/hoas
+// leave the parent row in place and count as updates of it.
+func auditAction(method string, before, after map[string]interface{}) string {
+	switch {
+	case before == nil && after == nil:
+		switch method {
+		case http.MethodPost:
+			return entity.AuditActionCreate
+		case http.MethodDelete:
+			return entity.AuditActionDelete
+		}
+	case before == nil:
+		return entity.AuditActionCreate
+	case after == nil:
+		return entity.AuditActionDelete
+	}
+	return entity.AuditActionUpdate
+}
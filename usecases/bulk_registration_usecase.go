@@ -1,25 +1,51 @@
 package usecases
 
 import (
-	"crypto/rand"
-	"encoding/base64"
+	"encoding/json"
 	"fmt"
 	"log"
 	"strings"
 	"time"
 
+	"a2sv.org/hub/Delivery/http/schemas"
 	"a2sv.org/hub/Domain/entity"
 	"a2sv.org/hub/Domain/repository"
-	"golang.org/x/crypto/bcrypt"
+	"a2sv.org/hub/infrastructure/email_services"
+	"a2sv.org/hub/infrastructure/password_services"
+	"gorm.io/gorm"
 )
 
-// BulkRegistrationUseCase defines methods for bulk user registration
+const (
+	// registrationJobWorkers is how many registration jobs run at once;
+	// later jobs stay pending until one finishes
+	registrationJobWorkers = 2
+	// registrationProgressInterval is how many rows are handled between
+	// saves of a job's progress
+	registrationProgressInterval = 20
+)
+
+// BulkRegistrationUseCase defines methods for bulk user registration. Users
+// are registered by a job running in the background, whose progress and
+// report are read back by the job ID.
 type BulkRegistrationUseCase interface {
-	RegisterUsers(emails string, roleID uint, groupID uint, countryID *uint) ([]RegistrationResult, error)
+	// RegisterUsers starts a job registering a comma-separated list of emails
+	RegisterUsers(actorID uint, emails string, roleID uint, groupID uint, countryID *uint, mode string) (*schemas.RegistrationJobResponse, error)
+	// ImportUsers starts a job registering the users of a sheet
+	ImportUsers(actorID uint, rows [][]string, options RegistrationImportOptions) (*schemas.RegistrationJobResponse, error)
+	// ValidateImport validates every row of a sheet without registering anyone
+	ValidateImport(actorID uint, rows [][]string, options RegistrationImportOptions) ([]RegistrationResult, error)
+	// GetJob returns a job started by the actor
+	GetJob(actorID, id uint) (*schemas.RegistrationJobResponse, error)
+	// GetJobReport returns the per-row results of a finished job started by the actor
+	GetJobReport(actorID, id uint) ([]RegistrationResult, error)
+	// FailInterruptedJobs fails the jobs a previous run of the server left
+	// unfinished
+	FailInterruptedJobs() error
 }
 
 // RegistrationResult represents the result of a single user registration
 type RegistrationResult struct {
+	Row     int    `json:"row,omitempty"` // Sheet row, only set for imported users
 	Email   string `json:"email"`
 	Success bool   `json:"success"`
 	Message string `json:"message"`
@@ -29,10 +55,14 @@ type RegistrationResult struct {
 
 // bulkRegistrationUseCase implements BulkRegistrationUseCase
 type bulkRegistrationUseCase struct {
-	userRepo    repository.UserRepository
-	roleRepo    repository.RoleRepository
-	groupRepo   repository.GroupRepository
-	countryRepo repository.CountryRepository
+	userRepo           repository.UserRepository
+	roleRepo           repository.RoleRepository
+	groupRepo          repository.GroupRepository
+	countryRepo        repository.CountryRepository
+	rolePermissionRepo repository.RolePermissionRepository
+	jobRepo            repository.RegistrationJobRepository
+	audit              *AuditUseCase
+	workers            chan struct{}
 }
 
 // NewBulkRegistrationUseCase creates a new BulkRegistrationUseCase instance
@@ -41,148 +71,389 @@ func NewBulkRegistrationUseCase(
 	roleRepo repository.RoleRepository,
 	groupRepo repository.GroupRepository,
 	countryRepo repository.CountryRepository,
+	rolePermissionRepo repository.RolePermissionRepository,
+	jobRepo repository.RegistrationJobRepository,
+	audit *AuditUseCase,
 ) BulkRegistrationUseCase {
 	return &bulkRegistrationUseCase{
-		userRepo:    userRepo,
-		roleRepo:    roleRepo,
-		groupRepo:   groupRepo,
-		countryRepo: countryRepo,
+		userRepo:           userRepo,
+		roleRepo:           roleRepo,
+		groupRepo:          groupRepo,
+		countryRepo:        countryRepo,
+		rolePermissionRepo: rolePermissionRepo,
+		jobRepo:            jobRepo,
+		audit:              audit,
+		workers:            make(chan struct{}, registrationJobWorkers),
 	}
 }
 
-// RegisterUsers registers multiple users from a comma-separated list of emails
-func (u *bulkRegistrationUseCase) RegisterUsers(emails string, roleID uint, groupID uint, countryID *uint) ([]RegistrationResult, error) {
-	// Split emails by comma and trim whitespace
-	emailList := strings.Split(emails, ",")
-	var results []RegistrationResult
+// RegisterUsers starts a job registering the users of a comma-separated list
+// of emails in the group and country
+func (u *bulkRegistrationUseCase) RegisterUsers(actorID uint, emails string, roleID uint, groupID uint, countryID *uint, mode string) (*schemas.RegistrationJobResponse, error) {
+	var rows []importRow
+	for _, email := range strings.Split(emails, ",") {
+		if email = strings.TrimSpace(email); email != "" {
+			rows = append(rows, importRow{fields: map[string]string{"email": email}})
+		}
+	}
+	if len(rows) == 0 {
+		return nil, fmt.Errorf("%w: no emails given", ErrInvalidRegistration)
+	}
+	if len(rows) > MaxRegistrationImportRows {
+		return nil, fmt.Errorf("%w: %d emails given, at most %d can be registered at once",
+			ErrInvalidRegistration, len(rows), MaxRegistrationImportRows)
+	}
+	return u.startJob(actorID, rows, RegistrationImportOptions{
+		RoleID:    roleID,
+		GroupID:   &groupID,
+		CountryID: countryID,
+		Mode:      mode,
+	})
+}
 
-	// Validate role exists
-	role, err := u.roleRepo.GetRoleByID(roleID)
+// GetJob returns a registration job and its progress
+func (u *bulkRegistrationUseCase) GetJob(actorID, id uint) (*schemas.RegistrationJobResponse, error) {
+	job, err := u.ownJob(actorID, id)
 	if err != nil {
-		return nil, fmt.Errorf("invalid role ID: %v", err)
+		return nil, err
 	}
+	return registrationJobToResponse(job), nil
+}
 
-	// Validate group exists
-	_, err = u.groupRepo.GetByID(groupID)
+// GetJobReport returns the per-row results of a finished registration job
+func (u *bulkRegistrationUseCase) GetJobReport(actorID, id uint) ([]RegistrationResult, error) {
+	job, err := u.ownJob(actorID, id)
 	if err != nil {
-		return nil, fmt.Errorf("invalid group ID: %v", err)
+		return nil, err
 	}
+	if !job.Finished() {
+		return nil, ErrRegistrationJobRunning
+	}
+	results := []RegistrationResult{}
+	if job.Report != "" {
+		if err := json.Unmarshal([]byte(job.Report), &results); err != nil {
+			return nil, err
+		}
+	}
+	return results, nil
+}
 
-	// Validate country exists if countryID is provided
-	if countryID != nil {
-		_, err = u.countryRepo.GetByID(*countryID)
-		if err != nil {
-			return nil, fmt.Errorf("invalid country ID: %v", err)
+// ownJob returns the job if the actor started it. The report lists the
+// email and outcome of every row, so other admins, even of the same groups,
+// get not found.
+func (u *bulkRegistrationUseCase) ownJob(actorID, id uint) (*entity.RegistrationJob, error) {
+	job, err := u.jobRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if job.CreatedByID != actorID {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return job, nil
+}
+
+// FailInterruptedJobs fails the jobs that were pending or running when the
+// server stopped. Jobs run inside the server process, so they do not resume.
+func (u *bulkRegistrationUseCase) FailInterruptedJobs() error {
+	count, err := u.jobRepo.FailUnfinished("interrupted by a server restart", time.Now())
+	if count > 0 {
+		log.Printf("Failed %d registration jobs interrupted by a restart", count)
+	}
+	return err
+}
+
+// startJob checks the options and records a pending job registering the
+// rows, which runs in the background once a worker is free
+func (u *bulkRegistrationUseCase) startJob(actorID uint, rows []importRow, options RegistrationImportOptions) (*schemas.RegistrationJobResponse, error) {
+	if options.Mode == "" {
+		options.Mode = entity.RegistrationModePerRow
+	}
+	if options.Mode != entity.RegistrationModePerRow && options.Mode != entity.RegistrationModeAllOrNothing {
+		return nil, fmt.Errorf("%w: unknown mode %q", ErrInvalidRegistration, options.Mode)
+	}
+	if err := u.checkOptions(actorID, options); err != nil {
+		return nil, err
+	}
+
+	job := &entity.RegistrationJob{
+		CreatedByID: actorID,
+		RoleID:      options.RoleID,
+		Mode:        options.Mode,
+		Status:      entity.RegistrationJobPending,
+		Total:       len(rows),
+		CreatedAt:   time.Now(),
+	}
+	if err := u.jobRepo.Create(job); err != nil {
+		return nil, err
+	}
+	response := registrationJobToResponse(job)
+	go u.runJob(job, rows, options)
+	return response, nil
+}
+
+// checkOptions verifies that the role, and the default group and country if
+// given, exist, and that the actor may grant the role
+func (u *bulkRegistrationUseCase) checkOptions(actorID uint, options RegistrationImportOptions) error {
+	if _, err := u.roleRepo.GetRoleByID(options.RoleID); err != nil {
+		return fmt.Errorf("%w: unknown role %d", ErrInvalidRegistration, options.RoleID)
+	}
+	if err := checkRoleGrant(u.userRepo, u.rolePermissionRepo, actorID, options.RoleID); err != nil {
+		return err
+	}
+	if options.GroupID != nil {
+		if _, err := u.groupRepo.GetByID(*options.GroupID); err != nil {
+			return fmt.Errorf("%w: unknown group %d", ErrInvalidRegistration, *options.GroupID)
+		}
+	}
+	if options.CountryID != nil {
+		if _, err := u.countryRepo.GetByID(*options.CountryID); err != nil {
+			return fmt.Errorf("%w: unknown country %d", ErrInvalidRegistration, *options.CountryID)
 		}
 	}
+	return nil
+}
+
+func (u *bulkRegistrationUseCase) runJob(job *entity.RegistrationJob, rows []importRow, options RegistrationImportOptions) {
+	u.workers <- struct{}{}
+	defer func() { <-u.workers }()
 
-	// Process each email
-	for _, email := range emailList {
-		email = strings.TrimSpace(email)
-		if email == "" {
-			continue // Skip empty emails
+	var results []RegistrationResult
+	var err error
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("registration job crashed: %v", r)
 		}
+		u.finishJob(job, results, err)
+	}()
+
+	now := time.Now()
+	job.Status = entity.RegistrationJobRunning
+	job.StartedAt = &now
+	u.saveJob(job)
 
-		result := RegistrationResult{
-			Email: email,
+	entries, err := u.validateRows(rows, options)
+	if err != nil {
+		return
+	}
+	if job.Mode == entity.RegistrationModeAllOrNothing {
+		results, err = u.registerAll(job, entries)
+		return
+	}
+	results = u.registerEach(job, entries)
+}
+
+// registerEach registers every valid row on its own
+func (u *bulkRegistrationUseCase) registerEach(job *entity.RegistrationJob, entries []registrationEntry) []RegistrationResult {
+	results := make([]RegistrationResult, len(entries))
+	for i, entry := range entries {
+		results[i] = entry.result
+		if entry.user != nil {
+			results[i] = u.createUser(job, entry.user, entry.result)
 		}
 
-		// Check if email already exists
-		_, err := u.userRepo.GetUserByEmail(email)
-		if err == nil {
-			result.Success = false
-			result.Message = "Email already registered"
-			results = append(results, result)
-			continue
+		job.Processed++
+		if results[i].Success {
+			job.Succeeded++
+		} else {
+			job.Failed++
+		}
+		if job.Processed%registrationProgressInterval == 0 {
+			u.saveJob(job)
 		}
+	}
+	return results
+}
 
-		// Generate random password
-		password, err := generateRandomPassword(12)
-		if err != nil {
-			result.Success = false
-			result.Message = "Failed to generate password"
-			results = append(results, result)
-			continue
+// registerAll registers every row in one transaction, or nobody if a row is
+// invalid or the transaction fails. Welcome emails are only sent once the
+// transaction has committed.
+func (u *bulkRegistrationUseCase) registerAll(job *entity.RegistrationJob, entries []registrationEntry) ([]RegistrationResult, error) {
+	results := make([]RegistrationResult, len(entries))
+	notRegistered := func(reason string) {
+		for i, entry := range entries {
+			results[i] = entry.result
+			if entry.user != nil {
+				results[i].Success = false
+				results[i].Message = reason
+			}
 		}
+	}
 
-		// Create new user with only required fields
-		user := &entity.User{
-			Email:     email,	
-			Password:  password, // Will be hashed in Create method
-			RoleID:    roleID,
-			GroupID:   &groupID, // Use pointer to groupID
-			CountryID: countryID, // Use pointer to countryID	
-			Name:      extractNameFromEmail(email),
-			CreatedAt: time.Now(),
-			UpdatedAt: time.Now(),
+	invalid := 0
+	for _, entry := range entries {
+		if entry.user == nil {
+			invalid++
 		}
+	}
+	if invalid > 0 {
+		notRegistered("Not registered because other rows are invalid")
+		return results, fmt.Errorf("%d of %d rows are invalid, nobody was registered", invalid, len(entries))
+	}
 
-		// Hash password
-		hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	// Hash every password before the transaction, which then stays short
+	users := make([]*entity.User, len(entries))
+	passwords := make([]string, len(entries))
+	for i, entry := range entries {
+		password, err := setRandomPassword(entry.user)
 		if err != nil {
-			result.Success = false
-			result.Message = "Failed to hash password"
-			results = append(results, result)
-			continue
+			notRegistered("Not registered because the batch failed")
+			return results, err
 		}
+		users[i], passwords[i] = entry.user, password
 
-		// Set hashed password
-		user.Password = string(hashedPassword)
-
-		// Save user to database
-		err = u.userRepo.CreateUser(user)
-		if err != nil {
-			// Check if it's a unique constraint violation (e.g., email already exists)
-			if strings.Contains(err.Error(), "unique") || strings.Contains(err.Error(), "duplicate") {
-				result.Success = false
-				result.Message = "Email already registered"
-				results = append(results, result)
-				continue
-			}
-			result.Success = false
-			result.Message = fmt.Sprintf("Failed to create user: %v", err)
-			results = append(results, result)
-			continue
+		job.Processed++
+		if job.Processed%registrationProgressInterval == 0 {
+			u.saveJob(job)
 		}
+	}
+	if err := u.userRepo.CreateUsers(users); err != nil {
+		notRegistered("Not registered because the batch failed")
+		return results, fmt.Errorf("registering the batch failed, nobody was registered: %w", err)
+	}
+	ids := make([]uint, len(users))
+	for i, user := range users {
+		ids[i] = user.ID
+	}
+	u.audit.Created(auditJobEntry(job), ids...)
 
-		// Verify the user was actually created
-		verifiedUser, err := u.userRepo.GetUserByID(user.ID)
-		if err != nil || verifiedUser == nil {
-			// If we can't find the user, it wasn't actually created
-			result.Success = false
-			result.Message = "User creation failed: user not found after create operation"
-			results = append(results, result)
-			continue
+	for i, entry := range entries {
+		results[i] = welcomeUser(entry.user, passwords[i], entry.result)
+		job.Succeeded++
+		if job.Succeeded%registrationProgressInterval == 0 {
+			u.saveJob(job)
 		}
+	}
+	return results, nil
+}
 
-		// Send welcome email with password
-		plainPassword := password // Store before we clear it from the result
-		err = u.sendWelcomeEmail(email, plainPassword, role.Type)
-		emailStatus := "Email sent successfully"
-		if err != nil {
-			emailStatus = fmt.Sprintf("User created but email failed: %v", err)
+// finishJob records the outcome and report of a job
+func (u *bulkRegistrationUseCase) finishJob(job *entity.RegistrationJob, results []RegistrationResult, err error) {
+	now := time.Now()
+	job.FinishedAt = &now
+	job.Status = entity.RegistrationJobCompleted
+	if err != nil {
+		job.Status = entity.RegistrationJobFailed
+		job.Error = err.Error()
+		log.Printf("Registration job %d failed: %v", job.ID, err)
+	}
+
+	job.Succeeded = 0
+	for _, result := range results {
+		if result.Success {
+			job.Succeeded++
 		}
+	}
+	job.Failed = job.Total - job.Succeeded
+	if results != nil {
+		job.Processed = len(results)
+	}
+	if report, err := json.Marshal(results); err == nil && results != nil {
+		job.Report = string(report)
+	}
+	u.saveJob(job)
+}
 
-		// Record successful registration
-		result.Success = true
-		result.Message = emailStatus
-		result.UserID = user.ID
-		results = append(results, result)
+func (u *bulkRegistrationUseCase) saveJob(job *entity.RegistrationJob) {
+	if err := u.jobRepo.Update(job); err != nil {
+		log.Printf("Failed to save registration job %d: %v", job.ID, err)
+	}
+}
 
-		// Log successful creation without exposing the password
-		log.Printf("Successfully created user with ID %d and email %s", user.ID, email)
+// createUser saves the user with a random password, which is sent to them in
+// the welcome email, and returns the completed result
+func (u *bulkRegistrationUseCase) createUser(job *entity.RegistrationJob, user *entity.User, result RegistrationResult) RegistrationResult {
+	password, err := setRandomPassword(user)
+	if err != nil {
+		result.Success = false
+		result.Message = "Failed to generate password"
+		return result
 	}
 
-	return results, nil
+	// Save user to database
+	err = u.userRepo.CreateUser(user)
+	if err != nil {
+		// Check if it's a unique constraint violation (e.g., email already exists)
+		if strings.Contains(err.Error(), "unique") || strings.Contains(err.Error(), "duplicate") {
+			result.Success = false
+			result.Message = "Email already registered"
+			return result
+		}
+		result.Success = false
+		result.Message = fmt.Sprintf("Failed to create user: %v", err)
+		return result
+	}
+	u.audit.Created(auditJobEntry(job), user.ID)
+	return welcomeUser(user, password, result)
+}
+
+// auditJobEntry attributes the users a job creates to the admin who started it
+func auditJobEntry(job *entity.RegistrationJob) AuditEntry {
+	return AuditEntry{
+		ActorID:    &job.CreatedByID,
+		Method:     auditMethodJob,
+		Route:      auditRouteBulkRegistration,
+		Path:       fmt.Sprintf("%s/%d", auditRouteBulkRegistration, job.ID),
+		EntityType: "users",
+	}
 }
 
-// generateRandomPassword generates a secure random password of the specified length
-func generateRandomPassword(length int) (string, error) {
-	bytes := make([]byte, length)
-	if _, err := rand.Read(bytes); err != nil {
+// setRandomPassword gives the user a random password and returns it
+func setRandomPassword(user *entity.User) (string, error) {
+	password, err := password_services.GenerateRandomPassword(12)
+	if err != nil {
+		return "", err
+	}
+	hashedPassword, err := password_services.HashPassword(password)
+	if err != nil {
 		return "", err
 	}
-	return base64.StdEncoding.EncodeToString(bytes)[:length], nil
+	user.Password = hashedPassword
+	now := time.Now()
+	user.CreatedAt = now
+	user.UpdatedAt = now
+	return password, nil
+}
+
+// welcomeUser emails a registered user their password and completes the
+// result. A failed email leaves the user registered.
+func welcomeUser(user *entity.User, password string, result RegistrationResult) RegistrationResult {
+	result.Success = true
+	result.UserID = user.ID
+	result.Message = "Email sent successfully"
+	if err := sendWelcomeEmail(user.Email, password); err != nil {
+		result.Message = fmt.Sprintf("User created but email failed: %v", err)
+	}
+
+	// Log successful creation without exposing the password
+	log.Printf("Successfully created user with ID %d and email %s", user.ID, user.Email)
+	return result
+}
+
+// sendWelcomeEmail emails a new user the temporary password of their account
+func sendWelcomeEmail(email, password string) error {
+	body := fmt.Sprintf("Welcome to A2SV Hub!\n\nYour account has been created successfully.\nYour temporary password is: %s\n\nPlease change your password after logging in.", password)
+	return email_services.SendEmail(email, "Welcome to A2SV Hub", body, "yene-hub-ls0y.onrender.com/api/auth/login")
+}
+
+func registrationJobToResponse(job *entity.RegistrationJob) *schemas.RegistrationJobResponse {
+	response := &schemas.RegistrationJobResponse{
+		ID:         job.ID,
+		Status:     job.Status,
+		Mode:       job.Mode,
+		RoleID:     job.RoleID,
+		Total:      job.Total,
+		Processed:  job.Processed,
+		Succeeded:  job.Succeeded,
+		Failed:     job.Failed,
+		Error:      job.Error,
+		CreatedAt:  job.CreatedAt,
+		StartedAt:  job.StartedAt,
+		FinishedAt: job.FinishedAt,
+	}
+	if job.Finished() {
+		response.ReportURL = fmt.Sprintf("/api/registration/jobs/%d/report", job.ID)
+	}
+	return response
 }
 
 // extractNameFromEmail extracts and formats a name from an email address
@@ -209,13 +480,3 @@ func extractNameFromEmail(email string) string {
 	// Join with spaces
 	return strings.Join(nameParts, " ")
 }
-
-// sendWelcomeEmail sends a welcome email to the user with their password
-func (u *bulkRegistrationUseCase) sendWelcomeEmail(email, password, roleType string) error {
-	// Simple implementation for now - just log the email details
-	log.Printf("[EMAIL SERVICE] Sending welcome email to %s with role %s", email, roleType)
-	log.Printf("[EMAIL CONTENT] Welcome to A2SV Hub! Your password is: %s", password)
-	return nil
-}
-
-
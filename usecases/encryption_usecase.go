@@ -0,0 +1,109 @@
+package usecases
+
+import (
+	"errors"
+	"fmt"
+
+	"a2sv.org/hub/Domain/entity"
+	"a2sv.org/hub/Domain/repository"
+	"a2sv.org/hub/infrastructure/encryption_services"
+	"gorm.io/gorm"
+)
+
+// reencryptBatchSize is how many rows of a column are re-encrypted per query
+const reencryptBatchSize = 100
+
+// EncryptionUseCase encrypts the values of encrypted columns and re-encrypts
+// stored values after the master key rotates
+type EncryptionUseCase struct {
+	envelope            *encryption_services.Envelope
+	encryptedColumnRepo repository.EncryptedColumnRepository
+}
+
+func NewEncryptionUseCase(envelope *encryption_services.Envelope, encryptedColumnRepo repository.EncryptedColumnRepository) *EncryptionUseCase {
+	return &EncryptionUseCase{
+		envelope:            envelope,
+		encryptedColumnRepo: encryptedColumnRepo,
+	}
+}
+
+// Encrypt returns the value to store in the column. Empty values stay empty.
+func (u *EncryptionUseCase) Encrypt(column entity.EncryptedColumn, plaintext string) (string, error) {
+	if plaintext == "" {
+		return "", nil
+	}
+	return u.envelope.Encrypt(plaintext, column.String())
+}
+
+// EncryptIfConfigured is Encrypt for columns the server cannot run without,
+// such as the JWT signing keys and two-factor secrets. Without a master key
+// the value is stored as it is, and Reencrypt encrypts it once one is added.
+func (u *EncryptionUseCase) EncryptIfConfigured(column entity.EncryptedColumn, plaintext string) (string, error) {
+	if u.envelope.CurrentKeyID() == "" {
+		return plaintext, nil
+	}
+	return u.Encrypt(column, plaintext)
+}
+
+// Decrypt returns the plaintext of a value stored in the column. Values
+// written before the column was encrypted are returned as they are until
+// Reencrypt encrypts them.
+func (u *EncryptionUseCase) Decrypt(column entity.EncryptedColumn, stored string) (string, error) {
+	if stored == "" || !encryption_services.IsEncrypted(stored) {
+		return stored, nil
+	}
+	return u.envelope.Decrypt(stored, column.String())
+}
+
+// Reencrypt rewrites every value of the encrypted columns that is not
+// encrypted with the current master key, including values stored before
+// encryption, and returns the number of values rewritten per column. Once it
+// completes, the previous master keys can be removed.
+func (u *EncryptionUseCase) Reencrypt() (map[string]int, error) {
+	if u.envelope.CurrentKeyID() == "" {
+		return nil, encryption_services.ErrNotConfigured
+	}
+	counts := make(map[string]int, len(entity.EncryptedColumns))
+	for _, column := range entity.EncryptedColumns {
+		count, err := u.reencryptColumn(column)
+		counts[column.String()] = count
+		if err != nil {
+			return counts, fmt.Errorf("%s: %w", column, err)
+		}
+	}
+	return counts, nil
+}
+
+func (u *EncryptionUseCase) reencryptColumn(column entity.EncryptedColumn) (int, error) {
+	count := 0
+	var afterID uint
+	for {
+		values, err := u.encryptedColumnRepo.ListStale(column, u.envelope.CurrentKeyID(), afterID, reencryptBatchSize)
+		if err != nil {
+			return count, err
+		}
+		for _, value := range values {
+			plaintext, err := u.Decrypt(column, value.Value)
+			if err != nil {
+				return count, fmt.Errorf("row %d: %w", value.ID, err)
+			}
+			encrypted, err := u.Encrypt(column, plaintext)
+			if err != nil {
+				return count, err
+			}
+			err = u.encryptedColumnRepo.Replace(column, value.ID, value.Value, encrypted)
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				// Rewritten meanwhile, which used the current key
+				continue
+			}
+			if err != nil {
+				return count, fmt.Errorf("row %d: %w", value.ID, err)
+			}
+			count++
+		}
+		if len(values) < reencryptBatchSize {
+			return count, nil
+		}
+		afterID = values[len(values)-1].ID
+	}
+}
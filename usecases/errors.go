@@ -0,0 +1,48 @@
+package usecases
+
+import "errors"
+
+// Errors returned by the usecases that handlers map to specific HTTP statuses
+var (
+	ErrUnknownPermission      = errors.New("unknown permission")
+	ErrOutOfScope             = errors.New("target group is outside of your scope")
+	ErrInvalidRefreshToken    = errors.New("invalid or expired refresh token")
+	ErrInvalidAPIToken        = errors.New("invalid or expired API token")
+	ErrUnknownScope           = errors.New("unknown scope")
+	ErrInvalidResetToken      = errors.New("invalid or expired password reset token")
+	ErrWrongPassword          = errors.New("current password is incorrect")
+	ErrPasswordChangeSelf     = errors.New("use the change-password endpoint to change your own password")
+	ErrInvalidInvite          = errors.New("invalid, expired or used invite")
+	ErrEmailAlreadyRegistered = errors.New("email already registered")
+	ErrEmailOfDeletedUser     = errors.New("email belongs to a deleted user, restore the user instead")
+	ErrRoleEscalation         = errors.New("cannot grant a role holding a permission you do not have")
+	ErrInvalidOAuthState      = errors.New("invalid or expired OAuth state")
+	ErrOAuthEmailNotVerified  = errors.New("the provider account has no verified email")
+	ErrOAuthUserNotRegistered = errors.New("no registered user has this email")
+	ErrOAuthAccountConflict   = errors.New("the user is already linked to another account of this provider")
+	ErrOAuthAccountTaken      = errors.New("the provider account is linked to another user")
+	ErrUnknownOAuthProvider   = errors.New("unknown OAuth provider")
+	ErrAccountLocked          = errors.New("too many failed logins")
+	ErrInvalidTwoFactorCode   = errors.New("invalid two-factor code")
+	ErrInvalidChallenge       = errors.New("invalid or expired two-factor challenge")
+	ErrTwoFactorEnabled       = errors.New("two-factor authentication is already enabled")
+	ErrTwoFactorNotEnrolled   = errors.New("two-factor authentication is not set up")
+	ErrTwoFactorRequired      = errors.New("two-factor authentication is required for your role")
+	ErrImpersonateSelf        = errors.New("cannot impersonate yourself")
+	ErrNotImpersonating       = errors.New("the token is not an impersonation token")
+	ErrTelegramNotConfigured  = errors.New("Telegram is not configured")
+	ErrInvalidTelegramAuth    = errors.New("invalid or expired Telegram login data")
+	ErrInvalidTelegramCode    = errors.New("invalid, expired or used Telegram link code")
+	ErrTelegramAccountTaken   = errors.New("the Telegram account is linked to another user")
+	ErrTelegramNotLinked      = errors.New("no user is linked to this Telegram account")
+	ErrInvalidRegistration    = errors.New("invalid registration request")
+	ErrRegistrationJobRunning = errors.New("the registration job has not finished")
+	ErrAccountInactive        = errors.New("the account is deactivated")
+	ErrUserLifecycleSelf      = errors.New("cannot deactivate or delete your own account")
+	ErrUserHasHistory         = errors.New("the user has submissions, attendance or stipends and cannot be purged")
+	ErrAlumniNotConfigured    = errors.New("the alumni transition is not configured")
+	ErrInvalidUpload          = errors.New("invalid file")
+	ErrUploadTooLarge         = errors.New("the file is too large")
+	ErrNotPostAuthor          = errors.New("only the author can change the attachments of a post")
+	ErrUserErased             = errors.New("the user's personal data has been erased")
+)
@@ -7,15 +7,20 @@ import (
 
 type ExerciseUseCase struct {
 	exerciseRepo repository.ExerciseRepository
+	scope        GroupScopeChecker
 }
 
-func NewExerciseUseCase(exerciseRepo repository.ExerciseRepository) *ExerciseUseCase {
+func NewExerciseUseCase(exerciseRepo repository.ExerciseRepository, scope GroupScopeChecker) *ExerciseUseCase {
 	return &ExerciseUseCase{
 		exerciseRepo: exerciseRepo,
+		scope:        scope,
 	}
 }
 
-func (uc *ExerciseUseCase) Create(exercise *entity.Exercise) error {
+func (uc *ExerciseUseCase) Create(actorID uint, exercise *entity.Exercise) error {
+	if err := uc.scope.CheckGroupScope(actorID, &exercise.GroupID); err != nil {
+		return err
+	}
 	return uc.exerciseRepo.Create(exercise)
 }
 func (uc *ExerciseUseCase) GetByID(id uint) (*entity.Exercise, error) {
@@ -24,10 +29,27 @@ func (uc *ExerciseUseCase) GetByID(id uint) (*entity.Exercise, error) {
 func (uc *ExerciseUseCase) GetAll() ([]*entity.Exercise, error) {
 	return uc.exerciseRepo.GetAll()
 }
-func (uc *ExerciseUseCase) Update(exercise *entity.Exercise) error {
+func (uc *ExerciseUseCase) Update(actorID uint, exercise *entity.Exercise) error {
+	existing, err := uc.exerciseRepo.GetByID(exercise.ID)
+	if err != nil {
+		return err
+	}
+	if err := uc.scope.CheckGroupScope(actorID, &existing.GroupID); err != nil {
+		return err
+	}
+	if err := uc.scope.CheckGroupScope(actorID, &exercise.GroupID); err != nil {
+		return err
+	}
 	return uc.exerciseRepo.Update(exercise)
 }
-func (uc *ExerciseUseCase) Delete(id uint) error {
+func (uc *ExerciseUseCase) Delete(actorID, id uint) error {
+	existing, err := uc.exerciseRepo.GetByID(id)
+	if err != nil {
+		return err
+	}
+	if err := uc.scope.CheckGroupScope(actorID, &existing.GroupID); err != nil {
+		return err
+	}
 	return uc.exerciseRepo.Delete(id)
 }
 func (uc *ExerciseUseCase) GetByGroupID(groupID uint) ([]*entity.Exercise, error) {
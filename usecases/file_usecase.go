@@ -0,0 +1,474 @@
+package usecases
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"a2sv.org/hub/Delivery/http/schemas"
+	"a2sv.org/hub/Domain/entity"
+	"a2sv.org/hub/Domain/repository"
+	"a2sv.org/hub/infrastructure/image_services"
+	"a2sv.org/hub/infrastructure/storage_services"
+	"a2sv.org/hub/infrastructure/token_services"
+	"gorm.io/gorm"
+)
+
+// Upload limits and the lifetime of download URLs
+const (
+	// MaxUploadSize is the largest file any upload accepts
+	MaxUploadSize     = 25 << 20
+	photoMaxSide      = 512
+	signedURLDuration = 15 * time.Minute
+)
+
+// uploadRule says which files an upload accepts
+type uploadRule struct {
+	maxSize int64
+	// types maps the accepted content types, as sniffed from the file's
+	// first bytes, to the extension the file is stored with
+	types map[string]string
+}
+
+var (
+	imageTypes = map[string]string{"image/jpeg": ".jpg", "image/png": ".png", "image/gif": ".gif"}
+
+	photoRule           = uploadRule{maxSize: 5 << 20, types: imageTypes}
+	cvRule              = uploadRule{maxSize: 5 << 20, types: map[string]string{"application/pdf": ".pdf"}}
+	sessionResourceRule = uploadRule{maxSize: MaxUploadSize, types: withTypes(imageTypes, map[string]string{
+		"application/pdf": ".pdf",
+		"application/zip": ".zip",
+		"text/plain":      ".txt",
+	})}
+	postAttachmentRule = uploadRule{maxSize: 10 << 20, types: withTypes(imageTypes, map[string]string{
+		"application/pdf": ".pdf",
+		"text/plain":      ".txt",
+	})}
+
+	// officeTypes are the ZIP based formats recognized by their extension
+	officeTypes = map[string]string{
+		".docx": "application/vnd.openxmlformats-officedocument.wordprocessingml.document",
+		".pptx": "application/vnd.openxmlformats-officedocument.presentationml.presentation",
+		".xlsx": "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+	}
+)
+
+func withTypes(types ...map[string]string) map[string]string {
+	merged := map[string]string{}
+	for _, t := range types {
+		for contentType, ext := range t {
+			merged[contentType] = ext
+		}
+	}
+	return merged
+}
+
+// FileUseCase stores uploaded profile photos, CVs, session resources and
+// post attachments, and signs their download URLs
+type FileUseCase struct {
+	storage        storage_services.Storage
+	attachmentRepo repository.AttachmentRepository
+	userRepo       repository.UserRepository
+	sessionRepo    repository.SessionRepository
+	postRepo       repository.PostRepository
+	scope          GroupScopeChecker
+}
+
+func NewFileUseCase(
+	storage storage_services.Storage,
+	attachmentRepo repository.AttachmentRepository,
+	userRepo repository.UserRepository,
+	sessionRepo repository.SessionRepository,
+	postRepo repository.PostRepository,
+	scope GroupScopeChecker,
+) *FileUseCase {
+	return &FileUseCase{
+		storage:        storage,
+		attachmentRepo: attachmentRepo,
+		userRepo:       userRepo,
+		sessionRepo:    sessionRepo,
+		postRepo:       postRepo,
+		scope:          scope,
+	}
+}
+
+// SetPhoto replaces the user's profile photo. The image is scaled down to fit
+// in 512x512 and stored as a JPEG.
+func (u *FileUseCase) SetPhoto(actorID, userID uint, r io.Reader) (*schemas.FileURLResponse, error) {
+	data, err := readUpload(r, photoRule)
+	if err != nil {
+		return nil, err
+	}
+	if _, _, err := sniff(photoRule, "", data); err != nil {
+		return nil, err
+	}
+	resized, err := image_services.FitJPEG(data, photoMaxSide)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidUpload, err)
+	}
+	return u.setUserFile(actorID, userID, "photos", resized, "image/jpeg", ".jpg", func(user *entity.User) *string {
+		return &user.Photo
+	})
+}
+
+// SetCV replaces the user's CV, which must be a PDF
+func (u *FileUseCase) SetCV(actorID, userID uint, r io.Reader) (*schemas.FileURLResponse, error) {
+	data, err := readUpload(r, cvRule)
+	if err != nil {
+		return nil, err
+	}
+	contentType, ext, err := sniff(cvRule, "", data)
+	if err != nil {
+		return nil, err
+	}
+	return u.setUserFile(actorID, userID, "cvs", data, contentType, ext, func(user *entity.User) *string {
+		return &user.CV
+	})
+}
+
+// RemovePhoto removes the user's profile photo
+func (u *FileUseCase) RemovePhoto(actorID, userID uint) error {
+	return u.removeUserFile(actorID, userID, func(user *entity.User) *string {
+		return &user.Photo
+	})
+}
+
+// RemoveCV removes the user's CV
+func (u *FileUseCase) RemoveCV(actorID, userID uint) error {
+	return u.removeUserFile(actorID, userID, func(user *entity.User) *string {
+		return &user.CV
+	})
+}
+
+// setUserFile stores the file and points the user's field at it. The file
+// it replaces is deleted afterwards.
+func (u *FileUseCase) setUserFile(actorID, userID uint, prefix string, data []byte, contentType, ext string, field func(*entity.User) *string) (*schemas.FileURLResponse, error) {
+	user, err := u.userForUpdate(actorID, userID)
+	if err != nil {
+		return nil, err
+	}
+	key, err := newFileKey(prefix, userID, ext)
+	if err != nil {
+		return nil, err
+	}
+	if err := u.storage.Put(key, bytes.NewReader(data), int64(len(data)), contentType); err != nil {
+		return nil, err
+	}
+
+	previous := *field(user)
+	*field(user) = key
+	if err := u.userRepo.UpdateUser(user); err != nil {
+		u.deleteStored(key)
+		return nil, err
+	}
+	u.deleteStored(previous)
+
+	expiresAt := time.Now().Add(signedURLDuration)
+	url, err := u.storage.SignedURL(key, signedURLDuration)
+	if err != nil {
+		return nil, err
+	}
+	return &schemas.FileURLResponse{URL: url, ExpiresAt: expiresAt}, nil
+}
+
+func (u *FileUseCase) removeUserFile(actorID, userID uint, field func(*entity.User) *string) error {
+	user, err := u.userForUpdate(actorID, userID)
+	if err != nil {
+		return err
+	}
+	previous := *field(user)
+	if previous == "" {
+		return nil
+	}
+	*field(user) = ""
+	if err := u.userRepo.UpdateUser(user); err != nil {
+		return err
+	}
+	u.deleteStored(previous)
+	return nil
+}
+
+// userForUpdate loads a user the actor may edit: themselves, or a user of a
+// group they manage
+func (u *FileUseCase) userForUpdate(actorID, userID uint) (*entity.User, error) {
+	user, err := u.userRepo.GetUserByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	if actorID != userID {
+		if err := u.scope.CheckGroupScope(actorID, user.GroupID); err != nil {
+			return nil, err
+		}
+	}
+	return user, nil
+}
+
+// URL returns a download URL for a stored photo or CV. Links saved before
+// uploads existed are returned as they are.
+func (u *FileUseCase) URL(stored string) string {
+	if stored == "" || isLink(stored) {
+		return stored
+	}
+	url, err := u.storage.SignedURL(stored, signedURLDuration)
+	if err != nil {
+		log.Printf("Failed to sign the URL of %s: %v", stored, err)
+		return ""
+	}
+	return url
+}
+
+// Open returns a file downloaded through a signed URL of the local storage,
+// with its content type. Other storages serve their signed URLs themselves.
+func (u *FileUseCase) Open(key, expires, signature string) (io.ReadCloser, string, error) {
+	verifier, ok := u.storage.(storage_services.URLVerifier)
+	if !ok {
+		return nil, "", storage_services.ErrNotFound
+	}
+	if err := verifier.Verify(key, expires, signature); err != nil {
+		return nil, "", err
+	}
+	file, err := u.storage.Open(key)
+	if err != nil {
+		return nil, "", err
+	}
+	contentType := mime.TypeByExtension(path.Ext(key))
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	return file, contentType, nil
+}
+
+// AddSessionResource uploads a file to the session's resources
+func (u *FileUseCase) AddSessionResource(actorID, sessionID uint, name string, r io.Reader) (*schemas.AttachmentResponse, error) {
+	if err := u.checkSessionScope(actorID, sessionID); err != nil {
+		return nil, err
+	}
+	return u.addAttachment(actorID, sessionResourceRule, "sessions", sessionID, name, r, func(attachment *entity.Attachment) {
+		attachment.SessionID = &sessionID
+	})
+}
+
+// ListSessionResources lists the files uploaded to the session's resources
+func (u *FileUseCase) ListSessionResources(sessionID uint) ([]*schemas.AttachmentResponse, error) {
+	if _, err := u.sessionRepo.GetSessionByID(sessionID); err != nil {
+		return nil, err
+	}
+	attachments, err := u.attachmentRepo.ListBySession(sessionID)
+	if err != nil {
+		return nil, err
+	}
+	return u.attachmentsToResponse(attachments), nil
+}
+
+// DeleteSessionResource removes a file from the session's resources
+func (u *FileUseCase) DeleteSessionResource(actorID, sessionID, attachmentID uint) error {
+	attachment, err := u.attachmentRepo.GetByID(attachmentID)
+	if err != nil {
+		return err
+	}
+	if attachment.SessionID == nil || *attachment.SessionID != sessionID {
+		return gorm.ErrRecordNotFound
+	}
+	if err := u.checkSessionScope(actorID, sessionID); err != nil {
+		return err
+	}
+	return u.deleteAttachment(attachment)
+}
+
+// AddPostAttachment attaches a file to a post of the actor
+func (u *FileUseCase) AddPostAttachment(actorID, postID uint, name string, r io.Reader) (*schemas.AttachmentResponse, error) {
+	if err := u.checkPostAuthor(actorID, postID); err != nil {
+		return nil, err
+	}
+	return u.addAttachment(actorID, postAttachmentRule, "posts", postID, name, r, func(attachment *entity.Attachment) {
+		attachment.PostID = &postID
+	})
+}
+
+// ListPostAttachments lists the files attached to the post
+func (u *FileUseCase) ListPostAttachments(postID uint) ([]*schemas.AttachmentResponse, error) {
+	if _, err := u.postRepo.GetPostByID(postID); err != nil {
+		return nil, err
+	}
+	attachments, err := u.attachmentRepo.ListByPost(postID)
+	if err != nil {
+		return nil, err
+	}
+	return u.attachmentsToResponse(attachments), nil
+}
+
+// DeletePostAttachment removes a file from a post of the actor
+func (u *FileUseCase) DeletePostAttachment(actorID, postID, attachmentID uint) error {
+	attachment, err := u.attachmentRepo.GetByID(attachmentID)
+	if err != nil {
+		return err
+	}
+	if attachment.PostID == nil || *attachment.PostID != postID {
+		return gorm.ErrRecordNotFound
+	}
+	if err := u.checkPostAuthor(actorID, postID); err != nil {
+		return err
+	}
+	return u.deleteAttachment(attachment)
+}
+
+func (u *FileUseCase) checkSessionScope(actorID, sessionID uint) error {
+	if _, err := u.sessionRepo.GetSessionByID(sessionID); err != nil {
+		return err
+	}
+	groupIDs, err := u.sessionRepo.GetSessionGroupIDs(sessionID)
+	if err != nil {
+		return err
+	}
+	return checkGroupsScope(u.scope, actorID, groupIDs)
+}
+
+func (u *FileUseCase) checkPostAuthor(actorID, postID uint) error {
+	post, err := u.postRepo.GetPostByID(postID)
+	if err != nil {
+		return err
+	}
+	if post.UserID != actorID {
+		return ErrNotPostAuthor
+	}
+	return nil
+}
+
+// addAttachment stores the file and records it; owner sets the session or
+// post it belongs to
+func (u *FileUseCase) addAttachment(actorID uint, rule uploadRule, prefix string, ownerID uint, name string, r io.Reader, owner func(*entity.Attachment)) (*schemas.AttachmentResponse, error) {
+	data, err := readUpload(r, rule)
+	if err != nil {
+		return nil, err
+	}
+	contentType, ext, err := sniff(rule, name, data)
+	if err != nil {
+		return nil, err
+	}
+	key, err := newFileKey(prefix, ownerID, ext)
+	if err != nil {
+		return nil, err
+	}
+	if err := u.storage.Put(key, bytes.NewReader(data), int64(len(data)), contentType); err != nil {
+		return nil, err
+	}
+
+	attachment := &entity.Attachment{
+		Key:          key,
+		Name:         attachmentName(name, ext),
+		ContentType:  contentType,
+		Size:         int64(len(data)),
+		UploadedByID: actorID,
+	}
+	owner(attachment)
+	if err := u.attachmentRepo.Create(attachment); err != nil {
+		u.deleteStored(key)
+		return nil, err
+	}
+	return u.attachmentToResponse(attachment), nil
+}
+
+func (u *FileUseCase) deleteAttachment(attachment *entity.Attachment) error {
+	if err := u.attachmentRepo.Delete(attachment.ID); err != nil {
+		return err
+	}
+	u.deleteStored(attachment.Key)
+	return nil
+}
+
+// deleteStored deletes a stored file, logging failures. Links are ignored.
+func (u *FileUseCase) deleteStored(stored string) {
+	if stored == "" || isLink(stored) {
+		return
+	}
+	if err := u.storage.Delete(stored); err != nil {
+		log.Printf("Failed to delete stored file %s: %v", stored, err)
+	}
+}
+
+func (u *FileUseCase) attachmentsToResponse(attachments []*entity.Attachment) []*schemas.AttachmentResponse {
+	responses := make([]*schemas.AttachmentResponse, 0, len(attachments))
+	for _, attachment := range attachments {
+		responses = append(responses, u.attachmentToResponse(attachment))
+	}
+	return responses
+}
+
+func (u *FileUseCase) attachmentToResponse(attachment *entity.Attachment) *schemas.AttachmentResponse {
+	return &schemas.AttachmentResponse{
+		ID:           attachment.ID,
+		Name:         attachment.Name,
+		ContentType:  attachment.ContentType,
+		Size:         attachment.Size,
+		UploadedByID: attachment.UploadedByID,
+		URL:          u.URL(attachment.Key),
+		CreatedAt:    attachment.CreatedAt,
+	}
+}
+
+// readUpload reads the whole file, enforcing the rule's size limit
+func readUpload(r io.Reader, rule uploadRule) ([]byte, error) {
+	data, err := io.ReadAll(io.LimitReader(r, rule.maxSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > rule.maxSize {
+		return nil, fmt.Errorf("%w: at most %d MB is accepted", ErrUploadTooLarge, rule.maxSize>>20)
+	}
+	if len(data) == 0 {
+		return nil, fmt.Errorf("%w: the file is empty", ErrInvalidUpload)
+	}
+	return data, nil
+}
+
+// sniff returns the content type and extension of an accepted file. The type
+// is detected from the contents; the file name only tells ZIP based office
+// documents apart.
+func sniff(rule uploadRule, name string, data []byte) (string, string, error) {
+	contentType, _, _ := mime.ParseMediaType(http.DetectContentType(data))
+	ext, ok := rule.types[contentType]
+	if !ok {
+		return "", "", fmt.Errorf("%w: %s files are not accepted", ErrInvalidUpload, contentType)
+	}
+	if contentType == "application/zip" {
+		nameExt := strings.ToLower(filepath.Ext(name))
+		if officeType, ok := officeTypes[nameExt]; ok {
+			return officeType, nameExt, nil
+		}
+	}
+	return contentType, ext, nil
+}
+
+// newFileKey returns a fresh key such as photos/42/<random>.jpg
+func newFileKey(prefix string, id uint, ext string) (string, error) {
+	name, err := token_services.GenerateConfirmationToken(24)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%s/%d/%s%s", prefix, id, name, ext), nil
+}
+
+// attachmentName keeps the base of the uploaded file name, or makes one up
+func attachmentName(name, ext string) string {
+	name = path.Base(strings.ReplaceAll(name, "\\", "/"))
+	if name == "." || name == "/" || !utf8.ValidString(name) {
+		name = "file" + ext
+	}
+	for len(name) > 255 {
+		_, size := utf8.DecodeLastRuneInString(name)
+		name = name[:len(name)-size]
+	}
+	return name
+}
+
+func isLink(stored string) bool {
+	return strings.HasPrefix(stored, "http://") || strings.HasPrefix(stored, "https://")
+}
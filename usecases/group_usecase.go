@@ -13,26 +13,35 @@ import (
 // PaginationMeta is returned for list endpoints
 // Conversion utilities are used internally
 type GroupUseCase interface {
-	Create(input *schemas.CreateGroupRequest) (*schemas.GroupResponse, error)
+	Create(actorID uint, input *schemas.CreateGroupRequest) (*schemas.GroupResponse, error)
 	GetByID(id uint) (*schemas.GroupResponse, error)
 	GetByName(name string) (*schemas.GroupResponse, error)
-	Update(id uint, input *schemas.UpdateGroupRequest) (*schemas.GroupResponse, error)
-	Delete(id uint) error
+	Update(actorID, id uint, input *schemas.UpdateGroupRequest) (*schemas.GroupResponse, error)
+	Delete(actorID, id uint) error
 	List() ([]*schemas.GroupResponse, *schemas.PaginationMeta, error)
 	GetByCountryID(countryID uint) ([]*schemas.GroupResponse, *schemas.PaginationMeta, error)
 }
 
+// groupUseCase restricts writes to the groups the actor manages. The HOA of
+// a group gets scope over it, so only actors with global scope may set it.
 type groupUseCase struct {
 	groupRepo repository.GroupRepository
+	scope     GroupScopeChecker
 }
 
-func NewGroupUseCase(groupRepo repository.GroupRepository) GroupUseCase {
+func NewGroupUseCase(groupRepo repository.GroupRepository, scope GroupScopeChecker) GroupUseCase {
 	return &groupUseCase{
 		groupRepo: groupRepo,
+		scope:     scope,
 	}
 }
 
-func (u *groupUseCase) Create(input *schemas.CreateGroupRequest) (*schemas.GroupResponse, error) {
+func (u *groupUseCase) Create(actorID uint, input *schemas.CreateGroupRequest) (*schemas.GroupResponse, error) {
+	if input.HOAID != nil {
+		if err := u.scope.CheckGlobalScope(actorID); err != nil {
+			return nil, err
+		}
+	}
 	group := &entity.Group{
 		Name:        input.Name,
 		ShortName:   derefString(input.ShortName),
@@ -65,11 +74,19 @@ func (u *groupUseCase) GetByName(name string) (*schemas.GroupResponse, error) {
 	return entityToGroupResponse(group), nil
 }
 
-func (u *groupUseCase) Update(id uint, input *schemas.UpdateGroupRequest) (*schemas.GroupResponse, error) {
+func (u *groupUseCase) Update(actorID, id uint, input *schemas.UpdateGroupRequest) (*schemas.GroupResponse, error) {
 	existing, err := u.groupRepo.GetByID(id)
 	if err != nil {
 		return nil, err
 	}
+	if err := u.scope.CheckGroupScope(actorID, &id); err != nil {
+		return nil, err
+	}
+	if input.HOAID != nil {
+		if err := u.scope.CheckGlobalScope(actorID); err != nil {
+			return nil, err
+		}
+	}
 	if input.Name != nil {
 		existing.Name = *input.Name
 	}
@@ -93,7 +110,10 @@ func (u *groupUseCase) Update(id uint, input *schemas.UpdateGroupRequest) (*sche
 	return entityToGroupResponse(updated), nil
 }
 
-func (u *groupUseCase) Delete(id uint) error {
+func (u *groupUseCase) Delete(actorID, id uint) error {
+	if err := u.scope.CheckGroupScope(actorID, &id); err != nil {
+		return err
+	}
 	return u.groupRepo.Delete(id)
 }
 
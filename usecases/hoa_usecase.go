@@ -0,0 +1,160 @@
+package usecases
+
+import (
+	"fmt"
+	"time"
+
+	"a2sv.org/hub/Domain/entity"
+	"a2sv.org/hub/Domain/repository"
+)
+
+// GroupScopeChecker restricts an actor to the groups they manage
+type GroupScopeChecker interface {
+	// CheckGroupScope returns ErrOutOfScope unless the actor manages the group.
+	// A nil group can only be managed by actors with global scope.
+	CheckGroupScope(actorID uint, groupID *uint) error
+	// CheckUserScope returns ErrOutOfScope unless the actor manages the user's group.
+	CheckUserScope(actorID uint, userID uint) error
+	// CheckGlobalScope returns ErrOutOfScope unless the actor has global scope
+	CheckGlobalScope(actorID uint) error
+}
+
+// HOAUseCase manages Heads of Academy and the group scope they grant
+type HOAUseCase struct {
+	hoaRepo            repository.HOARepository
+	userRepo           repository.UserRepository
+	rolePermissionRepo repository.RolePermissionRepository
+}
+
+func NewHOAUseCase(
+	hoaRepo repository.HOARepository,
+	userRepo repository.UserRepository,
+	rolePermissionRepo repository.RolePermissionRepository,
+) *HOAUseCase {
+	return &HOAUseCase{
+		hoaRepo:            hoaRepo,
+		userRepo:           userRepo,
+		rolePermissionRepo: rolePermissionRepo,
+	}
+}
+
+// AssignHOA makes the user a Head of Academy of the group. The actor must
+// manage the group.
+func (u *HOAUseCase) AssignHOA(actorID, groupID, userID uint) (*entity.HOA, error) {
+	if err := u.CheckGroupScope(actorID, &groupID); err != nil {
+		return nil, err
+	}
+	if _, err := u.userRepo.GetUserByID(userID); err != nil {
+		return nil, err
+	}
+	hoa := &entity.HOA{
+		GroupID:   groupID,
+		UserID:    userID,
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+	if err := u.hoaRepo.Create(hoa); err != nil {
+		return nil, err
+	}
+	return hoa, nil
+}
+
+// RemoveHOA removes the user from the group's Heads of Academy. The actor
+// must manage the group.
+func (u *HOAUseCase) RemoveHOA(actorID, groupID, userID uint) error {
+	if err := u.CheckGroupScope(actorID, &groupID); err != nil {
+		return err
+	}
+	return u.hoaRepo.Delete(groupID, userID)
+}
+
+// ListHOAs returns the Heads of Academy of a group
+func (u *HOAUseCase) ListHOAs(groupID uint) ([]*entity.HOA, error) {
+	return u.hoaRepo.GetByGroupID(groupID)
+}
+
+func (u *HOAUseCase) CheckGroupScope(actorID uint, groupID *uint) error {
+	global, err := u.hasGlobalScope(actorID)
+	if err != nil {
+		return err
+	}
+	if global {
+		return nil
+	}
+	if groupID == nil {
+		return ErrOutOfScope
+	}
+	managed, err := u.hoaRepo.GetManagedGroupIDs(actorID)
+	if err != nil {
+		return err
+	}
+	for _, id := range managed {
+		if id == *groupID {
+			return nil
+		}
+	}
+	return ErrOutOfScope
+}
+
+func (u *HOAUseCase) CheckUserScope(actorID uint, userID uint) error {
+	user, err := u.userRepo.GetUserByID(userID)
+	if err != nil {
+		return err
+	}
+	return u.CheckGroupScope(actorID, user.GroupID)
+}
+
+func (u *HOAUseCase) CheckGlobalScope(actorID uint) error {
+	global, err := u.hasGlobalScope(actorID)
+	if err != nil {
+		return err
+	}
+	if !global {
+		return ErrOutOfScope
+	}
+	return nil
+}
+
+func (u *HOAUseCase) hasGlobalScope(actorID uint) (bool, error) {
+	actor, err := u.userRepo.GetUserByID(actorID)
+	if err != nil {
+		return false, err
+	}
+	return u.rolePermissionRepo.HasPermission(actor.RoleID, entity.PermissionScopeAll)
+}
+
+// checkRoleGrant returns ErrRoleEscalation unless the actor holds every
+// permission of the role
+func checkRoleGrant(userRepo repository.UserRepository, rolePermissionRepo repository.RolePermissionRepository, actorID, roleID uint) error {
+	permissions, err := rolePermissionRepo.ListPermissionsByRoleID(roleID)
+	if err != nil {
+		return err
+	}
+	names := make([]string, 0, len(permissions))
+	for _, p := range permissions {
+		names = append(names, p.Permission)
+	}
+	return checkPermissionGrant(userRepo, rolePermissionRepo, actorID, names)
+}
+
+// checkPermissionGrant returns ErrRoleEscalation unless the actor holds every
+// one of the permissions; * and scope:all count like any other permission
+func checkPermissionGrant(userRepo repository.UserRepository, rolePermissionRepo repository.RolePermissionRepository, actorID uint, permissions []string) error {
+	actor, err := userRepo.GetUserByID(actorID)
+	if err != nil {
+		return err
+	}
+	if all, err := rolePermissionRepo.HasPermission(actor.RoleID, entity.PermissionAll); err != nil || all {
+		return err
+	}
+	for _, permission := range permissions {
+		held, err := rolePermissionRepo.HasPermission(actor.RoleID, permission)
+		if err != nil {
+			return err
+		}
+		if !held {
+			return fmt.Errorf("%w: %s", ErrRoleEscalation, permission)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,136 @@
+package usecases
+
+import (
+	"errors"
+	"time"
+
+	"a2sv.org/hub/Delivery/http/schemas"
+	"a2sv.org/hub/Domain/entity"
+	"a2sv.org/hub/Domain/repository"
+	"a2sv.org/hub/infrastructure/token_services"
+	"gorm.io/gorm"
+)
+
+// ImpersonationDuration is the lifetime of an impersonation token; it cannot be refreshed
+const ImpersonationDuration = 15 * time.Minute
+
+// ImpersonationUseCase lets admins act as another user with a read-only token
+type ImpersonationUseCase struct {
+	impersonationRepo  repository.ImpersonationRepository
+	userRepo           repository.UserRepository
+	rolePermissionRepo repository.RolePermissionRepository
+	scope              GroupScopeChecker
+	tokens             *TokenUseCase
+}
+
+func NewImpersonationUseCase(
+	impersonationRepo repository.ImpersonationRepository,
+	userRepo repository.UserRepository,
+	rolePermissionRepo repository.RolePermissionRepository,
+	scope GroupScopeChecker,
+	tokens *TokenUseCase,
+) *ImpersonationUseCase {
+	return &ImpersonationUseCase{
+		impersonationRepo:  impersonationRepo,
+		userRepo:           userRepo,
+		rolePermissionRepo: rolePermissionRepo,
+		scope:              scope,
+		tokens:             tokens,
+	}
+}
+
+// Start mints a token acting as the user on behalf of the actor. The user
+// must be in the actor's scope and hold no permission the actor lacks.
+func (u *ImpersonationUseCase) Start(actorID, userID uint, reason, ip string) (*schemas.ImpersonationResponse, error) {
+	if actorID == userID {
+		return nil, ErrImpersonateSelf
+	}
+	user, err := u.userRepo.GetUserByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.Inactive {
+		return nil, ErrAccountInactive
+	}
+	if err := u.scope.CheckGroupScope(actorID, user.GroupID); err != nil {
+		return nil, err
+	}
+	if err := checkRoleGrant(u.userRepo, u.rolePermissionRepo, actorID, user.RoleID); err != nil {
+		return nil, err
+	}
+
+	token, tokenID, err := token_services.CreateImpersonationJWTToken(user, actorID, u.tokens.keys, ImpersonationDuration)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	impersonation := &entity.Impersonation{
+		ActorID:   actorID,
+		UserID:    userID,
+		Reason:    reason,
+		TokenID:   tokenID,
+		IP:        ip,
+		StartedAt: now,
+		ExpiresAt: now.Add(ImpersonationDuration),
+	}
+	if err := u.impersonationRepo.Create(impersonation); err != nil {
+		return nil, err
+	}
+
+	return &schemas.ImpersonationResponse{
+		ImpersonationID: impersonation.ID,
+		Token:           token,
+		ExpiresIn:       int(ImpersonationDuration.Seconds()),
+		UserID:          userID,
+	}, nil
+}
+
+// End records the end of the impersonation and revokes its token
+func (u *ImpersonationUseCase) End(claims *entity.Claims) error {
+	if claims.ImpersonatorID == 0 {
+		return ErrNotImpersonating
+	}
+	err := u.impersonationRepo.End(claims.Id, time.Now())
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+	return u.tokens.RevokeAccessToken(claims)
+}
+
+// List returns impersonations, newest first
+func (u *ImpersonationUseCase) List(query *schemas.ImpersonationQuery) (*schemas.ImpersonationListResponse, error) {
+	if query.Page < 1 {
+		query.Page = 1
+	}
+	if query.PageSize < 1 || query.PageSize > 100 {
+		query.PageSize = 20
+	}
+
+	impersonations, total, err := u.impersonationRepo.List(query.ActorID, query.UserID, (query.Page-1)*query.PageSize, query.PageSize)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]*schemas.ImpersonationRecordResponse, 0, len(impersonations))
+	for _, i := range impersonations {
+		responses = append(responses, &schemas.ImpersonationRecordResponse{
+			ID:        i.ID,
+			ActorID:   i.ActorID,
+			UserID:    i.UserID,
+			Reason:    i.Reason,
+			IP:        i.IP,
+			StartedAt: i.StartedAt,
+			ExpiresAt: i.ExpiresAt,
+			EndedAt:   i.EndedAt,
+		})
+	}
+	return &schemas.ImpersonationListResponse{
+		Data: responses,
+		Meta: schemas.PaginationMeta{
+			Total:      int(total),
+			Page:       query.Page,
+			PageSize:   query.PageSize,
+			TotalPages: int((total + int64(query.PageSize) - 1) / int64(query.PageSize)),
+		},
+	}, nil
+}
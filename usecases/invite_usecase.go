@@ -0,0 +1,304 @@
+package usecases
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"a2sv.org/hub/Delivery/http/schemas"
+	"a2sv.org/hub/Domain/entity"
+	"a2sv.org/hub/Domain/repository"
+	"a2sv.org/hub/infrastructure/email_services"
+	"a2sv.org/hub/infrastructure/password_services"
+	"a2sv.org/hub/infrastructure/token_services"
+	"gorm.io/gorm"
+)
+
+// DefaultInviteDuration is how long an invite stays valid when no expiry is given
+const DefaultInviteDuration = 7 * 24 * time.Hour
+
+// defaultInviteURL is used when INVITE_URL is not set
+const defaultInviteURL = "https://yene-hub-ls0y.onrender.com/invite"
+
+// InviteUseCase manages invite links and onboarding through them
+type InviteUseCase struct {
+	inviteRepo         repository.InviteRepository
+	userRepo           repository.UserRepository
+	roleRepo           repository.RoleRepository
+	groupRepo          repository.GroupRepository
+	rolePermissionRepo repository.RolePermissionRepository
+	scope              GroupScopeChecker
+	tokens             *TokenUseCase
+	twoFactor          *TwoFactorUseCase
+	audit              *AuditUseCase
+}
+
+func NewInviteUseCase(
+	inviteRepo repository.InviteRepository,
+	userRepo repository.UserRepository,
+	roleRepo repository.RoleRepository,
+	groupRepo repository.GroupRepository,
+	rolePermissionRepo repository.RolePermissionRepository,
+	scope GroupScopeChecker,
+	tokens *TokenUseCase,
+	twoFactor *TwoFactorUseCase,
+	audit *AuditUseCase,
+) *InviteUseCase {
+	return &InviteUseCase{
+		inviteRepo:         inviteRepo,
+		userRepo:           userRepo,
+		roleRepo:           roleRepo,
+		groupRepo:          groupRepo,
+		rolePermissionRepo: rolePermissionRepo,
+		scope:              scope,
+		tokens:             tokens,
+		twoFactor:          twoFactor,
+		audit:              audit,
+	}
+}
+
+// Create makes an invite link for a group the actor manages
+func (u *InviteUseCase) Create(actorID uint, input *schemas.CreateInviteRequest) (*schemas.CreateInviteResponse, error) {
+	expiresAt, err := u.validateInvite(actorID, input.RoleID, input.GroupID, input.ExpiresAt)
+	if err != nil {
+		return nil, err
+	}
+	maxUses := 1
+	if input.MaxUses != nil {
+		maxUses = *input.MaxUses
+	}
+	return u.createInvite(actorID, input.RoleID, input.GroupID, nil, maxUses, expiresAt)
+}
+
+// EmailInvites sends a single-use invite to each address of a comma-separated list
+func (u *InviteUseCase) EmailInvites(actorID uint, input *schemas.EmailInvitesRequest) ([]RegistrationResult, error) {
+	expiresAt, err := u.validateInvite(actorID, input.RoleID, input.GroupID, input.ExpiresAt)
+	if err != nil {
+		return nil, err
+	}
+	group, err := u.groupRepo.GetByID(input.GroupID)
+	if err != nil {
+		return nil, err
+	}
+
+	var results []RegistrationResult
+	for _, email := range strings.Split(input.Emails, ",") {
+		email = strings.TrimSpace(email)
+		if email == "" {
+			continue
+		}
+		result := RegistrationResult{Email: email}
+
+		if _, err := u.userRepo.GetUserByEmail(email); err == nil {
+			result.Message = "Email already registered"
+			results = append(results, result)
+			continue
+		}
+		if err := checkDeletedUserEmail(u.userRepo, email); err != nil {
+			result.Message = err.Error()
+			results = append(results, result)
+			continue
+		}
+
+		invite, err := u.createInvite(actorID, input.RoleID, input.GroupID, &email, 1, expiresAt)
+		if err != nil {
+			result.Message = fmt.Sprintf("Failed to create invite: %v", err)
+			results = append(results, result)
+			continue
+		}
+
+		body := fmt.Sprintf("You have been invited to join %s on A2SV Hub.\n\nThe invite expires on %s.", group.Name, expiresAt.Format("2 Jan 2006"))
+		result.Success = true
+		result.Message = "Invite sent successfully"
+		if err := email_services.SendEmail(email, "You're invited to A2SV Hub", body, invite.Link); err != nil {
+			result.Message = fmt.Sprintf("Invite created but email failed: %v", err)
+		}
+		results = append(results, result)
+		log.Printf("Created invite %d for %s", invite.ID, email)
+	}
+	return results, nil
+}
+
+// List returns the invites of a group the actor manages
+func (u *InviteUseCase) List(actorID, groupID uint) ([]*schemas.InviteResponse, error) {
+	if err := u.scope.CheckGroupScope(actorID, &groupID); err != nil {
+		return nil, err
+	}
+	invites, err := u.inviteRepo.ListByGroupID(groupID)
+	if err != nil {
+		return nil, err
+	}
+	responses := make([]*schemas.InviteResponse, 0, len(invites))
+	for _, invite := range invites {
+		responses = append(responses, entityToInviteResponse(invite))
+	}
+	return responses, nil
+}
+
+// Revoke stops an invite from being redeemed
+func (u *InviteUseCase) Revoke(actorID, id uint) error {
+	invite, err := u.inviteRepo.GetByID(id)
+	if err != nil {
+		return err
+	}
+	if err := u.scope.CheckGroupScope(actorID, invite.GroupID); err != nil {
+		return err
+	}
+	return u.inviteRepo.Revoke(id, time.Now())
+}
+
+// Redeem creates the invitee's account in the invite's group and role and signs them in
+func (u *InviteUseCase) Redeem(input *schemas.RedeemInviteRequest, client LoginClient) (*schemas.TokenPairResponse, error) {
+	invite, err := u.inviteRepo.GetByKey(token_services.HashToken(input.Key))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInvalidInvite
+		}
+		return nil, err
+	}
+	now := time.Now()
+	if invite.RevokedAt != nil || invite.Used || now.After(invite.ExpiresAt) {
+		return nil, ErrInvalidInvite
+	}
+	if invite.Email != nil && !strings.EqualFold(*invite.Email, input.Email) {
+		return nil, fmt.Errorf("%w: it was sent to a different email", ErrInvalidInvite)
+	}
+	if _, err := u.userRepo.GetUserByEmail(input.Email); err == nil {
+		return nil, ErrEmailAlreadyRegistered
+	}
+	// The invitee only learns that an admin has to restore their old account
+	if err := checkDeletedUserEmail(u.userRepo, input.Email); errors.Is(err, ErrEmailOfDeletedUser) {
+		return nil, ErrEmailOfDeletedUser
+	} else if err != nil {
+		return nil, err
+	}
+
+	hashedPassword, err := password_services.HashPassword(input.Password)
+	if err != nil {
+		return nil, err
+	}
+	user := &entity.User{
+		Name:      input.Name,
+		Email:     input.Email,
+		Password:  hashedPassword,
+		RoleID:    invite.RoleID,
+		GroupID:   invite.GroupID,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if invite.GroupID != nil {
+		if group, err := u.groupRepo.GetByID(*invite.GroupID); err == nil {
+			user.CountryID = group.CountryID
+		}
+	}
+
+	if err := u.inviteRepo.ConsumeUse(invite.ID, now); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInvalidInvite
+		}
+		return nil, err
+	}
+	if err := u.userRepo.CreateUser(user); err != nil {
+		_ = u.inviteRepo.ReleaseUse(invite.ID)
+		return nil, err
+	}
+	u.audit.Created(AuditEntry{
+		ActorID:    &user.ID,
+		Method:     http.MethodPost,
+		Route:      auditRouteRedeemInvite,
+		EntityType: "users",
+		IP:         client.IP,
+	}, user.ID)
+
+	// A role that requires 2FA enrolls before its first session
+	challenge, err := u.twoFactor.BeginLogin(user, entity.LoginMethodInvite)
+	if err != nil {
+		return nil, err
+	}
+	if challenge != nil {
+		return &schemas.TokenPairResponse{TwoFactor: challenge}, nil
+	}
+	return u.tokens.IssueTokens(user, client)
+}
+
+// validateInvite checks the target group and role and returns the expiry to use
+func (u *InviteUseCase) validateInvite(actorID, roleID, groupID uint, expiresAt *time.Time) (time.Time, error) {
+	if err := u.scope.CheckGroupScope(actorID, &groupID); err != nil {
+		return time.Time{}, err
+	}
+	if _, err := u.groupRepo.GetByID(groupID); err != nil {
+		return time.Time{}, fmt.Errorf("%w: invalid group ID", ErrInvalidInvite)
+	}
+	if _, err := u.roleRepo.GetRoleByID(roleID); err != nil {
+		return time.Time{}, fmt.Errorf("%w: invalid role ID", ErrInvalidInvite)
+	}
+	if err := u.checkRoleGrant(actorID, roleID); err != nil {
+		return time.Time{}, err
+	}
+
+	if expiresAt == nil {
+		return time.Now().Add(DefaultInviteDuration), nil
+	}
+	if !expiresAt.After(time.Now()) {
+		return time.Time{}, fmt.Errorf("%w: expires_at must be in the future", ErrInvalidInvite)
+	}
+	return *expiresAt, nil
+}
+
+// checkRoleGrant keeps actors from inviting people into a role more powerful than their own
+func (u *InviteUseCase) checkRoleGrant(actorID, roleID uint) error {
+	return checkRoleGrant(u.userRepo, u.rolePermissionRepo, actorID, roleID)
+}
+
+func (u *InviteUseCase) createInvite(actorID, roleID, groupID uint, email *string, maxUses int, expiresAt time.Time) (*schemas.CreateInviteResponse, error) {
+	key, err := token_services.GenerateConfirmationToken(32)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	invite := &entity.Invite{
+		Key:       token_services.HashToken(key),
+		RoleID:    roleID,
+		UserID:    &actorID,
+		GroupID:   &groupID,
+		Email:     email,
+		MaxUses:   maxUses,
+		ExpiresAt: expiresAt,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if err := u.inviteRepo.Create(invite); err != nil {
+		return nil, err
+	}
+
+	baseURL := os.Getenv("INVITE_URL")
+	if baseURL == "" {
+		baseURL = defaultInviteURL
+	}
+	return &schemas.CreateInviteResponse{
+		InviteResponse: *entityToInviteResponse(invite),
+		Key:            key,
+		Link:           baseURL + "?key=" + url.QueryEscape(key),
+	}, nil
+}
+
+func entityToInviteResponse(invite *entity.Invite) *schemas.InviteResponse {
+	return &schemas.InviteResponse{
+		ID:        invite.ID,
+		RoleID:    invite.RoleID,
+		GroupID:   invite.GroupID,
+		CreatedBy: invite.UserID,
+		Email:     invite.Email,
+		MaxUses:   invite.MaxUses,
+		UseCount:  invite.UseCount,
+		Used:      invite.Used,
+		ExpiresAt: invite.ExpiresAt,
+		RevokedAt: invite.RevokedAt,
+		CreatedAt: invite.CreatedAt,
+	}
+}
@@ -0,0 +1,174 @@
+package usecases
+
+import (
+	"log"
+	"strings"
+	"time"
+
+	"a2sv.org/hub/Delivery/http/schemas"
+	"a2sv.org/hub/Domain/entity"
+	"a2sv.org/hub/Domain/repository"
+	"a2sv.org/hub/infrastructure/email_services"
+	"a2sv.org/hub/infrastructure/ip_services"
+)
+
+// LoginClient describes where a login attempt came from
+type LoginClient struct {
+	IP     string
+	Device string // User-Agent
+}
+
+// LoginHistoryUseCase records login attempts and alerts users about logins from new places
+type LoginHistoryUseCase struct {
+	loginEventRepo repository.LoginEventRepository
+	locator        ip_services.GeoLocator
+}
+
+func NewLoginHistoryUseCase(loginEventRepo repository.LoginEventRepository, locator ip_services.GeoLocator) *LoginHistoryUseCase {
+	return &LoginHistoryUseCase{
+		loginEventRepo: loginEventRepo,
+		locator:        locator,
+	}
+}
+
+// RecordSuccess records a successful login in the background. The user is
+// emailed when the device or country was never seen in an earlier login.
+func (u *LoginHistoryUseCase) RecordSuccess(user *entity.User, method string, client LoginClient) {
+	go func() {
+		if err := u.recordSuccess(user, method, client); err != nil {
+			log.Printf("Failed to record login of user %d: %v", user.ID, err)
+		}
+	}()
+}
+
+// RecordFailure records a failed login in the background. user is nil when
+// the email matched no account.
+func (u *LoginHistoryUseCase) RecordFailure(user *entity.User, email, method, reason string, client LoginClient) {
+	event := u.newEvent(email, method, client)
+	if user != nil {
+		event.UserID = &user.ID
+	}
+	event.FailureReason = reason
+	go func() {
+		u.locate(event)
+		if err := u.loginEventRepo.Create(event); err != nil {
+			log.Printf("Failed to record failed login for %s: %v", email, err)
+		}
+	}()
+}
+
+// List returns the user's login history, newest first
+func (u *LoginHistoryUseCase) List(userID uint, query *schemas.LoginHistoryQuery) (*schemas.LoginHistoryResponse, error) {
+	if query.Page < 1 {
+		query.Page = 1
+	}
+	if query.PageSize < 1 || query.PageSize > 100 {
+		query.PageSize = 20
+	}
+
+	events, total, err := u.loginEventRepo.ListByUserID(userID, (query.Page-1)*query.PageSize, query.PageSize)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]*schemas.LoginEventResponse, 0, len(events))
+	for _, e := range events {
+		responses = append(responses, &schemas.LoginEventResponse{
+			ID:            e.ID,
+			Method:        e.Method,
+			Success:       e.Success,
+			FailureReason: e.FailureReason,
+			IP:            e.IP,
+			Device:        e.Device,
+			City:          e.City,
+			Region:        e.Region,
+			Country:       e.Country,
+			CreatedAt:     e.CreatedAt,
+		})
+	}
+	return &schemas.LoginHistoryResponse{
+		Data: responses,
+		Meta: schemas.PaginationMeta{
+			Total:      int(total),
+			Page:       query.Page,
+			PageSize:   query.PageSize,
+			TotalPages: int((total + int64(query.PageSize) - 1) / int64(query.PageSize)),
+		},
+	}, nil
+}
+
+func (u *LoginHistoryUseCase) recordSuccess(user *entity.User, method string, client LoginClient) error {
+	event := u.newEvent(user.Email, method, client)
+	event.UserID = &user.ID
+	event.Success = true
+	u.locate(event)
+
+	// The first login has nothing to compare against
+	seenBefore, err := u.loginEventRepo.HasSuccessfulLogin(user.ID)
+	if err != nil {
+		return err
+	}
+	newDevice, newCountry := false, false
+	if seenBefore {
+		seen, err := u.loginEventRepo.HasSeenDevice(user.ID, event.Device)
+		if err != nil {
+			return err
+		}
+		newDevice = !seen
+		if event.Country != "" {
+			seen, err := u.loginEventRepo.HasSeenCountry(user.ID, event.Country)
+			if err != nil {
+				return err
+			}
+			newCountry = !seen
+		}
+	}
+
+	if err := u.loginEventRepo.Create(event); err != nil {
+		return err
+	}
+
+	if newDevice || newCountry {
+		return email_services.SendLoginAlertEmail(user.Email, event.IP, event.Device, formatLocation(event), passwordResetURL())
+	}
+	return nil
+}
+
+func (u *LoginHistoryUseCase) newEvent(email, method string, client LoginClient) *entity.LoginEvent {
+	device := client.Device
+	if len(device) > 512 {
+		device = device[:512]
+	}
+	return &entity.LoginEvent{
+		Email:     email,
+		Method:    method,
+		IP:        client.IP,
+		Device:    device,
+		CreatedAt: time.Now(),
+	}
+}
+
+// locate fills in the event's location; a failed lookup leaves it empty
+func (u *LoginHistoryUseCase) locate(event *entity.LoginEvent) {
+	info, err := u.locator.Locate(event.IP)
+	if err != nil {
+		log.Printf("Failed to locate IP %s: %v", event.IP, err)
+		return
+	}
+	event.City = info.City
+	event.Region = info.Region
+	event.Country = info.Country
+}
+
+func formatLocation(event *entity.LoginEvent) string {
+	var parts []string
+	for _, part := range []string{event.City, event.Region, event.Country} {
+		if part != "" {
+			parts = append(parts, part)
+		}
+	}
+	if len(parts) == 0 {
+		return "Unknown location"
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,246 @@
+package usecases
+
+import (
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"a2sv.org/hub/Delivery/http/schemas"
+	"a2sv.org/hub/Domain/entity"
+	"a2sv.org/hub/Domain/repository"
+	"a2sv.org/hub/infrastructure/oauth"
+	"a2sv.org/hub/infrastructure/token_services"
+	"gorm.io/gorm"
+)
+
+// OAuthStateDuration is how long a user has to finish signing in with the provider
+const OAuthStateDuration = 10 * time.Minute
+
+// oauthLinkPrefix starts the nonce of a flow linking a provider account to a
+// signed-in user, followed by the user's ID. The nonce is signed into the
+// state, so the callback cannot be pointed at another user.
+const oauthLinkPrefix = "link-"
+
+// OAuthFlow is a started sign-in. The nonce and code verifier must be kept by
+// the browser (not in the URL) and handed back on the callback.
+type OAuthFlow struct {
+	AuthURL      string
+	Nonce        string
+	CodeVerifier string
+}
+
+// OAuthUseCase signs users in with OAuth providers and links the provider
+// account to the user
+type OAuthUseCase struct {
+	userRepo         repository.UserRepository
+	oauthAccountRepo repository.OAuthAccountRepository
+	tokens           *TokenUseCase
+	history          *LoginHistoryUseCase
+	twoFactor        *TwoFactorUseCase
+	providers        map[string]oauth.Provider
+	stateSecret      string
+}
+
+func NewOAuthUseCase(
+	userRepo repository.UserRepository,
+	oauthAccountRepo repository.OAuthAccountRepository,
+	tokens *TokenUseCase,
+	history *LoginHistoryUseCase,
+	twoFactor *TwoFactorUseCase,
+	providers []oauth.Provider,
+	stateSecret string,
+) *OAuthUseCase {
+	byName := make(map[string]oauth.Provider, len(providers))
+	for _, p := range providers {
+		byName[p.Name()] = p
+	}
+	return &OAuthUseCase{
+		userRepo:         userRepo,
+		oauthAccountRepo: oauthAccountRepo,
+		tokens:           tokens,
+		history:          history,
+		twoFactor:        twoFactor,
+		providers:        byName,
+		stateSecret:      stateSecret,
+	}
+}
+
+// StartLogin creates the signed state and PKCE verifier for a new sign-in with the provider
+func (u *OAuthUseCase) StartLogin(providerName string) (*OAuthFlow, error) {
+	return u.startFlow(providerName, "")
+}
+
+// StartLink starts linking an account of the provider to a signed-in user.
+// The callback links the account the user signs in to at the provider,
+// whatever its email, so accounts that do not match by verified email can
+// still be linked.
+func (u *OAuthUseCase) StartLink(userID uint, providerName string) (*OAuthFlow, error) {
+	if _, ok := u.providers[providerName]; !ok {
+		return nil, ErrUnknownOAuthProvider
+	}
+	if _, err := u.oauthAccountRepo.GetByUserID(userID, providerName); err == nil {
+		return nil, ErrOAuthAccountConflict
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+	return u.startFlow(providerName, fmt.Sprintf("%s%d-", oauthLinkPrefix, userID))
+}
+
+func (u *OAuthUseCase) startFlow(providerName, noncePrefix string) (*OAuthFlow, error) {
+	provider, ok := u.providers[providerName]
+	if !ok {
+		return nil, ErrUnknownOAuthProvider
+	}
+	nonce, err := token_services.GenerateConfirmationToken(32)
+	if err != nil {
+		return nil, err
+	}
+	nonce = noncePrefix + nonce
+	verifier, err := oauth.NewCodeVerifier()
+	if err != nil {
+		return nil, err
+	}
+	state := oauth.SignState(u.stateSecret, nonce, time.Now().Add(OAuthStateDuration))
+	return &OAuthFlow{
+		AuthURL:      provider.AuthCodeURL(state, oauth.CodeChallenge(verifier)),
+		Nonce:        nonce,
+		CodeVerifier: verifier,
+	}, nil
+}
+
+// CompleteLogin checks the state against the browser's nonce, redeems the
+// code with the PKCE verifier and signs in the linked user. The first sign-in
+// links the provider account to the registered user with the same verified
+// email. A flow started by StartLink links the account to its user instead and
+// returns no token pair.
+func (u *OAuthUseCase) CompleteLogin(providerName, code, state, nonce, codeVerifier string, client LoginClient) (*schemas.TokenPairResponse, error) {
+	provider, ok := u.providers[providerName]
+	if !ok {
+		return nil, ErrUnknownOAuthProvider
+	}
+	stateNonce, err := oauth.VerifyState(u.stateSecret, state)
+	if err != nil {
+		return nil, ErrInvalidOAuthState
+	}
+	if nonce == "" || subtle.ConstantTimeCompare([]byte(stateNonce), []byte(nonce)) != 1 {
+		return nil, ErrInvalidOAuthState
+	}
+
+	token, err := provider.ExchangeCodeForToken(code, codeVerifier)
+	if err != nil {
+		return nil, err
+	}
+	info, err := provider.GetUserInfo(token.AccessToken)
+	if err != nil {
+		return nil, err
+	}
+	if userID, ok := linkTarget(stateNonce); ok {
+		return nil, u.link(providerName, userID, info)
+	}
+
+	user, err := u.linkedUser(providerName, info)
+	if err != nil {
+		if errors.Is(err, ErrOAuthEmailNotVerified) || errors.Is(err, ErrOAuthUserNotRegistered) || errors.Is(err, ErrOAuthAccountConflict) {
+			u.history.RecordFailure(nil, info.Email, providerName, err.Error(), client)
+		}
+		return nil, err
+	}
+
+	challenge, err := u.twoFactor.BeginLogin(user, providerName)
+	if err != nil {
+		return nil, err
+	}
+	if challenge != nil {
+		return &schemas.TokenPairResponse{TwoFactor: challenge}, nil
+	}
+	tokens, err := u.tokens.IssueTokens(user, client)
+	if err != nil {
+		return nil, err
+	}
+	u.history.RecordSuccess(user, providerName, client)
+	return tokens, nil
+}
+
+// linkedUser resolves the provider account to a user, linking it on first use
+func (u *OAuthUseCase) linkedUser(providerName string, info *oauth.UserInfo) (*entity.User, error) {
+	account, err := u.oauthAccountRepo.GetBySubject(providerName, info.Subject)
+	if err == nil {
+		return u.userRepo.GetUserByID(account.UserID)
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	user, err := u.matchUser(info)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := u.createAccount(providerName, user.ID, info); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// matchUser finds the registered user of an unlinked provider account by its
+// verified email. Profile fields such as the GitHub handle are typed in by
+// users and prove nothing, so other accounts are linked with StartLink.
+func (u *OAuthUseCase) matchUser(info *oauth.UserInfo) (*entity.User, error) {
+	if !info.EmailVerified || info.Email == "" {
+		return nil, ErrOAuthEmailNotVerified
+	}
+	user, err := u.userRepo.GetUserByEmail(info.Email)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrOAuthUserNotRegistered
+	}
+	return user, err
+}
+
+// link links the provider account to the user who started the flow
+func (u *OAuthUseCase) link(providerName string, userID uint, info *oauth.UserInfo) error {
+	account, err := u.oauthAccountRepo.GetBySubject(providerName, info.Subject)
+	if err == nil {
+		if account.UserID == userID {
+			return nil
+		}
+		return ErrOAuthAccountTaken
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+	return u.createAccount(providerName, userID, info)
+}
+
+// createAccount links the provider account to a user who has none of the provider
+func (u *OAuthUseCase) createAccount(providerName string, userID uint, info *oauth.UserInfo) error {
+	if _, err := u.oauthAccountRepo.GetByUserID(userID, providerName); err == nil {
+		return ErrOAuthAccountConflict
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+	return u.oauthAccountRepo.Create(&entity.OAuthAccount{
+		UserID:    userID,
+		Provider:  providerName,
+		Subject:   info.Subject,
+		Email:     info.Email,
+		Username:  info.GitHubLogin,
+		CreatedAt: time.Now(),
+	})
+}
+
+// linkTarget returns the user of a nonce made by StartLink
+func linkTarget(nonce string) (uint, bool) {
+	rest, ok := strings.CutPrefix(nonce, oauthLinkPrefix)
+	if !ok {
+		return 0, false
+	}
+	id, _, _ := strings.Cut(rest, "-")
+	userID, err := strconv.ParseUint(id, 10, 64)
+	if err != nil {
+		return 0, false
+	}
+	return uint(userID), true
+}
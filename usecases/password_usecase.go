@@ -0,0 +1,158 @@
+package usecases
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"time"
+
+	"a2sv.org/hub/Delivery/http/schemas"
+	"a2sv.org/hub/Domain/entity"
+	"a2sv.org/hub/Domain/repository"
+	"a2sv.org/hub/infrastructure/email_services"
+	"a2sv.org/hub/infrastructure/password_services"
+	"a2sv.org/hub/infrastructure/token_services"
+	"gorm.io/gorm"
+)
+
+// PasswordResetTokenDuration is how long a reset link stays valid
+const PasswordResetTokenDuration = time.Hour
+
+// defaultPasswordResetURL is used when PASSWORD_RESET_URL is not set
+const defaultPasswordResetURL = "https://yene-hub-ls0y.onrender.com/reset-password"
+
+// PasswordUseCase handles forgotten, reset and changed passwords
+type PasswordUseCase struct {
+	userRepo  repository.UserRepository
+	resetRepo repository.PasswordResetTokenRepository
+	tokens    *TokenUseCase
+	audit     *AuditUseCase
+}
+
+func NewPasswordUseCase(
+	userRepo repository.UserRepository,
+	resetRepo repository.PasswordResetTokenRepository,
+	tokens *TokenUseCase,
+	audit *AuditUseCase,
+) *PasswordUseCase {
+	return &PasswordUseCase{
+		userRepo:  userRepo,
+		resetRepo: resetRepo,
+		tokens:    tokens,
+		audit:     audit,
+	}
+}
+
+// ForgotPassword emails a reset link to the user. Unknown emails are ignored
+// so the endpoint does not reveal which addresses have an account.
+func (u *PasswordUseCase) ForgotPassword(email string) error {
+	user, err := u.userRepo.GetUserByEmail(email)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return err
+	}
+
+	token, err := token_services.GenerateConfirmationToken(48)
+	if err != nil {
+		return err
+	}
+
+	// Only the latest link works
+	now := time.Now()
+	if err := u.resetRepo.InvalidateByUserID(user.ID, now); err != nil {
+		return err
+	}
+	if err := u.resetRepo.Create(&entity.PasswordResetToken{
+		UserID:    user.ID,
+		TokenHash: token_services.HashToken(token),
+		ExpiresAt: now.Add(PasswordResetTokenDuration),
+		CreatedAt: now,
+	}); err != nil {
+		return err
+	}
+
+	link := passwordResetURL() + "?token=" + url.QueryEscape(token)
+	body := fmt.Sprintf("A password reset was requested for your A2SV Hub account.\n\nThe link expires in %d minutes and can only be used once. If you did not request it, you can ignore this email.", int(PasswordResetTokenDuration.Minutes()))
+	return email_services.SendEmail(user.Email, "Reset your A2SV Hub password", body, link)
+}
+
+// ResetPassword sets a new password with a reset token and signs the user out everywhere
+func (u *PasswordUseCase) ResetPassword(token, newPassword string) error {
+	stored, err := u.resetRepo.GetByHash(token_services.HashToken(token))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrInvalidResetToken
+		}
+		return err
+	}
+	now := time.Now()
+	if stored.UsedAt != nil || now.After(stored.ExpiresAt) {
+		return ErrInvalidResetToken
+	}
+	if err := u.resetRepo.MarkUsed(stored.ID, now); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrInvalidResetToken
+		}
+		return err
+	}
+
+	user, err := u.userRepo.GetUserByID(stored.UserID)
+	if err != nil {
+		return err
+	}
+	entry := AuditEntry{
+		ActorID:    &user.ID,
+		Method:     http.MethodPost,
+		Route:      auditRouteResetPassword,
+		EntityType: "users",
+		EntityID:   fmt.Sprint(user.ID),
+	}
+	return u.audit.Track(entry, func() (uint, error) {
+		return user.ID, u.SetPassword(user, newPassword)
+	})
+}
+
+// ChangePassword replaces the password after checking the current one.
+// Every session is ended and a fresh token pair is returned for the caller.
+func (u *PasswordUseCase) ChangePassword(userID uint, oldPassword, newPassword string, client LoginClient) (*schemas.TokenPairResponse, error) {
+	user, err := u.userRepo.GetUserByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := password_services.CheckPasswordHash(oldPassword, user.Password); err != nil {
+		return nil, ErrWrongPassword
+	}
+	if err := u.SetPassword(user, newPassword); err != nil {
+		return nil, err
+	}
+	return u.tokens.IssueTokens(user, client)
+}
+
+// SetPassword hashes and stores a new password, ends all of the user's
+// sessions and revokes their API tokens
+func (u *PasswordUseCase) SetPassword(user *entity.User, newPassword string) error {
+	hashedPassword, err := password_services.HashPassword(newPassword)
+	if err != nil {
+		return err
+	}
+	user.Password = hashedPassword
+	if err := u.userRepo.UpdateUser(user); err != nil {
+		return err
+	}
+	if err := u.resetRepo.InvalidateByUserID(user.ID, time.Now()); err != nil {
+		return err
+	}
+	return u.tokens.RevokeCredentials(user.ID)
+}
+
+// passwordResetURL is the page where users request or complete a password reset
+func passwordResetURL() string {
+	if baseURL := os.Getenv("PASSWORD_RESET_URL"); baseURL != "" {
+		return baseURL
+	}
+	return defaultPasswordResetURL
+}
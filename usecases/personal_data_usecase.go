@@ -0,0 +1,206 @@
+package usecases
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"a2sv.org/hub/Domain/entity"
+	"a2sv.org/hub/Domain/repository"
+	"a2sv.org/hub/infrastructure/password_services"
+)
+
+// PersonalDataUseCase exports the data held about a user and erases it on request
+type PersonalDataUseCase struct {
+	dataRepo           repository.PersonalDataRepository
+	userRepo           repository.UserRepository
+	rolePermissionRepo repository.RolePermissionRepository
+	scope              GroupScopeChecker
+	tokens             *TokenUseCase
+	files              *FileUseCase
+	encryption         *EncryptionUseCase
+}
+
+func NewPersonalDataUseCase(
+	dataRepo repository.PersonalDataRepository,
+	userRepo repository.UserRepository,
+	rolePermissionRepo repository.RolePermissionRepository,
+	scope GroupScopeChecker,
+	tokens *TokenUseCase,
+	files *FileUseCase,
+	encryption *EncryptionUseCase,
+) *PersonalDataUseCase {
+	return &PersonalDataUseCase{
+		dataRepo:           dataRepo,
+		userRepo:           userRepo,
+		rolePermissionRepo: rolePermissionRepo,
+		scope:              scope,
+		tokens:             tokens,
+		files:              files,
+		encryption:         encryption,
+	}
+}
+
+// exportedAssistantMessage leaves out the message's user, which is not
+// loaded and would otherwise be written as an empty user
+type exportedAssistantMessage struct {
+	entity.AssistantMessage
+	User *entity.User `json:"user,omitempty"`
+}
+
+// Export returns a ZIP archive with one JSON file for the user's profile and
+// one for every kind of record tied to them
+func (u *PersonalDataUseCase) Export(userID uint) ([]byte, error) {
+	user, err := u.userRepo.GetUserByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	user.Photo = u.files.URL(user.Photo)
+	user.CV = u.files.URL(user.CV)
+
+	stipends, err := u.dataRepo.ListStipends(userID)
+	if err != nil {
+		return nil, err
+	}
+	for _, stipend := range stipends {
+		accountNumber, err := u.encryption.Decrypt(entity.ColumnStipendAccountNumber, stipend.AccountNumber)
+		if err != nil {
+			return nil, err
+		}
+		stipend.AccountNumber = accountNumber
+	}
+
+	messages, err := u.dataRepo.ListAssistantMessages(userID)
+	if err != nil {
+		return nil, err
+	}
+	exportedMessages := make([]exportedAssistantMessage, 0, len(messages))
+	for _, message := range messages {
+		exportedMessages = append(exportedMessages, exportedAssistantMessage{AssistantMessage: *message})
+	}
+
+	sections := []struct {
+		name string
+		load func() (interface{}, error)
+	}{
+		{"profile.json", func() (interface{}, error) { return user, nil }},
+		{"submissions.json", func() (interface{}, error) { return u.dataRepo.ListSubmissions(userID) }},
+		{"attendances.json", func() (interface{}, error) { return u.dataRepo.ListAttendances(userID) }},
+		{"comments.json", func() (interface{}, error) { return u.dataRepo.ListComments(userID) }},
+		{"posts.json", func() (interface{}, error) { return u.dataRepo.ListPosts(userID) }},
+		{"votes.json", func() (interface{}, error) { return u.dataRepo.ListVotes(userID) }},
+		{"stipends.json", func() (interface{}, error) { return stipends, nil }},
+		{"assistant_messages.json", func() (interface{}, error) { return exportedMessages, nil }},
+		{"recent_actions.json", func() (interface{}, error) { return u.dataRepo.ListRecentActions(userID) }},
+		{"login_history.json", func() (interface{}, error) { return u.dataRepo.ListLoginEvents(userID) }},
+		{"invites.json", func() (interface{}, error) { return u.dataRepo.ListInvites(user.Email) }},
+	}
+
+	var archive bytes.Buffer
+	writer := zip.NewWriter(&archive)
+	now := time.Now()
+	for _, section := range sections {
+		data, err := section.load()
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", section.name, err)
+		}
+		content, err := json.MarshalIndent(data, "", "  ")
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", section.name, err)
+		}
+		file, err := writer.CreateHeader(&zip.FileHeader{Name: section.name, Method: zip.Deflate, Modified: now})
+		if err != nil {
+			return nil, err
+		}
+		if _, err := file.Write(content); err != nil {
+			return nil, err
+		}
+	}
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+	return archive.Bytes(), nil
+}
+
+// EraseSelf erases the caller's personal data once they confirm their password
+func (u *PersonalDataUseCase) EraseSelf(userID uint, password string) error {
+	user, err := u.userRepo.GetUserByID(userID)
+	if err != nil {
+		return err
+	}
+	if err := password_services.CheckPasswordHash(password, user.Password); err != nil {
+		return ErrWrongPassword
+	}
+	return u.erase(user)
+}
+
+// Erase erases another user's personal data, for requests made outside the
+// hub. The actor must manage the user's group and hold every permission of
+// the user's role.
+func (u *PersonalDataUseCase) Erase(actorID, userID uint) error {
+	if actorID == userID {
+		return ErrUserLifecycleSelf
+	}
+	if err := u.scope.CheckUserScope(actorID, userID); err != nil {
+		return err
+	}
+	user, err := u.userRepo.GetUserByID(userID)
+	if err != nil {
+		return err
+	}
+	if err := checkRoleGrant(u.userRepo, u.rolePermissionRepo, actorID, user.RoleID); err != nil {
+		return err
+	}
+	return u.erase(user)
+}
+
+// erase anonymizes the user and deactivates them. The user's ID and their
+// role, group, country, university and dates stay, so submissions,
+// attendance, votes and other statistics still count them.
+func (u *PersonalDataUseCase) erase(user *entity.User) error {
+	if user.ErasedAt != nil {
+		return ErrUserErased
+	}
+	// Nobody knows the new password, so the account cannot be signed in to
+	// even if it is reactivated
+	secret, err := password_services.GenerateRandomPassword(32)
+	if err != nil {
+		return err
+	}
+	hashedPassword, err := password_services.HashPassword(secret)
+	if err != nil {
+		return err
+	}
+	if err := u.tokens.RevokeAllForUser(user.ID); err != nil {
+		return err
+	}
+
+	photo, cv := user.Photo, user.CV
+	now := time.Now()
+	anonymized := &entity.User{
+		ID:                     user.ID,
+		Name:                   "Erased user",
+		Email:                  fmt.Sprintf("erased-%d@users.invalid", user.ID),
+		Password:               hashedPassword,
+		RoleID:                 user.RoleID,
+		GroupID:                user.GroupID,
+		FormerGroupID:          user.FormerGroupID,
+		CountryID:              user.CountryID,
+		University:             user.University,
+		Department:             user.Department,
+		ExpectedGraduationDate: user.ExpectedGraduationDate,
+		GraduatedAt:            user.GraduatedAt,
+		JoinedDate:             user.JoinedDate,
+		Inactive:               true,
+		CreatedAt:              user.CreatedAt,
+		ErasedAt:               &now,
+	}
+	if err := u.dataRepo.EraseUser(anonymized); err != nil {
+		return err
+	}
+	u.files.deleteStored(photo)
+	u.files.deleteStored(cv)
+	return nil
+}
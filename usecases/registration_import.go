@@ -0,0 +1,336 @@
+package usecases
+
+import (
+	"errors"
+	"fmt"
+	"net/mail"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"a2sv.org/hub/Delivery/http/schemas"
+	"a2sv.org/hub/Domain/entity"
+	"gorm.io/gorm"
+)
+
+// MaxRegistrationImportRows is the most users a single sheet may register
+const MaxRegistrationImportRows = 2000
+
+// registrationColumns maps normalized sheet headers to the profile field they
+// fill. Headers are lowercased with every run of other characters turned into
+// "_", and a trailing "_handle" or "_username" is dropped, so "Email Address"
+// and "GitHub Username" are recognized as well.
+var registrationColumns = map[string]string{
+	"name":          "name",
+	"full_name":     "name",
+	"email":         "email",
+	"email_address": "email",
+	"group":         "group",
+	"group_name":    "group",
+	"country":       "country",
+	"university":    "university",
+	"phone":         "phone",
+	"phone_number":  "phone",
+	"leetcode":      "leetcode",
+	"codeforces":    "codeforces",
+	"github":        "github",
+	"hackerrank":    "hackerrank",
+}
+
+// RegistrationImportOptions applies to every row of a registration
+type RegistrationImportOptions struct {
+	RoleID uint
+	// GroupID and CountryID are used for rows that leave the column empty
+	GroupID   *uint
+	CountryID *uint
+	// Mode is entity.RegistrationModePerRow (the default) or
+	// entity.RegistrationModeAllOrNothing
+	Mode string
+}
+
+// importRow is a sheet row mapped onto the profile fields
+type importRow struct {
+	number int // 0 for rows that do not come from a sheet
+	fields map[string]string
+}
+
+// registrationEntry is a validated row. user is nil when the row is invalid,
+// and result then says why.
+type registrationEntry struct {
+	result RegistrationResult
+	user   *entity.User
+}
+
+// importLookups caches the groups and countries resolved from sheet cells
+type importLookups struct {
+	groups    map[string]*entity.Group
+	countries map[string]*entity.Country
+}
+
+// ImportUsers starts a job registering a user for every row of a sheet whose
+// first row holds the column headers. Every row is validated, and a row that
+// fails validation is reported without registering it.
+func (u *bulkRegistrationUseCase) ImportUsers(actorID uint, rows [][]string, options RegistrationImportOptions) (*schemas.RegistrationJobResponse, error) {
+	importRows, err := parseImportRows(rows)
+	if err != nil {
+		return nil, err
+	}
+	return u.startJob(actorID, importRows, options)
+}
+
+// ValidateImport validates every row of a sheet like ImportUsers without
+// registering anyone. Valid rows are reported as such.
+func (u *bulkRegistrationUseCase) ValidateImport(actorID uint, rows [][]string, options RegistrationImportOptions) ([]RegistrationResult, error) {
+	if err := u.checkOptions(actorID, options); err != nil {
+		return nil, err
+	}
+	importRows, err := parseImportRows(rows)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := u.validateRows(importRows, options)
+	if err != nil {
+		return nil, err
+	}
+	results := make([]RegistrationResult, len(entries))
+	for i, entry := range entries {
+		results[i] = entry.result
+		if entry.user != nil {
+			results[i].Success = true
+			results[i].Message = "Valid"
+		}
+	}
+	return results, nil
+}
+
+// validateRows builds the user of every row, or reports the problems that
+// keep it from being registered
+func (u *bulkRegistrationUseCase) validateRows(rows []importRow, options RegistrationImportOptions) ([]registrationEntry, error) {
+	lookups := &importLookups{groups: map[string]*entity.Group{}, countries: map[string]*entity.Country{}}
+	seen := make(map[string]int, len(rows))
+	entries := make([]registrationEntry, 0, len(rows))
+	for i, row := range rows {
+		entry := registrationEntry{result: RegistrationResult{Row: row.number, Email: row.fields["email"]}}
+
+		user, problems, err := u.validateImportRow(row, options, lookups)
+		if err != nil {
+			return nil, err
+		}
+		if user != nil {
+			key := strings.ToLower(user.Email)
+			if first, ok := seen[key]; !ok {
+				seen[key] = i
+			} else if rows[first].number > 0 {
+				problems = append(problems, fmt.Sprintf("email repeats row %d", rows[first].number))
+			} else {
+				problems = append(problems, "email is listed more than once")
+			}
+		}
+		if len(problems) > 0 {
+			entry.result.Message = strings.Join(problems, "; ")
+		} else {
+			user.RoleID = options.RoleID
+			entry.user = user
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+// parseImportRows maps the rows below the header row onto the profile
+// fields, skipping blank rows and columns with unknown headers
+func parseImportRows(rows [][]string) ([]importRow, error) {
+	if len(rows) == 0 {
+		return nil, fmt.Errorf("%w: the sheet is empty", ErrInvalidRegistration)
+	}
+
+	columns := make(map[int]string)
+	used := make(map[string]bool)
+	for i, header := range rows[0] {
+		field, ok := registrationColumns[normalizeHeader(header)]
+		if !ok {
+			continue
+		}
+		if used[field] {
+			return nil, fmt.Errorf("%w: more than one %s column", ErrInvalidRegistration, field)
+		}
+		columns[i] = field
+		used[field] = true
+	}
+	if !used["email"] {
+		return nil, fmt.Errorf("%w: the header row has no email column", ErrInvalidRegistration)
+	}
+
+	var importRows []importRow
+	for i, cells := range rows[1:] {
+		row := importRow{number: i + 2, fields: map[string]string{}}
+		for column, value := range cells {
+			if field, ok := columns[column]; ok {
+				if value = strings.TrimSpace(value); value != "" {
+					row.fields[field] = value
+				}
+			}
+		}
+		if len(row.fields) == 0 {
+			continue
+		}
+		importRows = append(importRows, row)
+	}
+	if len(importRows) == 0 {
+		return nil, fmt.Errorf("%w: the sheet has no users", ErrInvalidRegistration)
+	}
+	if len(importRows) > MaxRegistrationImportRows {
+		return nil, fmt.Errorf("%w: the sheet has %d users, at most %d can be imported at once",
+			ErrInvalidRegistration, len(importRows), MaxRegistrationImportRows)
+	}
+	return importRows, nil
+}
+
+func normalizeHeader(header string) string {
+	normalized := strings.Join(strings.FieldsFunc(strings.ToLower(header), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}), "_")
+	normalized = strings.TrimSuffix(normalized, "_handle")
+	return strings.TrimSuffix(normalized, "_username")
+}
+
+// validateImportRow builds the user a row describes. It returns the problems
+// that keep the row from being registered, and an error only when the checks
+// themselves fail.
+func (u *bulkRegistrationUseCase) validateImportRow(row importRow, options RegistrationImportOptions, lookups *importLookups) (*entity.User, []string, error) {
+	var problems []string
+	fields := row.fields
+
+	email := fields["email"]
+	if email == "" {
+		return nil, []string{"email is required"}, nil
+	}
+	if address, err := mail.ParseAddress(email); err != nil || address.Address != email || len(email) > 255 {
+		return nil, []string{"invalid email"}, nil
+	}
+	_, err := u.userRepo.GetUserByEmail(email)
+	if err == nil {
+		problems = append(problems, "Email already registered")
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil, err
+	}
+	if err := checkDeletedUserEmail(u.userRepo, email); errors.Is(err, ErrEmailOfDeletedUser) {
+		problems = append(problems, err.Error())
+	} else if err != nil {
+		return nil, nil, err
+	}
+
+	user := &entity.User{
+		Email:      email,
+		Name:       fields["name"],
+		University: fields["university"],
+		Phone:      fields["phone"],
+		GroupID:    options.GroupID,
+		CountryID:  options.CountryID,
+	}
+	if user.Name == "" {
+		user.Name = extractNameFromEmail(email)
+	}
+	for _, limit := range []struct {
+		field string
+		max   int
+	}{{"name", 255}, {"university", 255}, {"phone", 20}} {
+		if len(fields[limit.field]) > limit.max {
+			problems = append(problems, fmt.Sprintf("%s is longer than %d characters", limit.field, limit.max))
+		}
+	}
+	for _, handle := range []struct {
+		field string
+		value **string
+	}{
+		{"leetcode", &user.Leetcode},
+		{"codeforces", &user.Codeforces},
+		{"github", &user.Github},
+		{"hackerrank", &user.Hackerrank},
+	} {
+		value := fields[handle.field]
+		if value == "" {
+			continue
+		}
+		if len(value) > 255 {
+			problems = append(problems, fmt.Sprintf("%s is longer than 255 characters", handle.field))
+		}
+		*handle.value = &value
+	}
+
+	if value := fields["group"]; value != "" {
+		group, err := u.lookupGroup(value, lookups)
+		if err != nil {
+			return nil, nil, err
+		}
+		if group == nil {
+			problems = append(problems, fmt.Sprintf("unknown group %q", value))
+		} else {
+			user.GroupID = &group.ID
+		}
+	}
+	if user.GroupID == nil {
+		problems = append(problems, "group is required")
+	}
+	if value := fields["country"]; value != "" {
+		country, err := u.lookupCountry(value, lookups)
+		if err != nil {
+			return nil, nil, err
+		}
+		if country == nil {
+			problems = append(problems, fmt.Sprintf("unknown country %q", value))
+		} else {
+			user.CountryID = &country.ID
+		}
+	}
+
+	return user, problems, nil
+}
+
+// lookupGroup finds a group by ID or by name, returning nil for none
+func (u *bulkRegistrationUseCase) lookupGroup(value string, lookups *importLookups) (*entity.Group, error) {
+	if group, ok := lookups.groups[value]; ok {
+		return group, nil
+	}
+	var group *entity.Group
+	var err error
+	if id, parseErr := strconv.ParseUint(value, 10, 32); parseErr == nil {
+		group, err = u.groupRepo.GetByID(uint(id))
+	} else {
+		group, err = u.groupRepo.GetByName(value)
+	}
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		group, err = nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	lookups.groups[value] = group
+	return group, nil
+}
+
+// lookupCountry finds a country by ID, by name or by short code, returning
+// nil for none
+func (u *bulkRegistrationUseCase) lookupCountry(value string, lookups *importLookups) (*entity.Country, error) {
+	if country, ok := lookups.countries[value]; ok {
+		return country, nil
+	}
+	var country *entity.Country
+	var err error
+	if id, parseErr := strconv.ParseUint(value, 10, 32); parseErr == nil {
+		country, err = u.countryRepo.GetByID(uint(id))
+	} else {
+		country, err = u.countryRepo.GetByName(value)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			country, err = u.countryRepo.GetByShortCode(strings.ToUpper(value))
+		}
+	}
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		country, err = nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	lookups.countries[value] = country
+	return country, nil
+}
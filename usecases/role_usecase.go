@@ -1,6 +1,7 @@
 package usecases
 
 import (
+	"fmt"
 	"time"
 
 	"a2sv.org/hub/Delivery/http/schemas"
@@ -13,25 +14,42 @@ import (
 // PaginationMeta is returned for list endpoints
 // Conversion utilities are used internally
 type RoleUseCaseInterface interface {
-	Create(input *schemas.CreateRoleRequest) (*schemas.RoleResponse, error)
+	Create(actorID uint, input *schemas.CreateRoleRequest) (*schemas.RoleResponse, error)
 	GetByID(id uint) (*schemas.RoleResponse, error)
 	GetByType(roleType string) ([]*schemas.RoleResponse, error)
-	Update(id uint, input *schemas.UpdateRoleRequest) (*schemas.RoleResponse, error)
-	Delete(id uint) error
+	Update(actorID, id uint, input *schemas.UpdateRoleRequest) (*schemas.RoleResponse, error)
+	Delete(actorID, id uint) error
 	List() ([]*schemas.RoleResponse, *schemas.PaginationMeta, error)
+	ListPermissions(roleID uint) ([]string, error)
+	AddPermission(actorID, roleID uint, permission string) error
+	RemovePermission(actorID, roleID uint, permission string) error
+	HasPermission(roleID uint, permission string) (bool, error)
 }
 
+// RoleUseCase keeps actors from granting permissions they do not hold: a
+// role's permissions may only be set to ones the actor has, and only roles
+// the actor could grant may be changed
 type RoleUseCase struct {
-	roleRepo repository.RoleRepository
+	roleRepo           repository.RoleRepository
+	rolePermissionRepo repository.RolePermissionRepository
+	userRepo           repository.UserRepository
 }
 
-func NewRoleUseCase(roleRepo repository.RoleRepository) *RoleUseCase {
+func NewRoleUseCase(roleRepo repository.RoleRepository, rolePermissionRepo repository.RolePermissionRepository, userRepo repository.UserRepository) *RoleUseCase {
 	return &RoleUseCase{
-		roleRepo: roleRepo,
+		roleRepo:           roleRepo,
+		rolePermissionRepo: rolePermissionRepo,
+		userRepo:           userRepo,
 	}
 }
 
-func (u *RoleUseCase) Create(input *schemas.CreateRoleRequest) (*schemas.RoleResponse, error) {
+func (u *RoleUseCase) Create(actorID uint, input *schemas.CreateRoleRequest) (*schemas.RoleResponse, error) {
+	if err := validatePermissions(input.Permissions); err != nil {
+		return nil, err
+	}
+	if err := checkPermissionGrant(u.userRepo, u.rolePermissionRepo, actorID, input.Permissions); err != nil {
+		return nil, err
+	}
 	role := &entity.Role{
 		Type:      input.Type,
 		CreatedAt: time.Now(),
@@ -41,7 +59,12 @@ func (u *RoleUseCase) Create(input *schemas.CreateRoleRequest) (*schemas.RoleRes
 	if err != nil {
 		return nil, err
 	}
-	return entityToRoleResponse(role), nil
+	if len(input.Permissions) > 0 {
+		if err := u.rolePermissionRepo.ReplacePermissions(role.ID, input.Permissions); err != nil {
+			return nil, err
+		}
+	}
+	return u.roleResponseWithPermissions(role)
 }
 
 func (u *RoleUseCase) GetByID(id uint) (*schemas.RoleResponse, error) {
@@ -49,7 +72,7 @@ func (u *RoleUseCase) GetByID(id uint) (*schemas.RoleResponse, error) {
 	if err != nil {
 		return nil, err
 	}
-	return entityToRoleResponse(role), nil
+	return u.roleResponseWithPermissions(role)
 }
 
 func (u *RoleUseCase) GetByType(roleType string) ([]*schemas.RoleResponse, error) {
@@ -59,28 +82,54 @@ func (u *RoleUseCase) GetByType(roleType string) ([]*schemas.RoleResponse, error
 	}
 	resp := make([]*schemas.RoleResponse, 0, len(roles))
 	for _, r := range roles {
-		resp = append(resp, entityToRoleResponse(r))
+		roleResp, err := u.roleResponseWithPermissions(r)
+		if err != nil {
+			return nil, err
+		}
+		resp = append(resp, roleResp)
 	}
 	return resp, nil
 }
 
-func (u *RoleUseCase) Update(id uint, input *schemas.UpdateRoleRequest) (*schemas.RoleResponse, error) {
+func (u *RoleUseCase) Update(actorID, id uint, input *schemas.UpdateRoleRequest) (*schemas.RoleResponse, error) {
 	existing, err := u.roleRepo.GetRoleByID(id)
 	if err != nil {
 		return nil, err
 	}
+	if err := checkRoleGrant(u.userRepo, u.rolePermissionRepo, actorID, id); err != nil {
+		return nil, err
+	}
 	if input.Type != nil {
 		existing.Type = *input.Type
 	}
+	if input.Permissions != nil {
+		if err := validatePermissions(*input.Permissions); err != nil {
+			return nil, err
+		}
+		if err := checkPermissionGrant(u.userRepo, u.rolePermissionRepo, actorID, *input.Permissions); err != nil {
+			return nil, err
+		}
+	}
 	existing.UpdatedAt = time.Now()
 	err = u.roleRepo.UpdateRole(existing)
 	if err != nil {
 		return nil, err
 	}
-	return entityToRoleResponse(existing), nil
+	if input.Permissions != nil {
+		if err := u.rolePermissionRepo.ReplacePermissions(existing.ID, *input.Permissions); err != nil {
+			return nil, err
+		}
+	}
+	return u.roleResponseWithPermissions(existing)
 }
 
-func (u *RoleUseCase) Delete(id uint) error {
+func (u *RoleUseCase) Delete(actorID, id uint) error {
+	if _, err := u.roleRepo.GetRoleByID(id); err != nil {
+		return err
+	}
+	if err := checkRoleGrant(u.userRepo, u.rolePermissionRepo, actorID, id); err != nil {
+		return err
+	}
 	return u.roleRepo.DeleteRole(id)
 }
 
@@ -91,7 +140,11 @@ func (u *RoleUseCase) List() ([]*schemas.RoleResponse, *schemas.PaginationMeta,
 	}
 	resp := make([]*schemas.RoleResponse, 0, len(roles))
 	for _, r := range roles {
-		resp = append(resp, entityToRoleResponse(r))
+		roleResp, err := u.roleResponseWithPermissions(r)
+		if err != nil {
+			return nil, nil, err
+		}
+		resp = append(resp, roleResp)
 	}
 	meta := &schemas.PaginationMeta{
 		Total:      len(resp),
@@ -102,6 +155,78 @@ func (u *RoleUseCase) List() ([]*schemas.RoleResponse, *schemas.PaginationMeta,
 	return resp, meta, nil
 }
 
+// ListPermissions returns the permission names granted to a role
+func (u *RoleUseCase) ListPermissions(roleID uint) ([]string, error) {
+	if _, err := u.roleRepo.GetRoleByID(roleID); err != nil {
+		return nil, err
+	}
+	permissions, err := u.rolePermissionRepo.ListPermissionsByRoleID(roleID)
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(permissions))
+	for _, p := range permissions {
+		names = append(names, p.Permission)
+	}
+	return names, nil
+}
+
+// AddPermission grants a permission to a role
+func (u *RoleUseCase) AddPermission(actorID, roleID uint, permission string) error {
+	if err := validatePermissions([]string{permission}); err != nil {
+		return err
+	}
+	if _, err := u.roleRepo.GetRoleByID(roleID); err != nil {
+		return err
+	}
+	if err := checkRoleGrant(u.userRepo, u.rolePermissionRepo, actorID, roleID); err != nil {
+		return err
+	}
+	if err := checkPermissionGrant(u.userRepo, u.rolePermissionRepo, actorID, []string{permission}); err != nil {
+		return err
+	}
+	return u.rolePermissionRepo.AddPermission(&entity.RolePermission{
+		RoleID:     roleID,
+		Permission: permission,
+		CreatedAt:  time.Now(),
+	})
+}
+
+// RemovePermission revokes a permission from a role
+func (u *RoleUseCase) RemovePermission(actorID, roleID uint, permission string) error {
+	if err := checkRoleGrant(u.userRepo, u.rolePermissionRepo, actorID, roleID); err != nil {
+		return err
+	}
+	return u.rolePermissionRepo.RemovePermission(roleID, permission)
+}
+
+// HasPermission reports whether the role holds the permission or the wildcard permission
+func (u *RoleUseCase) HasPermission(roleID uint, permission string) (bool, error) {
+	return u.rolePermissionRepo.HasPermission(roleID, permission)
+}
+
+func (u *RoleUseCase) roleResponseWithPermissions(role *entity.Role) (*schemas.RoleResponse, error) {
+	resp := entityToRoleResponse(role)
+	permissions, err := u.rolePermissionRepo.ListPermissionsByRoleID(role.ID)
+	if err != nil {
+		return nil, err
+	}
+	resp.Permissions = make([]string, 0, len(permissions))
+	for _, p := range permissions {
+		resp.Permissions = append(resp.Permissions, p.Permission)
+	}
+	return resp, nil
+}
+
+func validatePermissions(permissions []string) error {
+	for _, p := range permissions {
+		if !entity.IsKnownPermission(p) {
+			return fmt.Errorf("%w: %s", ErrUnknownPermission, p)
+		}
+	}
+	return nil
+}
+
 func entityToRoleResponse(r *entity.Role) *schemas.RoleResponse {
 	if r == nil {
 		return nil
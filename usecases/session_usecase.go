@@ -7,7 +7,7 @@ import (
 
 // SessionRepository defines methods for Session data operations
 type SessionUseCaseInterface interface {
-	CreateSession(Session *entity.Session) error
+	CreateSession(actorID uint, Session *entity.Session) error
 
 	ListSession() ([]*entity.Session, error)
 
@@ -15,22 +15,27 @@ type SessionUseCaseInterface interface {
 	GetSessionByID(id uint) (*entity.Session, error)
 	GetSessionByStartTime(startTime string) ([]*entity.Session, error)
 
-	UpdateSession(Session *entity.Session) error
+	UpdateSession(actorID uint, Session *entity.Session) error
 
-	DeleteSession(id uint) error
+	DeleteSession(actorID uint, id uint) error
 }
 
 type SessionUsecase struct {
 	SessionRepository repository.SessionRepository
+	scope             GroupScopeChecker
 }
 
-func NewSessionUsecase(sessionRepository repository.SessionRepository) *SessionUsecase {
+func NewSessionUsecase(sessionRepository repository.SessionRepository, scope GroupScopeChecker) *SessionUsecase {
 	return &SessionUsecase{
 		SessionRepository: sessionRepository,
+		scope:             scope,
 	}
 }
 
-func (s *SessionUsecase) CreateSession(session *entity.Session) error {
+func (s *SessionUsecase) CreateSession(actorID uint, session *entity.Session) error {
+	if err := s.checkSessionScope(actorID, sessionGroupIDs(session)); err != nil {
+		return err
+	}
 	err := s.SessionRepository.CreateSession(session)
 	if err != nil {
 		return err
@@ -68,14 +73,56 @@ func (s *SessionUsecase) GetSessionByStartTime(startTime string) ([]*entity.Sess
 	return sessions, nil
 }
 
-func (s *SessionUsecase) UpdateSession(session *entity.Session) error {
-	err := s.SessionRepository.UpdateSession(session)
+func (s *SessionUsecase) UpdateSession(actorID uint, session *entity.Session) error {
+	existingGroupIDs, err := s.SessionRepository.GetSessionGroupIDs(session.ID)
+	if err != nil {
+		return err
+	}
+	if err := s.checkSessionScope(actorID, append(existingGroupIDs, sessionGroupIDs(session)...)); err != nil {
+		return err
+	}
+	err = s.SessionRepository.UpdateSession(session)
 	if err != nil {
 		return err
 	}
 	return nil
 }
 
-func (s *SessionUsecase) DeleteSession(id uint) error {
+func (s *SessionUsecase) DeleteSession(actorID uint, id uint) error {
+	groupIDs, err := s.SessionRepository.GetSessionGroupIDs(id)
+	if err != nil {
+		return err
+	}
+	if err := s.checkSessionScope(actorID, groupIDs); err != nil {
+		return err
+	}
 	return s.SessionRepository.DeleteSession(id)
 }
+
+// checkSessionScope requires the actor to manage every group of the session.
+// Sessions without groups can only be managed with global scope.
+func (s *SessionUsecase) checkSessionScope(actorID uint, groupIDs []uint) error {
+	return checkGroupsScope(s.scope, actorID, groupIDs)
+}
+
+// checkGroupsScope requires the actor to manage every one of the groups, or
+// to have global scope when there are none
+func checkGroupsScope(scope GroupScopeChecker, actorID uint, groupIDs []uint) error {
+	if len(groupIDs) == 0 {
+		return scope.CheckGroupScope(actorID, nil)
+	}
+	for i := range groupIDs {
+		if err := scope.CheckGroupScope(actorID, &groupIDs[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func sessionGroupIDs(session *entity.Session) []uint {
+	groupIDs := make([]uint, 0, len(session.GroupSessions))
+	for _, gs := range session.GroupSessions {
+		groupIDs = append(groupIDs, gs.GroupID)
+	}
+	return groupIDs
+}
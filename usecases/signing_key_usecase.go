@@ -0,0 +1,245 @@
+package usecases
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"a2sv.org/hub/Domain/entity"
+	"a2sv.org/hub/Domain/repository"
+	"a2sv.org/hub/infrastructure/token_services"
+)
+
+// Signing key policy. Keys are reloaded from the database every
+// signingKeyRefreshInterval, or sooner when a token names an unknown key.
+// A replaced key keeps verifying for signingKeyGrace after its successor
+// activates, which outlives every token it signed.
+const (
+	DefaultSigningAlgorithm     = token_services.AlgorithmRS256
+	signingKeyRefreshInterval   = time.Minute
+	signingKeyMissReloadBackoff = 10 * time.Second
+	signingKeyGrace             = time.Hour
+)
+
+// SigningKeyUseCase keeps the JWT signing keys in memory and rotates them.
+// It implements token_services.KeySource.
+type SigningKeyUseCase struct {
+	signingKeyRepo repository.SigningKeyRepository
+	encryption     *EncryptionUseCase
+
+	mu       sync.RWMutex
+	keys     []*loadedSigningKey // Oldest activation first
+	loadedAt time.Time
+}
+
+type loadedSigningKey struct {
+	signing     *token_services.SigningKey
+	activatesAt time.Time
+	retiresAt   *time.Time
+}
+
+func NewSigningKeyUseCase(signingKeyRepo repository.SigningKeyRepository, encryption *EncryptionUseCase) *SigningKeyUseCase {
+	return &SigningKeyUseCase{
+		signingKeyRepo: signingKeyRepo,
+		encryption:     encryption,
+	}
+}
+
+// EnsureKey creates a key with the algorithm when there is none, so that a
+// fresh database can sign tokens right away
+func (u *SigningKeyUseCase) EnsureKey(algorithm string) error {
+	keys, err := u.signingKeyRepo.ListUnretired(time.Now())
+	if err != nil {
+		return err
+	}
+	if len(keys) == 0 {
+		if _, err := u.Rotate(algorithm, 0); err != nil {
+			return err
+		}
+	}
+	return u.reload()
+}
+
+// Rotate creates a key that starts signing after activateIn. Until then it is
+// only published, so that other services pick it up before seeing tokens
+// signed with it. The current keys retire signingKeyGrace after it activates,
+// and keys that already retired are deleted.
+func (u *SigningKeyUseCase) Rotate(algorithm string, activateIn time.Duration) (*entity.SigningKey, error) {
+	if algorithm == "" {
+		algorithm = DefaultSigningAlgorithm
+	}
+	kid, privatePEM, publicPEM, err := token_services.GenerateKeyPair(algorithm)
+	if err != nil {
+		return nil, err
+	}
+	privatePEM, err = u.encryption.EncryptIfConfigured(entity.ColumnSigningKeyPrivateKey, privatePEM)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	key := &entity.SigningKey{
+		KID:         kid,
+		Algorithm:   algorithm,
+		PrivateKey:  privatePEM,
+		PublicKey:   publicPEM,
+		CreatedAt:   now,
+		ActivatesAt: now.Add(activateIn),
+	}
+	if err := u.signingKeyRepo.RetireAll(key.ActivatesAt.Add(signingKeyGrace)); err != nil {
+		return nil, err
+	}
+	if err := u.signingKeyRepo.Create(key); err != nil {
+		return nil, err
+	}
+	if err := u.signingKeyRepo.DeleteRetired(now); err != nil {
+		return nil, err
+	}
+	return key, u.reload()
+}
+
+// List returns the keys that still verify tokens, oldest first
+func (u *SigningKeyUseCase) List() ([]*entity.SigningKey, error) {
+	return u.signingKeyRepo.ListUnretired(time.Now())
+}
+
+// SigningKey returns the newest active key
+func (u *SigningKeyUseCase) SigningKey() (*token_services.SigningKey, error) {
+	keys, err := u.current()
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	for i := len(keys) - 1; i >= 0; i-- {
+		if !keys[i].activatesAt.After(now) && !keys[i].retired(now) {
+			return keys[i].signing, nil
+		}
+	}
+	return nil, errors.New("no active signing key")
+}
+
+// VerificationKey returns the public key with the ID, reloading the keys once
+// in a while when it is unknown since another instance may have rotated them
+func (u *SigningKeyUseCase) VerificationKey(kid string) (*token_services.VerificationKey, error) {
+	keys, err := u.current()
+	if err != nil {
+		return nil, err
+	}
+	if key := findSigningKey(keys, kid, time.Now()); key != nil {
+		return key, nil
+	}
+
+	u.mu.RLock()
+	recentlyLoaded := time.Since(u.loadedAt) < signingKeyMissReloadBackoff
+	u.mu.RUnlock()
+	if recentlyLoaded {
+		return nil, token_services.ErrUnknownKey
+	}
+	if err := u.reload(); err != nil {
+		return nil, err
+	}
+	u.mu.RLock()
+	keys = u.keys
+	u.mu.RUnlock()
+	if key := findSigningKey(keys, kid, time.Now()); key != nil {
+		return key, nil
+	}
+	return nil, token_services.ErrUnknownKey
+}
+
+// JWKS returns the public keys that verify tokens, including keys that do
+// not sign yet
+func (u *SigningKeyUseCase) JWKS() ([]*token_services.JWK, error) {
+	keys, err := u.current()
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	jwks := make([]*token_services.JWK, 0, len(keys))
+	for _, k := range keys {
+		if k.retired(now) {
+			continue
+		}
+		jwk, err := token_services.PublicJWK(k.verification())
+		if err != nil {
+			return nil, err
+		}
+		jwks = append(jwks, jwk)
+	}
+	return jwks, nil
+}
+
+// current returns the cached keys, reloading them when they are stale
+func (u *SigningKeyUseCase) current() ([]*loadedSigningKey, error) {
+	u.mu.RLock()
+	keys, loadedAt := u.keys, u.loadedAt
+	u.mu.RUnlock()
+	if time.Since(loadedAt) < signingKeyRefreshInterval {
+		return keys, nil
+	}
+	if err := u.reload(); err != nil {
+		// Keep serving with the previous keys while the database is unreachable
+		if keys != nil {
+			return keys, nil
+		}
+		return nil, err
+	}
+	u.mu.RLock()
+	defer u.mu.RUnlock()
+	return u.keys, nil
+}
+
+func (u *SigningKeyUseCase) reload() error {
+	stored, err := u.signingKeyRepo.ListUnretired(time.Now())
+	if err != nil {
+		return err
+	}
+	keys := make([]*loadedSigningKey, 0, len(stored))
+	for _, s := range stored {
+		privatePEM, err := u.encryption.Decrypt(entity.ColumnSigningKeyPrivateKey, s.PrivateKey)
+		if err != nil {
+			return fmt.Errorf("signing key %s: %w", s.KID, err)
+		}
+		private, err := token_services.ParsePrivateKey(privatePEM)
+		if err != nil {
+			return err
+		}
+		keys = append(keys, &loadedSigningKey{
+			signing: &token_services.SigningKey{
+				ID:         s.KID,
+				Algorithm:  s.Algorithm,
+				PrivateKey: private,
+			},
+			activatesAt: s.ActivatesAt,
+			retiresAt:   s.RetiresAt,
+		})
+	}
+
+	u.mu.Lock()
+	u.keys = keys
+	u.loadedAt = time.Now()
+	u.mu.Unlock()
+	return nil
+}
+
+func (k *loadedSigningKey) retired(now time.Time) bool {
+	return k.retiresAt != nil && !k.retiresAt.After(now)
+}
+
+func (k *loadedSigningKey) verification() *token_services.VerificationKey {
+	return &token_services.VerificationKey{
+		ID:        k.signing.ID,
+		Algorithm: k.signing.Algorithm,
+		PublicKey: k.signing.PrivateKey.Public(),
+	}
+}
+
+func findSigningKey(keys []*loadedSigningKey, kid string, now time.Time) *token_services.VerificationKey {
+	for _, k := range keys {
+		if k.signing.ID == kid && !k.retired(now) {
+			return k.verification()
+		}
+	}
+	return nil
+}
@@ -20,20 +20,18 @@ type StipendUseCaseInterface interface {
 
 type StipendUsecase struct{
 	StipendRepository repository.StipendRepository
+	encryption        *EncryptionUseCase
 }
 
-func NewStipendUsecase(stipendRepository repository.StipendRepository) *StipendUsecase{
+func NewStipendUsecase(stipendRepository repository.StipendRepository, encryption *EncryptionUseCase) *StipendUsecase{
 	return &StipendUsecase{
 		StipendRepository: stipendRepository,
+		encryption:        encryption,
 	}
 }
 
 func (s *StipendUsecase) CreateStipend(stipend *entity.Stipend) error {
-	err := s.StipendRepository.CreateStipend(stipend)
-	if err != nil {
-		return err
-	}
-	return nil
+	return s.withEncryptedAccountNumber(stipend, s.StipendRepository.CreateStipend)
 }
 
 func (s *StipendUsecase) ListStipend() ([]*entity.Stipend, error) {
@@ -41,6 +39,11 @@ func (s *StipendUsecase) ListStipend() ([]*entity.Stipend, error) {
 	if err != nil {
 		return nil, err
 	}
+	for _, stipend := range stipends {
+		if err := s.decryptAccountNumber(stipend); err != nil {
+			return nil, err
+		}
+	}
 	return stipends, nil
 }
 
@@ -49,14 +52,36 @@ func (s *StipendUsecase) GetStipendByID(id uint) (*entity.Stipend, error) {
 	if err != nil {
 		return nil, err
 	}
+	if err := s.decryptAccountNumber(stipend); err != nil {
+		return nil, err
+	}
 	return stipend, nil
 }
 
 func (s *StipendUsecase) UpdateStipend(stipend *entity.Stipend) error {
-	err := s.StipendRepository.UpdateStipend(stipend)
+	return s.withEncryptedAccountNumber(stipend, s.StipendRepository.UpdateStipend)
+}
+
+// withEncryptedAccountNumber saves the stipend with its account number
+// encrypted and leaves the plaintext in the stipend afterwards
+func (s *StipendUsecase) withEncryptedAccountNumber(stipend *entity.Stipend, save func(*entity.Stipend) error) error {
+	plaintext := stipend.AccountNumber
+	encrypted, err := s.encryption.Encrypt(entity.ColumnStipendAccountNumber, plaintext)
+	if err != nil {
+		return err
+	}
+	stipend.AccountNumber = encrypted
+	err = save(stipend)
+	stipend.AccountNumber = plaintext
+	return err
+}
+
+func (s *StipendUsecase) decryptAccountNumber(stipend *entity.Stipend) error {
+	plaintext, err := s.encryption.Decrypt(entity.ColumnStipendAccountNumber, stipend.AccountNumber)
 	if err != nil {
 		return err
 	}
+	stipend.AccountNumber = plaintext
 	return nil
 }
 
@@ -7,28 +7,37 @@ import (
 
 // SuperGroupUseCase defines methods for super group business logic
 type SuperGroupUseCaseInterface interface {
-	Create(SuperGroup *entity.SuperGroup) (*entity.SuperGroup, error)
+	Create(actorID uint, SuperGroup *entity.SuperGroup) (*entity.SuperGroup, error)
 	GetByID(id uint) (*entity.SuperGroup, error)
 	GetByName(name string) (*entity.SuperGroup, error)
-	Update(SuperGroup *entity.SuperGroup) (error)
-	Delete(id uint) error
+	Update(actorID uint, SuperGroup *entity.SuperGroup) (error)
+	Delete(actorID, id uint) error
 	List() ([]*entity.SuperGroup, error)
 }
 
-// SuperGroupUseCase implements SuperGroupUseCase
+// SuperGroupUseCase implements SuperGroupUseCase. The lead of a super group
+// gets scope over all of its groups, so only actors with global scope may set
+// it, and changes need scope over every group of the super group.
 type SuperGroupUseCase struct {
 	superGroupRepo repository.SuperGroupRepository
+	scope          GroupScopeChecker
 }
 
 // NewSuperGroupUseCase creates a new SuperGroupUseCase instance
-func NewSuperGroupUseCase(superGroupRepo repository.SuperGroupRepository) *SuperGroupUseCase {
+func NewSuperGroupUseCase(superGroupRepo repository.SuperGroupRepository, scope GroupScopeChecker) *SuperGroupUseCase {
 	return &SuperGroupUseCase{
 		superGroupRepo: superGroupRepo,
+		scope:          scope,
 	}
 }
 
 // Create creates a new super group
-func (u *SuperGroupUseCase) Create(SuperGroup *entity.SuperGroup) (*entity.SuperGroup, error) {
+func (u *SuperGroupUseCase) Create(actorID uint, SuperGroup *entity.SuperGroup) (*entity.SuperGroup, error) {
+	if SuperGroup.LeadID != nil {
+		if err := u.scope.CheckGlobalScope(actorID); err != nil {
+			return nil, err
+		}
+	}
 	return SuperGroup ,u.superGroupRepo.CreateSuperGroup(SuperGroup)
 }
 
@@ -44,13 +53,30 @@ func (u *SuperGroupUseCase) GetByName(name string) (*entity.SuperGroup, error) {
 
 
 
-// Update updates a super group
-func (u *SuperGroupUseCase) Update(SuperGroup *entity.SuperGroup) ( error) {
+// Update updates a super group. A missing lead keeps the current one.
+func (u *SuperGroupUseCase) Update(actorID uint, SuperGroup *entity.SuperGroup) ( error) {
+	existing, err := u.superGroupRepo.GetSuperGroupByID(SuperGroup.ID)
+	if err != nil {
+		return err
+	}
+	if err := u.checkScope(actorID, existing.ID); err != nil {
+		return err
+	}
+	if SuperGroup.LeadID == nil {
+		SuperGroup.LeadID = existing.LeadID
+	} else if existing.LeadID == nil || *existing.LeadID != *SuperGroup.LeadID {
+		if err := u.scope.CheckGlobalScope(actorID); err != nil {
+			return err
+		}
+	}
 	return u.superGroupRepo.UpdateSuperGroup(SuperGroup)
 }
 
 // Delete deletes a super group
-func (u *SuperGroupUseCase) Delete(id uint) error {
+func (u *SuperGroupUseCase) Delete(actorID, id uint) error {
+	if err := u.checkScope(actorID, id); err != nil {
+		return err
+	}
 	return u.superGroupRepo.DeleteSuperGroup(id)
 }
 
@@ -58,3 +84,12 @@ func (u *SuperGroupUseCase) Delete(id uint) error {
 func (u *SuperGroupUseCase) List() ([]*entity.SuperGroup, error) {
 	return u.superGroupRepo.ListSuperGroup()
 }
+
+// checkScope requires the actor to manage every group of the super group
+func (u *SuperGroupUseCase) checkScope(actorID, superGroupID uint) error {
+	groupIDs, err := u.superGroupRepo.GetGroupIDs(superGroupID)
+	if err != nil {
+		return err
+	}
+	return checkGroupsScope(u.scope, actorID, groupIDs)
+}
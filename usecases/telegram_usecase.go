@@ -0,0 +1,239 @@
+package usecases
+
+import (
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"a2sv.org/hub/Delivery/http/schemas"
+	"a2sv.org/hub/Domain/entity"
+	"a2sv.org/hub/Domain/repository"
+	"a2sv.org/hub/infrastructure/telegram_services"
+	"a2sv.org/hub/infrastructure/token_services"
+	"gorm.io/gorm"
+)
+
+const (
+	// TelegramLinkCodeDuration is how long a user has to send the code to the bot
+	TelegramLinkCodeDuration = 15 * time.Minute
+	// TelegramAuthMaxAge is how old Login Widget data may be when it reaches us
+	TelegramAuthMaxAge = 10 * time.Minute
+
+	telegramLinkCodeLength = 10
+)
+
+// Bot replies to messages sent to the webhook
+const (
+	telegramReplyLinked  = "Your Telegram account is now linked to A2SV Hub as %s."
+	telegramReplyInvalid = "This code is invalid or expired. Create a new one on your A2SV Hub profile."
+	telegramReplyTaken   = "This Telegram account is already linked to another A2SV Hub user."
+	telegramReplyHelp    = "To link your Telegram account, create a link code on your A2SV Hub profile and send it here."
+)
+
+// TelegramUseCase links verified Telegram accounts to users and signs them in
+// with the account. An account is verified either by a one-time code the user
+// sends to the bot, or by the hash of the Telegram Login Widget.
+type TelegramUseCase struct {
+	telegramRepo repository.TelegramRepository
+	tokens       *TokenUseCase
+	history      *LoginHistoryUseCase
+	twoFactor    *TwoFactorUseCase
+}
+
+func NewTelegramUseCase(
+	telegramRepo repository.TelegramRepository,
+	tokens *TokenUseCase,
+	history *LoginHistoryUseCase,
+	twoFactor *TwoFactorUseCase,
+) *TelegramUseCase {
+	return &TelegramUseCase{
+		telegramRepo: telegramRepo,
+		tokens:       tokens,
+		history:      history,
+		twoFactor:    twoFactor,
+	}
+}
+
+// CreateLinkCode creates a one-time code the user sends to the bot from the
+// Telegram account to link
+func (u *TelegramUseCase) CreateLinkCode(userID uint) (*schemas.TelegramLinkCodeResponse, error) {
+	if os.Getenv("TELEGRAM_BOT_TOKEN") == "" {
+		return nil, ErrTelegramNotConfigured
+	}
+	code, err := token_services.GenerateConfirmationToken(telegramLinkCodeLength)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	linkCode := &entity.TelegramLinkCode{
+		UserID:    userID,
+		CodeHash:  token_services.HashToken(code),
+		ExpiresAt: now.Add(TelegramLinkCodeDuration),
+		CreatedAt: now,
+	}
+	if err := u.telegramRepo.CreateLinkCode(linkCode); err != nil {
+		return nil, err
+	}
+
+	response := &schemas.TelegramLinkCodeResponse{
+		Code:      code,
+		ExpiresAt: linkCode.ExpiresAt,
+	}
+	if bot := os.Getenv("TELEGRAM_BOT_USERNAME"); bot != "" {
+		response.Link = "https://t.me/" + strings.TrimPrefix(bot, "@") + "?start=" + code
+	}
+	return response, nil
+}
+
+// VerifyWebhookSecret reports whether a webhook call carries the secret token
+// the webhook was registered with
+func (u *TelegramUseCase) VerifyWebhookSecret(secret string) bool {
+	expected := os.Getenv("TELEGRAM_WEBHOOK_SECRET")
+	return expected != "" && subtle.ConstantTimeCompare([]byte(secret), []byte(expected)) == 1
+}
+
+// HandleBotUpdate links the sender of a "/start CODE" message, or of a bare
+// code, to the user who created the code. It returns the bot's reply, or nil
+// when the update needs none.
+func (u *TelegramUseCase) HandleBotUpdate(update *telegram_services.Update) (*telegram_services.SendMessage, error) {
+	message := update.Message
+	if message == nil || message.From == nil || message.From.IsBot || message.Chat.Type != "private" {
+		return nil, nil
+	}
+
+	code := ""
+	fields := strings.Fields(message.Text)
+	switch {
+	case len(fields) == 2 && fields[0] == "/start":
+		code = fields[1]
+	case len(fields) == 1 && !strings.HasPrefix(fields[0], "/"):
+		code = fields[0]
+	}
+	if code == "" {
+		return telegram_services.Reply(message.Chat.ID, telegramReplyHelp), nil
+	}
+
+	linkCode, err := u.telegramRepo.UseLinkCode(token_services.HashToken(code), time.Now())
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return telegram_services.Reply(message.Chat.ID, telegramReplyInvalid), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := u.link(linkCode.UserID, message.From.ID, message.From.Username)
+	switch {
+	case errors.Is(err, ErrTelegramAccountTaken):
+		return telegram_services.Reply(message.Chat.ID, telegramReplyTaken), nil
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		// The user was deleted after creating the code
+		return telegram_services.Reply(message.Chat.ID, telegramReplyInvalid), nil
+	case err != nil:
+		return nil, err
+	}
+	return telegram_services.Reply(message.Chat.ID, fmt.Sprintf(telegramReplyLinked, user.Name)), nil
+}
+
+// LinkWithWidget links the Telegram account that signed the Login Widget data
+func (u *TelegramUseCase) LinkWithWidget(userID uint, input *schemas.TelegramAuthRequest) (*schemas.TelegramAccountResponse, error) {
+	if err := u.verifyWidget(input); err != nil {
+		return nil, err
+	}
+	user, err := u.link(userID, input.ID, input.Username)
+	if err != nil {
+		return nil, err
+	}
+	return &schemas.TelegramAccountResponse{
+		TelegramUID:      user.TelegramUID,
+		TelegramUsername: user.TelegramUsername,
+		LinkedAt:         *user.TelegramLinkedAt,
+	}, nil
+}
+
+// Unlink removes the user's Telegram account, which then no longer signs in
+func (u *TelegramUseCase) Unlink(userID uint) error {
+	return u.telegramRepo.Unlink(userID)
+}
+
+// Login signs in the user linked to the Telegram account that signed the
+// Login Widget data
+func (u *TelegramUseCase) Login(input *schemas.TelegramAuthRequest, client LoginClient) (*schemas.TokenPairResponse, error) {
+	if err := u.verifyWidget(input); err != nil {
+		if errors.Is(err, ErrInvalidTelegramAuth) {
+			u.history.RecordFailure(nil, "", entity.LoginMethodTelegram, "invalid Telegram login data", client)
+		}
+		return nil, err
+	}
+
+	user, err := u.telegramRepo.GetLinkedUser(strconv.FormatInt(input.ID, 10))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		u.history.RecordFailure(nil, "", entity.LoginMethodTelegram, "Telegram account not linked", client)
+		return nil, ErrTelegramNotLinked
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	challenge, err := u.twoFactor.BeginLogin(user, entity.LoginMethodTelegram)
+	if err != nil {
+		return nil, err
+	}
+	if challenge != nil {
+		return &schemas.TokenPairResponse{TwoFactor: challenge}, nil
+	}
+	tokens, err := u.tokens.IssueTokens(user, client)
+	if err != nil {
+		return nil, err
+	}
+	u.history.RecordSuccess(user, entity.LoginMethodTelegram, client)
+	return tokens, nil
+}
+
+func (u *TelegramUseCase) verifyWidget(input *schemas.TelegramAuthRequest) error {
+	botToken := os.Getenv("TELEGRAM_BOT_TOKEN")
+	if botToken == "" {
+		return ErrTelegramNotConfigured
+	}
+	err := telegram_services.VerifyLoginData(botToken, &telegram_services.LoginData{
+		ID:        input.ID,
+		FirstName: input.FirstName,
+		LastName:  input.LastName,
+		Username:  input.Username,
+		PhotoURL:  input.PhotoURL,
+		AuthDate:  input.AuthDate,
+		Hash:      input.Hash,
+	}, TelegramAuthMaxAge)
+	if err != nil {
+		return ErrInvalidTelegramAuth
+	}
+	return nil
+}
+
+// link binds the verified Telegram account to the user, refusing an account
+// that is already bound to someone else, and returns the updated user
+func (u *TelegramUseCase) link(userID uint, telegramID int64, username string) (*entity.User, error) {
+	uid := strconv.FormatInt(telegramID, 10)
+	owner, err := u.telegramRepo.GetLinkedUser(uid)
+	if err == nil && owner.ID != userID {
+		return nil, ErrTelegramAccountTaken
+	}
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	var telegramUsername *string
+	if username != "" {
+		handle := "@" + username
+		telegramUsername = &handle
+	}
+	// The partial unique index on users.telegram_uid backs this check up
+	// against a concurrent link of the same account
+	if err := u.telegramRepo.Link(userID, uid, telegramUsername, time.Now()); err != nil {
+		return nil, err
+	}
+	return u.telegramRepo.GetLinkedUser(uid)
+}
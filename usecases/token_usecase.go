@@ -0,0 +1,290 @@
+package usecases
+
+import (
+	"errors"
+	"time"
+
+	"a2sv.org/hub/Delivery/http/schemas"
+	"a2sv.org/hub/Domain/entity"
+	"a2sv.org/hub/Domain/repository"
+	"a2sv.org/hub/infrastructure/token_services"
+	"gorm.io/gorm"
+)
+
+// sessionTouchInterval limits how often last_seen_at is written for a busy session
+const sessionTouchInterval = 5 * time.Minute
+
+// TokenUseCase issues access/refresh token pairs and manages the login
+// sessions they belong to
+type TokenUseCase struct {
+	userRepo         repository.UserRepository
+	refreshTokenRepo repository.RefreshTokenRepository
+	revokedTokenRepo repository.RevokedTokenRepository
+	loginSessionRepo repository.LoginSessionRepository
+	apiTokenRepo     repository.APITokenRepository
+	scope            GroupScopeChecker
+	keys             token_services.KeySource
+}
+
+func NewTokenUseCase(
+	userRepo repository.UserRepository,
+	refreshTokenRepo repository.RefreshTokenRepository,
+	revokedTokenRepo repository.RevokedTokenRepository,
+	loginSessionRepo repository.LoginSessionRepository,
+	apiTokenRepo repository.APITokenRepository,
+	scope GroupScopeChecker,
+	keys token_services.KeySource,
+) *TokenUseCase {
+	return &TokenUseCase{
+		userRepo:         userRepo,
+		refreshTokenRepo: refreshTokenRepo,
+		revokedTokenRepo: revokedTokenRepo,
+		loginSessionRepo: loginSessionRepo,
+		apiTokenRepo:     apiTokenRepo,
+		scope:            scope,
+		keys:             keys,
+	}
+}
+
+// IssueTokens starts a new login session for the user on the client's device
+func (u *TokenUseCase) IssueTokens(user *entity.User, client LoginClient) (*schemas.TokenPairResponse, error) {
+	if user.Inactive {
+		return nil, ErrAccountInactive
+	}
+	familyID, err := token_services.GenerateConfirmationToken(32)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	if err := u.loginSessionRepo.Create(&entity.LoginSession{
+		UserID:     user.ID,
+		FamilyID:   familyID,
+		IP:         client.IP,
+		Device:     client.Device,
+		CreatedAt:  now,
+		LastSeenAt: now,
+		ExpiresAt:  now.Add(token_services.RefreshTokenDuration),
+	}); err != nil {
+		return nil, err
+	}
+	return u.issueTokens(user, familyID)
+}
+
+// Refresh rotates a refresh token and returns a new token pair.
+// Presenting an already rotated token revokes the whole session, since it
+// means the token was copied.
+func (u *TokenUseCase) Refresh(refreshToken string) (*schemas.TokenPairResponse, error) {
+	stored, err := u.refreshTokenRepo.GetByHash(token_services.HashToken(refreshToken))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInvalidRefreshToken
+		}
+		return nil, err
+	}
+
+	now := time.Now()
+	if stored.RevokedAt != nil || now.After(stored.ExpiresAt) {
+		return nil, ErrInvalidRefreshToken
+	}
+	if stored.UsedAt != nil {
+		if err := u.endSession(stored.FamilyID, now); err != nil {
+			return nil, err
+		}
+		return nil, ErrInvalidRefreshToken
+	}
+	if err := u.refreshTokenRepo.MarkUsed(stored.ID, now); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInvalidRefreshToken
+		}
+		return nil, err
+	}
+
+	user, err := u.userRepo.GetUserByID(stored.UserID)
+	if err != nil || user.Inactive {
+		return nil, ErrInvalidRefreshToken
+	}
+	if err := u.loginSessionRepo.Extend(stored.FamilyID, now.Add(token_services.RefreshTokenDuration), now); err != nil {
+		return nil, err
+	}
+	return u.issueTokens(user, stored.FamilyID)
+}
+
+// Logout ends the session of the presented access token and/or refresh token.
+// Either may be nil/empty.
+func (u *TokenUseCase) Logout(claims *entity.Claims, refreshToken string) error {
+	now := time.Now()
+	if claims != nil {
+		if err := u.RevokeAccessToken(claims); err != nil {
+			return err
+		}
+		if claims.SessionID != "" {
+			if err := u.endSession(claims.SessionID, now); err != nil {
+				return err
+			}
+		}
+	}
+	if refreshToken != "" {
+		stored, err := u.refreshTokenRepo.GetByHash(token_services.HashToken(refreshToken))
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrInvalidRefreshToken
+			}
+			return err
+		}
+		if claims != nil && stored.UserID != claims.ID {
+			return ErrInvalidRefreshToken
+		}
+		if err := u.endSession(stored.FamilyID, now); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RevokeAccessToken denylists a single access token until it expires
+func (u *TokenUseCase) RevokeAccessToken(claims *entity.Claims) error {
+	if claims.Id == "" {
+		return nil
+	}
+	now := time.Now()
+	if err := u.revokedTokenRepo.DeleteExpired(now); err != nil {
+		return err
+	}
+	return u.revokedTokenRepo.Create(&entity.RevokedToken{
+		JTI:       claims.Id,
+		ExpiresAt: time.Unix(claims.ExpiresAt, 0),
+		CreatedAt: now,
+	})
+}
+
+// RevokeAllForUser signs the user out of every session
+func (u *TokenUseCase) RevokeAllForUser(userID uint) error {
+	now := time.Now()
+	if err := u.refreshTokenRepo.RevokeAllByUserID(userID, now); err != nil {
+		return err
+	}
+	return u.loginSessionRepo.RevokeAllByUserID(userID, now)
+}
+
+// RevokeCredentials signs the user out of every session and revokes their
+// API tokens, for when their password changes and may have leaked
+func (u *TokenUseCase) RevokeCredentials(userID uint) error {
+	if err := u.apiTokenRepo.DeleteByUserID(userID); err != nil {
+		return err
+	}
+	return u.RevokeAllForUser(userID)
+}
+
+// IsRevoked reports whether an access token was denylisted or its session ended.
+// It also records that the session was seen.
+func (u *TokenUseCase) IsRevoked(claims *entity.Claims) (bool, error) {
+	if claims.Id != "" {
+		revoked, err := u.revokedTokenRepo.IsRevoked(claims.Id)
+		if err != nil || revoked {
+			return revoked, err
+		}
+	}
+	if claims.SessionID == "" {
+		return false, nil
+	}
+	revoked, err := u.refreshTokenRepo.IsFamilyRevoked(claims.SessionID)
+	if err != nil || revoked {
+		return revoked, err
+	}
+	now := time.Now()
+	_ = u.loginSessionRepo.Touch(claims.SessionID, now, now.Add(-sessionTouchInterval))
+	return false, nil
+}
+
+// ListSessions returns the active sessions of a user, most recently seen first.
+// currentSessionID marks the caller's own session. Actors other than the
+// user must manage the user's group.
+func (u *TokenUseCase) ListSessions(actorID, userID uint, currentSessionID string) ([]*schemas.LoginSessionResponse, error) {
+	if err := u.checkSessionScope(actorID, userID); err != nil {
+		return nil, err
+	}
+	sessions, err := u.loginSessionRepo.ListActiveByUserID(userID, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	responses := make([]*schemas.LoginSessionResponse, 0, len(sessions))
+	for _, s := range sessions {
+		responses = append(responses, &schemas.LoginSessionResponse{
+			ID:         s.ID,
+			IP:         s.IP,
+			Device:     s.Device,
+			CreatedAt:  s.CreatedAt,
+			LastSeenAt: s.LastSeenAt,
+			ExpiresAt:  s.ExpiresAt,
+			Current:    currentSessionID != "" && s.FamilyID == currentSessionID,
+		})
+	}
+	return responses, nil
+}
+
+// RevokeSession ends one of the user's sessions. Its refresh token stops
+// working and its access tokens are rejected from the next request on.
+func (u *TokenUseCase) RevokeSession(actorID, userID, sessionID uint) error {
+	if err := u.checkSessionScope(actorID, userID); err != nil {
+		return err
+	}
+	session, err := u.loginSessionRepo.GetByID(sessionID)
+	if err != nil {
+		return err
+	}
+	if session.UserID != userID || session.RevokedAt != nil {
+		return gorm.ErrRecordNotFound
+	}
+	return u.endSession(session.FamilyID, time.Now())
+}
+
+// RevokeSessions ends every session of the user
+func (u *TokenUseCase) RevokeSessions(actorID, userID uint) error {
+	if err := u.checkSessionScope(actorID, userID); err != nil {
+		return err
+	}
+	return u.RevokeAllForUser(userID)
+}
+
+func (u *TokenUseCase) checkSessionScope(actorID, userID uint) error {
+	if actorID == userID {
+		return nil
+	}
+	return u.scope.CheckUserScope(actorID, userID)
+}
+
+// endSession revokes a refresh token family and the session it belongs to
+func (u *TokenUseCase) endSession(familyID string, now time.Time) error {
+	if err := u.refreshTokenRepo.RevokeFamily(familyID, now); err != nil {
+		return err
+	}
+	return u.loginSessionRepo.RevokeByFamilyID(familyID, now)
+}
+
+func (u *TokenUseCase) issueTokens(user *entity.User, familyID string) (*schemas.TokenPairResponse, error) {
+	refreshToken, refreshTokenHash, err := token_services.GenerateOpaqueToken()
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	if err := u.refreshTokenRepo.Create(&entity.RefreshToken{
+		UserID:    user.ID,
+		FamilyID:  familyID,
+		TokenHash: refreshTokenHash,
+		ExpiresAt: now.Add(token_services.RefreshTokenDuration),
+		CreatedAt: now,
+	}); err != nil {
+		return nil, err
+	}
+
+	accessToken, err := token_services.CreateSessionJWTToken(user, u.keys, token_services.AccessTokenDuration, familyID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &schemas.TokenPairResponse{
+		Token:        accessToken,
+		RefreshToken: refreshToken,
+		ExpiresIn:    int(token_services.AccessTokenDuration.Seconds()),
+	}, nil
+}
@@ -0,0 +1,463 @@
+package usecases
+
+import (
+	"crypto/rand"
+	"encoding/base32"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"a2sv.org/hub/Delivery/http/schemas"
+	"a2sv.org/hub/Domain/entity"
+	"a2sv.org/hub/Domain/repository"
+	"a2sv.org/hub/infrastructure/token_services"
+	"a2sv.org/hub/infrastructure/totp_services"
+	"gorm.io/gorm"
+)
+
+const (
+	// TwoFactorChallengeDuration is how long a login waits for its second factor
+	TwoFactorChallengeDuration = 5 * time.Minute
+
+	maxTwoFactorAttempts = 5
+	recoveryCodeCount    = 10
+)
+
+// TwoFactorUseCase handles TOTP enrollment, recovery codes and the second step of logins
+type TwoFactorUseCase struct {
+	twoFactorRepo      repository.TwoFactorRepository
+	userRepo           repository.UserRepository
+	rolePermissionRepo repository.RolePermissionRepository
+	tokens             *TokenUseCase
+	history            *LoginHistoryUseCase
+	lockout            *LockoutUseCase
+	audit              *AuditUseCase
+	encryption         *EncryptionUseCase
+}
+
+func NewTwoFactorUseCase(
+	twoFactorRepo repository.TwoFactorRepository,
+	userRepo repository.UserRepository,
+	rolePermissionRepo repository.RolePermissionRepository,
+	tokens *TokenUseCase,
+	history *LoginHistoryUseCase,
+	lockout *LockoutUseCase,
+	audit *AuditUseCase,
+	encryption *EncryptionUseCase,
+) *TwoFactorUseCase {
+	return &TwoFactorUseCase{
+		twoFactorRepo:      twoFactorRepo,
+		userRepo:           userRepo,
+		rolePermissionRepo: rolePermissionRepo,
+		tokens:             tokens,
+		history:            history,
+		lockout:            lockout,
+		audit:              audit,
+		encryption:         encryption,
+	}
+}
+
+// BeginLogin starts the second step of a login whose first factor succeeded.
+// It returns nil when the user has 2FA off and the role does not require it,
+// in which case the caller issues tokens right away.
+func (u *TwoFactorUseCase) BeginLogin(user *entity.User, method string) (*schemas.TwoFactorChallengeResponse, error) {
+	if user.Inactive {
+		return nil, ErrAccountInactive
+	}
+	enabled, err := u.isEnabled(user.ID)
+	if err != nil {
+		return nil, err
+	}
+	if !enabled {
+		required, err := u.RoleRequiresTwoFactor(user.RoleID)
+		if err != nil {
+			return nil, err
+		}
+		if !required {
+			return nil, nil
+		}
+	}
+
+	token, tokenHash, err := token_services.GenerateOpaqueToken()
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	err = u.twoFactorRepo.CreateChallenge(&entity.TwoFactorChallenge{
+		UserID:    user.ID,
+		TokenHash: tokenHash,
+		Method:    method,
+		ExpiresAt: now.Add(TwoFactorChallengeDuration),
+		CreatedAt: now,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &schemas.TwoFactorChallengeResponse{
+		ChallengeToken: token,
+		SetupRequired:  !enabled,
+		ExpiresIn:      int(TwoFactorChallengeDuration.Seconds()),
+	}, nil
+}
+
+// SetupLogin hands out a TOTP secret to a user who must enroll before the
+// login can complete
+func (u *TwoFactorUseCase) SetupLogin(challengeToken string) (*schemas.TwoFactorEnrollmentResponse, error) {
+	challenge, err := u.activeChallenge(challengeToken)
+	if err != nil {
+		return nil, err
+	}
+	var enrollment *schemas.TwoFactorEnrollmentResponse
+	err = u.trackLoginChange(challenge.UserID, auditRouteLogin2FASetup, "", func() error {
+		enrollment, err = u.Enroll(challenge.UserID)
+		return err
+	})
+	return enrollment, err
+}
+
+// CompleteLogin verifies the code of a challenge and issues the session.
+// If the user was enrolling, the code confirms the secret and the response
+// carries the new recovery codes.
+func (u *TwoFactorUseCase) CompleteLogin(challengeToken, code string, client LoginClient) (*schemas.TwoFactorLoginResponse, error) {
+	challenge, err := u.activeChallenge(challengeToken)
+	if err != nil {
+		return nil, err
+	}
+	user, err := u.userRepo.GetUserByID(challenge.UserID)
+	if err != nil {
+		return nil, ErrInvalidChallenge
+	}
+	if err := u.lockout.Check(user.Email, client.IP); err != nil {
+		return nil, err
+	}
+
+	twoFactor, err := u.twoFactorRepo.GetByUserID(user.ID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrTwoFactorNotEnrolled
+		}
+		return nil, err
+	}
+
+	enrolling := twoFactor.EnabledAt == nil
+	var ok bool
+	if enrolling {
+		ok, err = u.verifyTOTP(twoFactor, code)
+	} else {
+		ok, err = u.verifyCode(twoFactor, code)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		if _, err := u.twoFactorRepo.AddChallengeAttempt(challenge.ID); err != nil {
+			return nil, err
+		}
+		u.history.RecordFailure(user, user.Email, challenge.Method, "wrong two-factor code", client)
+		u.lockout.RecordFailure(user.Email, client.IP)
+		return nil, ErrInvalidTwoFactorCode
+	}
+
+	now := time.Now()
+	if err := u.twoFactorRepo.MarkChallengeUsed(challenge.ID, now); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInvalidChallenge
+		}
+		return nil, err
+	}
+
+	response := &schemas.TwoFactorLoginResponse{}
+	if enrolling {
+		err := u.trackLoginChange(user.ID, auditRouteLogin2FA, client.IP, func() error {
+			return u.twoFactorRepo.Enable(user.ID, now)
+		})
+		if err != nil {
+			return nil, err
+		}
+		if response.RecoveryCodes, err = u.newRecoveryCodes(user.ID); err != nil {
+			return nil, err
+		}
+	}
+
+	if response.TokenPairResponse, err = u.tokens.IssueTokens(user, client); err != nil {
+		return nil, err
+	}
+	u.history.RecordSuccess(user, challenge.Method, client)
+	u.lockout.RecordSuccess(user.Email)
+	return response, nil
+}
+
+// Status reports whether the user has 2FA on and whether the role requires it
+func (u *TwoFactorUseCase) Status(userID uint) (*schemas.TwoFactorStatusResponse, error) {
+	user, err := u.userRepo.GetUserByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	enabled, err := u.isEnabled(userID)
+	if err != nil {
+		return nil, err
+	}
+	required, err := u.RoleRequiresTwoFactor(user.RoleID)
+	if err != nil {
+		return nil, err
+	}
+	left, err := u.twoFactorRepo.CountRecoveryCodes(userID)
+	if err != nil {
+		return nil, err
+	}
+	return &schemas.TwoFactorStatusResponse{
+		Enabled:           enabled,
+		Required:          required,
+		RecoveryCodesLeft: left,
+	}, nil
+}
+
+// Enroll creates a new pending TOTP secret, replacing an unconfirmed one
+func (u *TwoFactorUseCase) Enroll(userID uint) (*schemas.TwoFactorEnrollmentResponse, error) {
+	user, err := u.userRepo.GetUserByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	twoFactor, err := u.twoFactorRepo.GetByUserID(userID)
+	switch {
+	case err == nil && twoFactor.EnabledAt != nil:
+		return nil, ErrTwoFactorEnabled
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		twoFactor = &entity.TwoFactor{UserID: userID}
+	case err != nil:
+		return nil, err
+	}
+
+	secret, err := totp_services.GenerateSecret()
+	if err != nil {
+		return nil, err
+	}
+	if twoFactor.Secret, err = u.encryption.EncryptIfConfigured(entity.ColumnTwoFactorSecret, secret); err != nil {
+		return nil, err
+	}
+	twoFactor.LastUsedStep = 0
+	if err := u.twoFactorRepo.Save(twoFactor); err != nil {
+		return nil, err
+	}
+	return &schemas.TwoFactorEnrollmentResponse{
+		Secret:          secret,
+		ProvisioningURI: totp_services.ProvisioningURI(secret, totpIssuer(), user.Email),
+	}, nil
+}
+
+// Confirm enables the pending secret with a first code and returns the recovery codes
+func (u *TwoFactorUseCase) Confirm(userID uint, code string) (*schemas.RecoveryCodesResponse, error) {
+	twoFactor, err := u.twoFactorRepo.GetByUserID(userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrTwoFactorNotEnrolled
+		}
+		return nil, err
+	}
+	if twoFactor.EnabledAt != nil {
+		return nil, ErrTwoFactorEnabled
+	}
+	ok, err := u.verifyTOTP(twoFactor, code)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, ErrInvalidTwoFactorCode
+	}
+
+	if err := u.twoFactorRepo.Enable(userID, time.Now()); err != nil {
+		return nil, err
+	}
+	codes, err := u.newRecoveryCodes(userID)
+	if err != nil {
+		return nil, err
+	}
+	return &schemas.RecoveryCodesResponse{RecoveryCodes: codes}, nil
+}
+
+// RegenerateRecoveryCodes replaces every recovery code of the user
+func (u *TwoFactorUseCase) RegenerateRecoveryCodes(userID uint, code string) (*schemas.RecoveryCodesResponse, error) {
+	twoFactor, err := u.enabledTwoFactor(userID, code)
+	if err != nil {
+		return nil, err
+	}
+	codes, err := u.newRecoveryCodes(twoFactor.UserID)
+	if err != nil {
+		return nil, err
+	}
+	return &schemas.RecoveryCodesResponse{RecoveryCodes: codes}, nil
+}
+
+// Disable turns 2FA off, unless the user's role requires it
+func (u *TwoFactorUseCase) Disable(userID uint, code string) error {
+	user, err := u.userRepo.GetUserByID(userID)
+	if err != nil {
+		return err
+	}
+	required, err := u.RoleRequiresTwoFactor(user.RoleID)
+	if err != nil {
+		return err
+	}
+	if required {
+		return ErrTwoFactorRequired
+	}
+	if _, err := u.enabledTwoFactor(userID, code); err != nil {
+		return err
+	}
+	return u.twoFactorRepo.Delete(userID)
+}
+
+// RoleRequiresTwoFactor reports whether the role holds one of entity.TwoFactorPermissions
+func (u *TwoFactorUseCase) RoleRequiresTwoFactor(roleID uint) (bool, error) {
+	if roleID == 0 {
+		return false, nil
+	}
+	for _, permission := range entity.TwoFactorPermissions {
+		has, err := u.rolePermissionRepo.HasPermission(roleID, permission)
+		if err != nil {
+			return false, err
+		}
+		if has {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (u *TwoFactorUseCase) isEnabled(userID uint) (bool, error) {
+	twoFactor, err := u.twoFactorRepo.GetByUserID(userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return false, nil
+		}
+		return false, err
+	}
+	return twoFactor.EnabledAt != nil, nil
+}
+
+// enabledTwoFactor returns the user's enabled secret after checking the code
+func (u *TwoFactorUseCase) enabledTwoFactor(userID uint, code string) (*entity.TwoFactor, error) {
+	twoFactor, err := u.twoFactorRepo.GetByUserID(userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrTwoFactorNotEnrolled
+		}
+		return nil, err
+	}
+	if twoFactor.EnabledAt == nil {
+		return nil, ErrTwoFactorNotEnrolled
+	}
+	ok, err := u.verifyCode(twoFactor, code)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, ErrInvalidTwoFactorCode
+	}
+	return twoFactor, nil
+}
+
+// trackLoginChange audits a write to the user's two-factor row made during a
+// login, on an /api/auth/ route the audit middleware skips
+func (u *TwoFactorUseCase) trackLoginChange(userID uint, route, ip string, write func() error) error {
+	entry := AuditEntry{
+		ActorID:    &userID,
+		Method:     http.MethodPost,
+		Route:      route,
+		EntityType: "two_factors",
+		IP:         ip,
+	}
+	if twoFactor, err := u.twoFactorRepo.GetByUserID(userID); err == nil {
+		entry.EntityID = fmt.Sprint(twoFactor.ID)
+	}
+	return u.audit.Track(entry, func() (uint, error) {
+		if err := write(); err != nil {
+			return 0, err
+		}
+		twoFactor, err := u.twoFactorRepo.GetByUserID(userID)
+		if err != nil {
+			return 0, err
+		}
+		return twoFactor.ID, nil
+	})
+}
+
+func (u *TwoFactorUseCase) activeChallenge(challengeToken string) (*entity.TwoFactorChallenge, error) {
+	challenge, err := u.twoFactorRepo.GetChallengeByHash(token_services.HashToken(challengeToken))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInvalidChallenge
+		}
+		return nil, err
+	}
+	if challenge.UsedAt != nil || challenge.Attempts >= maxTwoFactorAttempts || time.Now().After(challenge.ExpiresAt) {
+		return nil, ErrInvalidChallenge
+	}
+	return challenge, nil
+}
+
+// verifyCode accepts a TOTP code or an unused recovery code
+func (u *TwoFactorUseCase) verifyCode(twoFactor *entity.TwoFactor, code string) (bool, error) {
+	ok, err := u.verifyTOTP(twoFactor, code)
+	if ok || err != nil {
+		return ok, err
+	}
+	err = u.twoFactorRepo.UseRecoveryCode(twoFactor.UserID, hashRecoveryCode(code), time.Now())
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// verifyTOTP accepts a TOTP code once; replaying a used code fails
+func (u *TwoFactorUseCase) verifyTOTP(twoFactor *entity.TwoFactor, code string) (bool, error) {
+	secret, err := u.encryption.Decrypt(entity.ColumnTwoFactorSecret, twoFactor.Secret)
+	if err != nil {
+		return false, err
+	}
+	step, ok := totp_services.Validate(secret, code, time.Now())
+	if !ok {
+		return false, nil
+	}
+	err = u.twoFactorRepo.UseStep(twoFactor.UserID, step)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// newRecoveryCodes replaces the user's recovery codes and returns them in clear
+func (u *TwoFactorUseCase) newRecoveryCodes(userID uint) ([]string, error) {
+	codes := make([]string, 0, recoveryCodeCount)
+	hashes := make([]string, 0, recoveryCodeCount)
+	for i := 0; i < recoveryCodeCount; i++ {
+		b := make([]byte, 7)
+		if _, err := rand.Read(b); err != nil {
+			return nil, err
+		}
+		raw := strings.ToLower(base32.StdEncoding.EncodeToString(b))[:10]
+		codes = append(codes, raw[:5]+"-"+raw[5:])
+		hashes = append(hashes, hashRecoveryCode(raw))
+	}
+	if err := u.twoFactorRepo.ReplaceRecoveryCodes(userID, hashes); err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// hashRecoveryCode ignores case, spaces and dashes so codes can be typed loosely
+func hashRecoveryCode(code string) string {
+	code = strings.ToLower(code)
+	code = strings.NewReplacer("-", "", " ", "").Replace(code)
+	return token_services.HashToken(code)
+}
+
+func totpIssuer() string {
+	if issuer := os.Getenv("TOTP_ISSUER"); issuer != "" {
+		return issuer
+	}
+	return "A2SV Hub"
+}
@@ -4,48 +4,80 @@ import (
 	"errors"
 	"fmt"
 	"os"
-	"time"
+	"strings"
 
 	"a2sv.org/hub/Delivery/http/schemas"
 	"a2sv.org/hub/Domain/entity"
 	"a2sv.org/hub/Domain/repository"
-	"a2sv.org/hub/infrastructure/email_services"
 	"a2sv.org/hub/infrastructure/password_services"
-	"a2sv.org/hub/infrastructure/token_services"
 	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
 )
 
 // UserUseCase defines methods for user business logic
 type UserUseCaseInterface interface {
-	Create(input *schemas.CreateUserRequest) (*schemas.UserResponse, error)
+	Create(actorID uint, input *schemas.CreateUserRequest) (*schemas.UserResponse, error)
 	GetByID(id uint) (*schemas.UserResponse, error)
 	GetByEmail(email string) (*schemas.UserResponse, error)
-	Update(uid uint, input *schemas.UpdateUserRequest) error
-	Delete(id uint) error
+	Update(actorID, uid uint, input *schemas.UpdateUserRequest) error
+	Delete(actorID, id uint) error
+	Deactivate(actorID, id uint) error
+	Reactivate(actorID, id uint) error
+	ListDeleted(page, pageSize int) (*schemas.UserListResponse, error)
+	Restore(actorID, id uint) error
+	Purge(actorID, id uint) error
 	List(query *schemas.UserListQuery) (*schemas.UserListResponse, error)
-	Login(email, password string) (*schemas.LoginResponse, error)
+	Login(email, password string, client LoginClient) (*schemas.LoginResponse, error)
 }
 
 // TODO: Implement the caching in the user and other usecase 
 // UserUseCase implements UserUseCase
 type UserUseCase struct {
-	userRepo repository.UserRepository
+	userRepo           repository.UserRepository
+	rolePermissionRepo repository.RolePermissionRepository
+	scope              GroupScopeChecker
+	tokens             *TokenUseCase
+	history            *LoginHistoryUseCase
+	lockout            *LockoutUseCase
+	twoFactor          *TwoFactorUseCase
+	files              *FileUseCase
 }
 
 // NewUserUseCase creates a new UserUseCase instance
-func NewUserUseCase(userRepo repository.UserRepository) *UserUseCase {
+func NewUserUseCase(userRepo repository.UserRepository, rolePermissionRepo repository.RolePermissionRepository, scope GroupScopeChecker, tokens *TokenUseCase, history *LoginHistoryUseCase, lockout *LockoutUseCase, twoFactor *TwoFactorUseCase, files *FileUseCase) *UserUseCase {
 	return &UserUseCase{
-		userRepo: userRepo,
+		userRepo:           userRepo,
+		rolePermissionRepo: rolePermissionRepo,
+		scope:              scope,
+		tokens:             tokens,
+		history:            history,
+		lockout:            lockout,
+		twoFactor:          twoFactor,
+		files:              files,
 	}
 }
 
 // Create creates a new user
-func (u *UserUseCase) Create(input *schemas.CreateUserRequest) (*schemas.UserResponse, error) {
+func (u *UserUseCase) Create(actorID uint, input *schemas.CreateUserRequest) (*schemas.UserResponse, error) {
+	// The new user must land in a group the actor manages
+	if err := u.scope.CheckGroupScope(actorID, input.GroupID); err != nil {
+		return nil, err
+	}
+	// Nobody can hand out a role more powerful than their own
+	if input.RoleID != nil {
+		if err := checkRoleGrant(u.userRepo, u.rolePermissionRepo, actorID, *input.RoleID); err != nil {
+			return nil, err
+		}
+	}
+
 	// Check if email already exists
 	existingUser, err := u.userRepo.GetUserByEmail(input.Email)
 	if err == nil && existingUser != nil {
 		return nil, errors.New("email already exists")
 	}
+	if err := checkDeletedUserEmail(u.userRepo, input.Email); err != nil {
+		return nil, err
+	}
 
 	// Create user entity from input
 	user := &entity.User{
@@ -78,12 +110,6 @@ func (u *UserUseCase) Create(input *schemas.CreateUserRequest) (*schemas.UserRes
 	if input.Phone != nil {
 		user.Phone = *input.Phone
 	}
-	if input.TelegramUsername != nil {
-		user.TelegramUsername = input.TelegramUsername
-	}
-	if input.TelegramUID != nil {
-		user.TelegramUID = *input.TelegramUID
-	}
 	if input.Leetcode != nil {
 		user.Leetcode = input.Leetcode
 	}
@@ -134,8 +160,7 @@ func (u *UserUseCase) Create(input *schemas.CreateUserRequest) (*schemas.UserRes
 	}
 
 	// Send welcome email with password
-	emailContent := fmt.Sprintf("Welcome to A2SV Hub!\n\nYour account has been created successfully.\nYour temporary password is: %s\n\nPlease change your password after logging in.", userPassword)
-	if err := email_services.SendEmail(user.Email, "Welcome to A2SV Hub", emailContent, "yene-hub-ls0y.onrender.com/api/auth/login"); err != nil {
+	if err := sendWelcomeEmail(user.Email, userPassword); err != nil {
 		// Log the error but don't fail the request
 		fmt.Fprintf(os.Stderr, "Failed to send welcome email: %v\n", err)
 	}
@@ -163,17 +188,53 @@ func (u *UserUseCase) GetByEmail(email string) (*schemas.UserResponse, error) {
 }
 
 // Update updates a user
-func (u *UserUseCase) Update(uid uint, input *schemas.UpdateUserRequest) error {
+func (u *UserUseCase) Update(actorID, uid uint, input *schemas.UpdateUserRequest) error {
 	user, err := u.userRepo.GetUserByID(uid)
 	if err != nil {
 		return err
 	}
 
+	// Users may edit themselves; anyone else must manage the user's group and
+	// hold every permission of the user's role, so nobody can take over the
+	// account of a co-HOA or a more powerful user by setting their password.
+	// Moving a user requires managing the destination group too.
+	if actorID != uid {
+		if err := u.scope.CheckGroupScope(actorID, user.GroupID); err != nil {
+			return err
+		}
+		if err := checkRoleGrant(u.userRepo, u.rolePermissionRepo, actorID, user.RoleID); err != nil {
+			return err
+		}
+	}
+	if input.GroupID != nil {
+		if err := u.scope.CheckGroupScope(actorID, input.GroupID); err != nil {
+			return err
+		}
+	}
+	// Nobody, including the user themselves, can be given a role more
+	// powerful than the actor's
+	if input.RoleID != nil && *input.RoleID != user.RoleID {
+		if err := checkRoleGrant(u.userRepo, u.rolePermissionRepo, actorID, *input.RoleID); err != nil {
+			return err
+		}
+	}
+
+	// Changing your own password must go through the current password check
+	if input.Password != nil && actorID == uid {
+		return ErrPasswordChangeSelf
+	}
+
 	// Apply only non-nil fields
 	if input.Name != nil {
 		user.Name = *input.Name
 	}
-	if input.Email != nil {
+	if input.Email != nil && *input.Email != user.Email {
+		if _, err := u.userRepo.GetUserByEmail(*input.Email); err == nil {
+			return ErrEmailAlreadyRegistered
+		}
+		if err := checkDeletedUserEmail(u.userRepo, *input.Email); err != nil {
+			return err
+		}
 		user.Email = *input.Email
 	}
 	if input.RoleID != nil {
@@ -200,9 +261,6 @@ func (u *UserUseCase) Update(uid uint, input *schemas.UpdateUserRequest) error {
 	if input.Phone != nil {
 		user.Phone = *input.Phone
 	}
-	if input.TelegramUsername != nil {
-		user.TelegramUsername = input.TelegramUsername
-	}
 	if input.Leetcode != nil {
 		user.Leetcode = input.Leetcode
 	}
@@ -233,17 +291,162 @@ func (u *UserUseCase) Update(uid uint, input *schemas.UpdateUserRequest) error {
 	if input.PreferredLanguage != nil {
 		user.PreferredLanguage = *input.PreferredLanguage
 	}
+	if input.Password != nil {
+		hashedPassword, err := password_services.HashPassword(*input.Password)
+		if err != nil {
+			return err
+		}
+		user.Password = hashedPassword
+	}
 
 	// Save updated user
-	return u.userRepo.UpdateUser(user)
+	if err := u.userRepo.UpdateUser(user); err != nil {
+		return err
+	}
+
+	// A password set by an administrator ends the user's existing sessions
+	// and revokes their API tokens
+	if input.Password != nil {
+		return u.tokens.RevokeCredentials(user.ID)
+	}
+	return nil
+}
+
+// Delete soft deletes a user and ends their sessions. Their submissions,
+// attendance and stipends are kept, and the user can be restored.
+func (u *UserUseCase) Delete(actorID, id uint) error {
+	if actorID == id {
+		return ErrUserLifecycleSelf
+	}
+	user, err := u.userRepo.GetUserByID(id)
+	if err != nil {
+		return err
+	}
+	if err := u.checkManagedUser(actorID, user); err != nil {
+		return err
+	}
+	if err := u.userRepo.DeleteUser(id); err != nil {
+		return err
+	}
+	return u.tokens.RevokeAllForUser(id)
+}
+
+// Deactivate blocks a user from signing in and ends their sessions
+func (u *UserUseCase) Deactivate(actorID, id uint) error {
+	if actorID == id {
+		return ErrUserLifecycleSelf
+	}
+	user, err := u.userRepo.GetUserByID(id)
+	if err != nil {
+		return err
+	}
+	if err := u.checkManagedUser(actorID, user); err != nil {
+		return err
+	}
+	if err := u.userRepo.SetInactive(id, true); err != nil {
+		return err
+	}
+	return u.tokens.RevokeAllForUser(id)
+}
+
+// Reactivate lets a deactivated user sign in again. Erased users stay
+// deactivated.
+func (u *UserUseCase) Reactivate(actorID, id uint) error {
+	user, err := u.userRepo.GetUserByID(id)
+	if err != nil {
+		return err
+	}
+	if err := u.checkManagedUser(actorID, user); err != nil {
+		return err
+	}
+	if user.ErasedAt != nil {
+		return ErrUserErased
+	}
+	return u.userRepo.SetInactive(id, false)
+}
+
+// checkManagedUser requires the actor to manage the user's group and to hold
+// every permission of the user's role, so that nobody can deactivate, delete
+// or restore a co-HOA or a more powerful user
+func (u *UserUseCase) checkManagedUser(actorID uint, user *entity.User) error {
+	if err := u.scope.CheckGroupScope(actorID, user.GroupID); err != nil {
+		return err
+	}
+	return checkRoleGrant(u.userRepo, u.rolePermissionRepo, actorID, user.RoleID)
+}
+
+// ListDeleted retrieves one page of the deleted users
+func (u *UserUseCase) ListDeleted(page, pageSize int) (*schemas.UserListResponse, error) {
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 {
+		pageSize = 10
+	}
+	users, total, err := u.userRepo.ListDeletedUsers(page, pageSize)
+	if err != nil {
+		return nil, err
+	}
+	responses := make([]*schemas.UserResponse, 0, len(users))
+	for _, user := range users {
+		responses = append(responses, u.entityToResponse(user))
+	}
+	return &schemas.UserListResponse{
+		Data: responses,
+		Meta: schemas.PaginationMeta{
+			Page:       page,
+			PageSize:   pageSize,
+			Total:      total,
+			TotalPages: (total + pageSize - 1) / pageSize,
+		},
+	}, nil
 }
 
-// Delete deletes a user
-func (u *UserUseCase) Delete(id uint) error {
-	return u.userRepo.DeleteUser(id)
+// Restore undoes the deletion of a user. The user stays deactivated if they
+// were before, and signs in with their old password.
+func (u *UserUseCase) Restore(actorID, id uint) error {
+	user, err := u.userRepo.GetDeletedUser(id)
+	if err != nil {
+		return err
+	}
+	if err := u.checkManagedUser(actorID, user); err != nil {
+		return err
+	}
+	return u.userRepo.RestoreUser(id)
 }
 
-// List retrieves all users with pagination and filtering
+// Purge permanently removes a deleted user. Users whose submissions,
+// attendance or stipends are on record cannot be purged, so that history
+// stays intact.
+func (u *UserUseCase) Purge(actorID, id uint) error {
+	user, err := u.userRepo.GetDeletedUser(id)
+	if err != nil {
+		return err
+	}
+	if err := u.checkManagedUser(actorID, user); err != nil {
+		return err
+	}
+	hasHistory, err := u.userRepo.HasHistory(id)
+	if err != nil {
+		return err
+	}
+	if hasHistory {
+		return ErrUserHasHistory
+	}
+	if err := u.userRepo.PurgeUser(id); err != nil {
+		// Posts, comments and other records still reference the user
+		if strings.Contains(err.Error(), "foreign key") {
+			return ErrUserHasHistory
+		}
+		return err
+	}
+	// The photo and CV are no longer referenced
+	u.files.deleteStored(user.Photo)
+	u.files.deleteStored(user.CV)
+	return nil
+}
+
+// List retrieves one page of the users matching the query's filters, in its sort order
 func (u *UserUseCase) List(query *schemas.UserListQuery) (*schemas.UserListResponse, error) {
 	// Set default values if not provided
 	if query.Page < 1 {
@@ -252,6 +455,11 @@ func (u *UserUseCase) List(query *schemas.UserListQuery) (*schemas.UserListRespo
 	if query.PageSize < 1 {
 		query.PageSize = 10
 	}
+	// Deactivated users are only listed when asked for
+	if query.Inactive == nil {
+		active := false
+		query.Inactive = &active
+	}
 
 	// Get users with filters
 	users, total, err := u.userRepo.ListUsers(query)
@@ -260,7 +468,7 @@ func (u *UserUseCase) List(query *schemas.UserListQuery) (*schemas.UserListRespo
 	}
 
 	// Convert entities to responses
-	var responses []*schemas.UserResponse
+	responses := make([]*schemas.UserResponse, 0, len(users))
 	for _, user := range users {
 		responses = append(responses, u.entityToResponse(user))
 	}
@@ -268,37 +476,63 @@ func (u *UserUseCase) List(query *schemas.UserListQuery) (*schemas.UserListRespo
 	return &schemas.UserListResponse{
 		Data: responses,
 		Meta: schemas.PaginationMeta{
-			Page:     query.Page,
-			PageSize: query.PageSize,
-			Total:    total,
+			Page:       query.Page,
+			PageSize:   query.PageSize,
+			Total:      total,
+			TotalPages: (total + query.PageSize - 1) / query.PageSize,
 		},
 	}, nil
 }
 
 // Login handles user authentication
-func (u *UserUseCase) Login(email, password string) (*schemas.LoginResponse, error) {
+func (u *UserUseCase) Login(email, password string, client LoginClient) (*schemas.LoginResponse, error) {
+	if err := u.lockout.Check(email, client.IP); err != nil {
+		if errors.Is(err, ErrAccountLocked) {
+			u.history.RecordFailure(nil, email, entity.LoginMethodPassword, "locked out", client)
+		}
+		return nil, err
+	}
+
 	user, err := u.userRepo.GetUserByEmail(email)
 	if err != nil {
+		u.history.RecordFailure(nil, email, entity.LoginMethodPassword, "unknown email", client)
+		u.lockout.RecordFailure(email, client.IP)
 		return nil, errors.New("invalid credentials")
 	}
 
 	// Verify password
-	fmt.Println("Password:", password)
-	fmt.Println("Hashed Password:", user.Password)
-	fmt.Println("Password error:", bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password)) == nil)
 	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password)); err != nil {
+		u.history.RecordFailure(user, email, entity.LoginMethodPassword, "wrong password", client)
+		u.lockout.RecordFailure(email, client.IP)
 		return nil, errors.New("invalid credentials")
 	}
+	if user.Inactive {
+		u.history.RecordFailure(user, email, entity.LoginMethodPassword, "account deactivated", client)
+		return nil, ErrAccountInactive
+	}
 
-	// Generate JWT token
-	token, err := token_services.CreateJWTToken(user, os.Getenv("JWT_SECRET"), time.Hour*24)
+	// Users with 2FA get a challenge instead of tokens
+	challenge, err := u.twoFactor.BeginLogin(user, entity.LoginMethodPassword)
+	if err != nil {
+		return nil, err
+	}
+	if challenge != nil {
+		return &schemas.LoginResponse{TwoFactor: challenge}, nil
+	}
+	u.lockout.RecordSuccess(email)
+
+	// Issue a short-lived access token and a rotating refresh token
+	tokens, err := u.tokens.IssueTokens(user, client)
 	if err != nil {
 		return nil, fmt.Errorf("failed to generate token: %v", err)
 	}
+	u.history.RecordSuccess(user, entity.LoginMethodPassword, client)
 
 	return &schemas.LoginResponse{
-		Token: token,
-		User:  u.entityToResponse(user),
+		Token:        tokens.Token,
+		RefreshToken: tokens.RefreshToken,
+		ExpiresIn:    tokens.ExpiresIn,
+		User:         u.entityToResponse(user),
 	}, nil
 }
 
@@ -318,6 +552,7 @@ func (u *UserUseCase) entityToResponse(user *entity.User) *schemas.UserResponse
 		Email:     user.Email,
 		RoleID:    user.RoleID,
 		GroupID:   user.GroupID,
+		Inactive:  user.Inactive,
 		CreatedAt: user.CreatedAt,
 		UpdatedAt: user.UpdatedAt,
 
@@ -346,6 +581,33 @@ func (u *UserUseCase) entityToResponse(user *entity.User) *schemas.UserResponse
 		ShortBio:          &user.ShortBio,
 		PreferredLanguage: &user.PreferredLanguage,
 	}
+	if url := u.files.URL(user.Photo); url != "" {
+		response.Photo = &url
+	}
+	if url := u.files.URL(user.CV); url != "" {
+		response.CV = &url
+	}
+	if user.DeletedAt.Valid {
+		response.DeletedAt = &user.DeletedAt.Time
+	}
+	if user.TelegramLinkedAt != nil {
+		response.TelegramUID = &user.TelegramUID
+		response.TelegramLinkedAt = user.TelegramLinkedAt
+	}
 
 	return response
 }
+
+// checkDeletedUserEmail returns ErrEmailOfDeletedUser, naming the user, when a
+// deleted user has the email. Emails stay unique across deleted users so that
+// restoring one never clashes with a newer account.
+func checkDeletedUserEmail(userRepo repository.UserRepository, email string) error {
+	deleted, err := userRepo.GetDeletedUserByEmail(email)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return fmt.Errorf("%w (user %d)", ErrEmailOfDeletedUser, deleted.ID)
+}